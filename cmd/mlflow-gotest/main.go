@@ -0,0 +1,123 @@
+// Command mlflow-gotest records go test results as an MLflow run. It reads
+// go test output, JSON from go test -json or plain text, from standard input,
+// copies it to standard output and logs the test counts, failing tests and
+// benchmark results to a new run.
+//
+// Usage:
+//
+//	go test -json -bench . ./... | mlflow-gotest [-experiment name | -experiment-id id] [-run-name name] [-commit sha] [-tag key=value]...
+//
+// The tracking server is MLFLOW_TRACKING_URI, authenticated with
+// MLFLOW_TRACKING_TOKEN. Without -experiment or -experiment-id the
+// experiment comes from MLFLOW_EXPERIMENT_ID or MLFLOW_EXPERIMENT_NAME; an
+// experiment given by name is created if it does not exist. The commit
+// defaults to GITHUB_SHA, CI_COMMIT_SHA or the checkout in the current
+// directory. The command exits with status 1 if a test failed or the results
+// could not be logged.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/gotest"
+)
+
+// tagFlags collects repeated -tag key=value flags
+type tagFlags []mlflow.RunTag
+
+func (t *tagFlags) String() string { return fmt.Sprint(*t) }
+
+func (t *tagFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("tag must be key=value, got %q", value)
+	}
+	*t = append(*t, mlflow.RunTag{Key: key, Value: val})
+	return nil
+}
+
+func main() {
+	experimentName := flag.String("experiment", "", "name of the experiment, created if it does not exist")
+	experimentID := flag.String("experiment-id", "", "ID of the experiment")
+	runName := flag.String("run-name", gotest.DefaultRunName, "name of the run")
+	commit := flag.String("commit", "", "git commit to tag the run with (default: GITHUB_SHA, CI_COMMIT_SHA or git rev-parse HEAD)")
+	quiet := flag.Bool("q", false, "do not copy the test output to standard output")
+	var tags tagFlags
+	flag.Var(&tags, "tag", "tag the run with key=value (repeatable)")
+	flag.Parse()
+
+	var input io.Reader = os.Stdin
+	if !*quiet {
+		input = io.TeeReader(os.Stdin, os.Stdout)
+	}
+	report, err := gotest.Parse(input)
+	if err != nil {
+		fail(err)
+	}
+
+	client, err := mlflow.NewClientFromEnv()
+	if err != nil {
+		fail(err)
+	}
+	id, err := resolveExperiment(client, *experimentID, *experimentName)
+	if err != nil {
+		fail(err)
+	}
+	if *commit == "" {
+		*commit = detectCommit()
+	}
+
+	runID, err := gotest.Log(client, report, gotest.Options{
+		ExperimentID: id,
+		RunName:      *runName,
+		GitCommit:    *commit,
+		Tags:         tags,
+	})
+	if err != nil {
+		fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "mlflow-gotest: logged %d passed, %d failed, %d skipped and %d benchmark results to run %s in experiment %s\n",
+		report.Passed, report.Failed, report.Skipped, len(report.Benchmarks), runID, id)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+// resolveExperiment returns the ID of the experiment from the flags or the
+// MLflow environment variables
+func resolveExperiment(client *mlflow.Client, id, name string) (string, error) {
+	if id == "" && name == "" {
+		id, name = os.Getenv(mlflow.EnvExperimentID), os.Getenv(mlflow.EnvExperimentName)
+	}
+	if id != "" {
+		return id, nil
+	}
+	if name == "" {
+		return "", fmt.Errorf("an experiment is required: use -experiment, -experiment-id, %s or %s", mlflow.EnvExperimentID, mlflow.EnvExperimentName)
+	}
+	experiment, _, err := client.GetOrCreateExperiment(name, mlflow.ExperimentOptions{})
+	if err != nil {
+		return "", err
+	}
+	return experiment.ExperimentID, nil
+}
+
+func detectCommit() string {
+	for _, key := range []string{"GITHUB_SHA", "CI_COMMIT_SHA"} {
+		if sha := os.Getenv(key); sha != "" {
+			return sha
+		}
+	}
+	sha, _ := gotest.GitCommit("")
+	return sha
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "mlflow-gotest:", err)
+	os.Exit(1)
+}
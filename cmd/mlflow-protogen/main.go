@@ -0,0 +1,58 @@
+// Command mlflow-protogen generates the MLflow REST client surface in
+// pkg/mlflow from the vendored MLflow protobuf service definitions.
+//
+// Usage:
+//
+//	mlflow-protogen [-config protos/protogen.json] [-check]
+//
+// With -check nothing is written; the command exits with status 1 if the
+// vendored protos do not match protos/mlflow/SHA256SUMS or the generated
+// file is out of date.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/julpayne/mlflow-go-client/internal/protogen"
+)
+
+func main() {
+	configPath := flag.String("config", "protos/protogen.json", "path to the generator config")
+	check := flag.Bool("check", false, "report drift instead of writing the generated file")
+	verbose := flag.Bool("v", false, "list the generated client methods")
+	flag.Parse()
+
+	cfg, err := protogen.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var result *protogen.Result
+	if *check {
+		result, err = protogen.Check(cfg)
+	} else {
+		result, err = protogen.Write(cfg)
+	}
+	if result != nil {
+		for _, warning := range result.Warnings {
+			fmt.Fprintln(os.Stderr, "warning:", warning)
+		}
+		if *verbose {
+			for _, method := range result.Methods {
+				fmt.Println(method)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *check {
+		fmt.Printf("%s is up to date with the MLflow %s protos\n", cfg.OutputPath(), result.Version)
+	} else {
+		fmt.Printf("generated %d client methods from the MLflow %s protos into %s\n", len(result.Methods), result.Version, cfg.OutputPath())
+	}
+}
@@ -0,0 +1,35 @@
+// Package artifactpath validates artifact paths. It is shared by pkg/mlflow
+// and its artifact repository backends, so that every backend accepts the
+// same paths.
+package artifactpath
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Clean validates a slash separated artifact path relative to an artifact
+// root and returns it without redundant slashes. The root, "", is only
+// valid if allowRoot is set. A ".." segment is rejected, whether separated
+// by slashes or backslashes, but names such as "model..v2.bin" are not.
+func Clean(artifactPath string, allowRoot bool) (string, error) {
+	if artifactPath == "" && allowRoot {
+		return "", nil
+	}
+	clean := path.Clean("/" + artifactPath)
+	if artifactPath == "" || clean == "/" || hasParentSegment(artifactPath) {
+		return "", fmt.Errorf("invalid artifact path %q", artifactPath)
+	}
+	return strings.TrimPrefix(clean, "/"), nil
+}
+
+// hasParentSegment reports whether a segment of artifactPath is ".."
+func hasParentSegment(artifactPath string) bool {
+	for _, segment := range strings.FieldsFunc(artifactPath, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+// Package filterquote writes string values into MLflow search filters. It
+// is shared by pkg/mlflow, which searches by tag values, and
+// pkg/mlflow/search, which builds filters.
+package filterquote
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quote returns a string value as written in a filter. Values are
+// single-quoted, or double-quoted when they contain a single quote, because
+// MLflow does not unescape quotes inside string literals. Values containing
+// both kinds of quote cannot be written in a filter.
+func Quote(value string) (string, error) {
+	switch {
+	case !strings.Contains(value, "'"):
+		return "'" + value + "'", nil
+	case !strings.Contains(value, `"`):
+		return `"` + value + `"`, nil
+	}
+	return "", fmt.Errorf("value %q cannot be used in a filter: it contains both single and double quotes", value)
+}
@@ -0,0 +1,189 @@
+// Package protogen generates the MLflow REST client surface from MLflow's
+// vendored protobuf service definitions
+package protogen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the files recording the vendored proto release, kept next to the protos
+const (
+	VersionFile   = "VERSION"
+	ChecksumsFile = "SHA256SUMS"
+)
+
+// Config describes what to generate. Relative paths are resolved against
+// the directory of the config file.
+type Config struct {
+	// ProtoDir holds the vendored .proto files, VERSION and SHA256SUMS
+	ProtoDir string `json:"proto_dir"`
+	// Files are the .proto files (relative to ProtoDir) whose services are generated
+	Files []string `json:"files"`
+	// PackageDir is the Go package the code is generated into
+	PackageDir string `json:"package_dir"`
+	// Output is the generated Go file
+	Output string `json:"output"`
+	// Rename maps fully qualified proto message names to Go type names, for
+	// messages whose natural name is taken by an unrelated hand-written type
+	Rename map[string]string `json:"rename,omitempty"`
+	// Skip lists RPCs that are not generated, as Service.method
+	Skip []string `json:"skip,omitempty"`
+
+	dir string
+}
+
+// LoadConfig reads a generator config file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.ProtoDir == "" || cfg.PackageDir == "" || cfg.Output == "" || len(cfg.Files) == 0 {
+		return nil, fmt.Errorf("config %s must set proto_dir, files, package_dir and output", path)
+	}
+	cfg.dir = filepath.Dir(path)
+	return &cfg, nil
+}
+
+func (cfg *Config) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.dir, path)
+}
+
+// OutputPath returns the resolved path of the generated file
+func (cfg *Config) OutputPath() string {
+	return cfg.resolve(cfg.Output)
+}
+
+// protoSet is the vendored proto release read from disk
+type protoSet struct {
+	version string
+	// sources maps file names to their content, digests to their SHA-256
+	sources map[string][]byte
+	digests map[string]string
+}
+
+// loadProtos reads the vendored protos and verifies them against SHA256SUMS
+func (cfg *Config) loadProtos() (*protoSet, error) {
+	protoDir := cfg.resolve(cfg.ProtoDir)
+	version, err := os.ReadFile(filepath.Join(protoDir, VersionFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vendored proto version: %w", err)
+	}
+	set := &protoSet{
+		version: strings.TrimSpace(string(version)),
+		sources: map[string][]byte{},
+		digests: map[string]string{},
+	}
+	if set.version == "" {
+		return nil, fmt.Errorf("%s is empty", filepath.Join(protoDir, VersionFile))
+	}
+
+	locked, err := readChecksums(filepath.Join(protoDir, ChecksumsFile))
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, name := range cfg.Files {
+		src, err := os.ReadFile(filepath.Join(protoDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proto: %w", err)
+		}
+		sum := sha256.Sum256(src)
+		digest := hex.EncodeToString(sum[:])
+		set.sources[name] = src
+		set.digests[name] = digest
+		switch want, ok := locked[name]; {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is not listed in %s", name, ChecksumsFile))
+		case want != digest:
+			problems = append(problems, fmt.Sprintf("%s does not match the checksum recorded for MLflow %s", name, set.version))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &DriftError{Problems: problems}
+	}
+	return set, nil
+}
+
+// readChecksums parses a sha256sum style file
+func readChecksums(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proto checksums: %w", err)
+	}
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed line in %s: %q", path, scanner.Text())
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	return sums, scanner.Err()
+}
+
+// DriftError reports that the vendored protos, their lock files and the
+// generated code disagree
+type DriftError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *DriftError) Error() string {
+	return "generated MLflow API drift detected:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Check regenerates the API in memory and reports a *DriftError if the
+// vendored protos no longer match SHA256SUMS or the generated file is stale
+func Check(cfg *Config) (*Result, error) {
+	result, err := Generate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	current, err := os.ReadFile(cfg.OutputPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, &DriftError{Problems: []string{fmt.Sprintf("%s does not exist", cfg.Output)}}
+		}
+		return nil, err
+	}
+	if !bytes.Equal(current, result.Source) {
+		return result, &DriftError{Problems: []string{fmt.Sprintf(
+			"%s is out of date with the MLflow %s protos (first difference at line %d)",
+			cfg.Output, result.Version, firstDifference(current, result.Source))}}
+	}
+	return result, nil
+}
+
+func firstDifference(a, b []byte) int {
+	aLines := bytes.Split(a, []byte("\n"))
+	bLines := bytes.Split(b, []byte("\n"))
+	for i := 0; i < len(aLines) && i < len(bLines); i++ {
+		if !bytes.Equal(aLines[i], bLines[i]) {
+			return i + 1
+		}
+	}
+	if len(aLines) < len(bLines) {
+		return len(aLines) + 1
+	}
+	return len(bLines) + 1
+}
@@ -0,0 +1,141 @@
+package protogen
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Declarations are the identifiers already declared by hand in the target
+// package. Generated code reuses hand-written types and never redeclares an
+// identifier or an endpoint.
+type Declarations struct {
+	// Names holds every package-level type, func, const and var name
+	Names map[string]bool
+	// ClientMethods holds the names of the methods on *Client
+	ClientMethods map[string]bool
+	// Endpoints holds the values of string constants that look like API
+	// paths, without query strings
+	Endpoints map[string]bool
+}
+
+// ScanPackage collects the declarations of the non-test, non-generated Go
+// files in dir
+func ScanPackage(dir string) (*Declarations, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	decls := &Declarations{
+		Names:         map[string]bool{},
+		ClientMethods: map[string]bool{},
+		Endpoints:     map[string]bool{},
+	}
+	consts := map[string]ast.Expr{}
+	fset := gotoken.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := goparser.ParseFile(fset, filepath.Join(dir, name), nil, goparser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					decls.Names[d.Name.Name] = true
+				} else if receiverName(d.Recv) == "Client" {
+					decls.ClientMethods[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						decls.Names[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for i, ident := range s.Names {
+							decls.Names[ident.Name] = true
+							if d.Tok == gotoken.CONST && i < len(s.Values) {
+								consts[ident.Name] = s.Values[i]
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for name := range consts {
+		value, ok := evalString(name, consts, map[string]bool{})
+		if !ok || !strings.HasPrefix(value, "/api/") {
+			continue
+		}
+		if i := strings.IndexByte(value, '?'); i >= 0 {
+			value = value[:i]
+		}
+		decls.Endpoints[value] = true
+	}
+	return decls, nil
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	typ := recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// evalString evaluates a constant built from string literals, other
+// constants and +
+func evalString(name string, consts map[string]ast.Expr, visiting map[string]bool) (string, bool) {
+	expr, ok := consts[name]
+	if !ok || visiting[name] {
+		return "", false
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+	return evalExpr(expr, consts, visiting)
+}
+
+func evalExpr(expr ast.Expr, consts map[string]ast.Expr, visiting map[string]bool) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != gotoken.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(e.Value)
+		return value, err == nil
+	case *ast.Ident:
+		return evalString(e.Name, consts, visiting)
+	case *ast.ParenExpr:
+		return evalExpr(e.X, consts, visiting)
+	case *ast.BinaryExpr:
+		if e.Op != gotoken.ADD {
+			return "", false
+		}
+		left, ok := evalExpr(e.X, consts, visiting)
+		if !ok {
+			return "", false
+		}
+		right, ok := evalExpr(e.Y, consts, visiting)
+		return left + right, ok
+	}
+	return "", false
+}
@@ -0,0 +1,733 @@
+package protogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Result is the output of a generator run
+type Result struct {
+	// Source is the formatted Go file
+	Source []byte
+	// Version is the MLflow release of the vendored protos
+	Version string
+	// Methods lists the generated client methods
+	Methods []string
+	// Warnings lists RPCs and declarations that were skipped
+	Warnings []string
+}
+
+// Generate reads the vendored protos and returns the generated Go source for
+// every RPC the hand-written client does not already cover
+func Generate(cfg *Config) (*Result, error) {
+	protos, err := cfg.loadProtos()
+	if err != nil {
+		return nil, err
+	}
+	decls, err := ScanPackage(cfg.resolve(cfg.PackageDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", cfg.PackageDir, err)
+	}
+
+	g := &generator{
+		cfg:      cfg,
+		protos:   protos,
+		decls:    decls,
+		messages: map[string]*Message{},
+		enums:    map[string]*Enum{},
+		goNames:  map[string]string{},
+		queued:   map[string]bool{},
+		result:   &Result{Version: protos.version},
+	}
+	for _, name := range cfg.Files {
+		file, err := Parse(name, string(protos.sources[name]))
+		if err != nil {
+			return nil, err
+		}
+		g.addFile(file)
+	}
+	if err := g.plan(); err != nil {
+		return nil, err
+	}
+	src, err := g.emit()
+	if err != nil {
+		return nil, err
+	}
+	g.result.Source = src
+	return g.result, nil
+}
+
+// Write generates the API and writes it to the configured output file
+func Write(cfg *Config) (*Result, error) {
+	result, err := Generate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cfg.OutputPath(), result.Source, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", cfg.Output, err)
+	}
+	return result, nil
+}
+
+type generator struct {
+	cfg    *Config
+	protos *protoSet
+	decls  *Declarations
+	files  []*File
+
+	messages map[string]*Message
+	enums    map[string]*Enum
+	// goNames maps fully qualified proto names to Go type names
+	goNames map[string]string
+
+	rpcs []*rpcPlan
+	// queued holds the shared messages and enums to emit
+	queued map[string]bool
+
+	usesFmt  bool
+	usesJSON bool
+	result   *Result
+}
+
+// rpcPlan is an RPC selected for generation
+type rpcPlan struct {
+	rpc        *RPC
+	method     string
+	endpoint   Endpoint
+	constName  string
+	request    *Message
+	response   *Message
+	pathParams []*Field
+}
+
+func (g *generator) addFile(file *File) {
+	g.files = append(g.files, file)
+	for _, msg := range file.Messages {
+		g.messages[msg.FullName] = msg
+	}
+	for _, enum := range file.Enums {
+		g.enums[enum.FullName] = enum
+	}
+}
+
+func (g *generator) warnf(format string, args ...interface{}) {
+	g.result.Warnings = append(g.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// plan selects the RPCs to generate and assigns Go names to their types
+func (g *generator) plan() error {
+	skip := map[string]bool{}
+	for _, name := range g.cfg.Skip {
+		skip[name] = true
+	}
+	methods := map[string]bool{}
+	for _, file := range g.files {
+		for _, svc := range file.Services {
+			for _, rpc := range svc.RPCs {
+				if len(rpc.Endpoints) == 0 || skip[svc.Name+"."+rpc.Name] {
+					continue
+				}
+				endpoint := rpc.Endpoints[0]
+				if g.decls.Endpoints[apiPrefix(endpoint.Major)+endpoint.Path] {
+					// Covered by the hand-written client
+					continue
+				}
+				method := goIdent(rpc.Name)
+				if g.decls.ClientMethods[method] || methods[method] {
+					g.warnf("skipping %s.%s: Client.%s is already declared", svc.Name, rpc.Name, method)
+					continue
+				}
+				request := g.messages[g.resolve(rpc.Input, rpc.scope)]
+				response := g.messages[g.resolve(rpc.Output, rpc.scope)]
+				if request == nil || response == nil {
+					g.warnf("skipping %s.%s: cannot resolve its request or response message", svc.Name, rpc.Name)
+					continue
+				}
+				plan := &rpcPlan{rpc: rpc, method: method, endpoint: endpoint, request: request, response: response}
+				if !g.bindPathParams(plan) {
+					continue
+				}
+				methods[method] = true
+				g.rpcs = append(g.rpcs, plan)
+			}
+		}
+	}
+
+	// RPC messages are named after the method so they line up with the
+	// hand-written FooRequest/FooResponse types
+	for _, plan := range g.rpcs {
+		base := strings.TrimSuffix(goIdent(plan.request.Name), "Request")
+		g.goNames[plan.request.FullName] = base + "Request"
+		if plan.response.FullName == plan.request.FullName+".Response" {
+			g.goNames[plan.response.FullName] = base + "Response"
+		}
+	}
+	for full, name := range g.cfg.Rename {
+		g.goNames[full] = name
+	}
+
+	constNames := map[string]string{}
+	for _, plan := range g.rpcs {
+		plan.constName = endpointConstName(plan.endpoint)
+		value := apiPrefix(plan.endpoint.Major) + plan.endpoint.Path
+		if existing, ok := constNames[plan.constName]; ok && existing != value {
+			return fmt.Errorf("endpoint constant %s is used for both %s and %s", plan.constName, existing, value)
+		}
+		constNames[plan.constName] = value
+		if g.decls.Names[plan.constName] {
+			return fmt.Errorf("endpoint constant %s is already declared by hand", plan.constName)
+		}
+
+		for _, msg := range []*Message{plan.request, plan.response} {
+			if g.decls.Names[g.typeName(msg.FullName)] {
+				continue
+			}
+			for _, field := range msg.Fields {
+				g.need(field, msg)
+			}
+		}
+	}
+	return nil
+}
+
+// bindPathParams resolves the {param} segments of the endpoint path to
+// request fields
+func (g *generator) bindPathParams(plan *rpcPlan) bool {
+	for _, segment := range strings.Split(plan.endpoint.Path, "/") {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		var param *Field
+		for _, field := range plan.request.Fields {
+			if field.Name == name {
+				param = field
+			}
+		}
+		if param == nil || param.Repeated || param.Type != "string" {
+			g.warnf("skipping %s: path parameter %s is not a string field of %s", plan.rpc.Name, name, plan.request.Name)
+			return false
+		}
+		plan.pathParams = append(plan.pathParams, param)
+	}
+	return true
+}
+
+// need queues the message or enum a field refers to, unless it is
+// hand-written or a scalar
+func (g *generator) need(field *Field, scope *Message) {
+	for _, typ := range []string{field.Type, field.MapValue} {
+		if typ == "" || scalarTypes[typ] != "" || wellKnownTypes[strings.TrimPrefix(typ, ".")] != "" {
+			continue
+		}
+		full := g.resolve(typ, scope.FullName)
+		if full == "" {
+			continue
+		}
+		if g.queued[full] || g.decls.Names[g.typeName(full)] {
+			continue
+		}
+		g.queued[full] = true
+		if msg, ok := g.messages[full]; ok {
+			for _, inner := range msg.Fields {
+				g.need(inner, msg)
+			}
+		}
+	}
+}
+
+// resolve finds a type reference using protobuf scoping rules: the
+// innermost enclosing scope is searched first. It returns "" if the name is
+// not a known message or enum.
+func (g *generator) resolve(name, scope string) string {
+	defined := func(full string) bool {
+		_, isMessage := g.messages[full]
+		_, isEnum := g.enums[full]
+		return isMessage || isEnum
+	}
+	if strings.HasPrefix(name, ".") {
+		if full := name[1:]; defined(full) {
+			return full
+		}
+		return ""
+	}
+	for {
+		candidate := name
+		if scope != "" {
+			candidate = scope + "." + name
+		}
+		if defined(candidate) {
+			return candidate
+		}
+		if scope == "" {
+			return ""
+		}
+		if i := strings.LastIndexByte(scope, '.'); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// typeName returns the Go type name of a proto message or enum
+func (g *generator) typeName(full string) string {
+	if name, ok := g.goNames[full]; ok {
+		return name
+	}
+	name := full
+	if msg, ok := g.messages[full]; ok {
+		name = strings.TrimPrefix(full, msg.File.Package+".")
+	} else if enum, ok := g.enums[full]; ok {
+		name = strings.TrimPrefix(full, enum.File.Package+".")
+	}
+	var sb strings.Builder
+	for _, part := range strings.Split(name, ".") {
+		sb.WriteString(goIdent(part))
+	}
+	return sb.String()
+}
+
+// scalarTypes maps protobuf scalar types to Go types
+var scalarTypes = map[string]string{
+	"double":   "float64",
+	"float":    "float32",
+	"int32":    "int32",
+	"sint32":   "int32",
+	"sfixed32": "int32",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"uint32":   "uint32",
+	"fixed32":  "uint32",
+	"uint64":   "uint64",
+	"fixed64":  "uint64",
+	"bool":     "bool",
+	"string":   "string",
+	"bytes":    "[]byte",
+}
+
+// wellKnownTypes maps google.protobuf types to their JSON representation in Go
+var wellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp": "string",
+	"google.protobuf.Duration":  "string",
+	"google.protobuf.FieldMask": "string",
+	"google.protobuf.Struct":    "json.RawMessage",
+	"google.protobuf.Value":     "json.RawMessage",
+	"google.protobuf.ListValue": "json.RawMessage",
+	"google.protobuf.Any":       "json.RawMessage",
+	"google.protobuf.Empty":     "json.RawMessage",
+}
+
+// goType returns the Go type of a single (non-repeated) protobuf type
+// referenced from scope
+func (g *generator) goType(typ string, scope *Message, owner string) string {
+	if goType, ok := scalarTypes[typ]; ok {
+		return goType
+	}
+	if goType, ok := wellKnownTypes[strings.TrimPrefix(typ, ".")]; ok {
+		if goType == "json.RawMessage" {
+			g.usesJSON = true
+		}
+		return goType
+	}
+	full := g.resolve(typ, scope.FullName)
+	if full == "" {
+		g.warnf("%s.%s: unknown type %s is kept as raw JSON", scope.Name, owner, typ)
+		g.usesJSON = true
+		return "json.RawMessage"
+	}
+	return g.typeName(full)
+}
+
+// fieldType returns the Go type of a message field. Optional message fields
+// of requests are pointers so that an unset field is left out of the body.
+func (g *generator) fieldType(field *Field, msg *Message, request bool) string {
+	if field.MapValue != "" {
+		return "map[" + g.goType(field.MapKey, msg, field.Name) + "]" + g.goType(field.MapValue, msg, field.Name)
+	}
+	goType := g.goType(field.Type, msg, field.Name)
+	switch {
+	case field.Repeated:
+		return "[]" + goType
+	case goType == g.typeName(msg.FullName):
+		// A message cannot contain itself by value
+		return "*" + goType
+	case goType == "bool" && field.Default == "true":
+		// A plain bool could not distinguish false from unset
+		return "*bool"
+	case request && !field.Required && g.messages[g.resolve(field.Type, msg.FullName)] != nil:
+		return "*" + goType
+	}
+	return goType
+}
+
+func apiPrefix(major int) string {
+	return fmt.Sprintf("/api/%d.0", major)
+}
+
+// endpointConstName derives a constant name from an endpoint path, e.g.
+// /mlflow/logged-models/{model_id}/tags becomes endpointLoggedModelsByModelIDTags
+func endpointConstName(endpoint Endpoint) string {
+	var sb strings.Builder
+	sb.WriteString("endpoint")
+	if endpoint.Major != 2 {
+		fmt.Fprintf(&sb, "V%d", endpoint.Major)
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(endpoint.Path, "/mlflow/"), "/") {
+		if strings.HasPrefix(segment, "{") {
+			sb.WriteString("By" + goIdent(strings.Trim(segment, "{}")))
+			continue
+		}
+		sb.WriteString(goIdent(segment))
+	}
+	return sb.String()
+}
+
+// endpointConstValue returns the Go expression for an endpoint constant,
+// reusing apiBasePath for version 2 endpoints like the hand-written constants
+func endpointConstValue(endpoint Endpoint) string {
+	if endpoint.Major == 2 && strings.HasPrefix(endpoint.Path, "/mlflow/") {
+		return fmt.Sprintf("apiBasePath + %q", strings.TrimPrefix(endpoint.Path, "/mlflow"))
+	}
+	return fmt.Sprintf("%q", apiPrefix(endpoint.Major)+endpoint.Path)
+}
+
+// auditEntities maps the first path segment of an endpoint to the audit
+// entity constant and the request field identifying that entity
+var auditEntities = map[string]struct{ constant, idField string }{
+	"experiments":       {"AuditEntityExperiment", "experiment_id"},
+	"runs":              {"AuditEntityRun", "run_id"},
+	"metrics":           {"AuditEntityRun", "run_id"},
+	"artifacts":         {"AuditEntityRun", "run_id"},
+	"registered-models": {"AuditEntityModel", ""},
+	"model-versions":    {"AuditEntityModelVersion", ""},
+	"logged-models":     {"AuditEntityLoggedModel", "model_id"},
+	"traces":            {"AuditEntityTrace", "request_id"},
+	"webhooks":          {"AuditEntityWebhook", "webhook_id"},
+}
+
+// auditTarget returns the AuditTarget literal describing what a mutating
+// RPC changes
+func (g *generator) auditTarget(plan *rpcPlan) string {
+	segments := strings.Split(strings.TrimPrefix(plan.endpoint.Path, "/mlflow/"), "/")
+	if segments[0] == "databricks" && len(segments) > 1 {
+		segments = segments[1:]
+	}
+	entity, ok := auditEntities[segments[0]]
+	if !ok || !g.decls.Names[entity.constant] {
+		entity.constant = fmt.Sprintf("AuditEntity(%q)", strings.ReplaceAll(segments[0], "-", "_"))
+	}
+
+	fields := map[string]*Field{}
+	for _, field := range plan.request.Fields {
+		if !field.Repeated && field.Type == "string" {
+			fields[field.Name] = field
+		}
+	}
+	var parts []string
+	if entity.idField != "" && fields[entity.idField] != nil {
+		parts = append(parts, "ID: req."+goIdent(entity.idField))
+	}
+	if fields["name"] != nil {
+		parts = append(parts, "Name: req.Name")
+	}
+	if fields["version"] != nil {
+		parts = append(parts, "Version: req.Version")
+	}
+	if len(parts) == 0 && fields["experiment_id"] != nil {
+		// Bulk and create calls are recorded against their experiment
+		entity.constant = "AuditEntityExperiment"
+		parts = append(parts, "ID: req.ExperimentID")
+	}
+	return fmt.Sprintf("AuditTarget{%s}", strings.Join(append([]string{"Entity: " + entity.constant}, parts...), ", "))
+}
+
+// emit renders the generated file
+func (g *generator) emit() ([]byte, error) {
+	var body bytes.Buffer
+	g.emitEndpoints(&body)
+	g.emitTypes(&body)
+	for _, plan := range g.rpcs {
+		g.emitRPC(&body, plan)
+		g.result.Methods = append(g.result.Methods, plan.method)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by mlflow-protogen from the MLflow %s protos. DO NOT EDIT.\n", g.protos.version)
+	out.WriteString("//\n// Sources:\n")
+	names := append([]string(nil), g.cfg.Files...)
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&out, "//   %s sha256:%s\n", filepath.ToSlash(filepath.Join(g.cfg.ProtoDir, name)), g.protos.digests[name])
+	}
+	out.WriteString("\npackage mlflow\n\nimport (\n")
+	if g.usesJSON {
+		out.WriteString("\t\"encoding/json\"\n")
+	}
+	if g.usesFmt {
+		out.WriteString("\t\"fmt\"\n")
+	}
+	out.WriteString("\t\"net/http\"\n)\n\n")
+	out.WriteString("// ProtoVersion is the MLflow release whose protos the generated API was built from\n")
+	fmt.Fprintf(&out, "const ProtoVersion = %q\n\n", g.protos.version)
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) emitEndpoints(w *bytes.Buffer) {
+	if len(g.rpcs) == 0 {
+		return
+	}
+	w.WriteString("// Generated API endpoint constants\nconst (\n")
+	seen := map[string]bool{}
+	for _, plan := range g.rpcs {
+		if seen[plan.constName] {
+			continue
+		}
+		seen[plan.constName] = true
+		fmt.Fprintf(w, "\t%s = %s\n", plan.constName, endpointConstValue(plan.endpoint))
+	}
+	w.WriteString(")\n\n")
+}
+
+// emitTypes renders the shared enums and messages in declaration order
+func (g *generator) emitTypes(w *bytes.Buffer) {
+	for _, file := range g.files {
+		for _, enum := range file.Enums {
+			if g.queued[enum.FullName] {
+				g.emitEnum(w, enum)
+			}
+		}
+	}
+	for _, file := range g.files {
+		for _, msg := range file.Messages {
+			if g.queued[msg.FullName] {
+				g.emitMessage(w, msg, g.typeName(msg.FullName)+" is generated from the "+msg.FullName+" message", msg.Comment, false)
+			}
+		}
+	}
+}
+
+func (g *generator) emitEnum(w *bytes.Buffer, enum *Enum) {
+	typeName := g.typeName(enum.FullName)
+	writeDoc(w, "", typeName+" is generated from the "+enum.FullName+" enum", enum.Comment)
+	fmt.Fprintf(w, "type %s string\n\n", typeName)
+	w.WriteString("const (\n")
+	for _, value := range enum.Values {
+		constName := typeName + goIdent(trimEnumPrefix(value.Name, enum.Name))
+		if g.decls.Names[constName] {
+			g.warnf("skipping enum value %s: %s is already declared", value.Name, constName)
+			continue
+		}
+		writeComment(w, "\t", value.Comment)
+		fmt.Fprintf(w, "\t%s %s = %q\n", constName, typeName, value.Name)
+	}
+	w.WriteString(")\n\n")
+}
+
+// trimEnumPrefix removes the enum name (or a word-aligned part of it) from
+// the start of a value, e.g. LOGGED_MODEL_READY of LoggedModelStatus becomes READY
+func trimEnumPrefix(value, enumName string) string {
+	words := splitWords(enumName)
+	for i := range words {
+		words[i] = strings.ToUpper(words[i])
+	}
+	var candidates []string
+	for k := len(words); k >= 1; k-- {
+		candidates = append(candidates, strings.Join(words[:k], "_")+"_", strings.Join(words[len(words)-k:], "_")+"_")
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return len(candidates[i]) > len(candidates[j]) })
+	for _, prefix := range candidates {
+		rest := strings.TrimPrefix(value, prefix)
+		if rest != value && rest != "" && !isDigit(rest[0]) {
+			return rest
+		}
+	}
+	return value
+}
+
+func (g *generator) emitMessage(w *bytes.Buffer, msg *Message, summary string, comment []string, request bool) {
+	typeName := g.typeName(msg.FullName)
+	writeDoc(w, "", summary, comment)
+	fmt.Fprintf(w, "type %s struct {\n", typeName)
+	for _, field := range msg.Fields {
+		goType := g.fieldType(field, msg, request)
+		tag := field.Name
+		if !field.Required {
+			tag += ",omitempty"
+		}
+		writeComment(w, "\t", field.Comment)
+		fmt.Fprintf(w, "\t%s %s `json:\"%s\"`\n", goIdent(field.Name), goType, tag)
+	}
+	w.WriteString("}\n\n")
+}
+
+func (g *generator) emitRPC(w *bytes.Buffer, plan *rpcPlan) {
+	requestType := g.typeName(plan.request.FullName)
+	responseType := g.typeName(plan.response.FullName)
+	if !g.decls.Names[requestType] {
+		g.emitMessage(w, plan.request, requestType+" is the request for "+plan.method, nil, true)
+	}
+	hasResponse := len(plan.response.Fields) > 0
+	if hasResponse && !g.decls.Names[responseType] {
+		g.emitMessage(w, plan.response, responseType+" is the response from "+plan.method, nil, false)
+	}
+
+	returns, fail := "error", "return "
+	if hasResponse {
+		returns, fail = fmt.Sprintf("(*%s, error)", responseType), "return nil, "
+	}
+	endpoint := apiPrefix(plan.endpoint.Major) + plan.endpoint.Path
+	writeDoc(w, "", fmt.Sprintf("%s calls %s %s", plan.method, plan.endpoint.Method, endpoint), docComment(plan.rpc.Comment))
+	fmt.Fprintf(w, "func (c *Client) %s(req %s) %s {\n", plan.method, requestType, returns)
+
+	endpointExpr := plan.constName
+	if len(plan.pathParams) > 0 {
+		g.usesFmt = true
+		args := []string{plan.constName}
+		for _, param := range plan.pathParams {
+			fmt.Fprintf(w, "\tif req.%s == \"\" {\n\t\t%sfmt.Errorf(\"%s is required\")\n\t}\n", goIdent(param.Name), fail, param.Name)
+			args = append(args, "req."+goIdent(param.Name))
+		}
+		endpointExpr = fmt.Sprintf("expandEndpoint(%s)", strings.Join(args, ", "))
+	}
+
+	method := "http.Method" + goIdent(strings.ToLower(plan.endpoint.Method))
+	call := fmt.Sprintf("c.doRequest(%s, %s, req)", method, endpointExpr)
+	assign := ":="
+	if isMutation(plan) {
+		if g.hasKeyValue(plan.request) {
+			fmt.Fprintf(w, "\tvar err error\n\treq.Value, err = c.scrubValue(%q, req.Key, req.Value)\n\tif err != nil {\n\t\t%serr\n\t}\n", plan.method, fail)
+			assign = "="
+		}
+		call = fmt.Sprintf("c.doMutation(mutation{operation: %q, target: %s}, %s, %s, req)", plan.method, g.auditTarget(plan), method, endpointExpr)
+	}
+
+	if hasResponse {
+		fmt.Fprintf(w, "\trespBody, err := %s\n", call)
+		fmt.Fprintf(w, "\tif err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn unmarshalResponse[%s](respBody)\n}\n\n", responseType)
+		return
+	}
+	fmt.Fprintf(w, "\t_, err %s %s\n\treturn err\n}\n\n", assign, call)
+}
+
+// isMutation reports whether an RPC changes server state. Searches and
+// lookups sent as POST are reads and skip the audit log and policy checks.
+func isMutation(plan *rpcPlan) bool {
+	if plan.endpoint.Method == "GET" {
+		return false
+	}
+	for _, prefix := range []string{"Get", "Search", "List", "Test"} {
+		if strings.HasPrefix(plan.method, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasKeyValue reports whether a request sets a single key/value pair, which
+// the client's scrubber checks like SetTag
+func (g *generator) hasKeyValue(msg *Message) bool {
+	found := 0
+	for _, field := range msg.Fields {
+		if (field.Name == "key" || field.Name == "value") && field.Type == "string" && !field.Repeated {
+			found++
+		}
+	}
+	return found == 2
+}
+
+// docComment drops reStructuredText directives, which only make sense in
+// the MLflow documentation, from an RPC comment
+func docComment(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "..") {
+			break
+		}
+		out = append(out, line)
+	}
+	for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// writeDoc writes a doc comment made of a summary line and the proto comment
+func writeDoc(w *bytes.Buffer, indent, summary string, comment []string) {
+	fmt.Fprintf(w, "%s// %s\n", indent, summary)
+	if len(comment) > 0 {
+		fmt.Fprintf(w, "%s//\n", indent)
+		writeComment(w, indent, comment)
+	}
+}
+
+func writeComment(w *bytes.Buffer, indent string, comment []string) {
+	for _, line := range comment {
+		if line == "" {
+			fmt.Fprintf(w, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s// %s\n", indent, line)
+	}
+}
+
+// commonInitialisms are the words written in upper case in Go identifiers
+var commonInitialisms = map[string]string{
+	"api": "API", "html": "HTML", "http": "HTTP", "https": "HTTPS", "id": "ID",
+	"ids": "IDs", "json": "JSON", "ok": "OK", "sql": "SQL", "uri": "URI",
+	"uris": "URIs", "url": "URL", "urls": "URLs", "uuid": "UUID",
+}
+
+// goIdent converts a snake_case, kebab-case or camelCase name to an exported
+// Go identifier
+func goIdent(name string) string {
+	var sb strings.Builder
+	for _, word := range splitWords(name) {
+		lower := strings.ToLower(word)
+		if initialism, ok := commonInitialisms[lower]; ok {
+			sb.WriteString(initialism)
+			continue
+		}
+		sb.WriteString(strings.ToUpper(lower[:1]) + lower[1:])
+	}
+	return sb.String()
+}
+
+// splitWords splits a name at underscores, dashes, dots and case changes
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		start := 0
+		for i := 1; i < len(part); i++ {
+			prev, cur := part[i-1], part[i]
+			next := byte(0)
+			if i+1 < len(part) {
+				next = part[i+1]
+			}
+			lowerToUpper := isLower(prev) && isUpper(cur)
+			acronymEnd := isUpper(prev) && isUpper(cur) && isLower(next)
+			if lowerToUpper || acronymEnd {
+				words = append(words, part[start:i])
+				start = i
+			}
+		}
+		words = append(words, part[start:])
+	}
+	return words
+}
+
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
@@ -0,0 +1,151 @@
+package protogen
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+// token is a lexical token of a .proto file. comment holds the // comment
+// lines directly preceding the token.
+type token struct {
+	kind    tokenKind
+	text    string
+	line    int
+	comment []string
+}
+
+// lexer splits .proto source into tokens
+type lexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1}
+}
+
+// tokenize returns every token of the source, ending with tokenEOF
+func (l *lexer) tokenize() ([]token, error) {
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	var comment []string
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+			// A blank line detaches the preceding comment from the next token
+			if l.blankLineFollows() {
+				comment = nil
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			text := strings.TrimPrefix(l.src[l.pos:l.pos+end], "//")
+			comment = append(comment, strings.TrimPrefix(strings.TrimRight(text, " \t\r"), " "))
+			l.pos += end
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return token{}, fmt.Errorf("line %d: unterminated block comment", l.line)
+			}
+			l.line += strings.Count(l.src[l.pos:l.pos+2+end], "\n")
+			l.pos += end + 4
+			comment = nil
+		default:
+			tok, err := l.scan()
+			tok.comment = comment
+			return tok, err
+		}
+	}
+	return token{kind: tokenEOF, line: l.line}, nil
+}
+
+// blankLineFollows reports whether the line starting at pos is empty
+func (l *lexer) blankLineFollows() bool {
+	for i := l.pos; i < len(l.src); i++ {
+		switch l.src[i] {
+		case ' ', '\t', '\r':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (l *lexer) scan() (token, error) {
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case isIdentStart(c):
+		for l.pos < len(l.src) && (isIdentStart(l.src[l.pos]) || isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokenIdent, text: l.src[start:l.pos], line: l.line}, nil
+	case isDigit(c) || (c == '-' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		l.pos++
+		for l.pos < len(l.src) && (isIdentStart(l.src[l.pos]) || isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokenNumber, text: l.src[start:l.pos], line: l.line}, nil
+	case c == '"' || c == '\'':
+		l.pos++
+		var sb strings.Builder
+		for l.pos < len(l.src) && l.src[l.pos] != c {
+			if l.src[l.pos] == '\\' && l.pos+1 < len(l.src) {
+				l.pos++
+			}
+			if l.src[l.pos] == '\n' {
+				return token{}, fmt.Errorf("line %d: unterminated string", l.line)
+			}
+			sb.WriteByte(l.src[l.pos])
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("line %d: unterminated string", l.line)
+		}
+		l.pos++
+		return token{kind: tokenString, text: sb.String(), line: l.line}, nil
+	default:
+		l.pos++
+		return token{kind: tokenSymbol, text: string(c), line: l.line}, nil
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,618 @@
+package protogen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// File is a parsed .proto file. Only the parts the generator needs are kept;
+// imports, file options and extensions are skipped.
+type File struct {
+	Name     string
+	Package  string
+	Messages []*Message
+	Enums    []*Enum
+	Services []*Service
+}
+
+// Message is a protobuf message. Nested messages and enums are listed on the
+// file with their fully qualified names.
+type Message struct {
+	// FullName is the fully qualified name without a leading dot, e.g.
+	// "mlflow.SearchRuns.Response"
+	FullName string
+	Name     string
+	Comment  []string
+	Fields   []*Field
+	File     *File
+}
+
+// Field is a message field
+type Field struct {
+	Name     string
+	Type     string
+	Repeated bool
+	// MapKey and MapValue are set for map<K, V> fields
+	MapKey   string
+	MapValue string
+	Required bool
+	// Default is the value of the [default = ...] option
+	Default string
+	Comment []string
+}
+
+// Enum is a protobuf enum
+type Enum struct {
+	FullName string
+	Name     string
+	Comment  []string
+	Values   []EnumValue
+	File     *File
+}
+
+// EnumValue is a single enum constant
+type EnumValue struct {
+	Name    string
+	Comment []string
+}
+
+// Service is a protobuf service
+type Service struct {
+	Name string
+	RPCs []*RPC
+}
+
+// RPC is a service method together with its REST endpoints
+type RPC struct {
+	Name      string
+	Comment   []string
+	Input     string
+	Output    string
+	Endpoints []Endpoint
+	File      *File
+	// scope is the package used to resolve Input and Output
+	scope string
+}
+
+// Endpoint is an HTTP binding of an RPC from the (rpc) method option
+type Endpoint struct {
+	Method string
+	Path   string
+	// Major is the API major version, which selects /api/<major>.0
+	Major int
+}
+
+// Parse parses the source of a .proto file
+func Parse(name, src string) (*File, error) {
+	tokens, err := newLexer(src).tokenize()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	p := &parser{tokens: tokens, file: &File{Name: name}}
+	if err := p.parseFile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p.file, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	file   *File
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) is(text string) bool {
+	tok := p.peek()
+	return (tok.kind == tokenIdent || tok.kind == tokenSymbol) && tok.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	tok := p.advance()
+	if (tok.kind != tokenIdent && tok.kind != tokenSymbol) || tok.text != text {
+		return p.errorf(tok, "expected %q, found %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) ident() (token, error) {
+	tok := p.advance()
+	if tok.kind != tokenIdent {
+		return tok, p.errorf(tok, "expected identifier, found %q", tok.text)
+	}
+	return tok, nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseFile() error {
+	for {
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return nil
+		}
+		switch {
+		case p.accept(";"):
+		case p.accept("package"):
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			p.file.Package = name.text
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case p.is("syntax"), p.is("edition"), p.is("import"), p.is("option"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case p.is("message"):
+			if err := p.parseMessage(p.file.Package); err != nil {
+				return err
+			}
+		case p.is("enum"):
+			if err := p.parseEnum(p.file.Package); err != nil {
+				return err
+			}
+		case p.is("service"):
+			if err := p.parseService(); err != nil {
+				return err
+			}
+		case p.is("extend"):
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf(tok, "unexpected %q", tok.text)
+		}
+	}
+}
+
+// skipStatement skips tokens up to and including the next top-level ';',
+// stepping over any braces in between
+func (p *parser) skipStatement() error {
+	depth := 0
+	for {
+		tok := p.advance()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, "unexpected end of file")
+		case tok.kind == tokenSymbol && (tok.text == "{" || tok.text == "["):
+			depth++
+		case tok.kind == tokenSymbol && (tok.text == "}" || tok.text == "]"):
+			depth--
+		case tok.kind == tokenSymbol && tok.text == ";" && depth == 0:
+			return nil
+		}
+	}
+}
+
+// skipBlock skips a declaration followed by a balanced { ... } block
+func (p *parser) skipBlock() error {
+	for !p.is("{") {
+		if tok := p.advance(); tok.kind == tokenEOF {
+			return p.errorf(tok, "unexpected end of file")
+		}
+	}
+	depth := 0
+	for {
+		tok := p.advance()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, "unexpected end of file")
+		case tok.kind == tokenSymbol && tok.text == "{":
+			depth++
+		case tok.kind == tokenSymbol && tok.text == "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) parseMessage(scope string) error {
+	start := p.advance() // message
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	msg := &Message{FullName: scope + "." + name.text, Name: name.text, Comment: start.comment, File: p.file}
+	p.file.Messages = append(p.file.Messages, msg)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.accept("}") {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, "unexpected end of file in message %s", msg.Name)
+		case p.accept(";"):
+		case p.is("message"):
+			if err := p.parseMessage(msg.FullName); err != nil {
+				return err
+			}
+		case p.is("enum"):
+			if err := p.parseEnum(msg.FullName); err != nil {
+				return err
+			}
+		case p.is("option"), p.is("reserved"), p.is("extensions"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case p.is("extend"):
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		case p.is("oneof"):
+			p.advance()
+			if _, err := p.ident(); err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			for !p.accept("}") {
+				if p.is("option") {
+					if err := p.skipStatement(); err != nil {
+						return err
+					}
+					continue
+				}
+				field, err := p.parseField()
+				if err != nil {
+					return err
+				}
+				msg.Fields = append(msg.Fields, field)
+			}
+		default:
+			field, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseField() (*Field, error) {
+	first := p.peek()
+	field := &Field{Comment: first.comment}
+	switch {
+	case p.accept("repeated"):
+		field.Repeated = true
+	case p.accept("optional"), p.accept("required"):
+	}
+
+	if p.accept("map") {
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		value, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		field.MapKey, field.MapValue = key.text, value.text
+	} else {
+		prefix := ""
+		if p.accept(".") {
+			prefix = "."
+		}
+		typ, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		field.Type = prefix + typ.text
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name.text
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if tok := p.advance(); tok.kind != tokenNumber {
+		return nil, p.errorf(tok, "expected field number, found %q", tok.text)
+	}
+	if p.accept("[") {
+		for !p.accept("]") {
+			tok := p.advance()
+			switch {
+			case tok.kind == tokenEOF:
+				return nil, p.errorf(tok, "unexpected end of file in field options")
+			case tok.kind == tokenIdent && tok.text == "default" && p.accept("="):
+				field.Default = p.advance().text
+			case tok.kind == tokenIdent && tok.text == "validate_required":
+				// (validate_required) = true
+				if p.accept(")") && p.accept("=") && p.is("true") {
+					field.Required = true
+				}
+			}
+		}
+	}
+	if err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (p *parser) parseEnum(scope string) error {
+	start := p.advance() // enum
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	enum := &Enum{FullName: scope + "." + name.text, Name: name.text, Comment: start.comment, File: p.file}
+	p.file.Enums = append(p.file.Enums, enum)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.accept("}") {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, "unexpected end of file in enum %s", enum.Name)
+		case p.accept(";"):
+		case p.is("option"), p.is("reserved"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			value, err := p.ident()
+			if err != nil {
+				return err
+			}
+			enum.Values = append(enum.Values, EnumValue{Name: value.text, Comment: value.comment})
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseService() error {
+	p.advance() // service
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	svc := &Service{Name: name.text}
+	p.file.Services = append(p.file.Services, svc)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.accept("}") {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(tok, "unexpected end of file in service %s", svc.Name)
+		case p.accept(";"):
+		case p.is("option"):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case p.is("rpc"):
+			rpc, err := p.parseRPC()
+			if err != nil {
+				return err
+			}
+			svc.RPCs = append(svc.RPCs, rpc)
+		default:
+			return p.errorf(tok, "unexpected %q in service %s", tok.text, svc.Name)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseRPC() (*RPC, error) {
+	start := p.advance() // rpc
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	rpc := &RPC{Name: name.text, Comment: start.comment, File: p.file, scope: p.file.Package}
+
+	if rpc.Input, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if rpc.Output, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if p.accept(";") {
+		return rpc, nil
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.accept("}") {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, p.errorf(tok, "unexpected end of file in rpc %s", rpc.Name)
+		case p.accept(";"):
+		case p.accept("option"):
+			optName, err := p.optionName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value, err := p.parseTextValue()
+			if err != nil {
+				return nil, err
+			}
+			p.accept(";")
+			if optName == "rpc" {
+				rpc.Endpoints = endpointsFromOption(value)
+			}
+		default:
+			return nil, p.errorf(tok, "unexpected %q in rpc %s", tok.text, rpc.Name)
+		}
+	}
+	return rpc, nil
+}
+
+func (p *parser) parseRPCType() (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	p.accept("stream")
+	typ, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	return typ.text, p.expect(")")
+}
+
+// optionName parses an option name such as rpc, (rpc) or (scalapb.message).extends
+func (p *parser) optionName() (string, error) {
+	var sb strings.Builder
+	for !p.is("=") {
+		tok := p.advance()
+		if tok.kind == tokenEOF {
+			return "", p.errorf(tok, "unexpected end of file in option")
+		}
+		if tok.text != "(" && tok.text != ")" {
+			sb.WriteString(tok.text)
+		}
+	}
+	return sb.String(), nil
+}
+
+// textValue is a value of an option in protobuf text format: a scalar, a
+// list, or a message whose fields may repeat
+type textValue struct {
+	scalar string
+	list   []*textValue
+	fields map[string][]*textValue
+}
+
+func (v *textValue) field(name string) *textValue {
+	if v == nil || len(v.fields[name]) == 0 {
+		return nil
+	}
+	return v.fields[name][0]
+}
+
+// all returns the values of a field, flattening list syntax
+func (v *textValue) all(name string) []*textValue {
+	if v == nil {
+		return nil
+	}
+	var out []*textValue
+	for _, value := range v.fields[name] {
+		if value.list != nil {
+			out = append(out, value.list...)
+		} else {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func (p *parser) parseTextValue() (*textValue, error) {
+	switch {
+	case p.accept("{"):
+		msg := &textValue{fields: map[string][]*textValue{}}
+		for !p.accept("}") {
+			if p.accept(",") || p.accept(";") {
+				continue
+			}
+			key := p.advance()
+			if key.kind != tokenIdent {
+				return nil, p.errorf(key, "expected field name, found %q", key.text)
+			}
+			p.accept(":")
+			value, err := p.parseTextValue()
+			if err != nil {
+				return nil, err
+			}
+			msg.fields[key.text] = append(msg.fields[key.text], value)
+		}
+		return msg, nil
+	case p.accept("["):
+		list := &textValue{list: []*textValue{}}
+		for !p.accept("]") {
+			if p.accept(",") {
+				continue
+			}
+			value, err := p.parseTextValue()
+			if err != nil {
+				return nil, err
+			}
+			list.list = append(list.list, value)
+		}
+		return list, nil
+	default:
+		tok := p.advance()
+		if tok.kind == tokenEOF || tok.kind == tokenSymbol {
+			return nil, p.errorf(tok, "unexpected %q in option value", tok.text)
+		}
+		value := tok.text
+		// Adjacent string literals are concatenated
+		for tok.kind == tokenString && p.peek().kind == tokenString {
+			value += p.advance().text
+		}
+		return &textValue{scalar: value}, nil
+	}
+}
+
+// endpointsFromOption extracts the HTTP endpoints from an (rpc) option
+func endpointsFromOption(opt *textValue) []Endpoint {
+	var endpoints []Endpoint
+	for _, ep := range opt.all("endpoints") {
+		endpoint := Endpoint{Major: 2}
+		if method := ep.field("method"); method != nil {
+			endpoint.Method = method.scalar
+		}
+		if path := ep.field("path"); path != nil {
+			endpoint.Path = path.scalar
+		}
+		if major := ep.field("since").field("major"); major != nil {
+			fmt.Sscanf(major.scalar, "%d", &endpoint.Major)
+		}
+		if endpoint.Method != "" && endpoint.Path != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
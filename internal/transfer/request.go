@@ -0,0 +1,110 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryBackoff is the wait before the first retry of a request, doubling
+// for each further one
+const RetryBackoff = 200 * time.Millisecond
+
+// ParseErrorFunc returns the error of a non-2xx response with its body
+type ParseErrorFunc func(resp *http.Response, body []byte) error
+
+// Retry calls send, retrying it while it fails with an error retryable
+// accepts, up to maxRetries times, and returns the response of the first
+// call that succeeds
+func Retry(ctx context.Context, maxRetries int, retryable func(error) bool, send func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := send()
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= maxRetries || !retryable(err) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(RetryBackoff << attempt):
+		}
+	}
+}
+
+// RetryableNetworkError reports whether a request that got no response may
+// succeed if retried: network errors may, cancellation and untrusted
+// certificates do not
+func RetryableNetworkError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.As(err, &certErr)
+}
+
+// RetryableStatus reports whether a request that failed with status may
+// succeed if retried
+func RetryableStatus(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+}
+
+// Send sends a request and returns the response if its status is 2xx. The
+// caller must close its body. Other responses are read and turned into an
+// error by parseError.
+func Send(client *http.Client, req *http.Request, parseError ParseErrorFunc) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, parseError(resp, body)
+	}
+	return resp, nil
+}
+
+// DecodeXML reads a response, closing its body, and unmarshals its XML into
+// v unless v is nil. A body that is an error document, which some requests
+// send after a 2xx status, is turned into an error by parseError.
+func DecodeXML(resp *http.Response, v any, parseError ParseErrorFunc) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if bytes.Contains(body[:min(len(body), 256)], []byte("<Error>")) {
+		return parseError(resp, body)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := xml.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// ErrorMessage formats an error response of a service, such as "S3 error
+// 404 NoSuchKey: The specified key does not exist. (request 4442587FB7D0A2F9)"
+func ErrorMessage(service string, status int, code, message, requestID string) string {
+	msg := fmt.Sprintf("%s error %d", service, status)
+	if code != "" {
+		msg += " " + code
+	}
+	if message != "" {
+		msg += ": " + message
+	}
+	if requestID != "" {
+		msg += " (request " + requestID + ")"
+	}
+	return msg
+}
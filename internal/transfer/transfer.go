@@ -0,0 +1,211 @@
+// Package transfer moves large artifacts to and from object stores in
+// parts, several at once. It is shared by the object store backends of
+// pkg/mlflow, which supply the requests for a single part, and which send
+// those requests with the helpers of this package.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// PartSize returns the part size to upload size bytes in at most maxParts
+// parts, partSize unless the content is too large for it. An unknown
+// size, -1, keeps partSize.
+func PartSize(size, partSize int64, maxParts int) int64 {
+	if size > 0 && (size+partSize-1)/partSize > int64(maxParts) {
+		return (size + int64(maxParts) - 1) / int64(maxParts)
+	}
+	return partSize
+}
+
+// ReadPart reads up to limit bytes, fewer only at the end of content
+func ReadPart(content io.Reader, limit int64) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(content, limit))
+}
+
+// UploadFunc uploads part number, counting from 1
+type UploadFunc func(ctx context.Context, number int, data []byte) error
+
+// UploadParts uploads the parts already read, then the rest of content in
+// parts of partSize, with up to concurrency parts in flight. It returns the
+// number of bytes uploaded, and the first error, after which no further
+// parts are started and those in flight are cancelled.
+func UploadParts(ctx context.Context, content io.Reader, read [][]byte, partSize int64, maxParts, concurrency int, upload UploadFunc) (int64, error) {
+	partsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	slots := make(chan struct{}, concurrency)
+	var total int64
+	for number := 1; ; number++ {
+		var data []byte
+		if len(read) > 0 {
+			data, read = read[0], read[1:]
+		} else {
+			var err error
+			if data, err = ReadPart(content, partSize); err != nil {
+				fail(fmt.Errorf("failed to read artifact: %w", err))
+				break
+			}
+		}
+		if len(data) == 0 {
+			break
+		}
+		if number > maxParts {
+			fail(fmt.Errorf("artifact is larger than %d parts of %d bytes", maxParts, partSize))
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-partsCtx.Done():
+		}
+		if partsCtx.Err() != nil {
+			break
+		}
+		total += int64(len(data))
+		wg.Add(1)
+		go func(number int, data []byte) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := upload(partsCtx, number, data); err != nil {
+				fail(fmt.Errorf("failed to upload part %d: %w", number, err))
+			}
+		}(number, data)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return total, firstErr
+	}
+	return total, ctx.Err()
+}
+
+// GetFunc downloads the bytes start to end, inclusive, of an object
+type GetFunc func(ctx context.Context, start, end int64) ([]byte, error)
+
+// rangeReader reads an object from a stream of its first part and
+// concurrent ranged requests for the others, in order
+type rangeReader struct {
+	cancel context.CancelFunc
+	// first streams the first part, nil once read
+	first     io.ReadCloser
+	firstSize int64
+	firstRead int64
+	// parts deliver the other parts in order
+	parts []chan rangePart
+	next  int
+	// slots bound the parts fetched but not yet read
+	slots chan struct{}
+	buf   []byte
+	err   error
+}
+
+type rangePart struct {
+	data []byte
+	err  error
+}
+
+// NewRangeReader returns a reader of an object of total bytes whose first
+// partSize bytes stream from first. The other parts are fetched with get,
+// up to concurrency of them ahead of the reader. Closing the reader
+// cancels the fetches in flight.
+func NewRangeReader(ctx context.Context, first io.ReadCloser, partSize, total int64, concurrency int, get GetFunc) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	rr := &rangeReader{
+		cancel:    cancel,
+		first:     first,
+		firstSize: partSize,
+		parts:     make([]chan rangePart, (total+partSize-1)/partSize-1),
+		slots:     make(chan struct{}, concurrency),
+	}
+	for i := range rr.parts {
+		rr.parts[i] = make(chan rangePart, 1)
+	}
+	go func() {
+		for i := range rr.parts {
+			select {
+			case rr.slots <- struct{}{}:
+			case <-ctx.Done():
+				rr.parts[i] <- rangePart{err: ctx.Err()}
+				continue
+			}
+			start := int64(i+1) * partSize
+			end := min(start+partSize, total) - 1
+			go func(i int) {
+				data, err := get(ctx, start, end)
+				rr.parts[i] <- rangePart{data: data, err: err}
+			}(i)
+		}
+	}()
+	return rr
+}
+
+func (rr *rangeReader) Read(p []byte) (int, error) {
+	for {
+		if rr.err != nil {
+			return 0, rr.err
+		}
+		if rr.first != nil {
+			n, err := rr.first.Read(p)
+			rr.firstRead += int64(n)
+			if err == io.EOF {
+				rr.first.Close()
+				rr.first = nil
+				err = nil
+				if rr.firstRead != rr.firstSize {
+					err = io.ErrUnexpectedEOF
+				}
+			}
+			if err != nil {
+				rr.err = err
+			}
+			if n == 0 && err == nil {
+				continue
+			}
+			return n, err
+		}
+		if len(rr.buf) > 0 {
+			n := copy(p, rr.buf)
+			rr.buf = rr.buf[n:]
+			return n, nil
+		}
+		if rr.next == len(rr.parts) {
+			return 0, io.EOF
+		}
+		part := <-rr.parts[rr.next]
+		rr.next++
+		select {
+		case <-rr.slots:
+		default:
+		}
+		rr.buf, rr.err = part.data, part.err
+	}
+}
+
+// Close stops the downloads in flight
+func (rr *rangeReader) Close() error {
+	rr.cancel()
+	if rr.first != nil {
+		rr.first.Close()
+		rr.first = nil
+	}
+	if rr.err == nil {
+		rr.err = errors.New("read of closed artifact")
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package mlflow
+
+import "time"
+
+// millisToTime converts a timestamp in milliseconds since the epoch, as
+// reported by the server, to a time. An unset (zero) timestamp is the zero time.
+func millisToTime(millis int64) time.Time {
+	if millis == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(millis)
+}
+
+// StartedAt returns when the run started
+func (i RunInfo) StartedAt() time.Time {
+	return millisToTime(i.StartTime)
+}
+
+// EndedAt returns when the run ended, or the zero time if it has not ended
+func (i RunInfo) EndedAt() time.Time {
+	return millisToTime(i.EndTime)
+}
+
+// Duration returns how long the run ran for. For a run that has not ended it
+// is the time since the run started. A run without a start time has no
+// duration.
+func (i RunInfo) Duration() time.Duration {
+	if i.StartTime == 0 {
+		return 0
+	}
+	if i.EndTime == 0 {
+		return time.Since(i.StartedAt())
+	}
+	return i.EndedAt().Sub(i.StartedAt())
+}
+
+// IsTerminal reports whether the run has finished, failed or been killed
+func (i RunInfo) IsTerminal() bool {
+	return i.Status.IsTerminal()
+}
+
+// StartedAt returns when the run started
+func (r Run) StartedAt() time.Time {
+	return r.Info.StartedAt()
+}
+
+// EndedAt returns when the run ended, or the zero time if it has not ended
+func (r Run) EndedAt() time.Time {
+	return r.Info.EndedAt()
+}
+
+// Duration returns how long the run ran for, or has been running for
+func (r Run) Duration() time.Duration {
+	return r.Info.Duration()
+}
+
+// IsTerminal reports whether the run has finished, failed or been killed
+func (r Run) IsTerminal() bool {
+	return r.Info.IsTerminal()
+}
+
+// Param returns the value of a param and whether the run has it
+func (r Run) Param(key string) (string, bool) {
+	for _, param := range r.Data.Params {
+		if param.Key == key {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// Metric returns the latest value of a metric and whether the run has it
+func (r Run) Metric(key string) (float64, bool) {
+	for _, metric := range r.Data.Metrics {
+		if metric.Key == key {
+			return metric.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Tag returns the value of a tag and whether the run has it
+func (r Run) Tag(key string) (string, bool) {
+	for _, tag := range r.Data.Tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParamMap returns the run's params keyed by name
+func (r Run) ParamMap() map[string]string {
+	params := make(map[string]string, len(r.Data.Params))
+	for _, param := range r.Data.Params {
+		params[param.Key] = param.Value
+	}
+	return params
+}
+
+// MetricMap returns the latest value of each of the run's metrics keyed by name
+func (r Run) MetricMap() map[string]float64 {
+	metrics := make(map[string]float64, len(r.Data.Metrics))
+	for _, metric := range r.Data.Metrics {
+		metrics[metric.Key] = metric.Value
+	}
+	return metrics
+}
+
+// TagMap returns the run's tags keyed by name
+func (r Run) TagMap() map[string]string {
+	tags := make(map[string]string, len(r.Data.Tags))
+	for _, tag := range r.Data.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
+// CreatedAt returns when the experiment was created
+func (e Experiment) CreatedAt() time.Time {
+	return millisToTime(e.CreationTime)
+}
+
+// LastUpdatedAt returns when the experiment was last updated
+func (e Experiment) LastUpdatedAt() time.Time {
+	return millisToTime(e.LastUpdateTime)
+}
+
+// Tag returns the value of a tag and whether the experiment has it
+func (e Experiment) Tag(key string) (string, bool) {
+	for _, tag := range e.Tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// TagMap returns the experiment's tags keyed by name
+func (e Experiment) TagMap() map[string]string {
+	tags := make(map[string]string, len(e.Tags))
+	for _, tag := range e.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
+// CreatedAt returns when the registered model was created
+func (m RegisteredModel) CreatedAt() time.Time {
+	return millisToTime(m.CreationTimestamp)
+}
+
+// LastUpdatedAt returns when the registered model was last updated
+func (m RegisteredModel) LastUpdatedAt() time.Time {
+	return millisToTime(m.LastUpdatedTimestamp)
+}
+
+// Tag returns the value of a tag and whether the registered model has it
+func (m RegisteredModel) Tag(key string) (string, bool) {
+	for _, tag := range m.Tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// TagMap returns the registered model's tags keyed by name
+func (m RegisteredModel) TagMap() map[string]string {
+	tags := make(map[string]string, len(m.Tags))
+	for _, tag := range m.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
+// CreatedAt returns when the model version was created
+func (v ModelVersion) CreatedAt() time.Time {
+	return millisToTime(v.CreationTimestamp)
+}
+
+// LastUpdatedAt returns when the model version was last updated
+func (v ModelVersion) LastUpdatedAt() time.Time {
+	return millisToTime(v.LastUpdatedTimestamp)
+}
+
+// Tag returns the value of a tag and whether the model version has it
+func (v ModelVersion) Tag(key string) (string, bool) {
+	for _, tag := range v.Tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// TagMap returns the model version's tags keyed by name
+func (v ModelVersion) TagMap() map[string]string {
+	tags := make(map[string]string, len(v.Tags))
+	for _, tag := range v.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
@@ -0,0 +1,179 @@
+package mlflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Environment variables MLflow uses to hand a tracking server, experiment and
+// run to another process
+const (
+	EnvTrackingURI    = "MLFLOW_TRACKING_URI"
+	EnvTrackingToken  = "MLFLOW_TRACKING_TOKEN"
+	EnvRunID          = "MLFLOW_RUN_ID"
+	EnvExperimentID   = "MLFLOW_EXPERIMENT_ID"
+	EnvExperimentName = "MLFLOW_EXPERIMENT_NAME"
+)
+
+// NewClientFromEnv creates a client for the server in MLFLOW_TRACKING_URI,
+// authenticated with MLFLOW_TRACKING_TOKEN if it is set
+func NewClientFromEnv() (*Client, error) {
+	uri := os.Getenv(EnvTrackingURI)
+	if uri == "" {
+		return nil, fmt.Errorf("%s is not set", EnvTrackingURI)
+	}
+	c := NewClient(uri)
+	c.AuthToken = os.Getenv(EnvTrackingToken)
+	return c, nil
+}
+
+// ActiveRun is the run that the current unit of work logs to. It is carried
+// in a context.Context, so code deep in a call stack can log to the run
+// without its ID being passed through every function.
+type ActiveRun struct {
+	Client       *Client
+	RunID        string
+	ExperimentID string
+
+	monitor  *SystemMetricsMonitor
+	profiler *Profiler
+}
+
+type activeRunKey struct{}
+
+// ContextWithRun returns a copy of ctx that carries the active run
+func ContextWithRun(ctx context.Context, run *ActiveRun) context.Context {
+	return context.WithValue(ctx, activeRunKey{}, run)
+}
+
+// RunFromContext returns the active run carried by ctx, if any
+func RunFromContext(ctx context.Context) (*ActiveRun, bool) {
+	run, ok := ctx.Value(activeRunKey{}).(*ActiveRun)
+	return run, ok && run != nil
+}
+
+// StartRun returns the run this process should log to. If MLFLOW_RUN_ID is
+// set, the process was handed a run by its parent and attaches to it, and req
+// is ignored. Otherwise a run is created in req.ExperimentID, or if that is
+// empty in the experiment named by MLFLOW_EXPERIMENT_ID or
+// MLFLOW_EXPERIMENT_NAME.
+func (c *Client) StartRun(req CreateRunRequest) (*ActiveRun, error) {
+	if runID := os.Getenv(EnvRunID); runID != "" {
+		return c.AttachRun(runID)
+	}
+	if req.ExperimentID == "" {
+		experimentID, err := c.experimentIDFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		req.ExperimentID = experimentID
+	}
+	resp, err := c.CreateRun(req)
+	if err != nil {
+		return nil, err
+	}
+	return &ActiveRun{Client: c, RunID: resp.Run.Info.RunID, ExperimentID: resp.Run.Info.ExperimentID}, nil
+}
+
+// AttachRun returns an existing run as the active run. Deleted runs cannot be
+// attached to.
+func (c *Client) AttachRun(runID string) (*ActiveRun, error) {
+	resp, err := c.GetRun(runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to run %s: %w", runID, err)
+	}
+	if resp.Run.Info.LifecycleStage == LifecycleStageDeleted {
+		return nil, fmt.Errorf("failed to attach to run %s: the run is deleted", runID)
+	}
+	return &ActiveRun{Client: c, RunID: runID, ExperimentID: resp.Run.Info.ExperimentID}, nil
+}
+
+// experimentIDFromEnv resolves the experiment named by MLFLOW_EXPERIMENT_ID
+// or, if that is not set, MLFLOW_EXPERIMENT_NAME
+func (c *Client) experimentIDFromEnv() (string, error) {
+	if experimentID := os.Getenv(EnvExperimentID); experimentID != "" {
+		return experimentID, nil
+	}
+	name := os.Getenv(EnvExperimentName)
+	if name == "" {
+		return "", fmt.Errorf("experiment ID is required: set it on the request, %s or %s", EnvExperimentID, EnvExperimentName)
+	}
+	resp, err := c.GetExperimentByName(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get experiment %q from %s: %w", name, EnvExperimentName, err)
+	}
+	return resp.Experiment.ExperimentID, nil
+}
+
+// Environ returns the environment variables that hand the run to a
+// subprocess, in the KEY=value form used by exec.Cmd.Env. MLflow clients in
+// any language attach to the run through them.
+func (r *ActiveRun) Environ() []string {
+	env := []string{
+		EnvTrackingURI + "=" + r.Client.BaseURL,
+		EnvRunID + "=" + r.RunID,
+		EnvExperimentID + "=" + r.ExperimentID,
+	}
+	if r.Client.AuthToken != "" {
+		env = append(env, EnvTrackingToken+"="+r.Client.AuthToken)
+	}
+	return env
+}
+
+// LogMetric logs a metric to the run at the given step
+func (r *ActiveRun) LogMetric(key string, value float64, step int64) error {
+	return r.Client.LogMetric(LogMetricRequest{RunID: r.RunID, Key: key, Value: value, Step: step})
+}
+
+// LogParam logs a parameter to the run
+func (r *ActiveRun) LogParam(key, value string) error {
+	return r.Client.LogParam(LogParamRequest{RunID: r.RunID, Key: key, Value: value})
+}
+
+// SetTag sets a tag on the run
+func (r *ActiveRun) SetTag(key, value string) error {
+	return r.Client.SetTag(SetTagRequest{RunID: r.RunID, Key: key, Value: value})
+}
+
+// LogBatch logs multiple metrics, parameters, and tags to the run
+func (r *ActiveRun) LogBatch(metrics []Metric, params []Param, tags []RunTag) error {
+	return r.Client.LogBatch(r.RunID, metrics, params, tags)
+}
+
+// LogParamsFromStruct logs the fields of a config struct as params of the run
+func (r *ActiveRun) LogParamsFromStruct(cfg any) error {
+	return r.Client.LogParamsFromStruct(r.RunID, cfg)
+}
+
+// Run gets the current state of the run
+func (r *ActiveRun) Run() (*Run, error) {
+	resp, err := r.Client.GetRun(r.RunID)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Run, nil
+}
+
+// StartChild creates a run nested under this one
+func (r *ActiveRun) StartChild(req CreateRunRequest) (*ActiveRun, error) {
+	resp, err := r.Client.StartChildRun(r.RunID, req)
+	if err != nil {
+		return nil, err
+	}
+	return &ActiveRun{Client: r.Client, RunID: resp.Run.Info.RunID, ExperimentID: resp.Run.Info.ExperimentID}, nil
+}
+
+// End ends the run with a terminal status such as RunStatusFinished. A
+// profiler or system metrics monitor started on the run is stopped first.
+func (r *ActiveRun) End(status RunStatus) error {
+	if !status.IsTerminal() {
+		return fmt.Errorf("cannot end run %s with non-terminal status %s", r.RunID, status)
+	}
+	profilerErr := r.stopProfiler()
+	monitorErr := r.stopSystemMetrics()
+	_, err := r.Client.UpdateRun(UpdateRunRequest{RunID: r.RunID, Status: status, EndTime: time.Now().UnixMilli()})
+	return errors.Join(profilerErr, monitorErr, err)
+}
@@ -0,0 +1,386 @@
+package mlflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Content-addressed artifacts are stored once per experiment, as blobs named
+// by their SHA-256 under ArtifactBlobDir of the experiment's artifact
+// location. In the run's artifacts, each file is a pointer file: its path
+// with ArtifactPointerSuffix appended, holding the blob's digest, size and
+// store as JSON.
+const (
+	ArtifactBlobDir       = ".mlflow-blobs"
+	ArtifactPointerSuffix = ".mlflow-blob"
+	// maxPointerSize bounds what is read of a pointer file
+	maxPointerSize = 64 << 10
+)
+
+// experimentsPageSize is the page size used when searching for the
+// experiments that share a blob store
+const experimentsPageSize = 1000
+
+// artifactPointer is the content of a pointer file
+type artifactPointer struct {
+	// Store is the artifact URI the blob is under, the artifact location of
+	// the run's experiment
+	Store  string `json:"store"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// blobPath is the path of the pointer's blob relative to its store, fanned
+// out by the digest's first byte
+func (p artifactPointer) blobPath() string {
+	return blobPath(p.SHA256)
+}
+
+func blobPath(digest string) string {
+	return path.Join(ArtifactBlobDir, "sha256", digest[:2], digest)
+}
+
+func (p artifactPointer) validate() error {
+	if digest, err := hex.DecodeString(p.SHA256); err != nil || len(digest) != sha256.Size || p.SHA256 != strings.ToLower(p.SHA256) {
+		return fmt.Errorf("invalid SHA-256 %q", p.SHA256)
+	}
+	if p.Size < 0 || p.Store == "" {
+		return errors.New("invalid size or store")
+	}
+	return nil
+}
+
+// LogArtifactContentAddressed is LogArtifact for content-addressed
+// artifacts. The file is hashed and uploaded to the experiment's blob store
+// only if no run of the experiment has logged the same content before, and
+// the run gets a pointer file. OpenArtifact, the Download functions and
+// ArtifactFS resolve pointer files, so the artifact reads as if it had been
+// logged with LogArtifact.
+func (c *Client) LogArtifactContentAddressed(runID, localPath, artifactPath string) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	repo, blobs, err := c.runBlobStore(runID)
+	if err != nil {
+		return err
+	}
+	return c.logBlob(runID, repo, blobs, localPath, path.Join(artifactPath, filepath.Base(localPath)))
+}
+
+// LogArtifactsContentAddressed is LogArtifacts for content-addressed
+// artifacts, as with LogArtifactContentAddressed
+func (c *Client) LogArtifactsContentAddressed(runID, localDir, artifactPath string) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	if err := checkLocalDir(localDir); err != nil {
+		return err
+	}
+	repo, blobs, err := c.runBlobStore(runID)
+	if err != nil {
+		return err
+	}
+	return walkLocalArtifacts(localDir, artifactPath, func(localPath, artifactPath string) error {
+		return c.logBlob(runID, repo, blobs, localPath, artifactPath)
+	})
+}
+
+// blobStore is the blob store of an experiment
+type blobStore struct {
+	uri  string
+	repo ArtifactRepository
+}
+
+// runBlobStore returns the repository of the run's artifacts and the blob
+// store of its experiment
+func (c *Client) runBlobStore(runID string) (ArtifactRepository, *blobStore, error) {
+	run, err := c.GetRun(runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	repo, err := c.ArtifactRepository(run.Run.Info.ArtifactURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	exp, err := c.GetExperiment(run.Run.Info.ExperimentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	blobs, err := c.ArtifactRepository(exp.Experiment.ArtifactLocation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, &blobStore{uri: exp.Experiment.ArtifactLocation, repo: blobs}, nil
+}
+
+// logBlob uploads a regular local file to the blob store unless it is
+// there already, and writes its pointer file at artifactPath
+func (c *Client) logBlob(runID string, repo ArtifactRepository, blobs *blobStore, localPath, artifactPath string) error {
+	if _, err := cleanArtifactPath(artifactPath); err != nil {
+		return err
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("artifact %s is not a regular file", localPath)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to hash artifact: %w", err)
+	}
+	pointer := artifactPointer{Store: blobs.uri, SHA256: hex.EncodeToString(h.Sum(nil)), Size: info.Size()}
+
+	existing, err := blobs.repo.Stat(context.Background(), pointer.blobPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err != nil || existing.IsDir || existing.FileSize != pointer.Size {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+		if err := c.uploadArtifact(runID, blobs.repo, pointer.blobPath(), f, pointer.Size); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(pointer)
+	if err != nil {
+		return err
+	}
+	return c.uploadArtifact(runID, repo, artifactPath+ArtifactPointerSuffix, bytes.NewReader(data), int64(len(data)))
+}
+
+// pointer reads the pointer file of artifactPath, once
+func (r *resolvingRepository) pointer(ctx context.Context, artifactPath string) (artifactPointer, error) {
+	r.mu.Lock()
+	pointer, ok := r.pointers[artifactPath]
+	r.mu.Unlock()
+	if ok {
+		return pointer, nil
+	}
+
+	body, err := r.ArtifactRepository.Download(ctx, artifactPath+ArtifactPointerSuffix)
+	if err != nil {
+		return artifactPointer{}, err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(io.LimitReader(body, maxPointerSize)).Decode(&pointer); err != nil {
+		return artifactPointer{}, fmt.Errorf("failed to read pointer file of artifact %s: %w", artifactPath, err)
+	}
+	if err := pointer.validate(); err != nil {
+		return artifactPointer{}, fmt.Errorf("pointer file of artifact %s: %w", artifactPath, err)
+	}
+
+	r.mu.Lock()
+	r.pointers[artifactPath] = pointer
+	r.mu.Unlock()
+	return pointer, nil
+}
+
+// openBlob opens the blob of an artifact's pointer for reading
+func (r *resolvingRepository) openBlob(ctx context.Context, artifactPath string, pointer artifactPointer) (io.ReadCloser, error) {
+	store, err := r.blobStore(artifactPath, pointer)
+	if err != nil {
+		return nil, err
+	}
+	body, err := store.Download(ctx, pointer.blobPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to download blob of artifact %s: %w", artifactPath, err)
+	}
+	return &blobReader{body: body, artifactPath: artifactPath, pointer: pointer, hash: sha256.New()}, nil
+}
+
+// blobStore returns the repository of the blob store of the run's
+// experiment, resolving it once, if it is the pointer's store. Anyone who
+// can log artifacts to the run can write its pointer files, so the store
+// they name is not followed anywhere else: it could be another host, which
+// the client would send its credentials to.
+func (r *resolvingRepository) blobStore(artifactPath string, pointer artifactPointer) (ArtifactRepository, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.blobs == nil {
+		if r.runID == "" {
+			return nil, fmt.Errorf("artifact %s is content-addressed, but its run is unknown", artifactPath)
+		}
+		_, blobs, err := r.client.runBlobStore(r.runID)
+		if err != nil {
+			return nil, err
+		}
+		r.blobs = blobs
+	}
+	if pointer.Store != r.blobs.uri {
+		return nil, fmt.Errorf("pointer file of artifact %s names blob store %s, not the artifact location of run %s's experiment", artifactPath, pointer.Store, r.runID)
+	}
+	return r.blobs.repo, nil
+}
+
+// blobReader reads a blob, failing at its end if it does not match its
+// pointer
+type blobReader struct {
+	body         io.ReadCloser
+	artifactPath string
+	pointer      artifactPointer
+	hash         hash.Hash
+	n            int64
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.hash.Write(p[:n])
+	b.n += int64(n)
+	if err == io.EOF && (b.n != b.pointer.Size || hex.EncodeToString(b.hash.Sum(nil)) != b.pointer.SHA256) {
+		return n, fmt.Errorf("blob of artifact %s does not match its SHA-256 %s", b.artifactPath, b.pointer.SHA256)
+	}
+	return n, err
+}
+
+func (b *blobReader) Close() error {
+	return b.body.Close()
+}
+
+// ArtifactBlobGCOptions configures CollectArtifactBlobs
+type ArtifactBlobGCOptions struct {
+	// DryRun reports the unreferenced blobs without deleting them
+	DryRun bool
+}
+
+// ArtifactBlobGCResult is what CollectArtifactBlobs found
+type ArtifactBlobGCResult struct {
+	// Referenced is the number of blobs that pointer files refer to
+	Referenced int
+	// Deleted are the SHA-256 digests of the unreferenced blobs, in order,
+	// which were deleted unless it was a dry run
+	Deleted      []string
+	DeletedBytes int64
+}
+
+// CollectArtifactBlobs deletes the blobs of the experiment's blob store that
+// no pointer file refers to. Experiments with the same artifact location
+// share one blob store, so the runs of all of them, including deleted runs
+// and experiments, are scanned. Blobs being logged are only referenced once
+// their pointer file is written, so do not collect an experiment while
+// content-addressed artifacts are logged to any of them.
+//
+// Experiments are matched by their artifact location as written. A location
+// that reaches the same store under another URI, such as the proxy's http
+// URL for an mlflow-artifacts location, is not recognised, and collecting
+// would delete the blobs its runs refer to.
+func (c *Client) CollectArtifactBlobs(ctx context.Context, experimentID string, opts ArtifactBlobGCOptions) (*ArtifactBlobGCResult, error) {
+	exp, err := c.GetExperiment(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	location := exp.Experiment.ArtifactLocation
+	blobs, err := c.ArtifactRepository(location)
+	if err != nil {
+		return nil, err
+	}
+	experimentIDs, err := c.experimentsAt(location)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[string]bool{}
+	req := SearchRunsRequest{ExperimentIDs: experimentIDs, RunViewType: ViewTypeAll}
+	for {
+		resp, err := c.SearchRuns(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range resp.Runs {
+			if err := c.runBlobReferences(ctx, run.Info, location, referenced); err != nil {
+				return nil, fmt.Errorf("failed to read pointer files of run %s: %w", run.Info.RunID, err)
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+
+	result := &ArtifactBlobGCResult{Referenced: len(referenced)}
+	err = walkRepository(ctx, blobs, path.Join(ArtifactBlobDir, "sha256"), func(file FileInfo) error {
+		digest := path.Base(file.Path)
+		if referenced[digest] || len(digest) != 2*sha256.Size || file.Path != blobPath(digest) {
+			return nil
+		}
+		if !opts.DryRun {
+			if err := blobs.Delete(ctx, file.Path); err != nil {
+				return err
+			}
+		}
+		result.Deleted = append(result.Deleted, digest)
+		result.DeletedBytes += file.FileSize
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(result.Deleted)
+	return result, nil
+}
+
+// experimentsAt returns the IDs of the experiments, including deleted ones,
+// whose artifact location is location
+func (c *Client) experimentsAt(location string) ([]string, error) {
+	var ids []string
+	req := SearchExperimentsRequest{ViewType: ViewTypeAll, MaxResults: experimentsPageSize}
+	for {
+		resp, err := c.SearchExperiments(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for experiments sharing artifact location %s: %w", location, err)
+		}
+		for _, exp := range resp.Experiments {
+			if strings.TrimSuffix(exp.ArtifactLocation, "/") == strings.TrimSuffix(location, "/") {
+				ids = append(ids, exp.ExperimentID)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return ids, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// runBlobReferences adds the digests of the blobs in store that the pointer
+// files of a run refer to
+func (c *Client) runBlobReferences(ctx context.Context, run RunInfo, store string, referenced map[string]bool) error {
+	repo, err := c.ArtifactRepository(run.ArtifactURI)
+	if err != nil {
+		return err
+	}
+	pointers := newResolvingRepository(c, run.RunID, "", repo)
+	return walkRepository(ctx, repo, "", func(file FileInfo) error {
+		artifactPath, ok := strings.CutSuffix(file.Path, ArtifactPointerSuffix)
+		if !ok {
+			return nil
+		}
+		pointer, err := pointers.pointer(ctx, artifactPath)
+		if err != nil {
+			return err
+		}
+		if pointer.Store == store {
+			referenced[pointer.SHA256] = true
+		}
+		return nil
+	})
+}
@@ -0,0 +1,232 @@
+package mlflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// defaultDownloadConcurrency is the number of files DownloadRunArtifacts
+// downloads at once by default
+const defaultDownloadConcurrency = 4
+
+// DownloadRunArtifactsOptions configures DownloadRunArtifacts
+type DownloadRunArtifactsOptions struct {
+	// Concurrency is the number of files downloaded at once, 4 if zero
+	Concurrency int
+	// Progress is called after each file is downloaded or skipped. Calls are
+	// not concurrent.
+	Progress func(ArtifactManifestFile)
+}
+
+// ArtifactManifest describes the files DownloadRunArtifacts put in place
+type ArtifactManifest struct {
+	RunID        string `json:"run_id"`
+	ArtifactPath string `json:"artifact_path"`
+	// LocalPath is the local path of the downloaded file or directory
+	LocalPath string                 `json:"local_path"`
+	Files     []ArtifactManifestFile `json:"files"`
+}
+
+// ArtifactManifestFile is a file of an ArtifactManifest
+type ArtifactManifestFile struct {
+	// Path is the slash separated path relative to the run's artifact root
+	Path      string `json:"path"`
+	LocalPath string `json:"local_path"`
+	Size      int64  `json:"size"`
+	// Skipped is set if the file was already present with the listed size,
+	// so it was not downloaded
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// Downloaded returns the number of files and bytes that were downloaded,
+// leaving out skipped files
+func (m *ArtifactManifest) Downloaded() (files int, bytes int64) {
+	for _, file := range m.Files {
+		if !file.Skipped {
+			files++
+			bytes += file.Size
+		}
+	}
+	return files, bytes
+}
+
+// DownloadRunArtifacts downloads the artifact file or directory at
+// artifactPath, or all of the run's artifacts if it is empty, into destDir,
+// keeping their paths relative to the run's artifact root. The tree is
+// walked with every page of ListArtifacts and its files are downloaded
+// concurrently. Each file is checked against the size in the listing and
+// only appears at its path once it is complete; files already present with
+// that size are skipped. The manifest lists the files in path order.
+//
+// Cancelling ctx stops the download. Files already in place are kept, so
+// calling DownloadRunArtifacts again carries on where it stopped.
+func (c *Client) DownloadRunArtifacts(ctx context.Context, runID, artifactPath, destDir string, opts DownloadRunArtifactsOptions) (*ArtifactManifest, error) {
+	clean, err := cleanArtifactDir(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must not be negative")
+	}
+	repo, err := c.runArtifactReader(runID)
+	if err != nil {
+		return nil, err
+	}
+	dirs, files, err := c.walkArtifacts(ctx, runID, repo, clean)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifacts of run %s: %w", runID, err)
+	}
+
+	manifest := &ArtifactManifest{
+		RunID:        runID,
+		ArtifactPath: clean,
+		LocalPath:    filepath.Join(destDir, filepath.FromSlash(clean)),
+		Files:        make([]ArtifactManifestFile, len(files)),
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(destDir, filepath.FromSlash(dir.Path)), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	work := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errOnce sync.Once
+	var firstErr error
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultDownloadConcurrency
+	}
+	for i := 0; i < min(concurrency, len(files)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				file := files[i]
+				entry := ArtifactManifestFile{
+					Path:      file.Path,
+					LocalPath: filepath.Join(destDir, filepath.FromSlash(file.Path)),
+					Size:      file.FileSize,
+				}
+				if local, err := os.Stat(entry.LocalPath); err == nil && local.Mode().IsRegular() && local.Size() == file.FileSize {
+					entry.Skipped = true
+				} else if err := downloadFile(ctx, repo, file.Path, entry.LocalPath, file.FileSize); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				manifest.Files[i] = entry
+				if opts.Progress != nil {
+					mu.Lock()
+					opts.Progress(entry)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+send:
+	for i := range files {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(work)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// walkArtifacts returns the directories and files at or under
+// artifactPath, each in path order, as repo resolves them
+func (c *Client) walkArtifacts(ctx context.Context, runID string, repo *resolvingRepository, artifactPath string) (dirs, files []FileInfo, err error) {
+	root := FileInfo{Path: artifactPath, IsDir: true}
+	if artifactPath != "" {
+		parent := path.Dir(artifactPath)
+		if parent == "." {
+			parent = ""
+		}
+		listing, err := c.listArtifactDir(ctx, runID, parent)
+		if err == nil {
+			listing, err = repo.resolve(ctx, listing)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		i := sort.Search(len(listing), func(i int) bool { return listing[i].Path >= artifactPath })
+		if i == len(listing) || listing[i].Path != artifactPath {
+			return nil, nil, notExistError(artifactPath)
+		}
+		root = listing[i]
+		if !root.IsDir {
+			return nil, []FileInfo{root}, nil
+		}
+	}
+
+	dirs = []FileInfo{root}
+	for next := 0; next < len(dirs); next++ {
+		listing, err := c.listArtifactDir(ctx, runID, dirs[next].Path)
+		if err == nil {
+			listing, err = repo.resolve(ctx, listing)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range listing {
+			if file.IsDir {
+				dirs = append(dirs, file)
+			} else {
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Path < dirs[j].Path })
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return dirs, files, nil
+}
+
+// listArtifactDir returns the files and directories directly under dir,
+// from all pages of ListArtifacts, in path order
+func (c *Client) listArtifactDir(ctx context.Context, runID, dir string) ([]FileInfo, error) {
+	files := []FileInfo{}
+	token := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resp, err := c.ListArtifacts(runID, dir, token)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range resp.Files {
+			clean, err := cleanArtifactPath(file.Path)
+			if err != nil || path.Dir("/"+clean) != path.Clean("/"+dir) {
+				return nil, fmt.Errorf("ListArtifacts of %q returned %q", dir, file.Path)
+			}
+			file.Path = clean
+			files = append(files, file)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		token = resp.NextPageToken
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
@@ -0,0 +1,388 @@
+package mlflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Encrypted artifacts are stored as their ciphertext, at the artifact's path
+// with EncryptedArtifactSuffix appended, and an encryption sidecar, with
+// EncryptionSidecarSuffix appended. The sidecar holds the artifact's data
+// key wrapped by a KeyProvider, so rotating keys only rewrites sidecars.
+//
+// The content is encrypted with AES-256-GCM in segments of 64 KiB, so it
+// can be streamed. Each segment's nonce is a random prefix, the segment
+// number and whether it is the last segment, so segments cannot be
+// reordered, dropped or appended. The data key is wrapped with the run ID
+// and artifact path as associated data, so an encrypted artifact copied to
+// another path or run does not decrypt.
+const (
+	EncryptedArtifactSuffix = ".mlflow-enc"
+	EncryptionSidecarSuffix = ".mlflow-enc.json"
+
+	encryptionAlgorithm   = "AES-256-GCM-STREAM"
+	encryptionSegmentSize = 64 << 10
+	noncePrefixSize       = 7
+	// maxSidecarSize bounds what is read of a sidecar
+	maxSidecarSize = 64 << 10
+)
+
+// errNoKeyProvider is the error for encrypting or decrypting without keys
+var errNoKeyProvider = errors.New("no key provider: set Client.ArtifactKeys")
+
+// encryptionInfo is the content of an encryption sidecar
+type encryptionInfo struct {
+	Algorithm   string `json:"algorithm"`
+	SegmentSize int64  `json:"segment_size"`
+	NoncePrefix []byte `json:"nonce_prefix"`
+	KeyID       string `json:"key_id"`
+	WrappedKey  []byte `json:"wrapped_key"`
+	// Size is the size of the plaintext
+	Size int64 `json:"size"`
+}
+
+func (e encryptionInfo) validate() error {
+	if e.Algorithm != encryptionAlgorithm {
+		return fmt.Errorf("unsupported algorithm %q", e.Algorithm)
+	}
+	// The segment size is fixed: sidecars are read from the run's
+	// artifacts, and readers allocate a segment up front
+	if e.SegmentSize != encryptionSegmentSize {
+		return fmt.Errorf("unsupported segment size %d", e.SegmentSize)
+	}
+	if len(e.NoncePrefix) != noncePrefixSize || e.KeyID == "" || len(e.WrappedKey) == 0 || e.Size < 0 {
+		return errors.New("invalid encryption parameters")
+	}
+	if e.segments() > math.MaxUint32 {
+		return errors.New("too many segments")
+	}
+	return nil
+}
+
+// segments is the number of segments, at least one so that an empty
+// artifact is authenticated too
+func (e encryptionInfo) segments() int64 {
+	return max(1, (e.Size+e.SegmentSize-1)/e.SegmentSize)
+}
+
+// segmentLength is the plaintext length of segment i
+func (e encryptionInfo) segmentLength(i int64) int64 {
+	return min(e.SegmentSize, e.Size-i*e.SegmentSize)
+}
+
+// ciphertextSize is the size of the stored ciphertext
+func (e encryptionInfo) ciphertextSize(overhead int) int64 {
+	return e.Size + e.segments()*int64(overhead)
+}
+
+// nonce is the nonce of segment i
+func (e encryptionInfo) nonce(i int64) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, e.NoncePrefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], uint32(i))
+	if i == e.segments()-1 {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// dataKeyContext is the associated data the data key of an artifact is
+// wrapped with. artifactPath is relative to the run's artifact root.
+func dataKeyContext(runID, artifactPath string) []byte {
+	return []byte("mlflow-go artifact data key v1\n" + runID + "\n" + artifactPath)
+}
+
+func newDataKeyCipher(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != 32 {
+		return nil, fmt.Errorf("data key is %d bytes, not 32", len(dataKey))
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// LogArtifactEncrypted is LogArtifact with the file encrypted on the client
+// under a new data key, which Client.ArtifactKeys wraps. OpenArtifact, the
+// Download functions and ArtifactFS decrypt it with the same key provider,
+// so it reads as if it had been logged with LogArtifact.
+func (c *Client) LogArtifactEncrypted(runID, localPath, artifactPath string) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	if c.ArtifactKeys == nil {
+		return errNoKeyProvider
+	}
+	repo, err := c.RunArtifactRepository(runID)
+	if err != nil {
+		return err
+	}
+	return c.logEncrypted(runID, repo, localPath, path.Join(artifactPath, filepath.Base(localPath)))
+}
+
+// LogArtifactsEncrypted is LogArtifacts with each file encrypted as with
+// LogArtifactEncrypted
+func (c *Client) LogArtifactsEncrypted(runID, localDir, artifactPath string) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	if c.ArtifactKeys == nil {
+		return errNoKeyProvider
+	}
+	if err := checkLocalDir(localDir); err != nil {
+		return err
+	}
+	repo, err := c.RunArtifactRepository(runID)
+	if err != nil {
+		return err
+	}
+	return walkLocalArtifacts(localDir, artifactPath, func(localPath, artifactPath string) error {
+		return c.logEncrypted(runID, repo, localPath, artifactPath)
+	})
+}
+
+// logEncrypted streams a regular local file's ciphertext to artifactPath,
+// then writes its sidecar
+func (c *Client) logEncrypted(runID string, repo ArtifactRepository, localPath, artifactPath string) error {
+	if _, err := cleanArtifactPath(artifactPath); err != nil {
+		return err
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	if !stat.Mode().IsRegular() {
+		return fmt.Errorf("artifact %s is not a regular file", localPath)
+	}
+
+	dataKey := make([]byte, 32)
+	info := encryptionInfo{
+		Algorithm:   encryptionAlgorithm,
+		SegmentSize: encryptionSegmentSize,
+		NoncePrefix: make([]byte, noncePrefixSize),
+		KeyID:       c.ArtifactKeys.KeyID(),
+		Size:        stat.Size(),
+	}
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	if _, err := rand.Read(info.NoncePrefix); err != nil {
+		return err
+	}
+	if info.WrappedKey, err = c.ArtifactKeys.WrapKey(context.Background(), dataKey, dataKeyContext(runID, artifactPath)); err != nil {
+		return fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	aead, err := newDataKeyCipher(dataKey)
+	if err != nil {
+		return err
+	}
+	// the sidecar goes first: a listing drops a sidecar without its
+	// ciphertext, but cannot describe a ciphertext without its sidecar
+	if err := c.putSidecar(runID, repo, artifactPath, info); err != nil {
+		return err
+	}
+	content := &encryptingReader{src: f, aead: aead, info: info, artifactPath: artifactPath}
+	return c.uploadArtifact(runID, repo, artifactPath+EncryptedArtifactSuffix, content, info.ciphertextSize(aead.Overhead()))
+}
+
+// putSidecar writes the encryption sidecar of artifactPath
+func (c *Client) putSidecar(runID string, repo ArtifactRepository, artifactPath string, info encryptionInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return c.uploadArtifact(runID, repo, artifactPath+EncryptionSidecarSuffix, bytes.NewReader(data), int64(len(data)))
+}
+
+// encryptingReader reads the ciphertext of src
+type encryptingReader struct {
+	src          io.Reader
+	aead         cipher.AEAD
+	info         encryptionInfo
+	artifactPath string
+	segment      int64
+	plain        []byte
+	sealed       []byte
+	out          []byte
+}
+
+func (e *encryptingReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.segment == e.info.segments() {
+			return 0, io.EOF
+		}
+		n := e.info.segmentLength(e.segment)
+		if e.plain == nil {
+			e.plain = make([]byte, e.info.SegmentSize)
+		}
+		if _, err := io.ReadFull(e.src, e.plain[:n]); err != nil {
+			return 0, fmt.Errorf("failed to read artifact %s: %w", e.artifactPath, err)
+		}
+		e.sealed = e.aead.Seal(e.sealed[:0], e.info.nonce(e.segment), e.plain[:n], nil)
+		e.out = e.sealed
+		e.segment++
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// encryptionInfo reads the encryption sidecar of artifactPath, once
+func (r *resolvingRepository) encryptionInfo(ctx context.Context, artifactPath string) (encryptionInfo, error) {
+	r.mu.Lock()
+	info, ok := r.encryption[artifactPath]
+	r.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	body, err := r.ArtifactRepository.Download(ctx, artifactPath+EncryptionSidecarSuffix)
+	if err != nil {
+		return encryptionInfo{}, err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(io.LimitReader(body, maxSidecarSize)).Decode(&info); err != nil {
+		return encryptionInfo{}, fmt.Errorf("failed to read encryption sidecar of artifact %s: %w", artifactPath, err)
+	}
+	if err := info.validate(); err != nil {
+		return encryptionInfo{}, fmt.Errorf("encryption sidecar of artifact %s: %w", artifactPath, err)
+	}
+
+	r.mu.Lock()
+	r.encryption[artifactPath] = info
+	r.mu.Unlock()
+	return info, nil
+}
+
+// decrypt opens an encrypted artifact for reading its plaintext
+func (r *resolvingRepository) decrypt(ctx context.Context, artifactPath string, info encryptionInfo) (io.ReadCloser, error) {
+	if r.client.ArtifactKeys == nil {
+		return nil, fmt.Errorf("artifact %s is encrypted: %w", artifactPath, errNoKeyProvider)
+	}
+	if r.runID == "" {
+		return nil, fmt.Errorf("artifact %s is encrypted, but its run is unknown", artifactPath)
+	}
+	dataKey, err := r.client.ArtifactKeys.UnwrapKey(ctx, info.KeyID, info.WrappedKey, dataKeyContext(r.runID, r.runPath(artifactPath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt artifact %s: %w", artifactPath, err)
+	}
+	aead, err := newDataKeyCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt artifact %s: %w", artifactPath, err)
+	}
+	body, err := r.ArtifactRepository.Download(ctx, artifactPath+EncryptedArtifactSuffix)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingReader{body: body, aead: aead, info: info, artifactPath: artifactPath}, nil
+}
+
+// decryptingReader reads the plaintext of an encrypted artifact, failing if
+// any of it was altered
+type decryptingReader struct {
+	body         io.ReadCloser
+	aead         cipher.AEAD
+	info         encryptionInfo
+	artifactPath string
+	segment      int64
+	sealed       []byte
+	plain        []byte
+	out          []byte
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.segment == d.info.segments() {
+			if n, _ := d.body.Read(make([]byte, 1)); n > 0 {
+				return 0, fmt.Errorf("encrypted artifact %s is longer than its sidecar says", d.artifactPath)
+			}
+			return 0, io.EOF
+		}
+		n := d.info.segmentLength(d.segment) + int64(d.aead.Overhead())
+		if d.sealed == nil {
+			d.sealed = make([]byte, d.info.SegmentSize+int64(d.aead.Overhead()))
+		}
+		if _, err := io.ReadFull(d.body, d.sealed[:n]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+				return 0, fmt.Errorf("encrypted artifact %s is truncated", d.artifactPath)
+			}
+			return 0, err
+		}
+		plain, err := d.aead.Open(d.plain[:0], d.info.nonce(d.segment), d.sealed[:n], nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt artifact %s: %w", d.artifactPath, err)
+		}
+		d.plain, d.out = plain, plain
+		d.segment++
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptingReader) Close() error {
+	return d.body.Close()
+}
+
+// RotateArtifactKeys re-wraps the data keys of the run's encrypted
+// artifacts with the current key of Client.ArtifactKeys, which must still
+// unwrap the keys they were wrapped with. Only the sidecars are rewritten.
+// It returns the number of artifacts re-wrapped, leaving out those already
+// under the current key.
+func (c *Client) RotateArtifactKeys(ctx context.Context, runID string) (int, error) {
+	if c.ArtifactKeys == nil {
+		return 0, errNoKeyProvider
+	}
+	repo, err := c.runArtifactReader(runID)
+	if err != nil {
+		return 0, err
+	}
+	current := c.ArtifactKeys.KeyID()
+	rotated := 0
+	err = walkRepository(ctx, repo.ArtifactRepository, "", func(file FileInfo) error {
+		artifactPath, ok := strings.CutSuffix(file.Path, EncryptionSidecarSuffix)
+		if !ok {
+			return nil
+		}
+		info, err := repo.encryptionInfo(ctx, artifactPath)
+		if err != nil {
+			return err
+		}
+		if info.KeyID == current {
+			return nil
+		}
+		dataKey, err := c.ArtifactKeys.UnwrapKey(ctx, info.KeyID, info.WrappedKey, dataKeyContext(runID, artifactPath))
+		if err != nil {
+			return fmt.Errorf("failed to rotate the key of artifact %s: %w", artifactPath, err)
+		}
+		if info.WrappedKey, err = c.ArtifactKeys.WrapKey(ctx, dataKey, dataKeyContext(runID, artifactPath)); err != nil {
+			return fmt.Errorf("failed to rotate the key of artifact %s: %w", artifactPath, err)
+		}
+		info.KeyID = current
+		if err := c.putSidecar(runID, repo.ArtifactRepository, artifactPath, info); err != nil {
+			return err
+		}
+		rotated++
+		return nil
+	})
+	return rotated, err
+}
@@ -0,0 +1,363 @@
+package mlflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ArtifactFSOptions configures NewArtifactFS
+type ArtifactFSOptions struct {
+	// CacheDir is a local directory to keep downloaded artifacts in, with a
+	// subdirectory per run, empty for no cache. A cached file is used if
+	// its size matches the listing.
+	CacheDir string
+}
+
+// ArtifactFS is a read-only fs.FS of a run's artifacts, for fs.WalkDir,
+// template.ParseFS, http.FS and the like. Directories are listed with
+// ListArtifacts when first used and the listing is kept for the life of the
+// ArtifactFS; make a new one to see artifacts logged since. Files are
+// downloaded from the run's artifact repository when first read.
+type ArtifactFS struct {
+	client *Client
+	runID  string
+	opts   ArtifactFSOptions
+
+	mu   sync.Mutex
+	dirs map[string][]FileInfo
+	repo *resolvingRepository
+}
+
+var (
+	_ fs.ReadDirFS = (*ArtifactFS)(nil)
+	_ fs.StatFS    = (*ArtifactFS)(nil)
+)
+
+// NewArtifactFS returns the file system of the run's artifacts
+func NewArtifactFS(c *Client, runID string, opts ArtifactFSOptions) *ArtifactFS {
+	return &ArtifactFS{client: c, runID: runID, opts: opts, dirs: map[string][]FileInfo{}}
+}
+
+// ArtifactFS returns the file system of the run's artifacts, without a
+// local cache. See NewArtifactFS to configure one.
+func (c *Client) ArtifactFS(runID string) fs.FS {
+	return NewArtifactFS(c, runID, ArtifactFSOptions{})
+}
+
+// artifactPath returns the artifact path of a valid fs.FS name, "" for
+// the root
+func artifactPath(name string) string {
+	if name == "." {
+		return ""
+	}
+	return name
+}
+
+// list returns the files and directories directly under dir, from all
+// pages of ListArtifacts
+func (f *ArtifactFS) list(dir string) ([]FileInfo, error) {
+	f.mu.Lock()
+	files, ok := f.dirs[dir]
+	f.mu.Unlock()
+	if ok {
+		return files, nil
+	}
+
+	ctx := context.Background()
+	files, err := f.client.listArtifactDir(ctx, f.runID, dir)
+	if err != nil {
+		return nil, err
+	}
+	if needsResolving(files) {
+		repo, err := f.repository()
+		if err != nil {
+			return nil, err
+		}
+		if files, err = repo.resolve(ctx, files); err != nil {
+			return nil, err
+		}
+	}
+	f.mu.Lock()
+	f.dirs[dir] = files
+	f.mu.Unlock()
+	return files, nil
+}
+
+// stat describes the artifact of a valid fs.FS name from the listing of
+// its directory
+func (f *ArtifactFS) stat(name string) (FileInfo, error) {
+	if name == "." {
+		return FileInfo{IsDir: true}, nil
+	}
+	dir := path.Dir(name)
+	files, err := f.list(artifactPath(dir))
+	if err != nil {
+		return FileInfo{}, err
+	}
+	i := sort.Search(len(files), func(i int) bool { return files[i].Path >= name })
+	if i == len(files) || files[i].Path != name {
+		return FileInfo{}, fs.ErrNotExist
+	}
+	return files[i], nil
+}
+
+// Stat implements fs.StatFS
+func (f *ArtifactFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := f.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return artifactFileInfo{info}, nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (f *ArtifactFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, err := f.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+func (f *ArtifactFS) readDir(name string) ([]fs.DirEntry, error) {
+	info, err := f.stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir {
+		return nil, errors.New("not a directory")
+	}
+	files, err := f.list(artifactPath(name))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, len(files))
+	for i, file := range files {
+		entries[i] = fs.FileInfoToDirEntry(artifactFileInfo{file})
+	}
+	return entries, nil
+}
+
+// Open implements fs.FS. Opening a file does not download it; that
+// happens on the first Read or Seek. Files implement io.Seeker, as
+// http.FS needs.
+func (f *ArtifactFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := f.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if info.IsDir {
+		return &artifactDir{fsys: f, name: name, info: info}, nil
+	}
+	return &artifactFile{fsys: f, info: info}, nil
+}
+
+// repository returns the run's artifact repository, resolving it once
+func (f *ArtifactFS) repository() (*resolvingRepository, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.repo == nil {
+		repo, err := f.client.runArtifactReader(f.runID)
+		if err != nil {
+			return nil, err
+		}
+		f.repo = repo
+	}
+	return f.repo, nil
+}
+
+// open opens a file at offset, from the cache if there is one
+func (f *ArtifactFS) open(info FileInfo, offset int64) (io.ReadCloser, error) {
+	repo, err := f.repository()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if f.opts.CacheDir == "" {
+		body, err := repo.Download(ctx, info.Path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, body, offset); err != nil && err != io.EOF {
+			body.Close()
+			return nil, fmt.Errorf("failed to download artifact %s: %w", info.Path, err)
+		}
+		return body, nil
+	}
+
+	local := filepath.Join(f.opts.CacheDir, f.runID, filepath.FromSlash(info.Path))
+	if cached, err := os.Stat(local); err != nil || cached.Size() != info.FileSize {
+		if err := downloadFile(ctx, repo, info.Path, local, -1); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.Open(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cached artifact: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open cached artifact: %w", err)
+	}
+	return file, nil
+}
+
+// artifactFileInfo is the fs.FileInfo of an artifact. Artifacts have no
+// modification time.
+type artifactFileInfo struct {
+	info FileInfo
+}
+
+func (i artifactFileInfo) Name() string {
+	if i.info.Path == "" {
+		return "."
+	}
+	return path.Base(i.info.Path)
+}
+
+func (i artifactFileInfo) Size() int64 { return i.info.FileSize }
+
+func (i artifactFileInfo) Mode() fs.FileMode {
+	if i.info.IsDir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i artifactFileInfo) ModTime() time.Time { return time.Time{} }
+func (i artifactFileInfo) IsDir() bool        { return i.info.IsDir }
+
+// Sys returns the artifact's FileInfo
+func (i artifactFileInfo) Sys() any { return i.info }
+
+// artifactFile is an open artifact file. Its content is downloaded when
+// first read, and again after seeking if it is not cached.
+type artifactFile struct {
+	fsys   *ArtifactFS
+	info   FileInfo
+	body   io.ReadCloser
+	offset int64
+	closed bool
+}
+
+func (f *artifactFile) Stat() (fs.FileInfo, error) {
+	return artifactFileInfo{f.info}, nil
+}
+
+func (f *artifactFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.info.Path, Err: fs.ErrClosed}
+	}
+	if f.body == nil {
+		body, err := f.fsys.open(f.info, f.offset)
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.info.Path, Err: err}
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *artifactFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.Path, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.FileSize
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.Path, Err: fs.ErrInvalid}
+	}
+	if offset == f.offset {
+		return offset, nil
+	}
+	if seeker, ok := f.body.(io.Seeker); ok {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return 0, &fs.PathError{Op: "seek", Path: f.info.Path, Err: err}
+		}
+	} else if f.body != nil {
+		// Downloads are reopened at the new offset when next read
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *artifactFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.info.Path, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
+
+// artifactDir is an open artifact directory
+type artifactDir struct {
+	fsys    *ArtifactFS
+	name    string
+	info    FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *artifactDir) Stat() (fs.FileInfo, error) {
+	return artifactFileInfo{d.info}, nil
+}
+
+func (d *artifactDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *artifactDir) Close() error {
+	return nil
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *artifactDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.readDir(d.name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
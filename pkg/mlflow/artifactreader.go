@@ -0,0 +1,200 @@
+package mlflow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// resolvingRepository is a run's artifact repository that reads pointer
+// files as the blobs they point to and encrypted artifacts as their
+// plaintext. Listings show the artifact's path and size in place of the
+// stored files. Blobs are only read from the blob store of the run's
+// experiment.
+type resolvingRepository struct {
+	ArtifactRepository
+	client *Client
+	runID  string
+	// prefix is the path of the repository's root in the run's artifacts
+	prefix string
+
+	mu         sync.Mutex
+	pointers   map[string]artifactPointer
+	encryption map[string]encryptionInfo
+	blobs      *blobStore
+}
+
+func newResolvingRepository(c *Client, runID, prefix string, repo ArtifactRepository) *resolvingRepository {
+	return &resolvingRepository{
+		ArtifactRepository: repo,
+		client:             c,
+		runID:              runID,
+		prefix:             prefix,
+		pointers:           map[string]artifactPointer{},
+		encryption:         map[string]encryptionInfo{},
+	}
+}
+
+// runArtifactReader returns the run's artifact repository, resolving
+// pointer files and encrypted artifacts
+func (c *Client) runArtifactReader(runID string) (*resolvingRepository, error) {
+	repo, err := c.RunArtifactRepository(runID)
+	if err != nil {
+		return nil, err
+	}
+	return newResolvingRepository(c, runID, "", repo), nil
+}
+
+// runPath is the path of an artifact of the repository relative to the
+// run's artifact root
+func (r *resolvingRepository) runPath(artifactPath string) string {
+	return path.Join(r.prefix, artifactPath)
+}
+
+// needsResolving reports whether a listing has pointer files or encrypted
+// artifacts
+func needsResolving(files []FileInfo) bool {
+	for _, file := range files {
+		if !file.IsDir && (strings.HasSuffix(file.Path, ArtifactPointerSuffix) ||
+			strings.HasSuffix(file.Path, EncryptedArtifactSuffix) ||
+			strings.HasSuffix(file.Path, EncryptionSidecarSuffix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve replaces the pointer files and encrypted artifacts of a listing
+// with the artifacts they stand for and drops encryption sidecars and
+// ciphertexts without one, keeping it in path order
+func (r *resolvingRepository) resolve(ctx context.Context, files []FileInfo) ([]FileInfo, error) {
+	if !needsResolving(files) {
+		return files, nil
+	}
+	resolved := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		if file.IsDir {
+			resolved = append(resolved, file)
+			continue
+		}
+		if artifactPath, ok := strings.CutSuffix(file.Path, ArtifactPointerSuffix); ok {
+			pointer, err := r.pointer(ctx, artifactPath)
+			if err != nil {
+				return nil, err
+			}
+			file = FileInfo{Path: artifactPath, FileSize: pointer.Size}
+		} else if artifactPath, ok := strings.CutSuffix(file.Path, EncryptedArtifactSuffix); ok {
+			info, err := r.encryptionInfo(ctx, artifactPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				// an upload that stopped before its sidecar was written
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			file = FileInfo{Path: artifactPath, FileSize: info.Size}
+		} else if strings.HasSuffix(file.Path, EncryptionSidecarSuffix) {
+			continue
+		}
+		resolved = append(resolved, file)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Path < resolved[j].Path })
+	return resolved, nil
+}
+
+// List implements ArtifactRepository
+func (r *resolvingRepository) List(ctx context.Context, dir string) ([]FileInfo, error) {
+	files, err := r.ArtifactRepository.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return r.resolve(ctx, files)
+}
+
+// Stat implements ArtifactRepository
+func (r *resolvingRepository) Stat(ctx context.Context, artifactPath string) (FileInfo, error) {
+	info, err := r.ArtifactRepository.Stat(ctx, artifactPath)
+	if artifactPath == "" || !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+	return r.statMissing(ctx, artifactPath, err)
+}
+
+// statMissing describes an artifact that is not stored as itself from its
+// pointer file or encryption sidecar, returning notFound if it has neither
+func (r *resolvingRepository) statMissing(ctx context.Context, artifactPath string, notFound error) (FileInfo, error) {
+	pointer, err := r.pointer(ctx, artifactPath)
+	if err == nil {
+		return FileInfo{Path: artifactPath, FileSize: pointer.Size}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return FileInfo{}, err
+	}
+	info, err := r.encryptionInfo(ctx, artifactPath)
+	if err == nil {
+		return FileInfo{Path: artifactPath, FileSize: info.Size}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return FileInfo{}, err
+	}
+	return FileInfo{}, notFound
+}
+
+// Download implements ArtifactRepository
+func (r *resolvingRepository) Download(ctx context.Context, artifactPath string) (io.ReadCloser, error) {
+	if body, ok, err := r.open(ctx, artifactPath); ok {
+		return body, err
+	}
+	body, err := r.ArtifactRepository.Download(ctx, artifactPath)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return body, err
+	}
+	if _, err := r.statMissing(ctx, artifactPath, err); err != nil {
+		return nil, err
+	}
+	body, _, err = r.open(ctx, artifactPath)
+	return body, err
+}
+
+// open opens an artifact whose pointer file or encryption sidecar has been
+// read, reporting whether there was one
+func (r *resolvingRepository) open(ctx context.Context, artifactPath string) (io.ReadCloser, bool, error) {
+	r.mu.Lock()
+	pointer, isPointer := r.pointers[artifactPath]
+	info, isEncrypted := r.encryption[artifactPath]
+	r.mu.Unlock()
+	switch {
+	case isPointer:
+		body, err := r.openBlob(ctx, artifactPath, pointer)
+		return body, true, err
+	case isEncrypted:
+		body, err := r.decrypt(ctx, artifactPath, info)
+		return body, true, err
+	}
+	return nil, false, nil
+}
+
+// walkRepository calls fn for every file under dir of a repository
+func walkRepository(ctx context.Context, repo ArtifactRepository, dir string, fn func(FileInfo) error) error {
+	dirs := []string{dir}
+	for len(dirs) > 0 {
+		files, err := repo.List(ctx, dirs[0])
+		if err != nil {
+			return err
+		}
+		dirs = dirs[1:]
+		for _, file := range files {
+			if file.IsDir {
+				dirs = append(dirs, file.Path)
+			} else if err := fn(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
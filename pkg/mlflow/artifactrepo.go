@@ -0,0 +1,126 @@
+package mlflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/julpayne/mlflow-go-client/internal/artifactpath"
+)
+
+// ArtifactRepository stores the artifacts under one artifact URI, such as a
+// run's RunInfo.ArtifactURI or an experiment's ArtifactLocation. Paths are
+// slash separated and relative to that URI. Errors for a missing file or
+// directory satisfy errors.Is(err, fs.ErrNotExist).
+type ArtifactRepository interface {
+	// List lists the files and directories directly under dir, "" for the
+	// root. A missing directory has no entries.
+	List(ctx context.Context, dir string) ([]FileInfo, error)
+	// Upload stores the size bytes of content, -1 if unknown, as the file
+	// at path, replacing any file there
+	Upload(ctx context.Context, path string, content io.Reader, size int64) error
+	// Download opens the file at path. The caller must close it.
+	Download(ctx context.Context, path string) (io.ReadCloser, error)
+	// Delete removes the file or directory at path, "" for everything.
+	// Deleting a missing path is not an error.
+	Delete(ctx context.Context, path string) error
+	// Stat describes the file or directory at path, "" for the root
+	Stat(ctx context.Context, path string) (FileInfo, error)
+}
+
+// ArtifactRepositoryFactory returns the repository of an artifact URI. The
+// client is the one resolving the URI, for backends that talk to the
+// tracking server.
+type ArtifactRepositoryFactory func(c *Client, artifactURI string) (ArtifactRepository, error)
+
+var artifactRepositories = struct {
+	sync.RWMutex
+	factories map[string]ArtifactRepositoryFactory
+}{factories: map[string]ArtifactRepositoryFactory{
+	"file": func(_ *Client, artifactURI string) (ArtifactRepository, error) {
+		return NewLocalArtifactRepository(artifactURI)
+	},
+	"mlflow-artifacts": newProxyArtifactRepository,
+	"http":             newProxyArtifactRepository,
+	"https":            newProxyArtifactRepository,
+}}
+
+func newProxyArtifactRepository(c *Client, artifactURI string) (ArtifactRepository, error) {
+	return NewProxyArtifactRepository(c, artifactURI)
+}
+
+// RegisterArtifactRepository makes artifact URIs with the scheme resolve to
+// the repositories factory returns, replacing any backend registered for it.
+// Local paths without a scheme use the "file" backend.
+func RegisterArtifactRepository(scheme string, factory ArtifactRepositoryFactory) {
+	artifactRepositories.Lock()
+	defer artifactRepositories.Unlock()
+	artifactRepositories.factories[strings.ToLower(scheme)] = factory
+}
+
+// ArtifactRepository returns the repository of an artifact URI from the
+// backend registered for its scheme
+func (c *Client) ArtifactRepository(artifactURI string) (ArtifactRepository, error) {
+	scheme := "file"
+	if uri, err := url.Parse(artifactURI); err == nil && len(uri.Scheme) > 1 {
+		// A one-letter scheme is a Windows drive
+		scheme = strings.ToLower(uri.Scheme)
+	}
+	artifactRepositories.RLock()
+	factory, ok := artifactRepositories.factories[scheme]
+	artifactRepositories.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no artifact repository for scheme %q of artifact URI %q", scheme, artifactURI)
+	}
+	return factory(c, artifactURI)
+}
+
+// RunArtifactRepository returns the repository of the run's artifact URI
+func (c *Client) RunArtifactRepository(runID string) (ArtifactRepository, error) {
+	resp, err := c.GetRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	return c.ArtifactRepository(resp.Run.Info.ArtifactURI)
+}
+
+// cleanArtifactDir is cleanArtifactPath that also accepts "" for the root
+func cleanArtifactDir(dir string) (string, error) {
+	return artifactpath.Clean(dir, true)
+}
+
+// statFromList describes path from the listing of its directory, for
+// backends without a stat call
+func statFromList(ctx context.Context, repo ArtifactRepository, artifactPath string) (FileInfo, error) {
+	clean, err := cleanArtifactDir(artifactPath)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if clean == "" {
+		return FileInfo{IsDir: true}, nil
+	}
+	dir := path.Dir(clean)
+	if dir == "." {
+		dir = ""
+	}
+	files, err := repo.List(ctx, dir)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	for _, file := range files {
+		if file.Path == clean {
+			return file, nil
+		}
+	}
+	return FileInfo{}, notExistError(clean)
+}
+
+// notExistError is the error for a missing artifact
+func notExistError(artifactPath string) error {
+	return fmt.Errorf("artifact %s: %w", artifactPath, fs.ErrNotExist)
+}
@@ -0,0 +1,156 @@
+package mlflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalArtifactRepository stores artifacts in a local directory, for
+// file:// artifact URIs and plain paths
+type LocalArtifactRepository struct {
+	// Root is the directory the artifact paths are relative to
+	Root string
+}
+
+// NewLocalArtifactRepository returns the repository of a file:// URI or a
+// local path
+func NewLocalArtifactRepository(artifactURI string) (*LocalArtifactRepository, error) {
+	root := artifactURI
+	if uri, err := url.Parse(artifactURI); err == nil && uri.Scheme == "file" {
+		if uri.Host != "" && uri.Host != "localhost" {
+			return nil, fmt.Errorf("file artifact URI %q names a remote host", artifactURI)
+		}
+		root = uri.Path
+	}
+	if root == "" {
+		return nil, fmt.Errorf("artifact URI %q has no path", artifactURI)
+	}
+	return &LocalArtifactRepository{Root: filepath.FromSlash(root)}, nil
+}
+
+// local returns the local path of a clean artifact path
+func (r *LocalArtifactRepository) local(artifactPath string) string {
+	return filepath.Join(r.Root, filepath.FromSlash(artifactPath))
+}
+
+// List implements ArtifactRepository
+func (r *LocalArtifactRepository) List(_ context.Context, dir string) ([]FileInfo, error) {
+	clean, err := cleanArtifactDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(r.local(clean))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	files := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		file := FileInfo{Path: path.Join(clean, entry.Name()), IsDir: entry.IsDir()}
+		if !entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list artifacts: %w", err)
+			}
+			file.FileSize = info.Size()
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Upload implements ArtifactRepository. The file only appears once it is
+// complete.
+func (r *LocalArtifactRepository) Upload(_ context.Context, artifactPath string, content io.Reader, size int64) error {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return err
+	}
+	target := r.local(clean)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	written, err := io.Copy(tmp, content)
+	if err == nil {
+		// CreateTemp makes the file private, but the store is shared with
+		// the tracking server and other clients
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write artifact %s: %w", clean, err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("failed to write artifact %s: got %d of %d bytes", clean, written, size)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to write artifact %s: %w", clean, err)
+	}
+	return nil
+}
+
+// Download implements ArtifactRepository
+func (r *LocalArtifactRepository) Download(_ context.Context, artifactPath string) (io.ReadCloser, error) {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(r.local(clean))
+	if os.IsNotExist(err) {
+		return nil, notExistError(clean)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact %s: %w", clean, err)
+	}
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("artifact %s is a directory", clean)
+	}
+	return f, nil
+}
+
+// Delete implements ArtifactRepository
+func (r *LocalArtifactRepository) Delete(_ context.Context, artifactPath string) error {
+	clean, err := cleanArtifactDir(artifactPath)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(r.local(clean)); err != nil {
+		return fmt.Errorf("failed to delete artifact %s: %w", clean, err)
+	}
+	return nil
+}
+
+// Stat implements ArtifactRepository
+func (r *LocalArtifactRepository) Stat(_ context.Context, artifactPath string) (FileInfo, error) {
+	clean, err := cleanArtifactDir(artifactPath)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	info, err := os.Stat(r.local(clean))
+	if os.IsNotExist(err) {
+		return FileInfo{}, notExistError(clean)
+	}
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to stat artifact %s: %w", clean, err)
+	}
+	file := FileInfo{Path: clean, IsDir: info.IsDir()}
+	if !info.IsDir() {
+		file.FileSize = info.Size()
+	}
+	return file, nil
+}
@@ -0,0 +1,216 @@
+package mlflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// endpointArtifactsProxy is the tracking server's artifact proxy, which
+// serves artifact URIs with the mlflow-artifacts scheme
+const endpointArtifactsProxy = "/api/2.0/mlflow-artifacts/artifacts"
+
+// ProxyArtifactRepository stores artifacts through a tracking server's
+// artifact proxy, for mlflow-artifacts URIs and http(s) URLs of the proxy
+type ProxyArtifactRepository struct {
+	client *Client
+	root   artifactRoot
+}
+
+// artifactRoot is an artifact root on an artifact proxy
+type artifactRoot struct {
+	// baseURL is the server that proxies the artifacts, the tracking server
+	// unless the artifact URI names another host
+	baseURL string
+	// path is the root's path under the proxy endpoint, without slashes at
+	// either end
+	path string
+	// trackingServer is set when baseURL has the tracking server's scheme and
+	// host, the only server the client's token is sent to
+	trackingServer bool
+}
+
+// authorize adds the client's token to a request for the root, unless the
+// root is on another server than the tracking server
+func (r artifactRoot) authorize(req *http.Request, token string) {
+	if r.trackingServer && token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// NewProxyArtifactRepository returns the repository of an artifact URI
+// served by an artifact proxy. mlflow-artifacts:/path is served by the
+// client's tracking server and mlflow-artifacts://host/path by another host
+// with the tracking server's scheme. An http(s) URL must point into the
+// proxy's /api/2.0/mlflow-artifacts/artifacts endpoint. The client's token
+// is only sent when the proxy has the tracking server's scheme and host.
+func NewProxyArtifactRepository(c *Client, artifactURI string) (*ProxyArtifactRepository, error) {
+	uri, err := url.Parse(artifactURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URI %q: %w", artifactURI, err)
+	}
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracking URI %q: %w", c.BaseURL, err)
+	}
+	var root artifactRoot
+	switch uri.Scheme {
+	case "mlflow-artifacts":
+		root.baseURL = c.BaseURL
+		root.trackingServer = true
+		if uri.Host != "" {
+			root.baseURL = base.Scheme + "://" + uri.Host
+			root.trackingServer = strings.EqualFold(uri.Host, base.Host)
+		}
+		root.path = uri.Path
+	case "http", "https":
+		prefix, rest, ok := strings.Cut(uri.Path, endpointArtifactsProxy)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			return nil, fmt.Errorf("artifact URI %q is not under the artifact proxy endpoint %s", artifactURI, endpointArtifactsProxy)
+		}
+		root.baseURL = uri.Scheme + "://" + uri.Host + prefix
+		root.trackingServer = strings.EqualFold(uri.Scheme, base.Scheme) && strings.EqualFold(uri.Host, base.Host)
+		root.path = rest
+	default:
+		return nil, fmt.Errorf("artifact URI %q is not served by an artifact proxy", artifactURI)
+	}
+	root.path = strings.Trim(root.path, "/")
+	return &ProxyArtifactRepository{client: c, root: root}, nil
+}
+
+// url returns the URL of a clean artifact path under the root on an
+// endpoint of the artifact proxy. An empty path is the root itself.
+func (r artifactRoot) url(endpoint, artifactPath string) string {
+	var segments []string
+	for _, segment := range strings.Split(path.Join(r.path, artifactPath), "/") {
+		if segment != "" {
+			segments = append(segments, url.PathEscape(segment))
+		}
+	}
+	return r.baseURL + endpoint + "/" + strings.Join(segments, "/")
+}
+
+// do sends a request for a clean artifact path and returns the response if
+// its status is 2xx
+func (r *ProxyArtifactRepository) do(ctx context.Context, method, artifactPath string, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.root.url(endpointArtifactsProxy, artifactPath), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	r.root.authorize(req, r.client.AuthToken)
+	resp, err := r.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		apiErr := newAPIError(resp.StatusCode, respBody)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("artifact %s: %w: %w", artifactPath, fs.ErrNotExist, apiErr)
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+// List implements ArtifactRepository
+func (r *ProxyArtifactRepository) List(ctx context.Context, dir string) ([]FileInfo, error) {
+	clean, err := cleanArtifactDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := r.root.baseURL + endpointArtifactsProxy + "?" + url.Values{"path": {path.Join(r.root.path, clean)}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	r.root.authorize(req, r.client.AuthToken)
+	resp, err := r.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	var listing struct {
+		Files []FileInfo `json:"files"`
+	}
+	if err := json.Unmarshal(respBody, &listing); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	// The proxy lists base names
+	for i := range listing.Files {
+		listing.Files[i].Path = path.Join(clean, path.Base(listing.Files[i].Path))
+	}
+	return listing.Files, nil
+}
+
+// Upload implements ArtifactRepository
+func (r *ProxyArtifactRepository) Upload(ctx context.Context, artifactPath string, content io.Reader, size int64) error {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return err
+	}
+	resp, err := r.do(ctx, http.MethodPut, clean, content, size)
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Download implements ArtifactRepository
+func (r *ProxyArtifactRepository) Download(ctx context.Context, artifactPath string) (io.ReadCloser, error) {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.do(ctx, http.MethodGet, clean, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete implements ArtifactRepository
+func (r *ProxyArtifactRepository) Delete(ctx context.Context, artifactPath string) error {
+	clean, err := cleanArtifactDir(artifactPath)
+	if err != nil {
+		return err
+	}
+	resp, err := r.do(ctx, http.MethodDelete, clean, nil, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete artifact %s: %w", clean, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Stat implements ArtifactRepository. The proxy has no stat endpoint, so it
+// lists the parent directory.
+func (r *ProxyArtifactRepository) Stat(ctx context.Context, artifactPath string) (FileInfo, error) {
+	return statFromList(ctx, r, artifactPath)
+}
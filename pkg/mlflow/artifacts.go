@@ -0,0 +1,267 @@
+package mlflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/julpayne/mlflow-go-client/internal/artifactpath"
+)
+
+// LogText logs text as an artifact of the run at artifactPath, a slash
+// separated path relative to the run's artifact root
+func (c *Client) LogText(runID, artifactPath, text string) error {
+	return c.putArtifact(runID, artifactPath, []byte(text))
+}
+
+// LogArtifact uploads a local file to the run's artifact directory
+// artifactPath, keeping the file's name. An empty artifactPath uploads to
+// the root. The file is streamed, not read into memory.
+func (c *Client) LogArtifact(runID, localPath, artifactPath string) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	return c.logFile(runID, nil, localPath, path.Join(artifactPath, filepath.Base(localPath)))
+}
+
+// LogArtifacts uploads the files in a local directory and its
+// subdirectories to the run's artifact directory artifactPath, keeping their
+// relative paths. An empty artifactPath uploads to the root.
+func (c *Client) LogArtifacts(runID, localDir, artifactPath string) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	if err := checkLocalDir(localDir); err != nil {
+		return err
+	}
+	repo, err := c.RunArtifactRepository(runID)
+	if err != nil {
+		return err
+	}
+	return walkLocalArtifacts(localDir, artifactPath, func(localPath, artifactPath string) error {
+		return c.logFile(runID, repo, localPath, artifactPath)
+	})
+}
+
+// checkLocalDir checks that a directory to log exists
+func checkLocalDir(localDir string) error {
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", localDir)
+	}
+	return nil
+}
+
+// walkLocalArtifacts calls logFile for each file in a local directory and
+// its subdirectories, with its artifact path under artifactPath
+func walkLocalArtifacts(localDir, artifactPath string, logFile func(localPath, artifactPath string) error) error {
+	return filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read artifact directory: %w", err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		return logFile(localPath, path.Join(artifactPath, filepath.ToSlash(rel)))
+	})
+}
+
+// logFile streams a regular local file to artifactPath
+func (c *Client) logFile(runID string, repo ArtifactRepository, localPath, artifactPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("artifact %s is not a regular file", localPath)
+	}
+	return c.uploadArtifact(runID, repo, artifactPath, f, info.Size())
+}
+
+// putArtifact uploads an artifact to the run's artifact repository and
+// records it in the audit log
+func (c *Client) putArtifact(runID, artifactPath string, data []byte) error {
+	return c.uploadArtifact(runID, nil, artifactPath, bytes.NewReader(data), int64(len(data)))
+}
+
+// uploadArtifact streams size bytes to artifactPath in the run's artifact
+// repository, resolving it from the run if it is nil, and records the upload
+// in the audit log
+func (c *Client) uploadArtifact(runID string, repo ArtifactRepository, artifactPath string, content io.Reader, size int64) error {
+	m := mutation{operation: "LogArtifact", target: AuditTarget{Entity: AuditEntityRun, ID: runID}}
+	body := map[string]any{"run_id": runID, "path": artifactPath, "size": size}
+	if err := c.enforcePolicy(m); err != nil {
+		c.audit(m, body, err)
+		return err
+	}
+	err := c.doUploadArtifact(runID, repo, artifactPath, content, size)
+	c.audit(m, body, err)
+	return err
+}
+
+func (c *Client) doUploadArtifact(runID string, repo ArtifactRepository, artifactPath string, content io.Reader, size int64) error {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		if repo, err = c.RunArtifactRepository(runID); err != nil {
+			return err
+		}
+	}
+	return repo.Upload(context.Background(), clean, content, size)
+}
+
+// OpenArtifact opens an artifact file of the run for streaming. The caller
+// must close it.
+func (c *Client) OpenArtifact(runID, artifactPath string) (io.ReadCloser, error) {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := c.runArtifactReader(runID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Download(context.Background(), clean)
+}
+
+// DownloadArtifact downloads an artifact file of the run to localPath,
+// creating its directory. The file only appears once it is complete.
+func (c *Client) DownloadArtifact(runID, artifactPath, localPath string) error {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return err
+	}
+	repo, err := c.runArtifactReader(runID)
+	if err != nil {
+		return err
+	}
+	return downloadFile(context.Background(), repo, clean, localPath, -1)
+}
+
+// DownloadArtifacts downloads the artifact file or directory at artifactPath,
+// or all of the run's artifacts if it is empty, into localDir, keeping their
+// paths relative to the run's artifact root. It returns the local path of the
+// downloaded file or directory.
+func (c *Client) DownloadArtifacts(runID, artifactPath, localDir string) (string, error) {
+	clean, err := cleanArtifactDir(artifactPath)
+	if err != nil {
+		return "", err
+	}
+	repo, err := c.runArtifactReader(runID)
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	info, err := repo.Stat(ctx, clean)
+	if err != nil {
+		return "", fmt.Errorf("failed to download artifacts of run %s: %w", runID, err)
+	}
+	local := filepath.Join(localDir, filepath.FromSlash(clean))
+	if !info.IsDir {
+		return local, downloadFile(ctx, repo, clean, local, -1)
+	}
+
+	if err := os.MkdirAll(local, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	dirs := []string{clean}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+		files, err := repo.List(ctx, dir)
+		if err != nil {
+			return "", err
+		}
+		for _, file := range files {
+			filePath, err := cleanArtifactPath(file.Path)
+			if err != nil {
+				return "", fmt.Errorf("repository listed %w", err)
+			}
+			target := filepath.Join(localDir, filepath.FromSlash(filePath))
+			if file.IsDir {
+				if err := os.MkdirAll(target, 0o755); err != nil {
+					return "", fmt.Errorf("failed to create artifact directory: %w", err)
+				}
+				dirs = append(dirs, filePath)
+				continue
+			}
+			if err := downloadFile(ctx, repo, filePath, target, -1); err != nil {
+				return "", err
+			}
+		}
+	}
+	return local, nil
+}
+
+// downloadFile streams an artifact to a temporary file next to localPath and
+// renames it into place once complete. Unless size is negative, an artifact
+// of another size is an error and is not put in place.
+func downloadFile(ctx context.Context, repo ArtifactRepository, artifactPath, localPath string, size int64) error {
+	return downloadFileTee(ctx, repo, artifactPath, localPath, size, io.Discard)
+}
+
+// downloadFileTee is downloadFile that also writes what it downloads to w,
+// such as a hash of the file
+func downloadFileTee(ctx context.Context, repo ArtifactRepository, artifactPath, localPath string, size int64, w io.Writer) error {
+	body, err := repo.Download(ctx, artifactPath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(io.MultiWriter(tmp, w), body)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download artifact %s: %w", artifactPath, err)
+	}
+	if size >= 0 && n != size {
+		tmp.Close()
+		return fmt.Errorf("downloaded %d bytes of artifact %s, expected %d", n, artifactPath, size)
+	}
+	// CreateTemp makes the file private; give it the mode of any other
+	// downloaded file
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write artifact file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write artifact file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		return fmt.Errorf("failed to write artifact file: %w", err)
+	}
+	return nil
+}
+
+// cleanArtifactPath validates a slash separated artifact path relative to a
+// run's artifact root and returns it without redundant slashes
+func cleanArtifactPath(artifactPath string) (string, error) {
+	return artifactpath.Clean(artifactPath, false)
+}
@@ -0,0 +1,366 @@
+package mlflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntity identifies the kind of object a mutating call targets
+type AuditEntity string
+
+const (
+	AuditEntityExperiment   AuditEntity = "experiment"
+	AuditEntityRun          AuditEntity = "run"
+	AuditEntityModel        AuditEntity = "registered_model"
+	AuditEntityModelVersion AuditEntity = "model_version"
+	AuditEntityAlias        AuditEntity = "alias"
+	AuditEntityLoggedModel  AuditEntity = "logged_model"
+	AuditEntityTrace        AuditEntity = "trace"
+	AuditEntityWebhook      AuditEntity = "webhook"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+// redactedValue replaces sensitive values in audit payloads
+const redactedValue = "[REDACTED]"
+
+// DefaultAuditRedactKeys are the key fragments whose values are redacted from
+// audit payloads when AuditConfig.RedactKeys is nil
+var DefaultAuditRedactKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "credential", "authorization"}
+
+// AuditTarget identifies the entity changed by a mutating call
+type AuditTarget struct {
+	Entity  AuditEntity `json:"entity"`
+	ID      string      `json:"id,omitempty"`
+	Name    string      `json:"name,omitempty"`
+	Version string      `json:"version,omitempty"`
+	Alias   string      `json:"alias,omitempty"`
+}
+
+// AuditRecord is a single entry in the audit log
+type AuditRecord struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Actor     string      `json:"actor,omitempty"`
+	Operation string      `json:"operation"`
+	Target    AuditTarget `json:"target"`
+	Request   interface{} `json:"request,omitempty"`
+	Outcome   string      `json:"outcome"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// AuditSink receives audit records. Implementations must be safe for concurrent use.
+type AuditSink interface {
+	WriteAuditRecord(record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to the AuditSink interface
+type AuditSinkFunc func(record AuditRecord) error
+
+// WriteAuditRecord calls f(record)
+func (f AuditSinkFunc) WriteAuditRecord(record AuditRecord) error {
+	return f(record)
+}
+
+// AuditConfig configures the audit log written for every mutating call
+type AuditConfig struct {
+	// Sink receives the audit records
+	Sink AuditSink
+	// Actor is the caller-supplied identity recorded with every entry
+	Actor string
+	// RedactKeys are the key fragments (case insensitive) whose values are
+	// redacted from request payloads. DefaultAuditRedactKeys is used when nil.
+	RedactKeys []string
+	// OnError is called when a record cannot be written. Audit failures never
+	// change the result of the API call itself.
+	OnError func(err error)
+}
+
+// SetAudit enables audit logging of mutating calls. Passing nil disables it.
+func (c *Client) SetAudit(cfg *AuditConfig) {
+	c.Audit = cfg
+}
+
+// mutation describes a state-changing API call
+type mutation struct {
+	operation string
+	target    AuditTarget
+	// stage is the requested stage of a model version transition
+	stage string
+	// archiveExisting is set for a transition that archives the other
+	// versions in its stage
+	archiveExisting bool
+	// body is the request, for operations whose policy checks depend on it
+	body interface{}
+}
+
+// doMutation checks a state-changing request against the policy engine,
+// performs it and records it in the audit log
+func (c *Client) doMutation(m mutation, method, endpoint string, body interface{}) ([]byte, error) {
+	m.body = body
+	if err := c.enforcePolicy(m); err != nil {
+		c.audit(m, body, err)
+		return nil, err
+	}
+	respBody, err := c.doRequest(method, endpoint, body)
+	c.audit(m, body, err)
+	return respBody, err
+}
+
+// audit writes a record for a mutating call if auditing is enabled
+func (c *Client) audit(m mutation, body interface{}, callErr error) {
+	cfg := c.Audit
+	if cfg == nil || cfg.Sink == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Timestamp: time.Now().UTC(),
+		Actor:     cfg.Actor,
+		Operation: m.operation,
+		Target:    m.target,
+		Outcome:   AuditOutcomeSuccess,
+	}
+
+	redactKeys := cfg.RedactKeys
+	if redactKeys == nil {
+		redactKeys = DefaultAuditRedactKeys
+	}
+	payload, err := redactPayload(body, redactKeys)
+	if err != nil {
+		cfg.reportError(fmt.Errorf("failed to redact audit payload: %w", err))
+	} else {
+		record.Request = payload
+	}
+
+	if callErr != nil {
+		record.Outcome = AuditOutcomeFailure
+		if _, ok := IsPolicyError(callErr); ok {
+			record.Outcome = AuditOutcomeDenied
+		}
+		record.Error = callErr.Error()
+		if apiErr, ok := IsAPIError(callErr); ok {
+			record.ErrorCode = apiErr.GetErrorCode()
+		}
+	}
+
+	if err := cfg.Sink.WriteAuditRecord(record); err != nil {
+		cfg.reportError(fmt.Errorf("failed to write audit record: %w", err))
+	}
+}
+
+func (cfg *AuditConfig) reportError(err error) {
+	if cfg.OnError != nil {
+		cfg.OnError(err)
+	}
+}
+
+// redactPayload converts a request body to its generic JSON form and redacts
+// sensitive values. Both plain fields ({"password": ...}) and key/value pairs
+// such as params and tags ({"key": "db_password", "value": ...}) are redacted.
+func redactPayload(body interface{}, redactKeys []string) (interface{}, error) {
+	if body == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return redactValue(generic, redactKeys), nil
+}
+
+func redactValue(v interface{}, redactKeys []string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if key, ok := val["key"].(string); ok && isSensitiveKey(key, redactKeys) {
+			if _, hasValue := val["value"]; hasValue {
+				val["value"] = redactedValue
+			}
+		}
+		for k, inner := range val {
+			if isSensitiveKey(k, redactKeys) {
+				val[k] = redactedValue
+				continue
+			}
+			val[k] = redactValue(inner, redactKeys)
+		}
+		return val
+	case []interface{}:
+		for i, inner := range val {
+			val[i] = redactValue(inner, redactKeys)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// isSensitiveKey reports whether key contains any of the redact fragments
+func isSensitiveKey(key string, redactKeys []string) bool {
+	lower := strings.ToLower(key)
+	for _, fragment := range redactKeys {
+		if fragment != "" && strings.Contains(lower, strings.ToLower(fragment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriterAuditSink writes audit records as JSON Lines to an io.Writer
+type WriterAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterAuditSink creates an audit sink that writes JSON Lines to w
+func NewWriterAuditSink(w io.Writer) *WriterAuditSink {
+	return &WriterAuditSink{w: w}
+}
+
+// WriteAuditRecord writes the record as a single JSON line
+func (s *WriterAuditSink) WriteAuditRecord(record AuditRecord) error {
+	line, err := marshalAuditRecord(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// FileAuditSink appends audit records as JSON Lines to a file, rotating it
+// once it grows beyond maxBytes. Rotated files are named path.1 (newest)
+// through path.N (oldest).
+type FileAuditSink struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileAuditSink opens (or creates) an append-only audit log at path.
+// A maxBytes of zero disables rotation; maxBackups limits how many rotated
+// files are kept.
+func NewFileAuditSink(path string, maxBytes int64, maxBackups int) (*FileAuditSink, error) {
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("max bytes must not be negative")
+	}
+	if maxBackups < 0 {
+		return nil, fmt.Errorf("max backups must not be negative")
+	}
+	s := &FileAuditSink{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileAuditSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// WriteAuditRecord appends the record, rotating the file first if needed
+func (s *FileAuditSink) WriteAuditRecord(record AuditRecord) error {
+	line, err := marshalAuditRecord(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotate shifts the existing backups and starts a new log file
+func (s *FileAuditSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	s.file = nil
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove audit log: %w", err)
+		}
+		return s.open()
+	}
+
+	oldest := fmt.Sprintf("%s.%d", s.path, s.maxBackups)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove audit log backup: %w", err)
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", s.path, i)
+		to := fmt.Sprintf("%s.%d", s.path, i+1)
+		if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate audit log backup: %w", err)
+		}
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return s.open()
+}
+
+// Close closes the underlying file
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func marshalAuditRecord(record AuditRecord) ([]byte, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	return append(line, '\n'), nil
+}
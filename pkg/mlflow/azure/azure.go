@@ -0,0 +1,512 @@
+// Package azure stores MLflow artifacts in Azure Blob Storage, for
+// wasbs://container@account.blob.core.windows.net/path artifact URIs, and
+// in the Azurite emulator. Importing it registers the backend for the
+// wasbs scheme:
+//
+//	import _ "github.com/julpayne/mlflow-go-client/pkg/mlflow/azure"
+//
+// The registered backend is configured from the environment like MLflow's
+// own Azure support, see ConfigFromEnv.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/internal/artifactpath"
+	"github.com/julpayne/mlflow-go-client/internal/transfer"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Defaults of Config
+const (
+	DefaultEndpointSuffix = "core.windows.net"
+	DefaultBlockSize      = 4 << 20
+	DefaultConcurrency    = 4
+	DefaultMaxRetries     = 3
+)
+
+// APIVersion is the x-ms-version of requests
+const APIVersion = "2020-10-02"
+
+// maxBlocks is the most blocks a block blob can have
+const maxBlocks = 50000
+
+func init() {
+	factory := func(_ *mlflow.Client, artifactURI string) (mlflow.ArtifactRepository, error) {
+		cfg, err := ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return New(artifactURI, cfg)
+	}
+	mlflow.RegisterArtifactRepository("wasbs", factory)
+}
+
+// Config configures access to a storage account
+type Config struct {
+	// AccountName is the account the credentials are for. It must be the
+	// account of the artifact URI. If it is not set, the account is taken
+	// from the Endpoint, if there is one.
+	AccountName string
+	// AccountKey authorizes requests with Shared Key
+	AccountKey []byte
+	// SASToken is a shared access signature, the query string of a SAS
+	// URL, used if there is no AccountKey
+	SASToken string
+	// Endpoint is the URL of the blob service, such as Azurite's
+	// DevelopmentEndpoint. It defaults to the host of the artifact URI
+	// over HTTPS; plain HTTP is only used for an http:// Endpoint.
+	Endpoint string
+
+	// BlockSize is the size of the blocks of chunked uploads and of the
+	// ranges of downloads, DefaultBlockSize if zero. Files up to this
+	// size take a single request.
+	BlockSize int64
+	// Concurrency is the number of blocks transferred, or blobs deleted,
+	// at once, DefaultConcurrency if zero. Each transfer buffers up to
+	// BlockSize bytes.
+	Concurrency int
+	// MaxRetries is the number of retries of a request failing with a
+	// network error, a 5xx status or throttling, DefaultMaxRetries if zero.
+	// A negative value disables retries.
+	MaxRetries int
+
+	// HTTPClient defaults to a client sharing http.DefaultTransport's
+	// settings
+	HTTPClient *http.Client
+}
+
+// ConfigFromEnv reads the credentials of MLflow's Azure support:
+//   - AZURE_STORAGE_CONNECTION_STRING, see ParseConnectionString
+//   - otherwise AZURE_STORAGE_ACCESS_KEY, the key of the URI's account
+func ConfigFromEnv() (Config, error) {
+	if connectionString := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); connectionString != "" {
+		cfg, err := ParseConnectionString(connectionString)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid AZURE_STORAGE_CONNECTION_STRING: %w", err)
+		}
+		return cfg, nil
+	}
+	if key := os.Getenv("AZURE_STORAGE_ACCESS_KEY"); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid AZURE_STORAGE_ACCESS_KEY: %w", err)
+		}
+		return Config{AccountKey: decoded}, nil
+	}
+	return Config{}, nil
+}
+
+// Repository stores artifacts under a path of a blob container. It
+// implements mlflow.ArtifactRepository.
+type Repository struct {
+	// Account is the storage account name
+	Account string
+	// Container is the blob container name
+	Container string
+	// Prefix is the blob name prefix of the artifact root, without slashes
+	// at either end
+	Prefix string
+
+	cfg      Config
+	client   *http.Client
+	endpoint *url.URL
+	sas      url.Values
+}
+
+var _ mlflow.ArtifactRepository = (*Repository)(nil)
+
+// New returns the repository of a
+// wasbs://container@account.blob.core.windows.net/path artifact URI
+func New(artifactURI string, cfg Config) (*Repository, error) {
+	uri, err := url.Parse(artifactURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URI %q: %w", artifactURI, err)
+	}
+	scheme := strings.ToLower(uri.Scheme)
+	account, service, _ := strings.Cut(uri.Hostname(), ".")
+	if scheme != "wasbs" || uri.User == nil || uri.User.Username() == "" ||
+		account == "" || !strings.HasPrefix(service, "blob.") {
+		return nil, fmt.Errorf("artifact URI %q is not a wasbs://container@account.blob.core.windows.net/path URI", artifactURI)
+	}
+	if cfg.AccountKey == nil && cfg.SASToken == "" {
+		return nil, fmt.Errorf("no Azure credentials for account %s: set AZURE_STORAGE_CONNECTION_STRING or AZURE_STORAGE_ACCESS_KEY", account)
+	}
+	if cfg.BlockSize <= 0 {
+		cfg.BlockSize = DefaultBlockSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+
+	r := &Repository{
+		Account:   account,
+		Container: uri.User.Username(),
+		Prefix:    strings.Trim(uri.Path, "/"),
+		cfg:       cfg,
+		client:    cfg.HTTPClient,
+	}
+	if r.client == nil {
+		r.client = &http.Client{}
+	}
+	if cfg.Endpoint != "" {
+		if r.endpoint, err = url.Parse(cfg.Endpoint); err != nil || r.endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid Azure blob endpoint %q", cfg.Endpoint)
+		}
+		r.endpoint.Path = strings.TrimSuffix(r.endpoint.Path, "/")
+		r.endpoint.RawQuery = ""
+	} else {
+		r.endpoint = &url.URL{Scheme: "https", Host: uri.Host}
+	}
+	credentialAccount := cfg.AccountName
+	if cfg.Endpoint != "" {
+		served := endpointAccount(r.endpoint)
+		switch {
+		case credentialAccount == "" && served == "":
+			return nil, fmt.Errorf("cannot tell the account of Azure blob endpoint %q: set the AccountName of the credentials", cfg.Endpoint)
+		case credentialAccount == "":
+			credentialAccount = served
+		case served != "" && served != credentialAccount:
+			return nil, fmt.Errorf("the Azure blob endpoint %q is for account %s, not %s", cfg.Endpoint, served, credentialAccount)
+		}
+	}
+	if credentialAccount != "" && credentialAccount != account {
+		return nil, fmt.Errorf("the Azure credentials are for account %s, not %s", credentialAccount, account)
+	}
+	if cfg.AccountKey == nil {
+		if r.sas, err = url.ParseQuery(strings.TrimPrefix(cfg.SASToken, "?")); err != nil {
+			return nil, fmt.Errorf("invalid SAS token: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// endpointAccount returns the account a blob endpoint serves: the first
+// label of an account.blob host, or else the first path segment of a
+// path-style endpoint such as Azurite's. It is empty if the endpoint names
+// no account.
+func endpointAccount(endpoint *url.URL) string {
+	account, rest, _ := strings.Cut(endpoint.Hostname(), ".")
+	if strings.HasPrefix(rest, "blob.") || strings.Contains(rest, ".blob.") {
+		return account
+	}
+	first, _, _ := strings.Cut(strings.TrimPrefix(endpoint.Path, "/"), "/")
+	return first
+}
+
+// blobName returns the blob name of a clean artifact path, the prefix
+// itself for ""
+func (r *Repository) blobName(artifactPath string) string {
+	return strings.TrimPrefix(path.Join(r.Prefix, artifactPath), "/")
+}
+
+// dirPrefix returns the name prefix of the blobs under a clean artifact
+// directory
+func (r *Repository) dirPrefix(dir string) string {
+	if name := r.blobName(dir); name != "" {
+		return name + "/"
+	}
+	return ""
+}
+
+// blobURL returns the URL of a blob, or of the container for ""
+func (r *Repository) blobURL(blob string, query url.Values) *url.URL {
+	u := *r.endpoint
+	u.Path = r.endpoint.Path + "/" + r.Container
+	if blob != "" {
+		u.Path += "/" + blob
+	}
+	u.RawPath = ""
+	values := url.Values{}
+	for name, v := range query {
+		values[name] = v
+	}
+	for name, v := range r.sas {
+		values[name] = v
+	}
+	u.RawQuery = values.Encode()
+	return &u
+}
+
+// Error is an error response of the blob service. Errors for missing blobs
+// satisfy errors.Is(err, fs.ErrNotExist).
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	return transfer.ErrorMessage("Azure", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// Is makes a missing blob match fs.ErrNotExist. A missing container does
+// not.
+func (e *Error) Is(target error) bool {
+	return target == fs.ErrNotExist && (e.Code == "BlobNotFound" || e.StatusCode == http.StatusNotFound && e.Code == "")
+}
+
+// parseError returns the error of a non-2xx response
+func parseError(resp *http.Response, body []byte) error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Code:       resp.Header.Get("X-Ms-Error-Code"),
+		RequestID:  resp.Header.Get("X-Ms-Request-Id"),
+	}
+	var parsed struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(body, &parsed) == nil {
+		if e.Code == "" {
+			e.Code = parsed.Code
+		}
+		// The message ends with the request ID and time on lines of their own
+		e.Message, _, _ = strings.Cut(parsed.Message, "\n")
+	}
+	return e
+}
+
+// retryable reports whether a request failing with err may succeed if
+// retried
+func retryable(err error) bool {
+	var azErr *Error
+	if !errors.As(err, &azErr) {
+		return transfer.RetryableNetworkError(err)
+	}
+	return transfer.RetryableStatus(azErr.StatusCode)
+}
+
+// request is a blob service request
+type request struct {
+	method string
+	blob   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// do sends a request, retrying it on retryable failures, and returns the
+// response if its status is 2xx. The caller must close its body.
+func (r *Repository) do(ctx context.Context, req request) (*http.Response, error) {
+	return transfer.Retry(ctx, r.cfg.MaxRetries, retryable, func() (*http.Response, error) {
+		return r.send(ctx, req)
+	})
+}
+
+// send sends an authorized request once
+func (r *Repository) send(ctx context.Context, req request) (*http.Response, error) {
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, r.blobURL(req.blob, req.query).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("X-Ms-Version", APIVersion)
+	if r.cfg.AccountKey != nil {
+		Sign(httpReq, r.Account, r.cfg.AccountKey, time.Now())
+	}
+	return transfer.Send(r.client, httpReq, parseError)
+}
+
+// listPage is a page of List Blobs
+type listPage struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				ContentLength int64 `xml:"Content-Length"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+		BlobPrefix []struct {
+			Name string `xml:"Name"`
+		} `xml:"BlobPrefix"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// list calls fn with each page of the blobs under prefix, grouped into
+// prefixes at delimiter if it is not empty
+func (r *Repository) list(ctx context.Context, prefix, delimiter string, maxResults int, fn func(*listPage) error) error {
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if delimiter != "" {
+			query.Set("delimiter", delimiter)
+		}
+		if maxResults > 0 {
+			query.Set("maxresults", strconv.Itoa(maxResults))
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		var page listPage
+		if err := r.doXML(ctx, request{method: http.MethodGet, query: query}, &page); err != nil {
+			return fmt.Errorf("failed to list %s/%s: %w", r.Container, prefix, err)
+		}
+		if err := fn(&page); err != nil {
+			return err
+		}
+		if page.NextMarker == "" || maxResults > 0 {
+			return nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// doXML sends a request and unmarshals the XML of its response into v
+func (r *Repository) doXML(ctx context.Context, req request, v any) error {
+	resp, err := r.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	return transfer.DecodeXML(resp, v, parseError)
+}
+
+// List implements mlflow.ArtifactRepository
+func (r *Repository) List(ctx context.Context, dir string) ([]mlflow.FileInfo, error) {
+	clean, err := artifactpath.Clean(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.dirPrefix(clean)
+	var files []mlflow.FileInfo
+	err = r.list(ctx, prefix, "/", 0, func(page *listPage) error {
+		for _, p := range page.Blobs.BlobPrefix {
+			name := strings.TrimSuffix(strings.TrimPrefix(p.Name, prefix), "/")
+			if name != "" {
+				files = append(files, mlflow.FileInfo{Path: path.Join(clean, name), IsDir: true})
+			}
+		}
+		for _, blob := range page.Blobs.Blob {
+			name := strings.TrimPrefix(blob.Name, prefix)
+			// Names ending in a slash are directory markers
+			if name != "" && !strings.HasSuffix(name, "/") {
+				files = append(files, mlflow.FileInfo{Path: path.Join(clean, name), FileSize: blob.Properties.ContentLength})
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Stat implements mlflow.ArtifactRepository. A path that is not a blob is
+// a directory if blobs exist under it.
+func (r *Repository) Stat(ctx context.Context, artifactPath string) (mlflow.FileInfo, error) {
+	clean, err := artifactpath.Clean(artifactPath, true)
+	if err != nil {
+		return mlflow.FileInfo{}, err
+	}
+	if clean == "" {
+		return mlflow.FileInfo{IsDir: true}, nil
+	}
+	resp, err := r.do(ctx, request{method: http.MethodHead, blob: r.blobName(clean)})
+	if err == nil {
+		resp.Body.Close()
+		return mlflow.FileInfo{Path: clean, FileSize: resp.ContentLength}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return mlflow.FileInfo{}, fmt.Errorf("failed to stat artifact %s: %w", clean, err)
+	}
+	found := false
+	err = r.list(ctx, r.dirPrefix(clean), "", 1, func(page *listPage) error {
+		found = len(page.Blobs.Blob) > 0
+		return nil
+	})
+	if err != nil {
+		return mlflow.FileInfo{}, err
+	}
+	if !found {
+		return mlflow.FileInfo{}, fmt.Errorf("artifact %s: %w", clean, fs.ErrNotExist)
+	}
+	return mlflow.FileInfo{Path: clean, IsDir: true}, nil
+}
+
+// Delete implements mlflow.ArtifactRepository. The blob service deletes
+// one blob per request, so up to Concurrency are deleted at once.
+func (r *Repository) Delete(ctx context.Context, artifactPath string) error {
+	clean, err := artifactpath.Clean(artifactPath, true)
+	if err != nil {
+		return err
+	}
+	var names []string
+	if clean != "" {
+		names = append(names, r.blobName(clean))
+	}
+	err = r.list(ctx, r.dirPrefix(clean), "", 0, func(page *listPage) error {
+		for _, blob := range page.Blobs.Blob {
+			names = append(names, blob.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete artifact %s: %w", clean, err)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	slots := make(chan struct{}, r.cfg.Concurrency)
+	for _, name := range names {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			err := r.deleteBlob(ctx, name)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return fmt.Errorf("failed to delete artifact %s: %w", clean, firstErr)
+	}
+	return nil
+}
+
+// deleteBlob deletes a blob with its snapshots
+func (r *Repository) deleteBlob(ctx context.Context, name string) error {
+	header := http.Header{}
+	header.Set("X-Ms-Delete-Snapshots", "include")
+	resp, err := r.do(ctx, request{method: http.MethodDelete, blob: name, header: header})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
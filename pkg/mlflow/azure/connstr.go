@@ -0,0 +1,81 @@
+package azure
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Azurite's well-known development account, used for
+// UseDevelopmentStorage=true
+const (
+	DevelopmentAccountName = "devstoreaccount1"
+	DevelopmentAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	DevelopmentEndpoint    = "http://127.0.0.1:10000/" + DevelopmentAccountName
+)
+
+// ParseConnectionString returns the account, credentials and endpoint of
+// a storage account connection string, such as
+//
+//	DefaultEndpointsProtocol=https;AccountName=team;AccountKey=...;EndpointSuffix=core.windows.net
+//	BlobEndpoint=https://team.blob.core.windows.net;SharedAccessSignature=sv=...
+//	UseDevelopmentStorage=true
+//
+// Settings other than the blob service's are ignored.
+func ParseConnectionString(connectionString string) (Config, error) {
+	settings := map[string]string{}
+	for _, segment := range strings.Split(connectionString, ";") {
+		if strings.TrimSpace(segment) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(segment, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("invalid connection string setting %q", segment)
+		}
+		settings[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+
+	if dev, ok := settings["usedevelopmentstorage"]; ok {
+		if use, err := strconv.ParseBool(dev); err != nil || !use {
+			return Config{}, fmt.Errorf("invalid UseDevelopmentStorage %q", dev)
+		}
+		key, _ := base64.StdEncoding.DecodeString(DevelopmentAccountKey)
+		return Config{AccountName: DevelopmentAccountName, AccountKey: key, Endpoint: DevelopmentEndpoint}, nil
+	}
+
+	cfg := Config{
+		AccountName: settings["accountname"],
+		SASToken:    settings["sharedaccesssignature"],
+		Endpoint:    settings["blobendpoint"],
+	}
+	if key := settings["accountkey"]; key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid account key: %w", err)
+		}
+		cfg.AccountKey = decoded
+	}
+	if cfg.AccountKey == nil && cfg.SASToken == "" {
+		return Config{}, errors.New("connection string has neither AccountKey nor SharedAccessSignature")
+	}
+	if cfg.AccountKey != nil && cfg.AccountName == "" {
+		return Config{}, errors.New("connection string has an AccountKey but no AccountName")
+	}
+	if cfg.Endpoint == "" && cfg.AccountName != "" {
+		protocol := settings["defaultendpointsprotocol"]
+		if protocol == "" {
+			protocol = "https"
+		}
+		suffix := settings["endpointsuffix"]
+		if suffix == "" {
+			suffix = DefaultEndpointSuffix
+		}
+		cfg.Endpoint = protocol + "://" + cfg.AccountName + ".blob." + suffix
+	}
+	if cfg.Endpoint == "" {
+		return Config{}, errors.New("connection string has neither AccountName nor BlobEndpoint")
+	}
+	return cfg, nil
+}
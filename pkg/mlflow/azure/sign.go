@@ -0,0 +1,91 @@
+package azure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sign authorizes a request with the Shared Key of an account, setting its
+// x-ms-date and Authorization headers. Headers that are signed, such as
+// x-ms-version and Range, must be set before.
+func Sign(req *http.Request, account string, key []byte, now time.Time) {
+	req.Header.Set("X-Ms-Date", now.UTC().Format(http.TimeFormat))
+
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-Md5"),
+		req.Header.Get("Content-Type"),
+		// Date is empty because x-ms-date is set
+		"",
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalHeaders(req.Header) + canonicalResource(account, req.URL),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// canonicalHeaders returns the x-ms- headers, lowercase and sorted, each
+// followed by a newline
+func canonicalHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.Join(header.Values(name), ",") + "\n")
+	}
+	return b.String()
+}
+
+// canonicalResource returns the account and escaped path of a URL followed
+// by its query parameters, sorted, one per line
+func canonicalResource(account string, u *url.URL) string {
+	var b strings.Builder
+	b.WriteString("/" + account)
+	if p := u.EscapedPath(); p != "" {
+		b.WriteString(p)
+	} else {
+		b.WriteString("/")
+	}
+	query, _ := url.ParseQuery(u.RawQuery)
+	params := make(map[string][]string, len(query))
+	names := make([]string, 0, len(query))
+	for name, values := range query {
+		lower := strings.ToLower(name)
+		if _, ok := params[lower]; !ok {
+			names = append(names, lower)
+		}
+		params[lower] = append(params[lower], values...)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := params[name]
+		sort.Strings(values)
+		b.WriteString("\n" + name + ":" + strings.Join(values, ","))
+	}
+	return b.String()
+}
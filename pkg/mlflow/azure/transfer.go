@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/internal/artifactpath"
+	"github.com/julpayne/mlflow-go-client/internal/transfer"
+)
+
+// Upload implements mlflow.ArtifactRepository. Content up to the block
+// size takes a single request; larger content is staged in blocks, several
+// at once, and committed as a block list. Blocks of a failed upload are
+// never committed, and the blob service discards them.
+func (r *Repository) Upload(ctx context.Context, artifactPath string, content io.Reader, size int64) error {
+	clean, err := artifactpath.Clean(artifactPath, false)
+	if err != nil {
+		return err
+	}
+	name := r.blobName(clean)
+	blockSize := transfer.PartSize(size, r.cfg.BlockSize, maxBlocks)
+
+	first, err := transfer.ReadPart(content, blockSize)
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+	}
+	var second []byte
+	if int64(len(first)) == blockSize {
+		if second, err = transfer.ReadPart(content, blockSize); err != nil {
+			return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+		}
+	}
+	if len(second) == 0 {
+		if size >= 0 && int64(len(first)) != size {
+			return fmt.Errorf("failed to upload artifact %s: got %d of %d bytes", clean, len(first), size)
+		}
+		header := http.Header{}
+		header.Set("X-Ms-Blob-Type", "BlockBlob")
+		resp, err := r.do(ctx, request{method: http.MethodPut, blob: name, header: header, body: first})
+		if err != nil {
+			return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if err := r.uploadBlocks(ctx, name, content, size, blockSize, [][]byte{first, second}); err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+	}
+	return nil
+}
+
+// uploadBlocks stages the blocks already read, then the rest of content,
+// with up to Concurrency blocks in flight, and commits them
+func (r *Repository) uploadBlocks(ctx context.Context, name string, content io.Reader, size, blockSize int64, read [][]byte) error {
+	// Uncommitted blocks are per blob, so the IDs of concurrent uploads
+	// of the same blob must differ
+	var upload [8]byte
+	if _, err := rand.Read(upload[:]); err != nil {
+		return fmt.Errorf("failed to generate block IDs: %w", err)
+	}
+	blockID := func(number int) string {
+		// IDs must all have the same length
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s-%06d", hex.EncodeToString(upload[:]), number)))
+	}
+
+	total, err := transfer.UploadParts(ctx, content, read, blockSize, maxBlocks, r.cfg.Concurrency, func(ctx context.Context, number int, data []byte) error {
+		query := url.Values{"comp": {"block"}, "blockid": {blockID(number)}}
+		resp, err := r.do(ctx, request{method: http.MethodPut, blob: name, query: query, body: data})
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if size >= 0 && total != size {
+		return fmt.Errorf("got %d of %d bytes", total, size)
+	}
+
+	// Every block but the last is full
+	blocks := struct {
+		XMLName xml.Name `xml:"BlockList"`
+		Latest  []string `xml:"Latest"`
+	}{}
+	for number := 1; int64(number-1)*blockSize < total; number++ {
+		blocks.Latest = append(blocks.Latest, blockID(number))
+	}
+	body, err := xml.Marshal(blocks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	resp, err := r.do(ctx, request{method: http.MethodPut, blob: name, query: url.Values{"comp": {"blocklist"}}, header: header, body: append([]byte(xml.Header), body...)})
+	if err != nil {
+		return fmt.Errorf("failed to commit block list: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Download implements mlflow.ArtifactRepository. The first block is
+// requested as a range; if the blob is larger, the remaining blocks are
+// fetched concurrently while the first streams.
+func (r *Repository) Download(ctx context.Context, artifactPath string) (io.ReadCloser, error) {
+	clean, err := artifactpath.Clean(artifactPath, false)
+	if err != nil {
+		return nil, err
+	}
+	name := r.blobName(clean)
+	blockSize := r.cfg.BlockSize
+	header := http.Header{}
+	header.Set("X-Ms-Range", fmt.Sprintf("bytes=0-%d", blockSize-1))
+	resp, err := r.do(ctx, request{method: http.MethodGet, blob: name, header: header})
+	var azErr *Error
+	if errors.As(err, &azErr) && azErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// An empty blob has no first byte
+		resp, err = r.do(ctx, request{method: http.MethodGet, blob: name})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact %s: %w", clean, err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return resp.Body, nil
+	}
+	_, totalText, _ := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	total, err := strconv.ParseInt(totalText, 10, 64)
+	if err != nil || total <= blockSize {
+		return resp.Body, nil
+	}
+	etag := resp.Header.Get("ETag")
+	return transfer.NewRangeReader(ctx, resp.Body, blockSize, total, r.cfg.Concurrency, func(ctx context.Context, start, end int64) ([]byte, error) {
+		return r.getRange(ctx, name, start, end, etag)
+	}), nil
+}
+
+// getRange downloads the bytes start to end of a blob, retrying if the
+// body is cut short. The ETag makes the request fail if the blob was
+// replaced since the first block was read.
+func (r *Repository) getRange(ctx context.Context, name string, start, end int64, etag string) ([]byte, error) {
+	header := http.Header{}
+	header.Set("X-Ms-Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := r.do(ctx, request{method: http.MethodGet, blob: name, header: header})
+		if err != nil {
+			return nil, fmt.Errorf("failed to download bytes %d-%d: %w", start, end, err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && int64(len(data)) != end-start+1 {
+			err = fmt.Errorf("got %d of %d bytes", len(data), end-start+1)
+		}
+		if err == nil {
+			return data, nil
+		}
+		if attempt >= r.cfg.MaxRetries || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to download bytes %d-%d: %w", start, end, err)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,15 @@ type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	AuthToken  string
+	Audit      *AuditConfig
+	Policy     *PolicyEngine
+	Scrubber   *Scrubber
+	// ArtifactKeys encrypts and decrypts the data keys of encrypted
+	// artifacts, see LogArtifactEncrypted
+	ArtifactKeys KeyProvider
+
+	// policyOverride is the token presented to policy rules, see WithPolicyOverride
+	policyOverride string
 }
 
 // NewClient creates a new MLflow client
@@ -99,24 +109,28 @@ func (c *Client) doRequest(method, endpoint string, body interface{}) ([]byte, e
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var errorResp ErrorResponse
-		apiErr := &APIError{
-			StatusCode:   resp.StatusCode,
-			ResponseBody: respBody,
-		}
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
 
-		if err := json.Unmarshal(respBody, &errorResp); err == nil {
-			apiErr.ErrorCode = errorResp.ErrorCode
-			apiErr.Message = errorResp.Message
-		} else {
-			// If we can't parse the error response, use the raw body as message
-			apiErr.Message = string(respBody)
-		}
+	return respBody, nil
+}
 
-		return nil, apiErr
+// newAPIError builds the error for a response with a non-2xx status
+func newAPIError(statusCode int, respBody []byte) *APIError {
+	var errorResp ErrorResponse
+	apiErr := &APIError{
+		StatusCode:   statusCode,
+		ResponseBody: respBody,
 	}
 
-	return respBody, nil
+	if err := json.Unmarshal(respBody, &errorResp); err == nil {
+		apiErr.ErrorCode = errorResp.ErrorCode
+		apiErr.Message = errorResp.Message
+	} else {
+		// If we can't parse the error response, use the raw body as message
+		apiErr.Message = string(respBody)
+	}
+	return apiErr
 }
 
 // unmarshalResponse unmarshals JSON response body into a struct of type T
@@ -201,6 +215,20 @@ const (
 
 // Endpoint helper functions for parameterized endpoints
 
+// expandEndpoint fills the {param} segments of an endpoint path, in order,
+// with the path-escaped values
+func expandEndpoint(endpoint string, values ...string) string {
+	for _, value := range values {
+		start := strings.IndexByte(endpoint, '{')
+		end := strings.IndexByte(endpoint, '}')
+		if start < 0 || end < start {
+			break
+		}
+		endpoint = endpoint[:start] + url.PathEscape(value) + endpoint[end+1:]
+	}
+	return endpoint
+}
+
 // endpointMetricsGetHistory returns the endpoint for getting metric history with query parameters
 func endpointMetricsGetHistory(runID, metricKey string, maxResults int, pageToken string) string {
 	endpoint := fmt.Sprintf("%s?run_id=%s&metric_key=%s",
@@ -219,12 +247,12 @@ func endpointMetricsGetHistory(runID, metricKey string, maxResults int, pageToke
 }
 
 // endpointRegisteredModelsGetLatestVersionsWithParams returns the endpoint for getting latest model versions with query parameters
-func endpointRegisteredModelsGetLatestVersionsWithParams(name string, stages []string) string {
+func endpointRegisteredModelsGetLatestVersionsWithParams(name string, stages []ModelStage) string {
 	endpoint := fmt.Sprintf("%s?name=%s", endpointRegisteredModelsGetLatestVersions, url.QueryEscape(name))
 	if len(stages) > 0 {
 		params := url.Values{}
 		for _, stage := range stages {
-			params.Add("stages", stage)
+			params.Add("stages", string(stage))
 		}
 		endpoint += "&" + params.Encode()
 	}
@@ -259,7 +287,7 @@ func endpointModelVersionsSearchWithParams(filter string, maxResults int, orderB
 
 // CreateExperiment creates a new experiment
 func (c *Client) CreateExperiment(req CreateExperimentRequest) (*CreateExperimentResponse, error) {
-	respBody, err := c.doRequest(http.MethodPost, endpointExperimentsCreate, req)
+	respBody, err := c.doMutation(mutation{operation: "CreateExperiment", target: AuditTarget{Entity: AuditEntityExperiment, Name: req.Name}}, http.MethodPost, endpointExperimentsCreate, req)
 	if err != nil {
 		return nil, err
 	}
@@ -298,7 +326,7 @@ func (c *Client) DeleteExperiment(experimentID string) error {
 	req := map[string]string{
 		"experiment_id": experimentID,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointExperimentsDeleteBase, req)
+	_, err := c.doMutation(mutation{operation: "DeleteExperiment", target: AuditTarget{Entity: AuditEntityExperiment, ID: experimentID}}, http.MethodPost, endpointExperimentsDeleteBase, req)
 	return err
 }
 
@@ -307,7 +335,7 @@ func (c *Client) RestoreExperiment(experimentID string) error {
 	req := map[string]string{
 		"experiment_id": experimentID,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointExperimentsRestoreBase, req)
+	_, err := c.doMutation(mutation{operation: "RestoreExperiment", target: AuditTarget{Entity: AuditEntityExperiment, ID: experimentID}}, http.MethodPost, endpointExperimentsRestoreBase, req)
 	return err
 }
 
@@ -317,18 +345,22 @@ func (c *Client) UpdateExperiment(experimentID, newName string) error {
 		"experiment_id": experimentID,
 		"new_name":      newName,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointExperimentsUpdate, req)
+	_, err := c.doMutation(mutation{operation: "UpdateExperiment", target: AuditTarget{Entity: AuditEntityExperiment, ID: experimentID}}, http.MethodPost, endpointExperimentsUpdate, req)
 	return err
 }
 
 // SetExperimentTag sets a tag on an experiment
 func (c *Client) SetExperimentTag(experimentID, key, value string) error {
+	value, err := c.scrubValue("SetExperimentTag", key, value)
+	if err != nil {
+		return err
+	}
 	req := map[string]string{
 		"experiment_id": experimentID,
 		"key":           key,
 		"value":         value,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointExperimentsSetTag, req)
+	_, err = c.doMutation(mutation{operation: "SetExperimentTag", target: AuditTarget{Entity: AuditEntityExperiment, ID: experimentID}}, http.MethodPost, endpointExperimentsSetTag, req)
 	return err
 }
 
@@ -338,7 +370,7 @@ func (c *Client) DeleteExperimentTag(experimentID, key string) error {
 		"experiment_id": experimentID,
 		"key":           key,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointExperimentsDeleteTag, req)
+	_, err := c.doMutation(mutation{operation: "DeleteExperimentTag", target: AuditTarget{Entity: AuditEntityExperiment, ID: experimentID}}, http.MethodPost, endpointExperimentsDeleteTag, req)
 	return err
 }
 
@@ -347,6 +379,11 @@ func (c *Client) SearchExperiments(req SearchExperimentsRequest) (*SearchExperim
 	if req.MaxResults < 0 {
 		return nil, fmt.Errorf("max_results must be greater than zero when provided")
 	}
+	if req.ViewType != "" {
+		if err := req.ViewType.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	if req.MaxResults == 0 {
 		// put in a reasonable default value
 		req.MaxResults = 100
@@ -362,17 +399,32 @@ func (c *Client) SearchExperiments(req SearchExperimentsRequest) (*SearchExperim
 // Runs API
 
 // CreateRun creates a new run
+//
+// If req.Source is set and the environment artifact cannot be logged, the
+// created run is returned together with the error.
 func (c *Client) CreateRun(req CreateRunRequest) (*CreateRunResponse, error) {
 	if req.StartTime == 0 {
 		req.StartTime = time.Now().UnixMilli()
 	}
+	var source SourceInfo
+	if req.Source {
+		source = detectSource()
+		req.Tags = withSourceTags(req.Tags, source)
+	}
 
-	respBody, err := c.doRequest(http.MethodPost, endpointRunsCreate, req)
+	respBody, err := c.doMutation(mutation{operation: "CreateRun", target: AuditTarget{Entity: AuditEntityRun, Name: req.RunName}}, http.MethodPost, endpointRunsCreate, req)
 	if err != nil {
 		return nil, err
 	}
 
-	return unmarshalResponse[CreateRunResponse](respBody)
+	resp, err := unmarshalResponse[CreateRunResponse](respBody)
+	if err != nil || !req.Source {
+		return resp, err
+	}
+	if err := c.LogEnvironment(resp.Run.Info.RunID, source); err != nil {
+		return resp, fmt.Errorf("run %s was created but its environment could not be logged: %w", resp.Run.Info.RunID, err)
+	}
+	return resp, nil
 }
 
 // GetRun gets a run by ID
@@ -393,6 +445,11 @@ func (c *Client) SearchRuns(req SearchRunsRequest) (*SearchRunsResponse, error)
 	if req.MaxResults < 0 {
 		return nil, fmt.Errorf("max_results must be greater than zero when provided")
 	}
+	if req.RunViewType != "" {
+		if err := req.RunViewType.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	if req.MaxResults == 0 {
 		// put in a reasonable default value
 		req.MaxResults = 100
@@ -407,7 +464,12 @@ func (c *Client) SearchRuns(req SearchRunsRequest) (*SearchRunsResponse, error)
 
 // UpdateRun updates a run
 func (c *Client) UpdateRun(req UpdateRunRequest) (*UpdateRunResponse, error) {
-	respBody, err := c.doRequest(http.MethodPost, endpointRunsUpdate, req)
+	if req.Status != "" {
+		if err := req.Status.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	respBody, err := c.doMutation(mutation{operation: "UpdateRun", target: AuditTarget{Entity: AuditEntityRun, ID: req.RunID}}, http.MethodPost, endpointRunsUpdate, req)
 	if err != nil {
 		return nil, err
 	}
@@ -420,7 +482,7 @@ func (c *Client) DeleteRun(runID string) error {
 	req := map[string]string{
 		"run_id": runID,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointRunsDelete, req)
+	_, err := c.doMutation(mutation{operation: "DeleteRun", target: AuditTarget{Entity: AuditEntityRun, ID: runID}}, http.MethodPost, endpointRunsDelete, req)
 	return err
 }
 
@@ -429,7 +491,7 @@ func (c *Client) RestoreRun(runID string) error {
 	req := map[string]string{
 		"run_id": runID,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointRunsRestore, req)
+	_, err := c.doMutation(mutation{operation: "RestoreRun", target: AuditTarget{Entity: AuditEntityRun, ID: runID}}, http.MethodPost, endpointRunsRestore, req)
 	return err
 }
 
@@ -442,19 +504,29 @@ func (c *Client) LogMetric(req LogMetricRequest) error {
 		req.Step = 0
 	}
 
-	_, err := c.doRequest(http.MethodPost, endpointRunsLogMetric, req)
+	_, err := c.doMutation(mutation{operation: "LogMetric", target: AuditTarget{Entity: AuditEntityRun, ID: req.RunID}}, http.MethodPost, endpointRunsLogMetric, req)
 	return err
 }
 
 // LogParam logs a parameter to a run
 func (c *Client) LogParam(req LogParamRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointRunsLogParameter, req)
+	var err error
+	req.Value, err = c.scrubValue("LogParam", req.Key, req.Value)
+	if err != nil {
+		return err
+	}
+	_, err = c.doMutation(mutation{operation: "LogParam", target: AuditTarget{Entity: AuditEntityRun, ID: req.RunID}}, http.MethodPost, endpointRunsLogParameter, req)
 	return err
 }
 
 // SetTag sets a tag on a run
 func (c *Client) SetTag(req SetTagRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointRunsSetTag, req)
+	var err error
+	req.Value, err = c.scrubValue("SetTag", req.Key, req.Value)
+	if err != nil {
+		return err
+	}
+	_, err = c.doMutation(mutation{operation: "SetTag", target: AuditTarget{Entity: AuditEntityRun, ID: req.RunID}}, http.MethodPost, endpointRunsSetTag, req)
 	return err
 }
 
@@ -464,31 +536,35 @@ func (c *Client) DeleteTag(runID, key string) error {
 		"run_id": runID,
 		"key":    key,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointRunsDeleteTag, req)
+	_, err := c.doMutation(mutation{operation: "DeleteTag", target: AuditTarget{Entity: AuditEntityRun, ID: runID}}, http.MethodPost, endpointRunsDeleteTag, req)
 	return err
 }
 
 // LogBatch logs multiple metrics, parameters, and tags in a single request
 func (c *Client) LogBatch(runID string, metrics []Metric, params []Param, tags []RunTag) error {
+	params, tags, err := c.scrubBatch(params, tags)
+	if err != nil {
+		return err
+	}
 	req := LogBatchRequest{
 		RunID:   runID,
 		Metrics: metrics,
 		Params:  params,
 		Tags:    tags,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointRunsLogBatch, req)
+	_, err = c.doMutation(mutation{operation: "LogBatch", target: AuditTarget{Entity: AuditEntityRun, ID: runID}}, http.MethodPost, endpointRunsLogBatch, req)
 	return err
 }
 
 // LogModel logs a model to a run
 func (c *Client) LogModel(req LogModelRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointRunsLogModel, req)
+	_, err := c.doMutation(mutation{operation: "LogModel", target: AuditTarget{Entity: AuditEntityRun, ID: req.RunID}}, http.MethodPost, endpointRunsLogModel, req)
 	return err
 }
 
 // LogInputs logs inputs (datasets and/or model inputs) to a run
 func (c *Client) LogInputs(req LogInputsRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointRunsLogInputs, req)
+	_, err := c.doMutation(mutation{operation: "LogInputs", target: AuditTarget{Entity: AuditEntityRun, ID: req.RunID}}, http.MethodPost, endpointRunsLogInputs, req)
 	return err
 }
 
@@ -521,7 +597,7 @@ func (c *Client) ListArtifacts(runID, path string, pageToken string) (*ListArtif
 
 // CreateRegisteredModel creates a new registered model
 func (c *Client) CreateRegisteredModel(req CreateRegisteredModelRequest) (*CreateRegisteredModelResponse, error) {
-	respBody, err := c.doRequest(http.MethodPost, endpointRegisteredModelsCreate, req)
+	respBody, err := c.doMutation(mutation{operation: "CreateRegisteredModel", target: AuditTarget{Entity: AuditEntityModel, Name: req.Name}}, http.MethodPost, endpointRegisteredModelsCreate, req)
 	if err != nil {
 		return nil, err
 	}
@@ -550,7 +626,7 @@ func (c *Client) UpdateRegisteredModel(name, description string) error {
 	if description != "" {
 		req["description"] = description
 	}
-	_, err := c.doRequest(http.MethodPatch, endpointRegisteredModelsUpdate, req)
+	_, err := c.doMutation(mutation{operation: "UpdateRegisteredModel", target: AuditTarget{Entity: AuditEntityModel, Name: name}}, http.MethodPatch, endpointRegisteredModelsUpdate, req)
 	return err
 }
 
@@ -560,13 +636,13 @@ func (c *Client) DeleteRegisteredModel(name string) error {
 		"name":        name,
 		"max_results": 100,
 	}
-	_, err := c.doRequest(http.MethodDelete, endpointRegisteredModelsDelete, req)
+	_, err := c.doMutation(mutation{operation: "DeleteRegisteredModel", target: AuditTarget{Entity: AuditEntityModel, Name: name}}, http.MethodDelete, endpointRegisteredModelsDelete, req)
 	return err
 }
 
 // CreateModelVersion creates a new model version
 func (c *Client) CreateModelVersion(req CreateModelVersionRequest) (*CreateModelVersionResponse, error) {
-	respBody, err := c.doRequest(http.MethodPost, endpointModelVersionsCreate, req)
+	respBody, err := c.doMutation(mutation{operation: "CreateModelVersion", target: AuditTarget{Entity: AuditEntityModelVersion, Name: req.Name}}, http.MethodPost, endpointModelVersionsCreate, req)
 	if err != nil {
 		return nil, err
 	}
@@ -589,7 +665,7 @@ func (c *Client) GetModelVersion(name, version string) (*GetModelVersionResponse
 }
 
 // UpdateModelVersion updates a model version
-func (c *Client) UpdateModelVersion(name, version, description, stage string) error {
+func (c *Client) UpdateModelVersion(name, version, description string, stage ModelStage) error {
 	req := map[string]string{
 		"name":    name,
 		"version": version,
@@ -598,9 +674,12 @@ func (c *Client) UpdateModelVersion(name, version, description, stage string) er
 		req["description"] = description
 	}
 	if stage != "" {
-		req["stage"] = stage
+		if err := stage.Validate(); err != nil {
+			return err
+		}
+		req["stage"] = string(stage.Canonical())
 	}
-	_, err := c.doRequest(http.MethodPatch, endpointModelVersionsUpdate, req)
+	_, err := c.doMutation(mutation{operation: "UpdateModelVersion", target: AuditTarget{Entity: AuditEntityModelVersion, Name: name, Version: version}}, http.MethodPatch, endpointModelVersionsUpdate, req)
 	return err
 }
 
@@ -610,21 +689,25 @@ func (c *Client) DeleteModelVersion(name, version string) error {
 		"name":    name,
 		"version": version,
 	}
-	_, err := c.doRequest(http.MethodDelete, endpointModelVersionsDeleteBase, req)
+	_, err := c.doMutation(mutation{operation: "DeleteModelVersion", target: AuditTarget{Entity: AuditEntityModelVersion, Name: name, Version: version}}, http.MethodDelete, endpointModelVersionsDeleteBase, req)
 	return err
 }
 
 // TransitionModelVersionStage transitions a model version to a new stage
-func (c *Client) TransitionModelVersionStage(name, version, stage, archiveExistingVersions string) (*GetModelVersionResponse, error) {
+func (c *Client) TransitionModelVersionStage(name, version string, stage ModelStage, archiveExistingVersions string) (*GetModelVersionResponse, error) {
+	if err := stage.Validate(); err != nil {
+		return nil, err
+	}
+	stage = stage.Canonical()
 	req := map[string]string{
 		"name":    name,
 		"version": version,
-		"stage":   stage,
+		"stage":   string(stage),
 	}
 	if archiveExistingVersions != "" {
 		req["archive_existing_versions"] = archiveExistingVersions
 	}
-	respBody, err := c.doRequest(http.MethodPost, endpointModelVersionsTransitionStage, req)
+	respBody, err := c.doMutation(mutation{operation: "TransitionModelVersionStage", target: AuditTarget{Entity: AuditEntityModelVersion, Name: name, Version: version}, stage: string(stage), archiveExisting: strings.EqualFold(archiveExistingVersions, "true")}, http.MethodPost, endpointModelVersionsTransitionStage, req)
 	if err != nil {
 		return nil, err
 	}
@@ -634,7 +717,7 @@ func (c *Client) TransitionModelVersionStage(name, version, stage, archiveExisti
 
 // RenameRegisteredModel renames a registered model
 func (c *Client) RenameRegisteredModel(req RenameRegisteredModelRequest) (*RenameRegisteredModelResponse, error) {
-	respBody, err := c.doRequest(http.MethodPost, endpointRegisteredModelsRename, req)
+	respBody, err := c.doMutation(mutation{operation: "RenameRegisteredModel", target: AuditTarget{Entity: AuditEntityModel, Name: req.Name}}, http.MethodPost, endpointRegisteredModelsRename, req)
 	if err != nil {
 		return nil, err
 	}
@@ -644,6 +727,11 @@ func (c *Client) RenameRegisteredModel(req RenameRegisteredModelRequest) (*Renam
 
 // GetLatestModelVersions gets the latest model versions for a registered model
 func (c *Client) GetLatestModelVersions(req GetLatestModelVersionsRequest) (*GetLatestModelVersionsResponse, error) {
+	for _, stage := range req.Stages {
+		if err := stage.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	respBody, err := c.doRequest(http.MethodGet, endpointRegisteredModelsGetLatestVersionsWithParams(req.Name, req.Stages), nil)
 	if err != nil {
 		return nil, err
@@ -698,13 +786,23 @@ func (c *Client) SearchRegisteredModels(req SearchRegisteredModelsRequest) (*Sea
 
 // SetRegisteredModelTag sets a tag on a registered model
 func (c *Client) SetRegisteredModelTag(req SetRegisteredModelTagRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointRegisteredModelsSetTag, req)
+	var err error
+	req.Value, err = c.scrubValue("SetRegisteredModelTag", req.Key, req.Value)
+	if err != nil {
+		return err
+	}
+	_, err = c.doMutation(mutation{operation: "SetRegisteredModelTag", target: AuditTarget{Entity: AuditEntityModel, Name: req.Name}}, http.MethodPost, endpointRegisteredModelsSetTag, req)
 	return err
 }
 
 // SetModelVersionTag sets a tag on a model version
 func (c *Client) SetModelVersionTag(req SetModelVersionTagRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointModelVersionsSetTag, req)
+	var err error
+	req.Value, err = c.scrubValue("SetModelVersionTag", req.Key, req.Value)
+	if err != nil {
+		return err
+	}
+	_, err = c.doMutation(mutation{operation: "SetModelVersionTag", target: AuditTarget{Entity: AuditEntityModelVersion, Name: req.Name, Version: req.Version}}, http.MethodPost, endpointModelVersionsSetTag, req)
 	return err
 }
 
@@ -717,7 +815,7 @@ func (c *Client) DeleteRegisteredModelTag(req DeleteRegisteredModelTagRequest) e
 	if len([]byte(req.Key)) > 250 {
 		return fmt.Errorf("key length must be less than 250 bytes")
 	}
-	_, err := c.doRequest(http.MethodDelete, endpointRegisteredModelsDeleteTagBase, reqBody)
+	_, err := c.doMutation(mutation{operation: "DeleteRegisteredModelTag", target: AuditTarget{Entity: AuditEntityModel, Name: req.Name}}, http.MethodDelete, endpointRegisteredModelsDeleteTagBase, reqBody)
 	return err
 }
 
@@ -728,13 +826,13 @@ func (c *Client) DeleteModelVersionTag(req DeleteModelVersionTagRequest) error {
 		"version": req.Version,
 		"key":     req.Key,
 	}
-	_, err := c.doRequest(http.MethodDelete, endpointModelVersionsDeleteTagBase, reqBody)
+	_, err := c.doMutation(mutation{operation: "DeleteModelVersionTag", target: AuditTarget{Entity: AuditEntityModelVersion, Name: req.Name, Version: req.Version}}, http.MethodDelete, endpointModelVersionsDeleteTagBase, reqBody)
 	return err
 }
 
 // SetRegisteredModelAlias sets an alias for a registered model
 func (c *Client) SetRegisteredModelAlias(req SetRegisteredModelAliasRequest) error {
-	_, err := c.doRequest(http.MethodPost, endpointRegisteredModelsAliasBase, req)
+	_, err := c.doMutation(mutation{operation: "SetRegisteredModelAlias", target: AuditTarget{Entity: AuditEntityAlias, Name: req.Name, Version: req.Version, Alias: req.Alias}}, http.MethodPost, endpointRegisteredModelsAliasBase, req)
 	return err
 }
 
@@ -748,7 +846,7 @@ func (c *Client) DeleteRegisteredModelAlias(req DeleteRegisteredModelAliasReques
 		"alias":   req.Alias,
 		"version": req.Version,
 	}
-	_, err := c.doRequest(http.MethodPost, endpointRegisteredModelsAliasBase, reqBody)
+	_, err := c.doMutation(mutation{operation: "DeleteRegisteredModelAlias", target: AuditTarget{Entity: AuditEntityAlias, Name: req.Name, Version: req.Version, Alias: req.Alias}}, http.MethodPost, endpointRegisteredModelsAliasBase, reqBody)
 	return err
 }
 
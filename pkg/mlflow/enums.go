@@ -0,0 +1,121 @@
+package mlflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RunStatus is the status of a run
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "RUNNING"
+	RunStatusScheduled RunStatus = "SCHEDULED"
+	RunStatusFinished  RunStatus = "FINISHED"
+	RunStatusFailed    RunStatus = "FAILED"
+	RunStatusKilled    RunStatus = "KILLED"
+)
+
+// RunStatuses lists the valid run statuses
+var RunStatuses = []RunStatus{RunStatusRunning, RunStatusScheduled, RunStatusFinished, RunStatusFailed, RunStatusKilled}
+
+// Validate returns an error if s is not a valid run status
+func (s RunStatus) Validate() error {
+	return validateEnum("run status", s, RunStatuses, false)
+}
+
+// IsTerminal reports whether a run with this status has ended
+func (s RunStatus) IsTerminal() bool {
+	return s == RunStatusFinished || s == RunStatusFailed || s == RunStatusKilled
+}
+
+// ViewType selects active, deleted or all experiments and runs in a search
+type ViewType string
+
+const (
+	ViewTypeActiveOnly  ViewType = "ACTIVE_ONLY"
+	ViewTypeDeletedOnly ViewType = "DELETED_ONLY"
+	ViewTypeAll         ViewType = "ALL"
+)
+
+// ViewTypes lists the valid view types
+var ViewTypes = []ViewType{ViewTypeActiveOnly, ViewTypeDeletedOnly, ViewTypeAll}
+
+// Validate returns an error if v is not a valid view type
+func (v ViewType) Validate() error {
+	return validateEnum("view type", v, ViewTypes, false)
+}
+
+// LifecycleStage is whether an experiment or run is active or deleted
+type LifecycleStage string
+
+const (
+	LifecycleStageActive  LifecycleStage = "active"
+	LifecycleStageDeleted LifecycleStage = "deleted"
+)
+
+// LifecycleStages lists the valid lifecycle stages
+var LifecycleStages = []LifecycleStage{LifecycleStageActive, LifecycleStageDeleted}
+
+// Validate returns an error if l is not a valid lifecycle stage
+func (l LifecycleStage) Validate() error {
+	return validateEnum("lifecycle stage", l, LifecycleStages, false)
+}
+
+// ModelStage is the deployment stage of a model version
+type ModelStage string
+
+const (
+	ModelStageNone       ModelStage = "None"
+	ModelStageStaging    ModelStage = "Staging"
+	ModelStageProduction ModelStage = "Production"
+	ModelStageArchived   ModelStage = "Archived"
+)
+
+// ModelStages lists the valid model stages
+var ModelStages = []ModelStage{ModelStageNone, ModelStageStaging, ModelStageProduction, ModelStageArchived}
+
+// Validate returns an error if s is not a valid model stage. Like the server,
+// it ignores case.
+func (s ModelStage) Validate() error {
+	return validateEnum("model stage", s, ModelStages, true)
+}
+
+// Canonical returns the stage with the capitalization the server reports,
+// e.g. Production for "production". Invalid stages are returned unchanged.
+func (s ModelStage) Canonical() ModelStage {
+	for _, stage := range ModelStages {
+		if strings.EqualFold(string(s), string(stage)) {
+			return stage
+		}
+	}
+	return s
+}
+
+// ModelVersionStatus is the registration status of a model version
+type ModelVersionStatus string
+
+const (
+	ModelVersionStatusPendingRegistration ModelVersionStatus = "PENDING_REGISTRATION"
+	ModelVersionStatusFailedRegistration  ModelVersionStatus = "FAILED_REGISTRATION"
+	ModelVersionStatusReady               ModelVersionStatus = "READY"
+)
+
+// ModelVersionStatuses lists the valid model version statuses
+var ModelVersionStatuses = []ModelVersionStatus{ModelVersionStatusPendingRegistration, ModelVersionStatusFailedRegistration, ModelVersionStatusReady}
+
+// Validate returns an error if s is not a valid model version status
+func (s ModelVersionStatus) Validate() error {
+	return validateEnum("model version status", s, ModelVersionStatuses, false)
+}
+
+func validateEnum[T ~string](kind string, value T, valid []T, ignoreCase bool) error {
+	names := make([]string, len(valid))
+	for i, v := range valid {
+		if value == v || ignoreCase && strings.EqualFold(string(value), string(v)) {
+			return nil
+		}
+		names[i] = string(v)
+	}
+	return fmt.Errorf("invalid %s %q (valid: %s)", kind, value, strings.Join(names, ", "))
+}
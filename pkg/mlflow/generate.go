@@ -0,0 +1,3 @@
+package mlflow
+
+//go:generate go run ../../cmd/mlflow-protogen -config ../../protos/protogen.json
@@ -0,0 +1,183 @@
+package mlflow
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/internal/filterquote"
+)
+
+// TagIdempotencyKey is the run tag ResumeOrStartRun finds a run by
+const TagIdempotencyKey = "mlflow-go.idempotencyKey"
+
+// getOrCreateAttempts bounds how often GetOrCreateExperiment looks up an
+// experiment that another process reported as created
+const getOrCreateAttempts = 5
+
+// ExperimentOptions configures an experiment created by GetOrCreateExperiment
+type ExperimentOptions struct {
+	ArtifactLocation string
+	Tags             []ExperimentTag
+	// RestoreDeleted restores a deleted experiment with the name instead of
+	// returning an error
+	RestoreDeleted bool
+}
+
+// GetOrCreateExperiment gets the experiment with the given name, creating it
+// with opts if it does not exist, and reports whether it was created. It is
+// safe to call from several processes at once: a process that loses the race
+// to create the experiment gets the one the winner created. Options only
+// apply to a new experiment.
+func (c *Client) GetOrCreateExperiment(name string, opts ExperimentOptions) (*Experiment, bool, error) {
+	if name == "" {
+		return nil, false, fmt.Errorf("experiment name is required")
+	}
+	var lastErr error
+	for attempt := 0; attempt < getOrCreateAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 50 * time.Millisecond)
+		}
+		experiment, err := c.activeExperimentByName(name, opts.RestoreDeleted)
+		if err == nil {
+			return experiment, false, nil
+		}
+		if !hasErrorCode(err, ErrorCodeResourceDoesNotExist) {
+			return nil, false, err
+		}
+
+		created, err := c.CreateExperiment(CreateExperimentRequest{Name: name, ArtifactLocation: opts.ArtifactLocation, Tags: opts.Tags})
+		if err == nil {
+			resp, err := c.GetExperiment(created.ExperimentID)
+			if err != nil {
+				return nil, false, err
+			}
+			return &resp.Experiment, true, nil
+		}
+		if !hasErrorCode(err, ErrorCodeResourceAlreadyExists) {
+			return nil, false, err
+		}
+		// Another process created it since the lookup; get theirs
+		lastErr = err
+	}
+	return nil, false, fmt.Errorf("failed to get or create experiment %q: %w", name, lastErr)
+}
+
+// activeExperimentByName gets the experiment with the given name, restoring it
+// if it is deleted and restore is set
+func (c *Client) activeExperimentByName(name string, restore bool) (*Experiment, error) {
+	resp, err := c.GetExperimentByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Experiment.LifecycleStage != LifecycleStageDeleted {
+		return &resp.Experiment, nil
+	}
+	if !restore {
+		return nil, fmt.Errorf("experiment %q (%s) is deleted", name, resp.Experiment.ExperimentID)
+	}
+	if err := c.RestoreExperiment(resp.Experiment.ExperimentID); err != nil {
+		return nil, err
+	}
+	restored, err := c.GetExperiment(resp.Experiment.ExperimentID)
+	if err != nil {
+		return nil, err
+	}
+	return &restored.Experiment, nil
+}
+
+// ResumeOrStartRun returns the run tagged with idempotencyKey in the
+// experiment, and reports whether it already existed. An existing run that
+// has ended is set back to RUNNING; otherwise a run is created with the tag.
+// A job that restarts after preemption therefore logs to the same run as
+// before. If experimentID is empty, the experiment comes from
+// MLFLOW_EXPERIMENT_ID or MLFLOW_EXPERIMENT_NAME.
+//
+// When several processes start the same key at once, each may create a run.
+// Each process looks again after creating its run, and deletes it in favour
+// of the run that started first if that is another one. This narrows the race
+// but does not remove it: start times come from each process's clock, and a
+// process that looks before another's run is visible keeps its own, so a key
+// can still end up with more than one run. The duplicate is deleted without
+// consulting the policy engine, since the call created it moments before.
+func (c *Client) ResumeOrStartRun(experimentID, idempotencyKey string) (*ActiveRun, bool, error) {
+	if idempotencyKey == "" {
+		return nil, false, fmt.Errorf("idempotency key is required")
+	}
+	if experimentID == "" {
+		var err error
+		if experimentID, err = c.experimentIDFromEnv(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	existing, err := c.runByIdempotencyKey(experimentID, idempotencyKey)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		run, err := c.resumeRun(*existing)
+		return run, true, err
+	}
+
+	created, err := c.CreateRun(CreateRunRequest{
+		ExperimentID: experimentID,
+		Tags:         []RunTag{{Key: TagIdempotencyKey, Value: idempotencyKey}},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	// Look again in case another process created a run for the key too
+	winner, err := c.runByIdempotencyKey(experimentID, idempotencyKey)
+	if err != nil {
+		return nil, false, err
+	}
+	if winner == nil || winner.Info.RunID == created.Run.Info.RunID {
+		return &ActiveRun{Client: c, RunID: created.Run.Info.RunID, ExperimentID: experimentID}, false, nil
+	}
+	unchecked := *c
+	unchecked.Policy = nil
+	if err := unchecked.DeleteRun(created.Run.Info.RunID); err != nil {
+		return nil, false, fmt.Errorf("failed to delete duplicate run %s: %w", created.Run.Info.RunID, err)
+	}
+	run, err := c.resumeRun(*winner)
+	return run, true, err
+}
+
+// runByIdempotencyKey returns the first started active run tagged with key,
+// or nil if there is none
+func (c *Client) runByIdempotencyKey(experimentID, key string) (*Run, error) {
+	quoted, err := filterquote.Quote(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid idempotency key: %w", err)
+	}
+	resp, err := c.SearchRuns(SearchRunsRequest{
+		ExperimentIDs: []string{experimentID},
+		Filter:        fmt.Sprintf("tags.`%s` = %s", TagIdempotencyKey, quoted),
+		RunViewType:   ViewTypeActiveOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for run with idempotency key %q: %w", key, err)
+	}
+	if len(resp.Runs) == 0 {
+		return nil, nil
+	}
+	runs := resp.Runs
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].Info.StartTime != runs[j].Info.StartTime {
+			return runs[i].Info.StartTime < runs[j].Info.StartTime
+		}
+		return runs[i].Info.RunID < runs[j].Info.RunID
+	})
+	return &runs[0], nil
+}
+
+// resumeRun sets a run back to RUNNING if it has ended
+func (c *Client) resumeRun(run Run) (*ActiveRun, error) {
+	if run.Info.Status != RunStatusRunning {
+		if _, err := c.UpdateRun(UpdateRunRequest{RunID: run.Info.RunID, Status: RunStatusRunning}); err != nil {
+			return nil, fmt.Errorf("failed to resume run %s: %w", run.Info.RunID, err)
+		}
+	}
+	return &ActiveRun{Client: c, RunID: run.Info.RunID, ExperimentID: run.Info.ExperimentID}, nil
+}
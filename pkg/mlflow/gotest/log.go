@@ -0,0 +1,202 @@
+package gotest
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Metrics and tags Log records for the tests of a run
+const (
+	MetricPassed         = "tests/passed"
+	MetricFailed         = "tests/failed"
+	MetricSkipped        = "tests/skipped"
+	MetricFailedPackages = "tests/failed_packages"
+	MetricElapsedSeconds = "tests/elapsed_seconds"
+
+	TagFailedTests    = "mlflow-go.test.failed"
+	TagFailedPackages = "mlflow-go.test.failedPackages"
+	TagGOOS           = "mlflow-go.bench.goos"
+	TagGOARCH         = "mlflow-go.bench.goarch"
+	TagCPU            = "mlflow-go.bench.cpu"
+)
+
+// Limits of a single LogBatch request and of a tag value
+const (
+	maxBatchMetrics = 1000
+	maxTagValue     = 8000
+)
+
+// DefaultRunName is the name of runs created by Log when Options.RunName is
+// empty
+const DefaultRunName = "go test"
+
+// Options configures the run Log creates
+type Options struct {
+	ExperimentID string
+	RunName      string
+	// GitCommit is tagged as mlflow.source.git.commit. Use GitCommit to read
+	// it from a checkout.
+	GitCommit string
+	Tags      []mlflow.RunTag
+}
+
+// invalidKeyChars are the characters MLflow does not accept in metric keys
+var invalidKeyChars = regexp.MustCompile(`[^A-Za-z0-9_\-. /]`)
+
+// MetricKey returns the metric key of a benchmark value, such as
+// BenchmarkEncode/size_1k/ns_per_op for ns/op of BenchmarkEncode/size=1k.
+// The / of a unit becomes _per_ and characters MLflow does not accept in a
+// key become _.
+func MetricKey(benchmark, unit string) string {
+	unit = strings.ReplaceAll(unit, "/", "_per_")
+	return invalidKeyChars.ReplaceAllString(benchmark+"/"+unit, "_")
+}
+
+// Metrics returns the report's metrics: the test counts at step 0 and each
+// benchmark value under MetricKey. A benchmark that ran with several
+// GOMAXPROCS values, as with -cpu 1,4, keeps the -N suffix in its name. A
+// benchmark that ran more than once, as with -count, is logged at steps 0, 1,
+// 2 and so on.
+func (r *Report) Metrics(timestamp time.Time) []mlflow.Metric {
+	ts := timestamp.UnixMilli()
+	metrics := []mlflow.Metric{
+		{Key: MetricPassed, Value: float64(r.Passed), Timestamp: ts},
+		{Key: MetricFailed, Value: float64(r.Failed), Timestamp: ts},
+		{Key: MetricSkipped, Value: float64(r.Skipped), Timestamp: ts},
+		{Key: MetricFailedPackages, Value: float64(len(r.FailedPackages)), Timestamp: ts},
+		{Key: MetricElapsedSeconds, Value: r.Elapsed.Seconds(), Timestamp: ts},
+	}
+	procs := map[string]map[int]bool{}
+	for _, b := range r.Benchmarks {
+		if procs[b.Name] == nil {
+			procs[b.Name] = map[int]bool{}
+		}
+		procs[b.Name][b.Procs] = true
+	}
+	steps := map[string]int64{}
+	for _, b := range r.Benchmarks {
+		name := b.Name
+		if len(procs[b.Name]) > 1 {
+			name = fmt.Sprintf("%s-%d", b.Name, b.Procs)
+		}
+		units := make([]string, 0, len(b.Values))
+		for unit := range b.Values {
+			units = append(units, unit)
+		}
+		sort.Strings(units)
+		for _, unit := range units {
+			key := MetricKey(name, unit)
+			metrics = append(metrics, mlflow.Metric{Key: key, Value: b.Values[unit], Timestamp: ts, Step: steps[key]})
+			steps[key]++
+		}
+	}
+	return metrics
+}
+
+// Tags returns the report's tags: the failed tests and packages and the
+// machine the benchmarks ran on. Lists too long for a tag are cut short.
+func (r *Report) Tags() []mlflow.RunTag {
+	var tags []mlflow.RunTag
+	add := func(key, value string) {
+		if value != "" {
+			tags = append(tags, mlflow.RunTag{Key: key, Value: value})
+		}
+	}
+	add(TagFailedTests, joinLimited(r.FailedTests, maxTagValue))
+	add(TagFailedPackages, joinLimited(r.FailedPackages, maxTagValue))
+	add(TagGOOS, r.GOOS)
+	add(TagGOARCH, r.GOARCH)
+	add(TagCPU, r.CPU)
+	return tags
+}
+
+// joinLimited joins names with commas, ending with "and N more" if they do
+// not fit in limit bytes
+func joinLimited(names []string, limit int) string {
+	var b strings.Builder
+	for i, name := range names {
+		sep := ""
+		if i > 0 {
+			sep = ", "
+		}
+		more := fmt.Sprintf(", and %d more", len(names)-i)
+		if b.Len()+len(sep)+len(name)+len(more) > limit {
+			b.WriteString(more)
+			break
+		}
+		b.WriteString(sep + name)
+	}
+	return b.String()
+}
+
+// Log creates a run for the report in the experiment, logs its metrics and
+// tags and ends it, FINISHED if every test passed and FAILED otherwise. It
+// returns the run ID, also when logging fails after the run was created.
+func Log(client *mlflow.Client, report *Report, opts Options) (string, error) {
+	if opts.ExperimentID == "" {
+		return "", fmt.Errorf("experiment ID is required")
+	}
+	if opts.RunName == "" {
+		opts.RunName = DefaultRunName
+	}
+	tags := append([]mlflow.RunTag{}, opts.Tags...)
+	if opts.GitCommit != "" {
+		tags = append(tags, mlflow.RunTag{Key: mlflow.TagSourceGitCommit, Value: opts.GitCommit})
+	}
+	now := time.Now()
+	resp, err := client.CreateRun(mlflow.CreateRunRequest{
+		ExperimentID: opts.ExperimentID,
+		RunName:      opts.RunName,
+		StartTime:    now.Add(-report.Elapsed).UnixMilli(),
+		Tags:         tags,
+	})
+	if err != nil {
+		return "", err
+	}
+	runID := resp.Run.Info.RunID
+
+	status := mlflow.RunStatusFinished
+	if !report.OK() {
+		status = mlflow.RunStatusFailed
+	}
+	logErr := logReport(client, runID, report, now)
+	if logErr != nil {
+		status = mlflow.RunStatusFailed
+	}
+	_, err = client.UpdateRun(mlflow.UpdateRunRequest{RunID: runID, Status: status, EndTime: time.Now().UnixMilli()})
+	if logErr != nil {
+		return runID, logErr
+	}
+	return runID, err
+}
+
+func logReport(client *mlflow.Client, runID string, report *Report, timestamp time.Time) error {
+	metrics := report.Metrics(timestamp)
+	tags := report.Tags()
+	for len(metrics) > 0 || tags != nil {
+		n := min(len(metrics), maxBatchMetrics)
+		if err := client.LogBatch(runID, metrics[:n], nil, tags); err != nil {
+			return fmt.Errorf("failed to log test results to run %s: %w", runID, err)
+		}
+		metrics, tags = metrics[n:], nil
+	}
+	return nil
+}
+
+// GitCommit returns the commit checked out in dir, or in the current
+// directory if dir is empty
+func GitCommit(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git commit: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
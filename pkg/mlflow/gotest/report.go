@@ -0,0 +1,271 @@
+// Package gotest records go test results in MLflow: test counts, failing
+// tests and benchmark measurements, read from go test output or collected
+// with testing.Benchmark
+package gotest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Report is the outcome of a go test invocation
+type Report struct {
+	Passed  int
+	Failed  int
+	Skipped int
+	// FailedTests are the failed tests and subtests, as package.TestName
+	// when the package is known
+	FailedTests []string
+	// FailedPackages are the packages that failed, including those that did
+	// not build
+	FailedPackages []string
+	Benchmarks     []Benchmark
+	// Elapsed is the total time the packages' tests took
+	Elapsed time.Duration
+	// GOOS, GOARCH and CPU are from the header go test prints before
+	// benchmarks
+	GOOS   string
+	GOARCH string
+	CPU    string
+}
+
+// Benchmark is one benchmark result line
+type Benchmark struct {
+	Package string
+	// Name is the benchmark name without the -GOMAXPROCS suffix
+	Name       string
+	Procs      int
+	Iterations int64
+	// Values maps a unit, such as ns/op, B/op, allocs/op or a unit reported
+	// with b.ReportMetric, to the measured value
+	Values map[string]float64
+}
+
+// OK reports whether no test or package failed
+func (r *Report) OK() bool {
+	return r.Failed == 0 && len(r.FailedPackages) == 0
+}
+
+// AddBenchmark adds the result of testing.Benchmark to the report
+func (r *Report) AddBenchmark(name string, result testing.BenchmarkResult) {
+	values := map[string]float64{"B/op": float64(result.AllocedBytesPerOp()), "allocs/op": float64(result.AllocsPerOp())}
+	if result.N > 0 {
+		values["ns/op"] = float64(result.T.Nanoseconds()) / float64(result.N)
+	}
+	for unit, value := range result.Extra {
+		values[unit] = value
+	}
+	r.Benchmarks = append(r.Benchmarks, Benchmark{
+		Name:       name,
+		Procs:      runtime.GOMAXPROCS(0),
+		Iterations: int64(result.N),
+		Values:     values,
+	})
+}
+
+// Benchmark runs fn with testing.Benchmark, adds the result to the report
+// and returns it. It lets a program or TestMain record benchmarks without
+// parsing go test output.
+func (r *Report) Benchmark(name string, fn func(b *testing.B)) testing.BenchmarkResult {
+	result := testing.Benchmark(fn)
+	r.AddBenchmark(name, result)
+	return result
+}
+
+// event is a test2json event, as printed by go test -json
+type event struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// Parse reads go test output, either the JSON events of go test -json or the
+// plain text go test prints, and returns the report. Lines that are neither,
+// such as build errors, are ignored.
+func Parse(r io.Reader) (*Report, error) {
+	p := &parser{report: &Report{}, partial: map[string]string{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		p.line(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go test output: %w", err)
+	}
+	p.flush()
+	return p.report, nil
+}
+
+type parser struct {
+	report *Report
+	// partial holds the incomplete output line of each test in JSON mode
+	partial map[string]string
+	// pkg is the package of the benchmarks that follow in text mode, and
+	// pending the failed tests whose package is not printed yet
+	pkg     string
+	pending []string
+}
+
+var (
+	benchmarkLine  = regexp.MustCompile(`^(Benchmark\S*)\s+(\d+)\s+(.+)$`)
+	procsSuffix    = regexp.MustCompile(`-(\d+)$`)
+	resultLine     = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+	packageSummary = regexp.MustCompile(`^(ok|FAIL)\s+(\S+)(?:\s+([\d.]+)s)?`)
+)
+
+func (p *parser) line(line string) {
+	if strings.HasPrefix(line, "{") {
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err == nil && e.Action != "" {
+			p.event(e)
+			return
+		}
+	}
+	p.textLine(line, "", false)
+}
+
+func (p *parser) event(e event) {
+	switch e.Action {
+	case "output":
+		key := e.Package + "\x00" + e.Test
+		text := p.partial[key] + e.Output
+		for {
+			line, rest, ok := strings.Cut(text, "\n")
+			if !ok {
+				break
+			}
+			p.textLine(line, e.Package, true)
+			text = rest
+		}
+		p.partial[key] = text
+	case "pass", "fail", "skip":
+		if e.Test == "" {
+			p.report.Elapsed += time.Duration(e.Elapsed * float64(time.Second))
+			if e.Action == "fail" {
+				p.report.FailedPackages = append(p.report.FailedPackages, e.Package)
+			}
+			return
+		}
+		if p.count(e.Action, e.Test) {
+			p.report.FailedTests = append(p.report.FailedTests, e.Package+"."+e.Test)
+		}
+	}
+}
+
+// flush parses output lines that did not end with a newline
+func (p *parser) flush() {
+	for key, text := range p.partial {
+		if text != "" {
+			pkg, _, _ := strings.Cut(key, "\x00")
+			p.textLine(text, pkg, true)
+		}
+	}
+	p.report.FailedTests = append(p.report.FailedTests, p.pending...)
+	p.pending = nil
+}
+
+// count counts a test result and reports whether the test failed.
+// Benchmarks are not tests.
+func (p *parser) count(action, test string) bool {
+	if strings.HasPrefix(test, "Benchmark") {
+		return false
+	}
+	switch strings.ToLower(action) {
+	case "pass":
+		p.report.Passed++
+	case "skip":
+		p.report.Skipped++
+	case "fail":
+		p.report.Failed++
+		return true
+	}
+	return false
+}
+
+// textLine parses a line of go test output. Test results and package
+// summaries are only counted in text mode, as JSON mode has events for them.
+func (p *parser) textLine(line, pkg string, fromJSON bool) {
+	line = strings.TrimRight(line, "\r")
+	if m := benchmarkLine.FindStringSubmatch(line); m != nil {
+		if pkg == "" {
+			pkg = p.pkg
+		}
+		p.benchmark(pkg, m[1], m[2], m[3])
+		return
+	}
+	switch {
+	case strings.HasPrefix(line, "goos: "):
+		p.report.GOOS = strings.TrimPrefix(line, "goos: ")
+		return
+	case strings.HasPrefix(line, "goarch: "):
+		p.report.GOARCH = strings.TrimPrefix(line, "goarch: ")
+		return
+	case strings.HasPrefix(line, "cpu: "):
+		p.report.CPU = strings.TrimPrefix(line, "cpu: ")
+		return
+	case strings.HasPrefix(line, "pkg: "):
+		p.pkg = strings.TrimPrefix(line, "pkg: ")
+		return
+	}
+	if fromJSON {
+		return
+	}
+
+	if m := resultLine.FindStringSubmatch(line); m != nil {
+		if p.count(m[1], m[2]) {
+			// The package is printed after its tests; name them then
+			p.pending = append(p.pending, m[2])
+		}
+		return
+	}
+	if m := packageSummary.FindStringSubmatch(line); m != nil && strings.Contains(line, "\t") {
+		for _, name := range p.pending {
+			p.report.FailedTests = append(p.report.FailedTests, m[2]+"."+name)
+		}
+		p.pending = nil
+		if m[3] != "" {
+			if seconds, err := strconv.ParseFloat(m[3], 64); err == nil {
+				p.report.Elapsed += time.Duration(seconds * float64(time.Second))
+			}
+		}
+		if m[1] == "FAIL" {
+			p.report.FailedPackages = append(p.report.FailedPackages, m[2])
+		}
+	}
+}
+
+// benchmark parses the iterations and value-unit pairs of a benchmark line
+func (p *parser) benchmark(pkg, name, iterations, measurements string) {
+	n, err := strconv.ParseInt(iterations, 10, 64)
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(measurements)
+	if len(fields) < 2 || len(fields)%2 != 0 {
+		return
+	}
+	values := make(map[string]float64, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return
+		}
+		values[fields[i+1]] = value
+	}
+	b := Benchmark{Package: pkg, Name: name, Procs: 1, Iterations: n, Values: values}
+	if m := procsSuffix.FindStringSubmatch(name); m != nil {
+		b.Name = strings.TrimSuffix(name, m[0])
+		b.Procs, _ = strconv.Atoi(m[1])
+	}
+	p.report.Benchmarks = append(p.report.Benchmarks, b)
+}
@@ -0,0 +1,183 @@
+package mlflow
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultArtifactKeyEnv is the environment variable EnvKeyProvider reads by
+// default
+const DefaultArtifactKeyEnv = "MLFLOW_ARTIFACT_ENCRYPTION_KEY"
+
+// KeyProvider wraps the data keys of encrypted artifacts with a key
+// encryption key, and unwraps them again. Set one as Client.ArtifactKeys.
+type KeyProvider interface {
+	// KeyID identifies the key WrapKey wraps with. It is recorded with each
+	// artifact and passed back to UnwrapKey.
+	KeyID() string
+	// WrapKey encrypts a data key with the current key, authenticating
+	// associatedData with it. associatedData names the artifact, so that
+	// its data key cannot be used for another one.
+	WrapKey(ctx context.Context, dataKey, associatedData []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key wrapped by the key keyID, which need not
+	// be the current one. It fails unless associatedData is what the key
+	// was wrapped with.
+	UnwrapKey(ctx context.Context, keyID string, wrapped, associatedData []byte) ([]byte, error)
+}
+
+// AESKeyProvider wraps data keys with AES-256-GCM under local 32 byte
+// keys. The first key wraps; the others only unwrap, for key rotation.
+type AESKeyProvider struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewAESKeyProvider returns a provider that wraps with key and also unwraps
+// keys wrapped with the previous keys
+func NewAESKeyProvider(key []byte, previous ...[]byte) (*AESKeyProvider, error) {
+	p := &AESKeyProvider{keys: map[string]cipher.AEAD{}}
+	for i, k := range append([][]byte{key}, previous...) {
+		if len(k) != 32 {
+			return nil, fmt.Errorf("AES key %d is %d bytes, not 32", i+1, len(k))
+		}
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(k)
+		id := "aes:" + hex.EncodeToString(sum[:8])
+		if i == 0 {
+			p.current = id
+		}
+		p.keys[id] = aead
+	}
+	return p, nil
+}
+
+// KeyFileProvider returns an AESKeyProvider with the keys in local key
+// files, each holding 32 raw bytes or their base64 encoding. The first file
+// is the current key.
+func KeyFileProvider(path string, previous ...string) (*AESKeyProvider, error) {
+	var keys [][]byte
+	for _, file := range append([]string{path}, previous...) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		key := data
+		if len(data) != 32 {
+			if key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(data))); err != nil {
+				return nil, fmt.Errorf("key file %s is neither 32 bytes nor base64", file)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return NewAESKeyProvider(keys[0], keys[1:]...)
+}
+
+// EnvKeyProvider returns an AESKeyProvider with the base64 keys in an
+// environment variable, DefaultArtifactKeyEnv if name is empty. The
+// variable holds comma separated keys, the current one first.
+func EnvKeyProvider(name string) (*AESKeyProvider, error) {
+	if name == "" {
+		name = DefaultArtifactKeyEnv
+	}
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("%s is not set", name)
+	}
+	var keys [][]byte
+	for _, encoded := range strings.Split(value, ",") {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("%s holds an invalid base64 key", name)
+		}
+		keys = append(keys, key)
+	}
+	return NewAESKeyProvider(keys[0], keys[1:]...)
+}
+
+// KeyID implements KeyProvider. It is derived from the key, so the same
+// key always has the same ID.
+func (p *AESKeyProvider) KeyID() string {
+	return p.current
+}
+
+// WrapKey implements KeyProvider
+func (p *AESKeyProvider) WrapKey(_ context.Context, dataKey, associatedData []byte) ([]byte, error) {
+	aead := p.keys[p.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, aesKeyAAD(p.current, associatedData)), nil
+}
+
+// UnwrapKey implements KeyProvider
+func (p *AESKeyProvider) UnwrapKey(_ context.Context, keyID string, wrapped, associatedData []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("no key %s", keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("wrapped key is too short")
+	}
+	dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], aesKeyAAD(keyID, associatedData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %s: %w", keyID, err)
+	}
+	return dataKey, nil
+}
+
+// aesKeyAAD is the additional data of a wrapped key, which binds it to the
+// wrapping key's ID as well as to associatedData. Key IDs hold no newline.
+func aesKeyAAD(keyID string, associatedData []byte) []byte {
+	return append([]byte(keyID+"\n"), associatedData...)
+}
+
+// KMS is a key management service that encrypts and decrypts small
+// payloads with keys it holds, such as AWS KMS, Google Cloud KMS or Vault's
+// transit engine. associatedData must be authenticated with the payload,
+// for example as AWS KMS's encryption context or Google Cloud KMS's
+// additional authenticated data, so that Decrypt fails for other data.
+type KMS interface {
+	Encrypt(ctx context.Context, keyID string, plaintext, associatedData []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext, associatedData []byte) ([]byte, error)
+}
+
+type kmsKeyProvider struct {
+	kms   KMS
+	keyID string
+}
+
+// NewKMSKeyProvider returns a provider that wraps data keys with the KMS
+// key keyID. To rotate, make a provider with the new key ID; data keys are
+// unwrapped with the key that wrapped them.
+func NewKMSKeyProvider(kms KMS, keyID string) KeyProvider {
+	return &kmsKeyProvider{kms: kms, keyID: keyID}
+}
+
+func (p *kmsKeyProvider) KeyID() string {
+	return p.keyID
+}
+
+func (p *kmsKeyProvider) WrapKey(ctx context.Context, dataKey, associatedData []byte) ([]byte, error) {
+	return p.kms.Encrypt(ctx, p.keyID, dataKey, associatedData)
+}
+
+func (p *kmsKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped, associatedData []byte) ([]byte, error) {
+	return p.kms.Decrypt(ctx, keyID, wrapped, associatedData)
+}
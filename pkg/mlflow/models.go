@@ -7,7 +7,7 @@ type Experiment struct {
 	ExperimentID     string          `json:"experiment_id"`
 	Name             string          `json:"name"`
 	ArtifactLocation string          `json:"artifact_location"`
-	LifecycleStage   string          `json:"lifecycle_stage"`
+	LifecycleStage   LifecycleStage  `json:"lifecycle_stage"`
 	LastUpdateTime   int64           `json:"last_update_time"`
 	CreationTime     int64           `json:"creation_time"`
 	Tags             []ExperimentTag `json:"tags"`
@@ -41,15 +41,15 @@ type Run struct {
 
 // RunInfo contains metadata about a run
 type RunInfo struct {
-	RunID          string `json:"run_id"`
-	RunName        string `json:"run_name,omitempty"`
-	ExperimentID   string `json:"experiment_id"`
-	UserID         string `json:"user_id,omitempty"`
-	Status         string `json:"status"`
-	StartTime      int64  `json:"start_time"`
-	EndTime        int64  `json:"end_time,omitempty"`
-	ArtifactURI    string `json:"artifact_uri"`
-	LifecycleStage string `json:"lifecycle_stage"`
+	RunID          string         `json:"run_id"`
+	RunName        string         `json:"run_name,omitempty"`
+	ExperimentID   string         `json:"experiment_id"`
+	UserID         string         `json:"user_id,omitempty"`
+	Status         RunStatus      `json:"status"`
+	StartTime      int64          `json:"start_time"`
+	EndTime        int64          `json:"end_time,omitempty"`
+	ArtifactURI    string         `json:"artifact_uri"`
+	LifecycleStage LifecycleStage `json:"lifecycle_stage"`
 }
 
 // RunData contains metrics, parameters, and tags for a run
@@ -74,7 +74,7 @@ type RunOutputs struct {
 type ModelOutput struct {
 	ModelName    string          `json:"model_name"`
 	ModelVersion string          `json:"model_version,omitempty"`
-	ModelStage   string          `json:"model_stage,omitempty"`
+	ModelStage   ModelStage      `json:"model_stage,omitempty"`
 	Alias        string          `json:"alias,omitempty"`
 	Tags         []ModelInputTag `json:"tags,omitempty"`
 }
@@ -134,6 +134,9 @@ type CreateRunRequest struct {
 	RunName      string   `json:"run_name,omitempty"`
 	StartTime    int64    `json:"start_time,omitempty"`
 	Tags         []RunTag `json:"tags,omitempty"`
+	// Source tags the run with the program's source, user and host, and logs
+	// its Go environment as an artifact (see DetectSource)
+	Source bool `json:"-"`
 }
 
 // CreateRunResponse represents the response from creating a run
@@ -168,7 +171,7 @@ type SetTagRequest struct {
 type SearchRunsRequest struct {
 	ExperimentIDs []string `json:"experiment_ids,omitempty"`
 	Filter        string   `json:"filter,omitempty"`
-	RunViewType   string   `json:"run_view_type,omitempty"`
+	RunViewType   ViewType `json:"run_view_type,omitempty"`
 	MaxResults    int      `json:"max_results,omitempty"`
 	OrderBy       []string `json:"order_by,omitempty"`
 	PageToken     string   `json:"page_token,omitempty"`
@@ -182,9 +185,9 @@ type SearchRunsResponse struct {
 
 // UpdateRunRequest represents a request to update a run
 type UpdateRunRequest struct {
-	RunID   string `json:"run_id"`
-	Status  string `json:"status,omitempty"`
-	EndTime int64  `json:"end_time,omitempty"`
+	RunID   string    `json:"run_id"`
+	Status  RunStatus `json:"status,omitempty"`
+	EndTime int64     `json:"end_time,omitempty"`
 }
 
 // UpdateRunResponse represents the response from updating a run
@@ -194,19 +197,19 @@ type UpdateRunResponse struct {
 
 // ModelVersion represents a model version in MLflow
 type ModelVersion struct {
-	Name                 string            `json:"name"`
-	Version              string            `json:"version"`
-	CreationTimestamp    int64             `json:"creation_timestamp"`
-	LastUpdatedTimestamp int64             `json:"last_updated_timestamp"`
-	UserID               string            `json:"user_id,omitempty"`
-	CurrentStage         string            `json:"current_stage"`
-	Description          string            `json:"description,omitempty"`
-	Source               string            `json:"source"`
-	RunID                string            `json:"run_id,omitempty"`
-	Status               string            `json:"status"`
-	StatusMessage        string            `json:"status_message,omitempty"`
-	Tags                 []ModelVersionTag `json:"tags,omitempty"`
-	Aliases              []string          `json:"aliases,omitempty"`
+	Name                 string             `json:"name"`
+	Version              string             `json:"version"`
+	CreationTimestamp    int64              `json:"creation_timestamp"`
+	LastUpdatedTimestamp int64              `json:"last_updated_timestamp"`
+	UserID               string             `json:"user_id,omitempty"`
+	CurrentStage         ModelStage         `json:"current_stage"`
+	Description          string             `json:"description,omitempty"`
+	Source               string             `json:"source"`
+	RunID                string             `json:"run_id,omitempty"`
+	Status               ModelVersionStatus `json:"status"`
+	StatusMessage        string             `json:"status_message,omitempty"`
+	Tags                 []ModelVersionTag  `json:"tags,omitempty"`
+	Aliases              []string           `json:"aliases,omitempty"`
 }
 
 // ModelVersionTag represents a tag on a model version
@@ -311,6 +314,13 @@ type ErrorResponse struct {
 	Message   string `json:"message"`
 }
 
+// MLflow error codes the client handles
+const (
+	ErrorCodeResourceAlreadyExists = "RESOURCE_ALREADY_EXISTS"
+	ErrorCodeResourceDoesNotExist  = "RESOURCE_DOES_NOT_EXIST"
+	ErrorCodeNotImplemented        = "NOT_IMPLEMENTED"
+)
+
 // APIError represents an error from the MLflow API
 type APIError struct {
 	StatusCode   int
@@ -364,9 +374,15 @@ func IsAPIError(err error) (*APIError, bool) {
 	return apiErr, ok
 }
 
+// hasErrorCode reports whether err is an APIError with the given error code
+func hasErrorCode(err error, code string) bool {
+	apiErr, ok := IsAPIError(err)
+	return ok && apiErr.GetErrorCode() == code
+}
+
 // SearchExperimentsRequest represents a request to search experiments
 type SearchExperimentsRequest struct {
-	ViewType   string   `json:"view_type,omitempty"` // ACTIVE_ONLY, DELETED_ONLY, or ALL
+	ViewType   ViewType `json:"view_type,omitempty"`
 	MaxResults int      `json:"max_results,omitempty"`
 	PageToken  string   `json:"page_token,omitempty"`
 	Filter     string   `json:"filter,omitempty"`
@@ -439,7 +455,7 @@ type LogInputsRequest struct {
 type ModelInput struct {
 	ModelName    string          `json:"model_name"`
 	ModelVersion string          `json:"model_version,omitempty"`
-	ModelStage   string          `json:"model_stage,omitempty"`
+	ModelStage   ModelStage      `json:"model_stage,omitempty"`
 	Alias        string          `json:"alias,omitempty"`
 	Tags         []ModelInputTag `json:"tags,omitempty"`
 }
@@ -463,8 +479,8 @@ type RenameRegisteredModelResponse struct {
 
 // GetLatestModelVersionsRequest represents a request to get latest model versions
 type GetLatestModelVersionsRequest struct {
-	Name   string   `json:"name"`
-	Stages []string `json:"stages,omitempty"`
+	Name   string       `json:"name"`
+	Stages []ModelStage `json:"stages,omitempty"`
 }
 
 // GetLatestModelVersionsResponse represents the response from getting latest model versions
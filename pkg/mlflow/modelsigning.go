@@ -0,0 +1,361 @@
+package mlflow
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Tags SignModelVersion sets on a model version
+const (
+	// TagModelSignature is the base64 ed25519 signature of the model
+	// version's manifest
+	TagModelSignature = "mlflow-go.signature"
+	// TagModelSigner is the identity of the signer, which VerifyModelVersion
+	// looks up among its trusted keys
+	TagModelSigner = "mlflow-go.signature.signer"
+)
+
+// modelManifestContext prefixes the signed manifest so that its signature
+// cannot be taken for a signature of anything else
+const modelManifestContext = "mlflow-go model version manifest v1\n"
+
+// ModelSigner signs model version manifests. The signature must be an
+// ed25519 signature, so that VerifyModelVersion can check it with the
+// signer's public key; the private key may be held elsewhere, such as in a
+// KMS or an HSM.
+type ModelSigner interface {
+	// Identity names the signer, such as "ci@example.com". It is recorded
+	// with the signature.
+	Identity() string
+	// Sign signs message
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// Ed25519Signer signs with a local ed25519 private key
+type Ed25519Signer struct {
+	identity string
+	key      ed25519.PrivateKey
+}
+
+// NewEd25519Signer returns a signer with the given identity and key
+func NewEd25519Signer(identity string, key ed25519.PrivateKey) (*Ed25519Signer, error) {
+	if identity == "" {
+		return nil, errors.New("signer identity is empty")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("ed25519 private key is %d bytes, not %d", len(key), ed25519.PrivateKeySize)
+	}
+	return &Ed25519Signer{identity: identity, key: key}, nil
+}
+
+// Identity implements ModelSigner
+func (s *Ed25519Signer) Identity() string {
+	return s.identity
+}
+
+// Sign implements ModelSigner
+func (s *Ed25519Signer) Sign(_ context.Context, message []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message), nil
+}
+
+// PublicKey returns the key to trust for the signer's signatures
+func (s *Ed25519Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// ModelVersionManifest lists the files of a model version's artifacts with
+// their digests. It is what SignModelVersion signs.
+type ModelVersionManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Signer  string `json:"signer"`
+	// Files are in path order
+	Files []ModelVersionManifestFile `json:"files"`
+}
+
+// ModelVersionManifestFile is a file of a ModelVersionManifest
+type ModelVersionManifestFile struct {
+	// Path is the slash separated path relative to the model version's
+	// artifact root
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// LocalPath is where VerifyModelVersion downloaded the file. It is not
+	// part of what is signed.
+	LocalPath string `json:"-"`
+}
+
+// message is what is signed for the manifest
+func (m *ModelVersionManifest) message() ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(modelManifestContext), data...), nil
+}
+
+// SignModelVersion hashes every file of the model version's artifacts and
+// signs the resulting manifest, which also names the model version and the
+// signer. The signature and the signer's identity are recorded as the
+// TagModelSignature and TagModelSigner tags of the model version, replacing
+// any earlier signature.
+func (c *Client) SignModelVersion(name, version string, signer ModelSigner) (*ModelVersionManifest, error) {
+	ctx := context.Background()
+	manifest, err := c.modelVersionManifest(ctx, name, version, signer.Identity(), "")
+	if err != nil {
+		return nil, err
+	}
+	message, err := manifest.message()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign model version %s %s: %w", name, version, err)
+	}
+	// The signer goes first, so that a failure between the two tags leaves
+	// a signature that does not verify rather than one credited to the
+	// previous signer
+	tags := []ModelVersionTag{
+		{Key: TagModelSigner, Value: signer.Identity()},
+		{Key: TagModelSignature, Value: base64.StdEncoding.EncodeToString(signature)},
+	}
+	for _, tag := range tags {
+		if err := c.setSignatureTag(name, version, tag); err != nil {
+			return nil, err
+		}
+	}
+	// Read the tags back, so that a signature that was not stored as
+	// written fails here rather than at verification
+	resp, err := c.GetModelVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+	stored := map[string]string{}
+	for _, tag := range resp.ModelVersion.Tags {
+		stored[tag.Key] = tag.Value
+	}
+	for _, tag := range tags {
+		if stored[tag.Key] != tag.Value {
+			return nil, fmt.Errorf("tag %s of model version %s %s was not stored as written", tag.Key, name, version)
+		}
+	}
+	return manifest, nil
+}
+
+// setSignatureTag is SetModelVersionTag without the scrubber, which would
+// take a base64 signature for a secret. Signatures and signer identities
+// are public.
+func (c *Client) setSignatureTag(name, version string, tag ModelVersionTag) error {
+	req := SetModelVersionTagRequest{Name: name, Version: version, Key: tag.Key, Value: tag.Value}
+	_, err := c.doMutation(mutation{operation: "SetModelVersionTag", target: AuditTarget{Entity: AuditEntityModelVersion, Name: name, Version: version}}, http.MethodPost, endpointModelVersionsSetTag, req)
+	return err
+}
+
+// VerifyModelVersion downloads every file of the model version's artifacts
+// to localDir, which must be empty or not exist, and checks them against
+// the version's signature, which must be by a signer in trustedKeys, keyed
+// by identity. It fails unless the version is signed by a trusted signer
+// and its files are exactly the ones signed, and returns the verified
+// manifest with the local path of each file. The files hashed are the files
+// downloaded, so load the model from them. If verification fails, what was
+// downloaded is removed again.
+func (c *Client) VerifyModelVersion(name, version, localDir string, trustedKeys map[string]ed25519.PublicKey) (*ModelVersionManifest, error) {
+	entries, err := os.ReadDir(localDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read directory %s: %w", localDir, err)
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("directory %s is not empty", localDir)
+	}
+	manifest, err := c.verifyModelVersion(name, version, localDir, trustedKeys)
+	if err != nil {
+		entries, _ := os.ReadDir(localDir)
+		for _, entry := range entries {
+			os.RemoveAll(filepath.Join(localDir, entry.Name()))
+		}
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (c *Client) verifyModelVersion(name, version, localDir string, trustedKeys map[string]ed25519.PublicKey) (*ModelVersionManifest, error) {
+	resp, err := c.GetModelVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+	var signer, encoded string
+	for _, tag := range resp.ModelVersion.Tags {
+		switch tag.Key {
+		case TagModelSigner:
+			signer = tag.Value
+		case TagModelSignature:
+			encoded = tag.Value
+		}
+	}
+	if signer == "" || encoded == "" {
+		return nil, fmt.Errorf("model version %s %s is not signed", name, version)
+	}
+	key, ok := trustedKeys[signer]
+	if !ok || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("signer %q of model version %s %s is not trusted", signer, name, version)
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("model version %s %s has an invalid signature", name, version)
+	}
+
+	manifest, err := c.modelVersionManifest(context.Background(), name, version, signer, localDir)
+	if err != nil {
+		return nil, err
+	}
+	message, err := manifest.message()
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(key, message, signature) {
+		return nil, fmt.Errorf("artifacts of model version %s %s do not match its signature by %s", name, version, signer)
+	}
+	return manifest, nil
+}
+
+// modelVersionManifest hashes the files of a model version's artifacts,
+// downloading them to localDir unless it is ""
+func (c *Client) modelVersionManifest(ctx context.Context, name, version, signer, localDir string) (*ModelVersionManifest, error) {
+	repo, err := c.modelVersionRepository(name, version)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ModelVersionManifest{Name: name, Version: version, Signer: signer, Files: []ModelVersionManifestFile{}}
+	err = walkRepository(ctx, repo, "", func(file FileInfo) error {
+		entry := ModelVersionManifestFile{Path: file.Path, Size: file.FileSize}
+		var err error
+		if localDir == "" {
+			entry.SHA256, err = hashArtifact(ctx, repo, file)
+		} else {
+			entry.LocalPath, entry.SHA256, err = downloadHashed(ctx, repo, file, localDir)
+		}
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifacts of model version %s %s: %w", name, version, err)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("model version %s %s has no artifacts", name, version)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+	return manifest, nil
+}
+
+// hashArtifact returns the hex SHA-256 of a file, which must have the size
+// it is listed with
+func hashArtifact(ctx context.Context, repo ArtifactRepository, file FileInfo) (string, error) {
+	body, err := repo.Download(ctx, file.Path)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to download artifact %s: %w", file.Path, err)
+	}
+	if n != file.FileSize {
+		return "", fmt.Errorf("downloaded %d bytes of artifact %s, expected %d", n, file.Path, file.FileSize)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// downloadHashed downloads a file under localDir and returns its local path
+// and hex SHA-256, which is of exactly what was written
+func downloadHashed(ctx context.Context, repo ArtifactRepository, file FileInfo, localDir string) (string, string, error) {
+	clean, err := cleanArtifactPath(file.Path)
+	if err != nil {
+		return "", "", err
+	}
+	local := filepath.Join(localDir, filepath.FromSlash(clean))
+	h := sha256.New()
+	if err := downloadFileTee(ctx, repo, clean, local, file.FileSize, h); err != nil {
+		return "", "", err
+	}
+	return local, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// modelVersionRepository returns the repository of a model version's
+// artifacts, resolving pointer files and encrypted artifacts so that their
+// content is what is signed. A runs:/ source is resolved to the run's
+// artifact URI. Pointer files and encrypted artifacts are only resolved
+// for artifacts of the version's run.
+func (c *Client) modelVersionRepository(name, version string) (*resolvingRepository, error) {
+	resp, err := c.GetModelVersionDownloadURI(GetModelVersionDownloadURIRequest{Name: name, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	uri := resp.ArtifactURI
+	var runID, prefix string
+	if rest, ok := strings.CutPrefix(uri, "runs:/"); ok {
+		runID, prefix, _ = strings.Cut(rest, "/")
+		prefix = strings.Trim(prefix, "/")
+		run, err := c.GetRun(runID)
+		if err != nil {
+			return nil, err
+		}
+		uri = strings.TrimSuffix(run.Run.Info.ArtifactURI, "/")
+		if prefix != "" {
+			uri += "/" + prefix
+		}
+	} else if uri != "" {
+		runID, prefix, err = c.modelVersionRun(name, version, uri)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if uri == "" {
+		return nil, fmt.Errorf("model version %s %s has no artifact URI", name, version)
+	}
+	repo, err := c.ArtifactRepository(uri)
+	if err != nil {
+		return nil, err
+	}
+	return newResolvingRepository(c, runID, prefix, repo), nil
+}
+
+// modelVersionRun returns the run of a model version whose artifacts are at
+// uri and their path in the run's artifacts. The run ID is "" if the version
+// has no run or uri is not under the run's artifact URI.
+func (c *Client) modelVersionRun(name, version, uri string) (string, string, error) {
+	mv, err := c.GetModelVersion(name, version)
+	if err != nil || mv.ModelVersion.RunID == "" {
+		return "", "", err
+	}
+	run, err := c.GetRun(mv.ModelVersion.RunID)
+	if err != nil {
+		return "", "", err
+	}
+	root := strings.TrimSuffix(run.Run.Info.ArtifactURI, "/")
+	uri = strings.TrimSuffix(uri, "/")
+	if uri == root {
+		return mv.ModelVersion.RunID, "", nil
+	}
+	if prefix, ok := strings.CutPrefix(uri, root+"/"); ok {
+		return mv.ModelVersion.RunID, prefix, nil
+	}
+	return "", "", nil
+}
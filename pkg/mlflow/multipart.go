@@ -0,0 +1,582 @@
+package mlflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// endpointMultipartUpload is the artifact proxy's multipart upload service.
+// Its create, complete and abort endpoints take the artifact directory in the
+// URL and the file name in the body.
+const endpointMultipartUpload = "/api/2.0/mlflow-artifacts/mpu"
+
+// Multipart upload defaults and limits
+const (
+	DefaultMultipartPartSize    = 10 << 20
+	defaultMultipartConcurrency = 4
+	defaultMultipartRetries     = 3
+	defaultMultipartBackoff     = time.Second
+	// maxMultipartParts is the most parts S3 accepts in one upload
+	maxMultipartParts = 10000
+)
+
+// MultipartUploadCredential is where to upload one part of a multipart
+// upload, typically a presigned URL of the artifact store
+type MultipartUploadCredential struct {
+	URL        string            `json:"url"`
+	PartNumber int64             `json:"part_number"`
+	Headers    map[string]string `json:"headers,omitempty"`
+}
+
+// MultipartUploadPart is an uploaded part, identified by the ETag the
+// artifact store returned for it
+type MultipartUploadPart struct {
+	PartNumber int64  `json:"part_number"`
+	ETag       string `json:"etag"`
+	URL        string `json:"url,omitempty"`
+}
+
+// CreateMultipartUploadResponse is the started upload and where to upload
+// each of its parts
+type CreateMultipartUploadResponse struct {
+	UploadID    string                      `json:"upload_id"`
+	Credentials []MultipartUploadCredential `json:"credentials"`
+}
+
+type createMultipartUploadRequest struct {
+	Path     string `json:"path"`
+	NumParts int64  `json:"num_parts"`
+}
+
+type completeMultipartUploadRequest struct {
+	Path     string                `json:"path"`
+	UploadID string                `json:"upload_id"`
+	Parts    []MultipartUploadPart `json:"parts"`
+}
+
+type abortMultipartUploadRequest struct {
+	Path     string `json:"path"`
+	UploadID string `json:"upload_id"`
+}
+
+// MultipartUploadOptions configures LogArtifactMultipart
+type MultipartUploadOptions struct {
+	// PartSize is the size of every part but the last, DefaultMultipartPartSize
+	// if zero. It is raised for files that would need more than 10000 parts.
+	PartSize int64
+	// Concurrency is the number of parts uploaded at once, 4 if zero
+	Concurrency int
+	// MaxRetries is how often a failed part is retried, 3 if zero. Negative
+	// disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry of a part, doubling
+	// with each retry, 1s if zero
+	RetryBackoff time.Duration
+	// Progress is called after each part is uploaded. Calls are not
+	// concurrent.
+	Progress func(MultipartProgress)
+	// StateFile is a local file recording the upload's progress. If it
+	// describes an unfinished upload of the same file, the upload resumes
+	// with the parts it has not finished. It is removed once the upload
+	// completes or is aborted.
+	StateFile string
+}
+
+// MultipartProgress reports the progress of a multipart upload
+type MultipartProgress struct {
+	// PartNumber is the part just uploaded
+	PartNumber int64
+	PartsDone  int
+	Parts      int
+	BytesDone  int64
+	Bytes      int64
+}
+
+// multipartState is the content of a state file
+type multipartState struct {
+	RunID        string                      `json:"run_id"`
+	ArtifactPath string                      `json:"artifact_path"`
+	Size         int64                       `json:"size"`
+	ModTime      time.Time                   `json:"mod_time"`
+	PartSize     int64                       `json:"part_size"`
+	UploadID     string                      `json:"upload_id"`
+	Credentials  []MultipartUploadCredential `json:"credentials"`
+	Parts        []MultipartUploadPart       `json:"parts"`
+}
+
+// CreateMultipartUpload starts a multipart upload of numParts parts to
+// artifactPath under the run's artifact root. It fails with
+// ErrorCodeNotImplemented if the server's artifact store does not support
+// multipart uploads.
+func (c *Client) CreateMultipartUpload(runID, artifactPath string, numParts int64) (*CreateMultipartUploadResponse, error) {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := c.runProxyRoot(runID)
+	if err != nil {
+		return nil, err
+	}
+	return c.createMultipartUpload(context.Background(), root, clean, numParts)
+}
+
+// CompleteMultipartUpload completes a multipart upload from its uploaded
+// parts
+func (c *Client) CompleteMultipartUpload(runID, artifactPath, uploadID string, parts []MultipartUploadPart) error {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return err
+	}
+	root, err := c.runProxyRoot(runID)
+	if err != nil {
+		return err
+	}
+	return c.completeMultipartUpload(context.Background(), root, clean, uploadID, parts)
+}
+
+// AbortMultipartUpload aborts a multipart upload, discarding its parts
+func (c *Client) AbortMultipartUpload(runID, artifactPath, uploadID string) error {
+	clean, err := cleanArtifactPath(artifactPath)
+	if err != nil {
+		return err
+	}
+	root, err := c.runProxyRoot(runID)
+	if err != nil {
+		return err
+	}
+	return c.abortMultipartUpload(root, clean, uploadID)
+}
+
+// runProxyRoot returns the run's artifact root on the artifact proxy, which
+// multipart uploads go through
+func (c *Client) runProxyRoot(runID string) (artifactRoot, error) {
+	repo, err := c.RunArtifactRepository(runID)
+	if err != nil {
+		return artifactRoot{}, err
+	}
+	proxy, ok := repo.(*ProxyArtifactRepository)
+	if !ok {
+		return artifactRoot{}, fmt.Errorf("multipart uploads need an artifact URI served by the artifact proxy")
+	}
+	return proxy.root, nil
+}
+
+func (c *Client) createMultipartUpload(ctx context.Context, root artifactRoot, artifactPath string, numParts int64) (*CreateMultipartUploadResponse, error) {
+	dir, name := splitArtifactPath(artifactPath)
+	respBody, err := c.doMultipartRequest(ctx, root, root.url(endpointMultipartUpload+"/create", dir), createMultipartUploadRequest{Path: name, NumParts: numParts})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := unmarshalResponse[CreateMultipartUploadResponse](respBody)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(resp.Credentials)) != numParts {
+		return nil, fmt.Errorf("server returned %d part credentials for %d parts", len(resp.Credentials), numParts)
+	}
+	return resp, nil
+}
+
+func (c *Client) completeMultipartUpload(ctx context.Context, root artifactRoot, artifactPath, uploadID string, parts []MultipartUploadPart) error {
+	dir, name := splitArtifactPath(artifactPath)
+	_, err := c.doMultipartRequest(ctx, root, root.url(endpointMultipartUpload+"/complete", dir), completeMultipartUploadRequest{Path: name, UploadID: uploadID, Parts: parts})
+	return err
+}
+
+// abortMultipartUpload is not cancellable, so that it can clean up after a
+// cancelled upload
+func (c *Client) abortMultipartUpload(root artifactRoot, artifactPath, uploadID string) error {
+	dir, name := splitArtifactPath(artifactPath)
+	_, err := c.doMultipartRequest(context.Background(), root, root.url(endpointMultipartUpload+"/abort", dir), abortMultipartUploadRequest{Path: name, UploadID: uploadID})
+	return err
+}
+
+// splitArtifactPath splits a clean artifact path into its directory, empty
+// for the root, and file name
+func splitArtifactPath(artifactPath string) (string, string) {
+	dir, name := path.Split(artifactPath)
+	return path.Clean("/" + dir)[1:], name
+}
+
+func (c *Client) doMultipartRequest(ctx context.Context, root artifactRoot, endpointURL string, body any) ([]byte, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	root.authorize(req, c.AuthToken)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+// LogArtifactMultipart uploads a large local file to the run's artifact
+// directory artifactPath, keeping the file's name, as a multipart upload. The
+// parts are uploaded concurrently and each is retried on network errors and
+// server errors. If the server's artifact store does not support multipart
+// uploads, or the run's artifacts are not stored through the artifact proxy,
+// the file is uploaded in one piece as with LogArtifact.
+//
+// Cancelling ctx aborts the upload. Otherwise, with a StateFile, a failed
+// upload is left open so that calling LogArtifactMultipart again with the
+// same file and state file, also from another process, resumes it.
+func (c *Client) LogArtifactMultipart(ctx context.Context, runID, localPath, artifactPath string, opts MultipartUploadOptions) error {
+	if _, err := cleanArtifactDir(artifactPath); err != nil {
+		return err
+	}
+	target, err := cleanArtifactPath(path.Join(artifactPath, filepath.Base(localPath)))
+	if err != nil {
+		return err
+	}
+	if opts.PartSize < 0 || opts.Concurrency < 0 || opts.RetryBackoff < 0 {
+		return fmt.Errorf("part size, concurrency and retry backoff must not be negative")
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("artifact %s is not a regular file", localPath)
+	}
+
+	m := mutation{operation: "LogArtifact", target: AuditTarget{Entity: AuditEntityRun, ID: runID}}
+	body := map[string]any{"run_id": runID, "path": target, "size": info.Size(), "multipart": true}
+	if err := c.enforcePolicy(m); err != nil {
+		c.audit(m, body, err)
+		return err
+	}
+	u := &multipartUpload{client: c, runID: runID, artifactPath: target, file: f, info: info, opts: opts}
+	err = u.run(ctx)
+	c.audit(m, body, err)
+	return err
+}
+
+// multipartUpload is one LogArtifactMultipart call
+type multipartUpload struct {
+	client       *Client
+	runID        string
+	artifactPath string
+	file         *os.File
+	info         os.FileInfo
+	opts         MultipartUploadOptions
+	root         artifactRoot
+
+	// mu guards state, the state file and progress calls
+	mu    sync.Mutex
+	state multipartState
+	done  int64
+}
+
+func (u *multipartUpload) run(ctx context.Context) error {
+	repo, err := u.client.RunArtifactRepository(u.runID)
+	if err != nil {
+		return err
+	}
+	proxy, ok := repo.(*ProxyArtifactRepository)
+	if !ok {
+		// Other backends upload large files themselves
+		return repo.Upload(ctx, u.artifactPath, io.NewSectionReader(u.file, 0, u.info.Size()), u.info.Size())
+	}
+	u.root = proxy.root
+
+	resumed := u.loadState()
+	if !resumed {
+		if err := u.create(ctx); err != nil {
+			if hasErrorCode(err, ErrorCodeNotImplemented) {
+				return proxy.Upload(ctx, u.artifactPath, io.NewSectionReader(u.file, 0, u.info.Size()), u.info.Size())
+			}
+			return err
+		}
+	}
+
+	err = u.uploadAndComplete(ctx)
+	if err != nil && resumed && ctx.Err() == nil && !isRetryablePartError(err) {
+		// The upload recorded in the state file may have expired or been
+		// aborted; start it over
+		_ = u.client.abortMultipartUpload(u.root, u.artifactPath, u.state.UploadID)
+		u.state = multipartState{}
+		u.removeState()
+		if err = u.create(ctx); err == nil {
+			err = u.uploadAndComplete(ctx)
+		}
+	}
+	switch {
+	case err == nil:
+		u.removeState()
+		return nil
+	case ctx.Err() != nil || u.opts.StateFile == "":
+		// Nothing can resume the upload, so don't leave its parts behind
+		if u.state.UploadID != "" {
+			_ = u.client.abortMultipartUpload(u.root, u.artifactPath, u.state.UploadID)
+		}
+		u.removeState()
+	}
+	return fmt.Errorf("failed to upload artifact %s: %w", u.artifactPath, err)
+}
+
+// loadState reports whether the state file describes an unfinished upload of
+// this file to the same artifact and, if so, continues from it
+func (u *multipartUpload) loadState() bool {
+	if u.opts.StateFile == "" {
+		return false
+	}
+	data, err := os.ReadFile(u.opts.StateFile)
+	if err != nil {
+		return false
+	}
+	var state multipartState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return false
+	}
+	if state.RunID != u.runID || state.ArtifactPath != u.artifactPath || state.Size != u.info.Size() ||
+		!state.ModTime.Equal(u.info.ModTime()) || state.UploadID == "" || state.PartSize <= 0 ||
+		int64(len(state.Credentials)) != partCount(state.Size, state.PartSize) {
+		return false
+	}
+	u.state = state
+	for _, part := range state.Parts {
+		u.done += u.partLength(part.PartNumber)
+	}
+	return true
+}
+
+// create starts a new upload and records it in the state file
+func (u *multipartUpload) create(ctx context.Context) error {
+	partSize := u.opts.PartSize
+	if partSize == 0 {
+		partSize = DefaultMultipartPartSize
+	}
+	size := u.info.Size()
+	if partCount(size, partSize) > maxMultipartParts {
+		partSize = (size + maxMultipartParts - 1) / maxMultipartParts
+	}
+	resp, err := u.client.createMultipartUpload(ctx, u.root, u.artifactPath, partCount(size, partSize))
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.state = multipartState{
+		RunID:        u.runID,
+		ArtifactPath: u.artifactPath,
+		Size:         size,
+		ModTime:      u.info.ModTime(),
+		PartSize:     partSize,
+		UploadID:     resp.UploadID,
+		Credentials:  resp.Credentials,
+	}
+	u.done = 0
+	return u.saveState()
+}
+
+// partCount returns the number of parts of a file, at least one so that
+// empty files can be uploaded
+func partCount(size, partSize int64) int64 {
+	return max(1, (size+partSize-1)/partSize)
+}
+
+// partLength returns the size of a part, numbered from 1
+func (u *multipartUpload) partLength(partNumber int64) int64 {
+	offset := (partNumber - 1) * u.state.PartSize
+	return max(0, min(u.state.PartSize, u.state.Size-offset))
+}
+
+// uploadAndComplete uploads the parts that are not done yet and completes
+// the upload. The first part that fails after its retries stops the others.
+func (u *multipartUpload) uploadAndComplete(ctx context.Context) error {
+	uploaded := map[int64]bool{}
+	for _, part := range u.state.Parts {
+		uploaded[part.PartNumber] = true
+	}
+	var pending []MultipartUploadCredential
+	for _, cred := range u.state.Credentials {
+		if !uploaded[cred.PartNumber] {
+			pending = append(pending, cred)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	work := make(chan MultipartUploadCredential)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	concurrency := u.opts.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultMultipartConcurrency
+	}
+	for i := 0; i < min(concurrency, len(pending)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cred := range work {
+				if err := u.uploadPart(ctx, cred); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+send:
+	for _, cred := range pending {
+		select {
+		case work <- cred:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(work)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	parts := append([]MultipartUploadPart{}, u.state.Parts...)
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return u.client.completeMultipartUpload(ctx, u.root, u.artifactPath, u.state.UploadID, parts)
+}
+
+// uploadPart uploads a part, retrying network errors and server errors with
+// exponential backoff, and records it
+func (u *multipartUpload) uploadPart(ctx context.Context, cred MultipartUploadCredential) error {
+	retries := u.opts.MaxRetries
+	if retries == 0 {
+		retries = defaultMultipartRetries
+	}
+	backoff := u.opts.RetryBackoff
+	if backoff == 0 {
+		backoff = defaultMultipartBackoff
+	}
+	var etag string
+	var err error
+	for attempt := 0; ; attempt++ {
+		etag, err = u.putPart(ctx, cred)
+		if err == nil || attempt >= retries || !isRetryablePartError(err) || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(backoff << attempt):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("part %d: %w", cred.PartNumber, err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.state.Parts = append(u.state.Parts, MultipartUploadPart{PartNumber: cred.PartNumber, ETag: etag, URL: cred.URL})
+	u.done += u.partLength(cred.PartNumber)
+	if err := u.saveState(); err != nil {
+		return err
+	}
+	if u.opts.Progress != nil {
+		u.opts.Progress(MultipartProgress{
+			PartNumber: cred.PartNumber,
+			PartsDone:  len(u.state.Parts),
+			Parts:      len(u.state.Credentials),
+			BytesDone:  u.done,
+			Bytes:      u.state.Size,
+		})
+	}
+	return nil
+}
+
+// putPart uploads a part to its credential's URL and returns its ETag
+func (u *multipartUpload) putPart(ctx context.Context, cred MultipartUploadCredential) (string, error) {
+	length := u.partLength(cred.PartNumber)
+	content := io.NewSectionReader(u.file, (cred.PartNumber-1)*u.state.PartSize, length)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, cred.URL, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = length
+	for key, value := range cred.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := u.client.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", newAPIError(resp.StatusCode, respBody)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// isRetryablePartError reports whether a failed request may succeed when
+// retried: network errors, timeouts, throttling and server errors
+func isRetryablePartError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return !errors.Is(err, context.Canceled)
+	}
+	return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// saveState writes the state file, replacing it atomically. The caller holds
+// u.mu.
+func (u *multipartUpload) saveState() error {
+	if u.opts.StateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(u.state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload state: %w", err)
+	}
+	tmp := u.opts.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write upload state: %w", err)
+	}
+	if err := os.Rename(tmp, u.opts.StateFile); err != nil {
+		return fmt.Errorf("failed to write upload state: %w", err)
+	}
+	return nil
+}
+
+func (u *multipartUpload) removeState() {
+	if u.opts.StateFile != "" {
+		_ = os.Remove(u.opts.StateFile)
+	}
+}
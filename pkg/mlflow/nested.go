@@ -0,0 +1,210 @@
+package mlflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/internal/filterquote"
+)
+
+// TagParentRunID is the system tag MLflow uses to nest a run under its parent
+const TagParentRunID = "mlflow.parentRunId"
+
+// childRunsPageSize is the page size used when searching for child runs
+const childRunsPageSize = 1000
+
+// ParentRunID returns the ID of the run's parent and whether it is nested
+func (r Run) ParentRunID() (string, bool) {
+	return r.Tag(TagParentRunID)
+}
+
+// StartChildRun creates a run nested under parentRunID, in the parent's
+// experiment. Setting another experiment in req is an error, because run
+// trees only search for children in their parent's experiment.
+func (c *Client) StartChildRun(parentRunID string, req CreateRunRequest) (*CreateRunResponse, error) {
+	if parentRunID == "" {
+		return nil, fmt.Errorf("parent run ID is required")
+	}
+	parent, err := c.GetRun(parentRunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent run %s: %w", parentRunID, err)
+	}
+	if req.ExperimentID == "" {
+		req.ExperimentID = parent.Run.Info.ExperimentID
+	} else if req.ExperimentID != parent.Run.Info.ExperimentID {
+		return nil, fmt.Errorf("child run must be in experiment %s of its parent, not %s", parent.Run.Info.ExperimentID, req.ExperimentID)
+	}
+	tags := make([]RunTag, 0, len(req.Tags)+1)
+	for _, tag := range req.Tags {
+		if tag.Key != TagParentRunID {
+			tags = append(tags, tag)
+		}
+	}
+	req.Tags = append(tags, RunTag{Key: TagParentRunID, Value: parentRunID})
+	return c.CreateRun(req)
+}
+
+// RunTree is a run and the runs nested under it
+type RunTree struct {
+	Run      Run
+	Children []*RunTree
+}
+
+// Status returns the aggregated status of the run and its descendants. The
+// tree is RUNNING if any run is running, otherwise SCHEDULED if any run is
+// scheduled. Once every run has ended it is FAILED if any run failed, KILLED
+// if any was killed, and FINISHED otherwise.
+func (t *RunTree) Status() RunStatus {
+	seen := map[RunStatus]bool{}
+	_ = t.Walk(func(node *RunTree, _ int) error {
+		seen[node.Run.Info.Status] = true
+		return nil
+	})
+	for _, status := range []RunStatus{RunStatusRunning, RunStatusScheduled, RunStatusFailed, RunStatusKilled} {
+		if seen[status] {
+			return status
+		}
+	}
+	return RunStatusFinished
+}
+
+// Walk calls fn for each run in the tree, parents before their children, with
+// the depth of the run below the root. It stops at the first error.
+func (t *RunTree) Walk(fn func(node *RunTree, depth int) error) error {
+	return t.walk(fn, 0)
+}
+
+func (t *RunTree) walk(fn func(node *RunTree, depth int) error, depth int) error {
+	if err := fn(t, depth); err != nil {
+		return err
+	}
+	for _, child := range t.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the subtree rooted at runID, or nil if it is not in the tree
+func (t *RunTree) Find(runID string) *RunTree {
+	if t.Run.Info.RunID == runID {
+		return t
+	}
+	for _, child := range t.Children {
+		if found := child.Find(runID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Len returns the number of runs in the tree
+func (t *RunTree) Len() int {
+	n := 1
+	for _, child := range t.Children {
+		n += child.Len()
+	}
+	return n
+}
+
+// GetRunTree gets a run and, recursively, the active runs nested under it.
+// Children are searched for in their parent's experiment and ordered by start
+// time.
+func (c *Client) GetRunTree(rootRunID string) (*RunTree, error) {
+	root, err := c.GetRun(rootRunID)
+	if err != nil {
+		return nil, err
+	}
+	tree := &RunTree{Run: root.Run}
+	visited := map[string]bool{rootRunID: true}
+	queue := []*RunTree{tree}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		children, err := c.childRuns(node.Run)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			// A run tagged as its own ancestor would otherwise loop forever
+			if visited[child.Info.RunID] {
+				continue
+			}
+			visited[child.Info.RunID] = true
+			subtree := &RunTree{Run: child}
+			node.Children = append(node.Children, subtree)
+			queue = append(queue, subtree)
+		}
+	}
+	return tree, nil
+}
+
+// childRuns returns the active runs nested directly under parent
+func (c *Client) childRuns(parent Run) ([]Run, error) {
+	quoted, err := filterquote.Quote(parent.Info.RunID)
+	if err != nil {
+		return nil, err
+	}
+	req := SearchRunsRequest{
+		ExperimentIDs: []string{parent.Info.ExperimentID},
+		Filter:        fmt.Sprintf("tags.`%s` = %s", TagParentRunID, quoted),
+		RunViewType:   ViewTypeActiveOnly,
+		MaxResults:    childRunsPageSize,
+	}
+	var runs []Run
+	for {
+		resp, err := c.SearchRuns(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search child runs of %s: %w", parent.Info.RunID, err)
+		}
+		runs = append(runs, resp.Runs...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].Info.StartTime != runs[j].Info.StartTime {
+			return runs[i].Info.StartTime < runs[j].Info.StartTime
+		}
+		return runs[i].Info.RunID < runs[j].Info.RunID
+	})
+	return runs, nil
+}
+
+// EndRunTree ends every run in the tree rooted at rootRunID that has not
+// already ended, with the given terminal status. Children are ended before
+// their parents, and runs that already ended keep their status. Every run is
+// attempted; the errors of the runs that could not be ended are joined.
+func (c *Client) EndRunTree(rootRunID string, status RunStatus) error {
+	if err := status.Validate(); err != nil {
+		return err
+	}
+	if !status.IsTerminal() {
+		return fmt.Errorf("cannot end runs with non-terminal status %s", status)
+	}
+	tree, err := c.GetRunTree(rootRunID)
+	if err != nil {
+		return err
+	}
+	// Collect the runs parents first, then end them in reverse
+	var open []string
+	_ = tree.Walk(func(node *RunTree, _ int) error {
+		if !node.Run.IsTerminal() {
+			open = append(open, node.Run.Info.RunID)
+		}
+		return nil
+	})
+	endTime := time.Now().UnixMilli()
+	var errs []error
+	for i := len(open) - 1; i >= 0; i-- {
+		_, err := c.UpdateRun(UpdateRunRequest{RunID: open[i], Status: status, EndTime: endTime})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to end run %s: %w", open[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
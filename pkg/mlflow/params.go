@@ -0,0 +1,460 @@
+package mlflow
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxParamsPerBatch is the number of params MLflow accepts in one LogBatch call
+const MaxParamsPerBatch = 100
+
+// paramTag is the struct tag read by FlattenParams and LoadParamsInto
+const paramTag = "mlflow"
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FlattenParams flattens a config struct into params with dot-separated keys,
+// sorted by key.
+//
+// Fields are named by their `mlflow:"name"` tag, or by the field name when
+// there is no tag. A tag of "-" skips the field and the "omitempty" option
+// skips zero values. Unexported fields and nil pointers are skipped, and the
+// fields of embedded structs without a tag are promoted. Nested structs and
+// maps add a key segment per level (map keys are sorted), and slice and array
+// elements add their index, e.g. "optimizer.lr" or "layers.0.units".
+//
+// Values are formatted deterministically: strings as is, numbers in their
+// shortest round-trip form, time.Duration with String and types implementing
+// encoding.TextMarshaler (such as time.Time) with MarshalText.
+func FlattenParams(cfg any) ([]Param, error) {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, fmt.Errorf("cannot flatten params from a nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot flatten params from %s: expected a struct", v.Type())
+	}
+
+	f := &flattener{seen: map[string]bool{}}
+	if err := f.flattenStruct("", v); err != nil {
+		return nil, err
+	}
+	sort.Slice(f.params, func(i, j int) bool { return f.params[i].Key < f.params[j].Key })
+	return f.params, nil
+}
+
+// LogParamsFromStruct flattens cfg with FlattenParams and logs the params to a
+// run, in batches of MaxParamsPerBatch
+func (c *Client) LogParamsFromStruct(runID string, cfg any) error {
+	params, err := FlattenParams(cfg)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(params); start += MaxParamsPerBatch {
+		end := min(start+MaxParamsPerBatch, len(params))
+		if err := c.LogBatch(runID, nil, params[start:end], nil); err != nil {
+			return fmt.Errorf("failed to log params %d-%d of %d: %w", start+1, end, len(params), err)
+		}
+	}
+	return nil
+}
+
+// LoadParamsInto rebuilds a config struct from the params of a run, using the
+// same key layout as FlattenParams. cfg must be a non-nil pointer to a struct.
+// Fields without a matching param keep their current value, and params that
+// match no field are ignored.
+func LoadParamsInto(run *Run, cfg any) error {
+	if run == nil {
+		return fmt.Errorf("cannot load params from a nil run")
+	}
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot load params into %T: expected a non-nil pointer to a struct", cfg)
+	}
+	l := &loader{params: run.ParamMap()}
+	return l.loadStruct("", v.Elem())
+}
+
+// paramField is a struct field as seen by FlattenParams and LoadParamsInto
+type paramField struct {
+	name      string
+	index     int
+	omitEmpty bool
+	// promoted is set for embedded structs whose fields are flattened into
+	// the parent
+	promoted bool
+}
+
+func paramFields(t reflect.Type) []paramField {
+	var fields []paramField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup(paramTag)
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		embeddedStruct := sf.Anonymous && indirectType(sf.Type).Kind() == reflect.Struct
+		if !sf.IsExported() && !embeddedStruct {
+			continue
+		}
+		field := paramField{
+			name:      name,
+			index:     i,
+			omitEmpty: options == "omitempty",
+			promoted:  embeddedStruct && (!hasTag || name == ""),
+		}
+		if field.name == "" {
+			field.name = sf.Name
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+type flattener struct {
+	params []Param
+	seen   map[string]bool
+}
+
+func (f *flattener) add(key, value string) error {
+	if f.seen[key] {
+		return fmt.Errorf("duplicate param key %q", key)
+	}
+	f.seen[key] = true
+	f.params = append(f.params, Param{Key: key, Value: value})
+	return nil
+}
+
+func (f *flattener) flattenStruct(prefix string, v reflect.Value) error {
+	for _, field := range paramFields(v.Type()) {
+		fv := v.Field(field.index)
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		if field.promoted {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := f.flattenStruct(prefix, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := f.flatten(joinKey(prefix, field.name), fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *flattener) flatten(key string, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if value, ok, err := formatParamValue(v); ok || err != nil {
+		if err != nil {
+			return fmt.Errorf("failed to format param %q: %w", key, err)
+		}
+		return f.add(key, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return f.flattenStruct(key, v)
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			mapKey, ok, err := formatParamValue(iter.Key())
+			if !ok || err != nil {
+				return fmt.Errorf("unsupported map key type %s for param %q", v.Type().Key(), key)
+			}
+			keys = append(keys, mapKey)
+			values[mapKey] = iter.Value()
+		}
+		sort.Strings(keys)
+		for _, mapKey := range keys {
+			if err := f.flatten(joinKey(key, mapKey), values[mapKey]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := f.flatten(joinKey(key, strconv.Itoa(i)), v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s for param %q", v.Type(), key)
+}
+
+// formatParamValue formats a scalar value. ok is false for values that are
+// not scalars, such as structs, maps and slices.
+func formatParamValue(v reflect.Value) (value string, ok bool, err error) {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), true, nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true, nil
+	}
+	return "", false, nil
+}
+
+// isScalarType reports whether values of t are logged as a single param
+func isScalarType(t reflect.Type) bool {
+	t = indirectType(t)
+	if t == durationType || t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+type loader struct {
+	params map[string]string
+}
+
+// hasPrefix reports whether any param is nested under key
+func (l *loader) hasPrefix(key string) bool {
+	prefix := key + "."
+	for k := range l.params {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *loader) loadStruct(prefix string, v reflect.Value) error {
+	for _, field := range paramFields(v.Type()) {
+		fv := v.Field(field.index)
+		if field.promoted {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := l.loadStruct(prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := l.load(joinKey(prefix, field.name), fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loader) load(key string, v reflect.Value) error {
+	value, isLeaf := l.params[key]
+	if !isLeaf && !l.hasPrefix(key) {
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return l.load(key, v.Elem())
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0 && isLeaf:
+		v.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if isLeaf {
+		ok, err := parseParamValue(value, v)
+		if err != nil {
+			return fmt.Errorf("failed to load param %q: %w", key, err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return l.loadStruct(key, v)
+	case reflect.Map:
+		return l.loadMap(key, v)
+	case reflect.Slice:
+		n := l.elements(key)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		return l.loadElements(key, v)
+	case reflect.Array:
+		return l.loadElements(key, v)
+	}
+	return fmt.Errorf("unsupported type %s for param %q", v.Type(), key)
+}
+
+// elements returns the length of the slice logged under key
+func (l *loader) elements(key string) int {
+	n := 0
+	prefix := key + "."
+	for k := range l.params {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		index, _, _ := strings.Cut(rest, ".")
+		if i, err := strconv.Atoi(index); err == nil && i >= n {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+func (l *loader) loadElements(key string, v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := l.load(joinKey(key, strconv.Itoa(i)), v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loader) loadMap(key string, v reflect.Value) error {
+	// Keys of scalar maps may contain dots themselves, so the whole remainder
+	// of the param key is the map key. Otherwise the map key is the next segment.
+	elemType := v.Type().Elem()
+	scalar := isScalarType(elemType)
+	prefix := key + "."
+	mapKeys := map[string]bool{}
+	for k := range l.params {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		if !scalar {
+			rest, _, _ = strings.Cut(rest, ".")
+		}
+		mapKeys[rest] = true
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(mapKeys)))
+	}
+	for mapKey := range mapKeys {
+		kv := reflect.New(v.Type().Key()).Elem()
+		if ok, err := parseParamValue(mapKey, kv); !ok || err != nil {
+			return fmt.Errorf("failed to load map key %q of param %q", mapKey, key)
+		}
+		ev := reflect.New(elemType).Elem()
+		if existing := v.MapIndex(kv); existing.IsValid() {
+			ev.Set(existing)
+		}
+		if err := l.load(joinKey(key, mapKey), ev); err != nil {
+			return err
+		}
+		v.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+// parseParamValue parses a scalar value into v. ok is false when v is not a
+// scalar.
+func parseParamValue(value string, v reflect.Value) (ok bool, err error) {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return true, err
+		}
+		v.SetInt(int64(d))
+		return true, nil
+	}
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) && v.CanAddr() {
+		return true, v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return true, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		v.SetFloat(f)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,421 @@
+package mlflow
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/internal/filterquote"
+)
+
+// PolicyOperation identifies a destructive operation that is checked against
+// the client's policy engine before it is sent to the server
+type PolicyOperation string
+
+const (
+	PolicyOpDeleteExperiment           PolicyOperation = "DeleteExperiment"
+	PolicyOpDeleteRun                  PolicyOperation = "DeleteRun"
+	PolicyOpDeleteRegisteredModel      PolicyOperation = "DeleteRegisteredModel"
+	PolicyOpDeleteModelVersion         PolicyOperation = "DeleteModelVersion"
+	PolicyOpDeleteRegisteredModelAlias PolicyOperation = "DeleteRegisteredModelAlias"
+	PolicyOpRenameRegisteredModel      PolicyOperation = "RenameRegisteredModel"
+	// PolicyOpArchiveModelVersion is a TransitionModelVersionStage call to the
+	// Archived stage, or one that archives the other versions in its stage
+	PolicyOpArchiveModelVersion PolicyOperation = "TransitionModelVersionStage"
+	// PolicyOpDeleteRuns is a bulk DeleteRuns call. Every active run of the
+	// experiment it could delete is checked against the rules for
+	// PolicyOpDeleteRun as well as those for PolicyOpDeleteRuns.
+	PolicyOpDeleteRuns PolicyOperation = "DeleteRuns"
+	// PolicyOpDeleteTraces is a DeleteTraces call, checked against the
+	// traces' experiment
+	PolicyOpDeleteTraces      PolicyOperation = "DeleteTraces"
+	PolicyOpDeleteLoggedModel PolicyOperation = "DeleteLoggedModel"
+)
+
+// stageArchived is the model registry stage that makes a version unavailable for serving
+const stageArchived = string(ModelStageArchived)
+
+// PolicyRule describes a set of resources that destructive operations must not
+// touch. Every criterion that is set must match for the rule to apply; a rule
+// without criteria applies to every resource of its operations.
+type PolicyRule struct {
+	// Name identifies the rule in policy errors
+	Name string
+	// Operations the rule applies to. Empty means all destructive operations.
+	Operations []PolicyOperation
+	// NamePattern is a path.Match glob matched against the experiment, run
+	// or registered model name, e.g. "prod-*"
+	NamePattern string
+	// Tags must all be present with the given value. A value of "*" matches
+	// any value.
+	Tags map[string]string
+	// Aliases matches resources holding any of these aliases, e.g. "champion".
+	// A value of "*" matches any alias.
+	Aliases []string
+	// Stages matches model versions in any of these stages. For registered
+	// models the stages of the latest versions are used.
+	Stages []string
+	// OverrideToken, when set, allows the operation for clients created with
+	// WithPolicyOverride using the same token. Rules without a token cannot
+	// be overridden.
+	OverrideToken string
+}
+
+// PolicyResource holds the facts about a resource that rules are matched against
+type PolicyResource struct {
+	Entity  AuditEntity
+	ID      string
+	Name    string
+	Version string
+	Tags    map[string]string
+	Aliases []string
+	Stages  []string
+}
+
+// PolicyError is returned when a destructive operation is blocked by a policy rule
+type PolicyError struct {
+	Operation PolicyOperation
+	Rule      string
+	Resource  PolicyResource
+	Reason    string
+}
+
+// Error implements the error interface
+func (e *PolicyError) Error() string {
+	target := e.Resource.Name
+	if target == "" {
+		target = e.Resource.ID
+	}
+	if e.Resource.Version != "" {
+		target += " version " + e.Resource.Version
+	}
+	return fmt.Sprintf("policy violation: %s on %s %q blocked by rule %q: %s",
+		e.Operation, e.Resource.Entity, target, e.Rule, e.Reason)
+}
+
+// IsPolicyError checks if an error is a PolicyError and returns it
+func IsPolicyError(err error) (*PolicyError, bool) {
+	var policyErr *PolicyError
+	if errors.As(err, &policyErr) {
+		return policyErr, true
+	}
+	return nil, false
+}
+
+// PolicyEngine evaluates destructive operations against a set of rules
+type PolicyEngine struct {
+	rules []PolicyRule
+}
+
+// NewPolicyEngine creates a policy engine, validating the rules
+func NewPolicyEngine(rules ...PolicyRule) (*PolicyEngine, error) {
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("policy rule %d has no name", i)
+		}
+		if rule.NamePattern != "" {
+			if _, err := path.Match(rule.NamePattern, ""); err != nil {
+				return nil, fmt.Errorf("policy rule %q has an invalid name pattern: %w", rule.Name, err)
+			}
+		}
+	}
+	return &PolicyEngine{rules: append([]PolicyRule(nil), rules...)}, nil
+}
+
+// Rules returns a copy of the engine's rules
+func (e *PolicyEngine) Rules() []PolicyRule {
+	return append([]PolicyRule(nil), e.rules...)
+}
+
+// appliesTo reports whether any rule covers the operation, so that resource
+// lookups can be skipped when nothing needs to be checked
+func (e *PolicyEngine) appliesTo(op PolicyOperation) bool {
+	for _, rule := range e.rules {
+		if rule.coversOperation(op) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks an operation on a resource against every rule. It returns a
+// *PolicyError for the first matching rule that is not overridden by overrideToken.
+func (e *PolicyEngine) Evaluate(op PolicyOperation, resource PolicyResource, overrideToken string) error {
+	for _, rule := range e.rules {
+		if !rule.coversOperation(op) || !rule.matches(resource) {
+			continue
+		}
+		if rule.OverrideToken == "" {
+			return &PolicyError{Operation: op, Rule: rule.Name, Resource: resource, Reason: "resource is protected"}
+		}
+		if overrideToken == "" {
+			return &PolicyError{Operation: op, Rule: rule.Name, Resource: resource, Reason: "resource is protected and requires an override token"}
+		}
+		if subtle.ConstantTimeCompare([]byte(rule.OverrideToken), []byte(overrideToken)) != 1 {
+			return &PolicyError{Operation: op, Rule: rule.Name, Resource: resource, Reason: "override token does not match"}
+		}
+	}
+	return nil
+}
+
+func (r PolicyRule) coversOperation(op PolicyOperation) bool {
+	if len(r.Operations) == 0 {
+		return true
+	}
+	for _, candidate := range r.Operations {
+		if candidate == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (r PolicyRule) matches(resource PolicyResource) bool {
+	if r.NamePattern != "" {
+		matched, err := path.Match(r.NamePattern, resource.Name)
+		if err != nil || !matched {
+			return false
+		}
+	}
+	for key, want := range r.Tags {
+		got, ok := resource.Tags[key]
+		if !ok || (want != "*" && got != want) {
+			return false
+		}
+	}
+	if len(r.Aliases) > 0 && !matchesAny(r.Aliases, resource.Aliases, false) {
+		return false
+	}
+	if len(r.Stages) > 0 && !matchesAny(r.Stages, resource.Stages, true) {
+		return false
+	}
+	return true
+}
+
+// matchesAny reports whether any wanted value is present in values; "*"
+// matches any value
+func matchesAny(wanted, values []string, ignoreCase bool) bool {
+	for _, want := range wanted {
+		for _, value := range values {
+			if want == "*" || value == want || (ignoreCase && strings.EqualFold(value, want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPolicy installs a policy engine that is evaluated before destructive
+// calls. Passing nil disables policy checks.
+func (c *Client) SetPolicy(engine *PolicyEngine) {
+	c.Policy = engine
+}
+
+// WithPolicyOverride returns a copy of the client whose destructive calls
+// carry the given override token. The original client is not modified.
+func (c *Client) WithPolicyOverride(token string) *Client {
+	clone := *c
+	clone.policyOverride = token
+	return &clone
+}
+
+// enforcePolicy evaluates a mutation against the policy engine, looking up the
+// target resource when a rule covers the operation
+func (c *Client) enforcePolicy(m mutation) error {
+	if c.Policy == nil {
+		return nil
+	}
+	op := PolicyOperation(m.operation)
+	switch op {
+	case PolicyOpDeleteExperiment, PolicyOpDeleteRun, PolicyOpDeleteRegisteredModel,
+		PolicyOpDeleteModelVersion, PolicyOpDeleteRegisteredModelAlias, PolicyOpRenameRegisteredModel,
+		PolicyOpDeleteTraces, PolicyOpDeleteLoggedModel:
+	case PolicyOpDeleteRuns:
+		req, ok := m.body.(DeleteRunsRequest)
+		if !ok {
+			return fmt.Errorf("failed to evaluate policy for %s: unexpected request %T", op, m.body)
+		}
+		return c.enforceDeleteRuns(req)
+	case PolicyOpArchiveModelVersion:
+		if !c.Policy.appliesTo(op) {
+			return nil
+		}
+		if m.archiveExisting {
+			if err := c.enforceArchiveExisting(m.target, m.stage); err != nil {
+				return err
+			}
+		}
+		if !strings.EqualFold(m.stage, stageArchived) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if !c.Policy.appliesTo(op) {
+		return nil
+	}
+
+	resource, err := c.policyResource(op, m.target)
+	if err != nil {
+		if hasErrorCode(err, ErrorCodeResourceDoesNotExist) {
+			// Nothing to protect; the call itself reports the missing resource
+			return nil
+		}
+		return fmt.Errorf("failed to evaluate policy for %s: %w", op, err)
+	}
+	return c.Policy.Evaluate(op, *resource, c.policyOverride)
+}
+
+// enforceDeleteRuns evaluates each active run of the experiment that a
+// DeleteRuns call could delete. Which of them the server deletes when
+// MaxRuns is set is up to the server, so all of them are checked.
+func (c *Client) enforceDeleteRuns(req DeleteRunsRequest) error {
+	var ops []PolicyOperation
+	for _, op := range []PolicyOperation{PolicyOpDeleteRuns, PolicyOpDeleteRun} {
+		if c.Policy.appliesTo(op) {
+			ops = append(ops, op)
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	search := SearchRunsRequest{
+		ExperimentIDs: []string{req.ExperimentID},
+		Filter:        fmt.Sprintf("attributes.start_time <= %d", req.MaxTimestampMillis),
+		RunViewType:   ViewTypeActiveOnly,
+	}
+	for {
+		resp, err := c.SearchRuns(search)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate policy for %s: %w", PolicyOpDeleteRuns, err)
+		}
+		for _, run := range resp.Runs {
+			resource := PolicyResource{Entity: AuditEntityRun, ID: run.Info.RunID, Name: run.Info.RunName, Tags: run.TagMap()}
+			for _, op := range ops {
+				if err := c.Policy.Evaluate(op, resource, c.policyOverride); err != nil {
+					if policyErr, ok := IsPolicyError(err); ok {
+						policyErr.Operation = PolicyOpDeleteRuns
+					}
+					return err
+				}
+			}
+		}
+		if resp.NextPageToken == "" {
+			return nil
+		}
+		search.PageToken = resp.NextPageToken
+	}
+}
+
+// enforceArchiveExisting evaluates the archiving of the other versions in
+// stage that a transition with archive_existing_versions causes
+func (c *Client) enforceArchiveExisting(target AuditTarget, stage string) error {
+	quoted, err := filterquote.Quote(target.Name)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate policy for %s: %w", PolicyOpArchiveModelVersion, err)
+	}
+	req := SearchModelVersionsRequest{Filter: "name = " + quoted}
+	for {
+		resp, err := c.SearchModelVersions(req)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate policy for %s: %w", PolicyOpArchiveModelVersion, err)
+		}
+		for _, version := range resp.ModelVersions {
+			if version.Name != target.Name || version.Version == target.Version || !strings.EqualFold(string(version.CurrentStage), stage) {
+				continue
+			}
+			other := target
+			other.Version = version.Version
+			resource, err := c.policyResource(PolicyOpArchiveModelVersion, other)
+			if err != nil {
+				return fmt.Errorf("failed to evaluate policy for %s: %w", PolicyOpArchiveModelVersion, err)
+			}
+			if err := c.Policy.Evaluate(PolicyOpArchiveModelVersion, *resource, c.policyOverride); err != nil {
+				return err
+			}
+		}
+		if resp.NextPageToken == "" {
+			return nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// policyResource fetches the facts about the target of a destructive operation
+func (c *Client) policyResource(op PolicyOperation, target AuditTarget) (*PolicyResource, error) {
+	switch op {
+	case PolicyOpDeleteExperiment, PolicyOpDeleteTraces:
+		resp, err := c.GetExperiment(target.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &PolicyResource{Entity: AuditEntityExperiment, ID: target.ID, Name: resp.Experiment.Name, Tags: resp.Experiment.TagMap()}, nil
+
+	case PolicyOpDeleteRun:
+		resp, err := c.GetRun(target.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &PolicyResource{Entity: AuditEntityRun, ID: target.ID, Name: resp.Run.Info.RunName, Tags: resp.Run.TagMap()}, nil
+
+	case PolicyOpDeleteLoggedModel:
+		resp, err := c.GetLoggedModel(GetLoggedModelRequest{ModelID: target.ID})
+		if err != nil {
+			return nil, err
+		}
+		info := resp.Model.Info
+		tags := map[string]string{}
+		for _, tag := range info.Tags {
+			tags[tag.Key] = tag.Value
+		}
+		return &PolicyResource{Entity: AuditEntityLoggedModel, ID: target.ID, Name: info.Name, Tags: tags}, nil
+
+	case PolicyOpDeleteRegisteredModel, PolicyOpRenameRegisteredModel:
+		resp, err := c.GetRegisteredModel(target.Name)
+		if err != nil {
+			return nil, err
+		}
+		model := resp.RegisteredModel
+		resource := &PolicyResource{Entity: AuditEntityModel, Name: model.Name, Tags: model.TagMap(), Aliases: model.Aliases}
+		for _, version := range model.LatestVersions {
+			resource.Stages = append(resource.Stages, string(version.CurrentStage))
+		}
+		return resource, nil
+
+	case PolicyOpDeleteModelVersion, PolicyOpArchiveModelVersion, PolicyOpDeleteRegisteredModelAlias:
+		var version ModelVersion
+		if op == PolicyOpDeleteRegisteredModelAlias {
+			resp, err := c.GetModelVersionByAlias(GetModelVersionByAliasRequest{Name: target.Name, Alias: target.Alias})
+			if err != nil {
+				return nil, err
+			}
+			version = resp.ModelVersion
+		} else {
+			resp, err := c.GetModelVersion(target.Name, target.Version)
+			if err != nil {
+				return nil, err
+			}
+			version = resp.ModelVersion
+		}
+		model, err := c.GetRegisteredModel(target.Name)
+		if err != nil {
+			return nil, err
+		}
+		// Version tags take precedence over the tags of the registered model
+		tags := model.RegisteredModel.TagMap()
+		for _, tag := range version.Tags {
+			tags[tag.Key] = tag.Value
+		}
+		return &PolicyResource{
+			Entity:  AuditEntityModelVersion,
+			Name:    version.Name,
+			Version: version.Version,
+			Tags:    tags,
+			Aliases: version.Aliases,
+			Stages:  []string{string(version.CurrentStage)},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported policy operation %s", op)
+}
@@ -0,0 +1,326 @@
+package mlflow
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics the profiler logs from the profiles it captures
+const (
+	ProfileMetricCPUSeconds        = "profile/cpu_seconds"
+	ProfileMetricHeapInuse         = "profile/heap_inuse_megabytes"
+	ProfileMetricAllocs            = "profile/allocs_megabytes"
+	ProfileMetricGoroutines        = "profile/goroutines"
+	ProfileMetricMutexDelaySeconds = "profile/mutex_delay_seconds"
+	ProfileMetricBlockDelaySeconds = "profile/block_delay_seconds"
+	ProfileMetricHeapAllocRate     = "profile/heap_alloc_rate_megabytes_per_second"
+)
+
+// ProfileCPU is the name of the CPU profile among the profiles a profiler
+// captures
+const ProfileCPU = "cpu"
+
+// ProfilesArtifactDir is the artifact directory profiles are uploaded to, as
+// profiles/<profile>/step-<step>-<UTC time>.pb.gz
+const ProfilesArtifactDir = "profiles"
+
+const (
+	defaultProfileInterval  = time.Minute
+	defaultCPUProfileWindow = 10 * time.Second
+	defaultProfileTop       = 5
+	// goMetricHeapAllocs is the runtime/metrics total of heap allocations
+	goMetricHeapAllocs = "/gc/heap/allocs:bytes"
+)
+
+// defaultProfileSnapshots are the profiles captured when
+// ProfilerOptions.Snapshots is empty
+var defaultProfileSnapshots = []string{"heap", "goroutine", "mutex"}
+
+// profileSummary describes how a profile is summarized: the sample type
+// totalled into a metric, the metric's scale and whether the top functions
+// are worth a tag
+type profileSummary struct {
+	sampleType string
+	metric     string
+	scale      float64
+	top        bool
+}
+
+var profileSummaries = map[string]profileSummary{
+	ProfileCPU:  {"cpu", ProfileMetricCPUSeconds, 1e-9, true},
+	"heap":      {"inuse_space", ProfileMetricHeapInuse, 1.0 / bytesPerMegabyte, true},
+	"allocs":    {"alloc_space", ProfileMetricAllocs, 1.0 / bytesPerMegabyte, true},
+	"goroutine": {"goroutine", ProfileMetricGoroutines, 1, false},
+	"mutex":     {"delay", ProfileMetricMutexDelaySeconds, 1e-9, true},
+	"block":     {"delay", ProfileMetricBlockDelaySeconds, 1e-9, true},
+}
+
+// ProfileTopTag returns the tag the profiler sets to the top functions of a
+// profile, such as mlflow-go.profile.cpu.top
+func ProfileTopTag(profile string) string {
+	return "mlflow-go.profile." + profile + ".top"
+}
+
+// ProfilerOptions configures a Profiler
+type ProfilerOptions struct {
+	// Interval is the time between captures. Defaults to 1 minute.
+	Interval time.Duration
+	// CPUWindow is how long the CPU profile at the start of each interval
+	// records. Defaults to 10 seconds, or the interval if that is shorter. A
+	// negative window disables CPU profiling.
+	CPUWindow time.Duration
+	// Snapshots are the runtime/pprof profiles captured at the end of each
+	// interval and when the profiler stops. Defaults to heap, goroutine and
+	// mutex.
+	Snapshots []string
+	// MutexProfileFraction, if positive, is set with
+	// runtime.SetMutexProfileFraction while the profiler runs. The mutex
+	// profile is empty unless the program or this option sets a fraction.
+	MutexProfileFraction int
+	// TopFunctions is the number of functions in the top tags. Defaults to 5.
+	TopFunctions int
+	// OnError is called when a profile cannot be captured, uploaded or
+	// logged. The profiler keeps running either way.
+	OnError func(error)
+}
+
+// Profiler captures pprof profiles of the running program and uploads them
+// as artifacts of a run. Each interval is one step: a CPU profile of the
+// window at its start and snapshots at its end, with the profile totals
+// logged as metrics and the top functions as tags so that runs can be
+// compared. Only one CPU profile can record at a time in a process, so only
+// one profiler should profile CPU.
+type Profiler struct {
+	client *Client
+	runID  string
+	opts   ProfilerOptions
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	step          int64
+	metrics       []Metric
+	tags          []RunTag
+	lastAllocs    uint64
+	lastAllocTime time.Time
+	mutexFraction int
+	err           error
+}
+
+// StartProfiler starts profiling the program for the run until the profiler
+// is stopped
+func (c *Client) StartProfiler(runID string, opts ProfilerOptions) (*Profiler, error) {
+	if runID == "" {
+		return nil, fmt.Errorf("run ID is required")
+	}
+	if opts.Interval < 0 {
+		return nil, fmt.Errorf("profile interval must not be negative, got %v", opts.Interval)
+	}
+	if opts.TopFunctions < 0 {
+		return nil, fmt.Errorf("top functions must not be negative, got %d", opts.TopFunctions)
+	}
+	if opts.Interval == 0 {
+		opts.Interval = defaultProfileInterval
+	}
+	if opts.CPUWindow == 0 {
+		opts.CPUWindow = min(defaultCPUProfileWindow, opts.Interval)
+	}
+	if opts.CPUWindow > opts.Interval {
+		return nil, fmt.Errorf("CPU window %v must not be longer than the interval %v", opts.CPUWindow, opts.Interval)
+	}
+	if len(opts.Snapshots) == 0 {
+		opts.Snapshots = defaultProfileSnapshots
+	}
+	for _, name := range opts.Snapshots {
+		if name == ProfileCPU || pprof.Lookup(name) == nil {
+			return nil, fmt.Errorf("invalid snapshot profile %q", name)
+		}
+	}
+	if opts.TopFunctions == 0 {
+		opts.TopFunctions = defaultProfileTop
+	}
+
+	p := &Profiler{
+		client:        c,
+		runID:         runID,
+		opts:          opts,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		lastAllocs:    readHeapAllocs(),
+		lastAllocTime: time.Now(),
+	}
+	if opts.MutexProfileFraction > 0 {
+		p.mutexFraction = runtime.SetMutexProfileFraction(opts.MutexProfileFraction)
+	}
+	go p.run()
+	return p, nil
+}
+
+// Stop ends the current CPU window early, captures the snapshots a last time
+// and returns the first error that occurred while uploading or logging, if
+// any. Stop can be called more than once.
+func (p *Profiler) Stop() error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	<-p.done
+	return p.err
+}
+
+func (p *Profiler) run() {
+	defer close(p.done)
+	if p.opts.MutexProfileFraction > 0 {
+		defer runtime.SetMutexProfileFraction(p.mutexFraction)
+	}
+	for {
+		stopped := p.profileCPU()
+		if !stopped {
+			stopped = p.wait(p.opts.Interval - max(p.opts.CPUWindow, 0))
+		}
+		p.snapshot()
+		if stopped {
+			return
+		}
+	}
+}
+
+// wait waits for d or until the profiler is stopped, and reports whether it
+// was stopped
+func (p *Profiler) wait(d time.Duration) bool {
+	if d <= 0 {
+		select {
+		case <-p.stop:
+			return true
+		default:
+			return false
+		}
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return false
+	case <-p.stop:
+		return true
+	}
+}
+
+// profileCPU records a CPU profile for the window and reports whether the
+// profiler was stopped during it
+func (p *Profiler) profileCPU() bool {
+	if p.opts.CPUWindow < 0 {
+		return false
+	}
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		p.reportError(fmt.Errorf("failed to start CPU profile: %w", err))
+		return p.wait(p.opts.CPUWindow)
+	}
+	stopped := p.wait(p.opts.CPUWindow)
+	pprof.StopCPUProfile()
+	p.record(ProfileCPU, buf.Bytes())
+	return stopped
+}
+
+// snapshot captures the snapshot profiles and logs the step's metrics and
+// tags
+func (p *Profiler) snapshot() {
+	for _, name := range p.opts.Snapshots {
+		var buf bytes.Buffer
+		if err := pprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+			p.reportError(fmt.Errorf("failed to write %s profile: %w", name, err))
+			continue
+		}
+		p.record(name, buf.Bytes())
+	}
+
+	now := time.Now()
+	allocs := readHeapAllocs()
+	if elapsed := now.Sub(p.lastAllocTime).Seconds(); elapsed > 0 {
+		p.addMetric(ProfileMetricHeapAllocRate, float64(allocs-p.lastAllocs)/bytesPerMegabyte/elapsed)
+	}
+	p.lastAllocs, p.lastAllocTime = allocs, now
+
+	if err := p.client.LogBatch(p.runID, p.metrics, nil, p.tags); err != nil {
+		p.fail(fmt.Errorf("failed to log profile summaries to run %s: %w", p.runID, err))
+	}
+	p.metrics, p.tags = nil, nil
+	p.step++
+}
+
+// record uploads a profile and adds its summary to the step's metrics and
+// tags
+func (p *Profiler) record(name string, data []byte) {
+	artifactPath := fmt.Sprintf("%s/%s/step-%06d-%s.pb.gz", ProfilesArtifactDir, name, p.step, time.Now().UTC().Format("20060102T150405.000Z"))
+	if err := p.client.putArtifact(p.runID, artifactPath, data); err != nil {
+		p.fail(fmt.Errorf("failed to upload %s profile to run %s: %w", name, p.runID, err))
+	}
+
+	spec, ok := profileSummaries[name]
+	if !ok {
+		return
+	}
+	summary, err := SummarizeProfile(data, spec.sampleType, p.opts.TopFunctions)
+	if err != nil {
+		p.reportError(fmt.Errorf("failed to summarize %s profile: %w", name, err))
+		return
+	}
+	p.addMetric(spec.metric, float64(summary.Total)*spec.scale)
+	if spec.top && len(summary.Top) > 0 {
+		p.tags = append(p.tags, RunTag{Key: ProfileTopTag(name), Value: summary.String()})
+	}
+}
+
+func (p *Profiler) addMetric(key string, value float64) {
+	p.metrics = append(p.metrics, Metric{Key: key, Value: value, Timestamp: time.Now().UnixMilli(), Step: p.step})
+	sort.Slice(p.metrics, func(i, j int) bool { return p.metrics[i].Key < p.metrics[j].Key })
+}
+
+// fail records the first error Stop returns and reports it
+func (p *Profiler) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+	p.reportError(err)
+}
+
+func (p *Profiler) reportError(err error) {
+	if p.opts.OnError != nil {
+		p.opts.OnError(err)
+	}
+}
+
+func readHeapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: goMetricHeapAllocs}}
+	metrics.Read(sample)
+	return uint64Value(sample[0])
+}
+
+// StartProfiler starts profiling the program for the run. End stops the
+// profiler before ending the run.
+func (r *ActiveRun) StartProfiler(opts ProfilerOptions) error {
+	if r.profiler != nil {
+		return fmt.Errorf("run %s is already being profiled", r.RunID)
+	}
+	profiler, err := r.Client.StartProfiler(r.RunID, opts)
+	if err != nil {
+		return err
+	}
+	r.profiler = profiler
+	return nil
+}
+
+// stopProfiler stops the run's profiler, if it has one
+func (r *ActiveRun) stopProfiler() error {
+	if r.profiler == nil {
+		return nil
+	}
+	err := r.profiler.Stop()
+	r.profiler = nil
+	return err
+}
@@ -0,0 +1,295 @@
+package mlflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ProfileSummary is the total of a pprof profile's main sample type and the
+// functions that account for most of it
+type ProfileSummary struct {
+	// SampleType and Unit name the summarized values, such as cpu and
+	// nanoseconds
+	SampleType string
+	Unit       string
+	Total      int64
+	// Top holds the functions with the largest flat values, largest first
+	Top []FunctionShare
+}
+
+// FunctionShare is the flat value of a function in a profile and its share
+// of the profile's total
+type FunctionShare struct {
+	Function string
+	Value    int64
+	// Fraction is Value divided by the profile's total
+	Fraction float64
+}
+
+// String formats the top functions as "name=12.5%" pairs for a tag
+func (s ProfileSummary) String() string {
+	parts := make([]string, 0, len(s.Top))
+	for _, f := range s.Top {
+		parts = append(parts, fmt.Sprintf("%s=%.1f%%", f.Function, 100*f.Fraction))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SummarizeProfile summarizes a gzipped pprof profile, such as one written by
+// runtime/pprof, by the sample type named sampleType. An empty sampleType
+// selects the profile's last sample type, which is the one pprof shows by
+// default. Top holds at most top functions.
+func SummarizeProfile(data []byte, sampleType string, top int) (ProfileSummary, error) {
+	p, err := parseProfile(data)
+	if err != nil {
+		return ProfileSummary{}, fmt.Errorf("failed to parse profile: %w", err)
+	}
+	if len(p.sampleTypes) == 0 {
+		return ProfileSummary{}, fmt.Errorf("failed to parse profile: no sample types")
+	}
+	index := len(p.sampleTypes) - 1
+	if sampleType != "" {
+		index = -1
+		var names []string
+		for i, st := range p.sampleTypes {
+			names = append(names, p.str(st.typ))
+			if p.str(st.typ) == sampleType {
+				index = i
+			}
+		}
+		if index < 0 {
+			return ProfileSummary{}, fmt.Errorf("profile has no sample type %q (has %s)", sampleType, strings.Join(names, ", "))
+		}
+	}
+
+	summary := ProfileSummary{
+		SampleType: p.str(p.sampleTypes[index].typ),
+		Unit:       p.str(p.sampleTypes[index].unit),
+	}
+	flat := map[string]int64{}
+	for _, s := range p.samples {
+		if index >= len(s.values) {
+			continue
+		}
+		value := s.values[index]
+		summary.Total += value
+		if len(s.locationIDs) > 0 {
+			flat[p.leafFunction(s.locationIDs[0])] += value
+		}
+	}
+	for name, value := range flat {
+		if value > 0 {
+			summary.Top = append(summary.Top, FunctionShare{Function: name, Value: value})
+		}
+	}
+	sort.Slice(summary.Top, func(i, j int) bool {
+		if summary.Top[i].Value != summary.Top[j].Value {
+			return summary.Top[i].Value > summary.Top[j].Value
+		}
+		return summary.Top[i].Function < summary.Top[j].Function
+	})
+	if len(summary.Top) > top {
+		summary.Top = summary.Top[:top]
+	}
+	for i := range summary.Top {
+		summary.Top[i].Fraction = float64(summary.Top[i].Value) / float64(summary.Total)
+	}
+	return summary, nil
+}
+
+// profile holds the parts of a pprof profile.proto message a summary needs
+type profile struct {
+	sampleTypes []valueType
+	samples     []profileSample
+	// locations maps a location ID to the ID of its innermost function
+	locations map[uint64]uint64
+	functions map[uint64]int64
+	strings   []string
+}
+
+type valueType struct {
+	typ, unit int64
+}
+
+type profileSample struct {
+	locationIDs []uint64
+	values      []int64
+}
+
+func (p *profile) str(index int64) string {
+	if index < 0 || index >= int64(len(p.strings)) {
+		return ""
+	}
+	return p.strings[index]
+}
+
+func (p *profile) leafFunction(locationID uint64) string {
+	if name := p.str(p.functions[p.locations[locationID]]); name != "" {
+		return name
+	}
+	return "<unknown>"
+}
+
+// parseProfile decodes a gzipped profile.proto message
+func parseProfile(data []byte) (*profile, error) {
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	p := &profile{locations: map[uint64]uint64{}, functions: map[uint64]int64{}}
+	err := decodeMessage(data, func(field int, wire int, v uint64, b []byte) error {
+		switch field {
+		case 1:
+			var vt valueType
+			err := decodeMessage(b, func(field, _ int, v uint64, _ []byte) error {
+				switch field {
+				case 1:
+					vt.typ = int64(v)
+				case 2:
+					vt.unit = int64(v)
+				}
+				return nil
+			})
+			p.sampleTypes = append(p.sampleTypes, vt)
+			return err
+		case 2:
+			var s profileSample
+			err := decodeMessage(b, func(field, wire int, v uint64, b []byte) error {
+				switch field {
+				case 1:
+					return decodeRepeated(wire, v, b, func(v uint64) { s.locationIDs = append(s.locationIDs, v) })
+				case 2:
+					return decodeRepeated(wire, v, b, func(v uint64) { s.values = append(s.values, int64(v)) })
+				}
+				return nil
+			})
+			p.samples = append(p.samples, s)
+			return err
+		case 4:
+			var id, functionID uint64
+			line := true
+			err := decodeMessage(b, func(field, _ int, v uint64, b []byte) error {
+				switch field {
+				case 1:
+					id = v
+				case 4:
+					// The first line is the innermost function of an
+					// inlined call
+					if line {
+						line = false
+						return decodeMessage(b, func(field, _ int, v uint64, _ []byte) error {
+							if field == 1 {
+								functionID = v
+							}
+							return nil
+						})
+					}
+				}
+				return nil
+			})
+			p.locations[id] = functionID
+			return err
+		case 5:
+			var id uint64
+			var name int64
+			err := decodeMessage(b, func(field, _ int, v uint64, _ []byte) error {
+				switch field {
+				case 1:
+					id = v
+				case 2:
+					name = int64(v)
+				}
+				return nil
+			})
+			p.functions[id] = name
+			return err
+		case 6:
+			p.strings = append(p.strings, string(b))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// decodeMessage calls fn for each field of a protocol buffer message with
+// the field's number, wire type and either its numeric value or its bytes
+func decodeMessage(data []byte, fn func(field, wire int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid field key")
+		}
+		data = data[n:]
+		field, wire := int(key>>3), int(key&7)
+		var v uint64
+		var b []byte
+		switch wire {
+		case wireVarint:
+			if v, n = binary.Uvarint(data); n <= 0 {
+				return fmt.Errorf("invalid varint in field %d", field)
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("truncated field %d", field)
+			}
+			v, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("truncated field %d", field)
+			}
+			v, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("truncated field %d", field)
+			}
+			b, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", wire, field)
+		}
+		if err := fn(field, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeRepeated calls fn for each value of a repeated integer field, which
+// is either packed into bytes or a single varint
+func decodeRepeated(wire int, v uint64, b []byte, fn func(uint64)) error {
+	if wire != wireBytes {
+		fn(v)
+		return nil
+	}
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("invalid packed varint")
+		}
+		fn(v)
+		b = b[n:]
+	}
+	return nil
+}
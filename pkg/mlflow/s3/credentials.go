@@ -0,0 +1,504 @@
+package s3
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are AWS credentials. Temporary credentials have a session
+// token and expire.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is when temporary credentials expire, zero if they do not
+	Expires time.Time
+}
+
+// CredentialsProvider retrieves AWS credentials
+type CredentialsProvider interface {
+	// Retrieve returns the credentials, or an error wrapping
+	// ErrNoCredentials if the provider is not configured
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// ErrNoCredentials is returned by a provider that has no credentials, so
+// that a chain tries the next one
+var ErrNoCredentials = errors.New("no AWS credentials")
+
+// credentialsRefreshWindow is how long before they expire credentials are
+// refreshed
+const credentialsRefreshWindow = 5 * time.Minute
+
+// metadataTimeout bounds requests to the container and instance metadata
+// endpoints, which do not answer off AWS
+const metadataTimeout = 2 * time.Second
+
+// StaticCredentials are fixed credentials
+type StaticCredentials Credentials
+
+// Retrieve implements CredentialsProvider
+func (c StaticCredentials) Retrieve(context.Context) (Credentials, error) {
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("static credentials: %w", ErrNoCredentials)
+	}
+	return Credentials(c), nil
+}
+
+// EnvCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN
+type EnvCredentials struct{}
+
+// Retrieve implements CredentialsProvider
+func (EnvCredentials) Retrieve(context.Context) (Credentials, error) {
+	creds := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("environment: %w", ErrNoCredentials)
+	}
+	return creds, nil
+}
+
+// SharedCredentials reads a profile of the shared credentials and config
+// files, ~/.aws/credentials and ~/.aws/config by default
+type SharedCredentials struct {
+	// CredentialsFile defaults to AWS_SHARED_CREDENTIALS_FILE, then
+	// ~/.aws/credentials
+	CredentialsFile string
+	// ConfigFile defaults to AWS_CONFIG_FILE, then ~/.aws/config
+	ConfigFile string
+	// Profile defaults to AWS_PROFILE, then "default"
+	Profile string
+}
+
+// unsupportedProfileKeys are the keys of profiles that assume a role, use
+// IAM Identity Center or run a credential process. Such profiles fail
+// rather than fall through to other providers with other credentials.
+var unsupportedProfileKeys = []string{"role_arn", "source_profile", "credential_source", "credential_process", "sso_session", "sso_start_url"}
+
+// Retrieve implements CredentialsProvider. Keys in the credentials file
+// take precedence over those in the config file. Only static keys are
+// supported; a profile that assumes a role, uses IAM Identity Center or
+// runs a credential process is an error.
+func (s SharedCredentials) Retrieve(context.Context) (Credentials, error) {
+	profile := s.profile()
+	creds, err := s.credentialsFile()
+	if err != nil {
+		return Credentials{}, err
+	}
+	values, err := readProfile(creds, profile, false)
+	if err != nil {
+		return Credentials{}, err
+	}
+	config, err := s.configFile()
+	if err != nil {
+		return Credentials{}, err
+	}
+	configValues, err := readProfile(config, profile, true)
+	if err != nil {
+		return Credentials{}, err
+	}
+	for key, value := range configValues {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+	for _, key := range unsupportedProfileKeys {
+		if values[key] != "" {
+			return Credentials{}, fmt.Errorf("shared profile %q sets %s, which is not supported: export its credentials with aws configure export-credentials", profile, key)
+		}
+	}
+	if values["aws_access_key_id"] == "" || values["aws_secret_access_key"] == "" {
+		return Credentials{}, fmt.Errorf("shared profile %q: %w", profile, ErrNoCredentials)
+	}
+	return Credentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}, nil
+}
+
+// Region returns the region of the profile in the config file, "" if it
+// has none
+func (s SharedCredentials) Region() string {
+	config, err := s.configFile()
+	if err != nil {
+		return ""
+	}
+	values, _ := readProfile(config, s.profile(), true)
+	return values["region"]
+}
+
+func (s SharedCredentials) profile() string {
+	if s.Profile != "" {
+		return s.Profile
+	}
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+func (s SharedCredentials) credentialsFile() (string, error) {
+	return sharedFile(s.CredentialsFile, "AWS_SHARED_CREDENTIALS_FILE", "credentials")
+}
+
+func (s SharedCredentials) configFile() (string, error) {
+	return sharedFile(s.ConfigFile, "AWS_CONFIG_FILE", "config")
+}
+
+// sharedFile returns the path of a shared file from the field, then the
+// environment variable, then ~/.aws
+func sharedFile(name, env, base string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	if name := os.Getenv(env); name != "" {
+		return name, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("shared %s file: %w", base, ErrNoCredentials)
+	}
+	return filepath.Join(home, ".aws", base), nil
+}
+
+// readProfile reads the keys of a profile from an INI file. Profiles in the
+// config file other than default are named "profile <name>". A missing
+// file has no keys.
+func readProfile(name, profile string, config bool) (map[string]string, error) {
+	values := map[string]string{}
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS shared file: %w", err)
+	}
+	defer f.Close()
+
+	section := profile
+	if config && profile != "default" {
+		section = "profile " + profile
+	}
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.Join(strings.Fields(line[1:len(line)-1]), " ") == section
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inSection {
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read AWS shared file: %w", err)
+	}
+	return values, nil
+}
+
+// WebIdentityCredentials exchanges a web identity token, such as the one of
+// an EKS service account, for credentials of a role with STS
+type WebIdentityCredentials struct {
+	// TokenFile defaults to AWS_WEB_IDENTITY_TOKEN_FILE
+	TokenFile string
+	// RoleARN defaults to AWS_ROLE_ARN
+	RoleARN string
+	// SessionName defaults to AWS_ROLE_SESSION_NAME, then
+	// "mlflow-go-client"
+	SessionName string
+	// Endpoint defaults to AWS_ENDPOINT_URL_STS, then the regional STS
+	// endpoint of Region
+	Endpoint string
+	Region   string
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Retrieve implements CredentialsProvider
+func (w WebIdentityCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	tokenFile := firstNonEmpty(w.TokenFile, os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	roleARN := firstNonEmpty(w.RoleARN, os.Getenv("AWS_ROLE_ARN"))
+	if tokenFile == "" || roleARN == "" {
+		return Credentials{}, fmt.Errorf("web identity: %w", ErrNoCredentials)
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	endpoint := firstNonEmpty(w.Endpoint, os.Getenv("AWS_ENDPOINT_URL_STS"))
+	if endpoint == "" {
+		endpoint = "https://sts." + firstNonEmpty(w.Region, "us-east-1") + ".amazonaws.com"
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {firstNonEmpty(w.SessionName, os.Getenv("AWS_ROLE_SESSION_NAME"), "mlflow-go-client")},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := fetchCredentials(w.HTTPClient, req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to assume role %s with web identity: %w", roleARN, err)
+	}
+	var resp struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("failed to unmarshal STS response: %w", err)
+	}
+	return Credentials{
+		AccessKeyID:     resp.Credentials.AccessKeyID,
+		SecretAccessKey: resp.Credentials.SecretAccessKey,
+		SessionToken:    resp.Credentials.SessionToken,
+		Expires:         resp.Credentials.Expiration,
+	}, nil
+}
+
+// ContainerCredentials reads the credentials of an ECS task or an EKS pod
+// identity from the container credentials endpoint
+type ContainerCredentials struct {
+	// URL defaults to AWS_CONTAINER_CREDENTIALS_FULL_URI, or
+	// AWS_CONTAINER_CREDENTIALS_RELATIVE_URI on the ECS agent
+	URL string
+	// AuthToken defaults to AWS_CONTAINER_AUTHORIZATION_TOKEN, or the
+	// contents of AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE
+	AuthToken string
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Retrieve implements CredentialsProvider
+func (c ContainerCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	endpoint := firstNonEmpty(c.URL, os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"))
+	if endpoint == "" {
+		if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+			endpoint = "http://169.254.170.2" + relative
+		}
+	}
+	if endpoint == "" {
+		return Credentials{}, fmt.Errorf("container: %w", ErrNoCredentials)
+	}
+	token := firstNonEmpty(c.AuthToken, os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"))
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); token == "" && tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to read container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	body, err := fetchCredentials(c.HTTPClient, req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get container credentials: %w", err)
+	}
+	return parseMetadataCredentials(body)
+}
+
+// InstanceCredentials reads the credentials of an EC2 instance's role from
+// the instance metadata service, with IMDSv2 session tokens
+type InstanceCredentials struct {
+	// Endpoint defaults to AWS_EC2_METADATA_SERVICE_ENDPOINT, then
+	// http://169.254.169.254
+	Endpoint string
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Retrieve implements CredentialsProvider. It has no credentials if
+// AWS_EC2_METADATA_DISABLED is true or the metadata service does not answer.
+func (i InstanceCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return Credentials{}, fmt.Errorf("instance metadata: %w", ErrNoCredentials)
+	}
+	endpoint := strings.TrimSuffix(firstNonEmpty(i.Endpoint, os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"), "http://169.254.169.254"), "/")
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+	token, err := fetchCredentials(i.HTTPClient, req)
+	if err != nil {
+		// Off EC2 nothing answers
+		return Credentials{}, fmt.Errorf("instance metadata: %w: %v", ErrNoCredentials, err)
+	}
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/latest/meta-data/iam/security-credentials/"+path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+		return fetchCredentials(i.HTTPClient, req)
+	}
+	roles, err := get("")
+	if err != nil {
+		return Credentials{}, fmt.Errorf("instance metadata: %w: %v", ErrNoCredentials, err)
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(string(roles)), "\n")
+	if role == "" {
+		return Credentials{}, fmt.Errorf("instance metadata: no role: %w", ErrNoCredentials)
+	}
+	body, err := get(role)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get credentials of instance role %s: %w", role, err)
+	}
+	return parseMetadataCredentials(body)
+}
+
+// parseMetadataCredentials parses the JSON credentials of the container and
+// instance metadata endpoints
+func parseMetadataCredentials(body []byte) (Credentials, error) {
+	var resp struct {
+		Code            string
+		Message         string
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+	if resp.Code != "" && resp.Code != "Success" {
+		return Credentials{}, fmt.Errorf("failed to get credentials: %s: %s", resp.Code, resp.Message)
+	}
+	if resp.AccessKeyID == "" || resp.SecretAccessKey == "" {
+		return Credentials{}, errors.New("failed to get credentials: response has no access key")
+	}
+	return Credentials{
+		AccessKeyID:     resp.AccessKeyID,
+		SecretAccessKey: resp.SecretAccessKey,
+		SessionToken:    resp.Token,
+		Expires:         resp.Expiration,
+	}, nil
+}
+
+// fetchCredentials sends a request to a credentials endpoint and returns
+// the body of a 2xx response
+func fetchCredentials(client *http.Client, req *http.Request) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// ChainCredentials tries providers in order and returns the credentials of
+// the first one that has them
+type ChainCredentials []CredentialsProvider
+
+// Retrieve implements CredentialsProvider. A provider failing with an
+// error other than ErrNoCredentials stops the chain.
+func (c ChainCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	for _, provider := range c {
+		creds, err := provider.Retrieve(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return creds, err
+	}
+	return Credentials{}, fmt.Errorf("%w: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, AWS_PROFILE, or run with a role", ErrNoCredentials)
+}
+
+// DefaultCredentials resolves credentials in the order the AWS SDKs do:
+// from the environment, a web identity token, the static keys of the
+// shared credentials and config files, the container credentials endpoint,
+// then the EC2 instance metadata service. Temporary credentials are reused until shortly before
+// they expire.
+func DefaultCredentials() CredentialsProvider {
+	return &CachedCredentials{Provider: ChainCredentials{
+		EnvCredentials{},
+		WebIdentityCredentials{Region: firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))},
+		SharedCredentials{},
+		ContainerCredentials{},
+		InstanceCredentials{},
+	}}
+}
+
+// CachedCredentials reuses the expiring credentials of a provider until
+// shortly before they expire. Credentials that do not expire are retrieved
+// again each time, so that changes to the environment or shared files
+// apply.
+type CachedCredentials struct {
+	Provider CredentialsProvider
+
+	mu    sync.Mutex
+	creds Credentials
+}
+
+// Retrieve implements CredentialsProvider
+func (c *CachedCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.creds.Expires.IsZero() && time.Until(c.creds.Expires) > credentialsRefreshWindow {
+		return c.creds, nil
+	}
+	creds, err := c.Provider.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.creds = creds
+	return creds, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
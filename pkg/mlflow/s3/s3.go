@@ -0,0 +1,587 @@
+// Package s3 stores MLflow artifacts in Amazon S3 and S3-compatible stores
+// such as MinIO and Ceph, for s3://bucket/prefix artifact URIs. Importing
+// it registers the backend for the s3 scheme:
+//
+//	import _ "github.com/julpayne/mlflow-go-client/pkg/mlflow/s3"
+//
+// The registered backend is configured from the environment like MLflow's
+// own S3 support, see ConfigFromEnv.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/internal/artifactpath"
+	"github.com/julpayne/mlflow-go-client/internal/transfer"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Defaults of Config
+const (
+	DefaultRegion      = "us-east-1"
+	DefaultPartSize    = 16 << 20
+	DefaultConcurrency = 4
+	DefaultMaxRetries  = 3
+)
+
+// Server-side encryption modes
+const (
+	EncryptionAES256 = "AES256"
+	EncryptionKMS    = "aws:kms"
+)
+
+// maxParts is the most parts S3 accepts in a multipart upload
+const maxParts = 10000
+
+func init() {
+	mlflow.RegisterArtifactRepository("s3", func(_ *mlflow.Client, artifactURI string) (mlflow.ArtifactRepository, error) {
+		cfg, err := ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return New(artifactURI, cfg)
+	})
+}
+
+// Config configures access to S3
+type Config struct {
+	// Region is the bucket's region, DefaultRegion if empty
+	Region string
+	// Endpoint is the URL of an S3-compatible store such as MinIO or
+	// Ceph, empty for AWS. Buckets on a custom endpoint are addressed by
+	// path rather than by host name.
+	Endpoint string
+	// Credentials default to DefaultCredentials
+	Credentials CredentialsProvider
+	// InsecureSkipVerify disables TLS certificate verification, for
+	// on-premises stores with self-signed certificates
+	InsecureSkipVerify bool
+
+	// ServerSideEncryption is the encryption of uploaded objects,
+	// EncryptionAES256 or EncryptionKMS, empty for the bucket's default
+	ServerSideEncryption string
+	// SSEKMSKeyID is the KMS key of EncryptionKMS, empty for the AWS
+	// managed key
+	SSEKMSKeyID string
+	// SSECustomerKey is a 256-bit key to encrypt objects with (SSE-C).
+	// S3 does not keep it, so the same key is needed to download them.
+	SSECustomerKey []byte
+
+	// PartSize is the size of the parts of multipart uploads and ranged
+	// downloads, DefaultPartSize if zero. Files up to this size take a
+	// single request. S3 requires parts of at least 5 MiB.
+	PartSize int64
+	// Concurrency is the number of parts transferred at once,
+	// DefaultConcurrency if zero. Each buffers up to PartSize bytes.
+	Concurrency int
+	// MaxRetries is the number of retries of a request failing with a
+	// network error, a 5xx status or throttling, DefaultMaxRetries if zero.
+	// A negative value disables retries.
+	MaxRetries int
+
+	// HTTPClient defaults to a client sharing http.DefaultTransport's
+	// settings
+	HTTPClient *http.Client
+}
+
+// uploadExtraArgs are the keys of MLFLOW_S3_UPLOAD_EXTRA_ARGS this package
+// supports
+var uploadExtraArgs = map[string]func(*Config, string) error{
+	"ServerSideEncryption": func(cfg *Config, value string) error {
+		cfg.ServerSideEncryption = value
+		return nil
+	},
+	"SSEKMSKeyId": func(cfg *Config, value string) error {
+		cfg.SSEKMSKeyID = value
+		return nil
+	},
+	"SSECustomerAlgorithm": func(_ *Config, value string) error {
+		if value != EncryptionAES256 {
+			return fmt.Errorf("unsupported SSECustomerAlgorithm %q", value)
+		}
+		return nil
+	},
+	"SSECustomerKey": func(cfg *Config, value string) error {
+		cfg.SSECustomerKey = []byte(value)
+		return nil
+	},
+}
+
+// defaultCredentials is shared by the repositories of the registered
+// backend, so that temporary credentials are cached across them
+var defaultCredentials = sync.OnceValue(DefaultCredentials)
+
+// ConfigFromEnv reads the configuration of MLflow's S3 support:
+//   - AWS_REGION or AWS_DEFAULT_REGION, then the profile's region
+//   - MLFLOW_S3_ENDPOINT_URL for an S3-compatible store
+//   - MLFLOW_S3_IGNORE_TLS=true to skip TLS verification
+//   - MLFLOW_S3_UPLOAD_EXTRA_ARGS, a JSON object with ServerSideEncryption,
+//     SSEKMSKeyId, SSECustomerAlgorithm and SSECustomerKey
+//
+// Credentials are resolved with DefaultCredentials.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Region:      firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), SharedCredentials{}.Region()),
+		Endpoint:    os.Getenv("MLFLOW_S3_ENDPOINT_URL"),
+		Credentials: defaultCredentials(),
+	}
+	if ignore := os.Getenv("MLFLOW_S3_IGNORE_TLS"); ignore != "" {
+		skip, err := strconv.ParseBool(ignore)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid MLFLOW_S3_IGNORE_TLS %q", ignore)
+		}
+		cfg.InsecureSkipVerify = skip
+	}
+	if extra := os.Getenv("MLFLOW_S3_UPLOAD_EXTRA_ARGS"); extra != "" {
+		var args map[string]string
+		if err := json.Unmarshal([]byte(extra), &args); err != nil {
+			return Config{}, fmt.Errorf("invalid MLFLOW_S3_UPLOAD_EXTRA_ARGS: %w", err)
+		}
+		for key, value := range args {
+			set, ok := uploadExtraArgs[key]
+			if !ok {
+				return Config{}, fmt.Errorf("unsupported MLFLOW_S3_UPLOAD_EXTRA_ARGS key %q", key)
+			}
+			if err := set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("invalid MLFLOW_S3_UPLOAD_EXTRA_ARGS: %w", err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// Repository stores artifacts under a prefix of a bucket. It implements
+// mlflow.ArtifactRepository.
+type Repository struct {
+	// Bucket is the bucket name
+	Bucket string
+	// Prefix is the key prefix of the artifact root, without slashes at
+	// either end
+	Prefix string
+
+	cfg      Config
+	client   *http.Client
+	endpoint *url.URL
+	// pathStyle addresses the bucket in the path rather than the host
+	pathStyle bool
+}
+
+var _ mlflow.ArtifactRepository = (*Repository)(nil)
+
+// insecureTransport is shared by the clients that skip TLS verification
+var insecureTransport = sync.OnceValue(func() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return transport
+})
+
+// New returns the repository of an s3://bucket/prefix artifact URI
+func New(artifactURI string, cfg Config) (*Repository, error) {
+	uri, err := url.Parse(artifactURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URI %q: %w", artifactURI, err)
+	}
+	if !strings.EqualFold(uri.Scheme, "s3") || uri.Host == "" {
+		return nil, fmt.Errorf("artifact URI %q is not an s3://bucket/prefix URI", artifactURI)
+	}
+	switch cfg.ServerSideEncryption {
+	case "", EncryptionAES256, EncryptionKMS:
+	default:
+		return nil, fmt.Errorf("unsupported server-side encryption %q", cfg.ServerSideEncryption)
+	}
+	if cfg.SSECustomerKey != nil && len(cfg.SSECustomerKey) != 32 {
+		return nil, fmt.Errorf("SSE-C keys must be 32 bytes, got %d", len(cfg.SSECustomerKey))
+	}
+	if cfg.SSECustomerKey != nil && cfg.ServerSideEncryption != "" {
+		return nil, errors.New("SSE-C cannot be combined with server-side encryption " + cfg.ServerSideEncryption)
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+	if cfg.Credentials == nil {
+		cfg.Credentials = DefaultCredentials()
+	}
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = DefaultPartSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+
+	r := &Repository{
+		Bucket: uri.Host,
+		Prefix: strings.Trim(uri.Path, "/"),
+		cfg:    cfg,
+		client: cfg.HTTPClient,
+	}
+	if r.client == nil {
+		r.client = &http.Client{}
+		if cfg.InsecureSkipVerify {
+			r.client.Transport = insecureTransport()
+		}
+	}
+	if cfg.Endpoint != "" {
+		if r.endpoint, err = url.Parse(cfg.Endpoint); err != nil || r.endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+		}
+		r.endpoint.Path = strings.TrimSuffix(r.endpoint.Path, "/")
+		r.pathStyle = true
+	} else {
+		r.endpoint = &url.URL{Scheme: "https", Host: "s3." + cfg.Region + ".amazonaws.com"}
+		// Dotted bucket names do not match the wildcard certificate
+		if strings.Contains(r.Bucket, ".") {
+			r.pathStyle = true
+		} else {
+			r.endpoint.Host = r.Bucket + "." + r.endpoint.Host
+		}
+	}
+	return r, nil
+}
+
+// key returns the object key of a clean artifact path, the prefix itself
+// for ""
+func (r *Repository) key(artifactPath string) string {
+	return strings.TrimPrefix(path.Join(r.Prefix, artifactPath), "/")
+}
+
+// dirPrefix returns the key prefix of the objects under a clean artifact
+// directory
+func (r *Repository) dirPrefix(dir string) string {
+	if key := r.key(dir); key != "" {
+		return key + "/"
+	}
+	return ""
+}
+
+// objectURL returns the URL of an object, or of the bucket for ""
+func (r *Repository) objectURL(key string, query url.Values) *url.URL {
+	u := *r.endpoint
+	u.Path = r.endpoint.Path + "/" + key
+	if r.pathStyle {
+		u.Path = r.endpoint.Path + "/" + r.Bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	}
+	u.RawPath = escape(u.Path, true)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// Error is an error response of S3. Errors for missing objects satisfy
+// errors.Is(err, fs.ErrNotExist).
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Key        string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	return transfer.ErrorMessage("S3", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// Is makes a missing key match fs.ErrNotExist. A missing bucket does not.
+func (e *Error) Is(target error) bool {
+	return target == fs.ErrNotExist && (e.Code == "NoSuchKey" || e.StatusCode == http.StatusNotFound && e.Code == "")
+}
+
+// parseError returns the error of a non-2xx response
+func parseError(resp *http.Response, body []byte) error {
+	e := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Amz-Request-Id")}
+	var parsed struct {
+		Code      string `xml:"Code"`
+		Message   string `xml:"Message"`
+		Key       string `xml:"Key"`
+		RequestID string `xml:"RequestId"`
+	}
+	if xml.Unmarshal(body, &parsed) == nil {
+		e.Code, e.Message, e.Key = parsed.Code, parsed.Message, parsed.Key
+		if parsed.RequestID != "" {
+			e.RequestID = parsed.RequestID
+		}
+	}
+	return e
+}
+
+// retryable reports whether a request failing with err may succeed if
+// retried
+func retryable(err error) bool {
+	var s3Err *Error
+	if !errors.As(err, &s3Err) {
+		return transfer.RetryableNetworkError(err) && !errors.Is(err, ErrNoCredentials)
+	}
+	return transfer.RetryableStatus(s3Err.StatusCode) ||
+		s3Err.Code == "SlowDown" || s3Err.Code == "RequestTimeout" || s3Err.Code == "RequestTimeTooSkewed"
+}
+
+// request is an S3 request
+type request struct {
+	method string
+	key    string
+	query  url.Values
+	header http.Header
+	body   []byte
+	// sse sends the SSE-C headers, which S3 requires on every request that
+	// reads or writes an object's data
+	sse bool
+}
+
+// do sends a request, retrying it on retryable failures, and returns the
+// response if its status is 2xx. The caller must close its body.
+func (r *Repository) do(ctx context.Context, req request) (*http.Response, error) {
+	return transfer.Retry(ctx, r.cfg.MaxRetries, retryable, func() (*http.Response, error) {
+		return r.send(ctx, req)
+	})
+}
+
+// send sends a signed request once
+func (r *Repository) send(ctx context.Context, req request) (*http.Response, error) {
+	creds, err := r.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, r.objectURL(req.key, req.query).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if req.sse && r.cfg.SSECustomerKey != nil {
+		sum := md5.Sum(r.cfg.SSECustomerKey)
+		httpReq.Header.Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", EncryptionAES256)
+		httpReq.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key", base64.StdEncoding.EncodeToString(r.cfg.SSECustomerKey))
+		httpReq.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	payloadHash := EmptyPayloadHash
+	if len(req.body) > 0 {
+		sum := sha256.Sum256(req.body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	httpReq.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	Sign(httpReq, creds, r.cfg.Region, "s3", payloadHash, time.Now())
+	return transfer.Send(r.client, httpReq, parseError)
+}
+
+// doXML sends a request and unmarshals the XML of its response into v.
+// Some requests fail after a 200 status has been sent.
+func (r *Repository) doXML(ctx context.Context, req request, v any) error {
+	resp, err := r.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	return transfer.DecodeXML(resp, v, parseError)
+}
+
+// uploadHeader returns the encryption headers of a new object
+func (r *Repository) uploadHeader() http.Header {
+	header := http.Header{}
+	if r.cfg.ServerSideEncryption != "" {
+		header.Set("X-Amz-Server-Side-Encryption", r.cfg.ServerSideEncryption)
+	}
+	if r.cfg.SSEKMSKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", r.cfg.SSEKMSKeyID)
+	}
+	return header
+}
+
+// listPage is a page of ListObjectsV2
+type listPage struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list calls fn with each page of the objects under prefix, grouped into
+// common prefixes at delimiter if it is not empty
+func (r *Repository) list(ctx context.Context, prefix, delimiter string, maxKeys int, fn func(*listPage) error) error {
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if delimiter != "" {
+			query.Set("delimiter", delimiter)
+		}
+		if maxKeys > 0 {
+			query.Set("max-keys", strconv.Itoa(maxKeys))
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		var page listPage
+		if err := r.doXML(ctx, request{method: http.MethodGet, query: query}, &page); err != nil {
+			return fmt.Errorf("failed to list s3://%s/%s: %w", r.Bucket, prefix, err)
+		}
+		if err := fn(&page); err != nil {
+			return err
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" || maxKeys > 0 {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// List implements mlflow.ArtifactRepository
+func (r *Repository) List(ctx context.Context, dir string) ([]mlflow.FileInfo, error) {
+	clean, err := artifactpath.Clean(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.dirPrefix(clean)
+	var files []mlflow.FileInfo
+	err = r.list(ctx, prefix, "/", 0, func(page *listPage) error {
+		for _, p := range page.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(p.Prefix, prefix), "/")
+			if name != "" {
+				files = append(files, mlflow.FileInfo{Path: path.Join(clean, name), IsDir: true})
+			}
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(object.Key, prefix)
+			// Keys ending in a slash are directory markers
+			if name != "" && !strings.HasSuffix(name, "/") {
+				files = append(files, mlflow.FileInfo{Path: path.Join(clean, name), FileSize: object.Size})
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Stat implements mlflow.ArtifactRepository. A path that is not an object
+// is a directory if objects exist under it.
+func (r *Repository) Stat(ctx context.Context, artifactPath string) (mlflow.FileInfo, error) {
+	clean, err := artifactpath.Clean(artifactPath, true)
+	if err != nil {
+		return mlflow.FileInfo{}, err
+	}
+	if clean == "" {
+		return mlflow.FileInfo{IsDir: true}, nil
+	}
+	resp, err := r.do(ctx, request{method: http.MethodHead, key: r.key(clean), sse: true})
+	if err == nil {
+		resp.Body.Close()
+		return mlflow.FileInfo{Path: clean, FileSize: resp.ContentLength}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return mlflow.FileInfo{}, fmt.Errorf("failed to stat artifact %s: %w", clean, err)
+	}
+	found := false
+	err = r.list(ctx, r.dirPrefix(clean), "", 1, func(page *listPage) error {
+		found = len(page.Contents) > 0
+		return nil
+	})
+	if err != nil {
+		return mlflow.FileInfo{}, err
+	}
+	if !found {
+		return mlflow.FileInfo{}, fmt.Errorf("artifact %s: %w", clean, fs.ErrNotExist)
+	}
+	return mlflow.FileInfo{Path: clean, IsDir: true}, nil
+}
+
+// deleteBatch is the most keys DeleteObjects accepts
+const deleteBatch = 1000
+
+// Delete implements mlflow.ArtifactRepository
+func (r *Repository) Delete(ctx context.Context, artifactPath string) error {
+	clean, err := artifactpath.Clean(artifactPath, true)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	if clean != "" {
+		keys = append(keys, r.key(clean))
+	}
+	err = r.list(ctx, r.dirPrefix(clean), "", 0, func(page *listPage) error {
+		for _, object := range page.Contents {
+			keys = append(keys, object.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete artifact %s: %w", clean, err)
+	}
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), deleteBatch)]
+		keys = keys[len(batch):]
+		if err := r.deleteObjects(ctx, batch); err != nil {
+			return fmt.Errorf("failed to delete artifact %s: %w", clean, err)
+		}
+	}
+	return nil
+}
+
+// deleteObjects deletes up to deleteBatch keys with one DeleteObjects
+// request. Missing keys are not an error.
+func (r *Repository) deleteObjects(ctx context.Context, keys []string) error {
+	type object struct {
+		Key string `xml:"Key"`
+	}
+	deleteReq := struct {
+		XMLName xml.Name `xml:"Delete"`
+		Quiet   bool     `xml:"Quiet"`
+		Objects []object `xml:"Object"`
+	}{Quiet: true}
+	for _, key := range keys {
+		deleteReq.Objects = append(deleteReq.Objects, object{Key: key})
+	}
+	body, err := xml.Marshal(deleteReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	sum := md5.Sum(body)
+	header := http.Header{}
+	header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	header.Set("Content-Type", "application/xml")
+	var resp struct {
+		Errors []struct {
+			Key     string `xml:"Key"`
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		} `xml:"Error"`
+	}
+	if err := r.doXML(ctx, request{method: http.MethodPost, query: url.Values{"delete": {""}}, header: header, body: body}, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		first := resp.Errors[0]
+		return fmt.Errorf("%d objects not deleted, %s: %w", len(resp.Errors), first.Key, &Error{StatusCode: http.StatusOK, Code: first.Code, Message: first.Message, Key: first.Key})
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/internal/artifactpath"
+	"github.com/julpayne/mlflow-go-client/internal/transfer"
+)
+
+// abortTimeout bounds aborting a failed multipart upload, which happens
+// after the caller's context may have been cancelled
+const abortTimeout = 30 * time.Second
+
+// Upload implements mlflow.ArtifactRepository. Content up to the part size
+// takes a single request; larger content is uploaded in parts, several at
+// once, and the upload is aborted if a part fails.
+func (r *Repository) Upload(ctx context.Context, artifactPath string, content io.Reader, size int64) error {
+	clean, err := artifactpath.Clean(artifactPath, false)
+	if err != nil {
+		return err
+	}
+	key := r.key(clean)
+	partSize := transfer.PartSize(size, r.cfg.PartSize, maxParts)
+
+	first, err := transfer.ReadPart(content, partSize)
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+	}
+	var second []byte
+	if int64(len(first)) == partSize {
+		if second, err = transfer.ReadPart(content, partSize); err != nil {
+			return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+		}
+	}
+	if len(second) == 0 {
+		if size >= 0 && int64(len(first)) != size {
+			return fmt.Errorf("failed to upload artifact %s: got %d of %d bytes", clean, len(first), size)
+		}
+		resp, err := r.do(ctx, request{method: http.MethodPut, key: key, header: r.uploadHeader(), body: first, sse: true})
+		if err != nil {
+			return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if err := r.uploadMultipart(ctx, key, content, size, partSize, [][]byte{first, second}); err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %w", clean, err)
+	}
+	return nil
+}
+
+// completedPart is a part of a multipart upload
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadMultipart uploads the parts already read, then the rest of content,
+// with up to Concurrency parts in flight
+func (r *Repository) uploadMultipart(ctx context.Context, key string, content io.Reader, size, partSize int64, read [][]byte) error {
+	var created struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err := r.doXML(ctx, request{method: http.MethodPost, key: key, query: url.Values{"uploads": {""}}, header: r.uploadHeader(), sse: true}, &created)
+	if err != nil {
+		return fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		parts []completedPart
+	)
+	total, err := transfer.UploadParts(ctx, content, read, partSize, maxParts, r.cfg.Concurrency, func(ctx context.Context, number int, data []byte) error {
+		etag, err := r.uploadPart(ctx, key, created.UploadID, number, data)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		parts = append(parts, completedPart{PartNumber: number, ETag: etag})
+		mu.Unlock()
+		return nil
+	})
+	if err == nil && size >= 0 && total != size {
+		err = fmt.Errorf("got %d of %d bytes", total, size)
+	}
+	if err == nil {
+		err = r.completeMultipart(ctx, key, created.UploadID, parts)
+	}
+	if err != nil {
+		abortCtx, cancelAbort := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+		defer cancelAbort()
+		if abortErr := r.abortMultipart(abortCtx, key, created.UploadID); abortErr != nil {
+			return fmt.Errorf("%w (and failed to abort the upload: %v)", err, abortErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// uploadPart uploads a part and returns its ETag
+func (r *Repository) uploadPart(ctx context.Context, key, uploadID string, number int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	resp, err := r.do(ctx, request{method: http.MethodPut, key: key, query: query, body: data, sse: true})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (r *Repository) completeMultipart(ctx context.Context, key, uploadID string, parts []completedPart) error {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	err = r.doXML(ctx, request{method: http.MethodPost, key: key, query: url.Values{"uploadId": {uploadID}}, header: header, body: body}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) abortMultipart(ctx context.Context, key, uploadID string) error {
+	resp, err := r.do(ctx, request{method: http.MethodDelete, key: key, query: url.Values{"uploadId": {uploadID}}})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Download implements mlflow.ArtifactRepository. The first part is
+// requested as a range; if the object is larger, the remaining parts are
+// fetched concurrently while the first streams.
+func (r *Repository) Download(ctx context.Context, artifactPath string) (io.ReadCloser, error) {
+	clean, err := artifactpath.Clean(artifactPath, false)
+	if err != nil {
+		return nil, err
+	}
+	key := r.key(clean)
+	partSize := r.cfg.PartSize
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=0-%d", partSize-1))
+	resp, err := r.do(ctx, request{method: http.MethodGet, key: key, header: header, sse: true})
+	var s3Err *Error
+	if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// An empty object has no first byte
+		resp, err = r.do(ctx, request{method: http.MethodGet, key: key, sse: true})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact %s: %w", clean, err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return resp.Body, nil
+	}
+	_, totalText, _ := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	total, err := strconv.ParseInt(totalText, 10, 64)
+	if err != nil || total <= partSize {
+		return resp.Body, nil
+	}
+	etag := resp.Header.Get("ETag")
+	return transfer.NewRangeReader(ctx, resp.Body, partSize, total, r.cfg.Concurrency, func(ctx context.Context, start, end int64) ([]byte, error) {
+		return r.getRange(ctx, key, start, end, etag)
+	}), nil
+}
+
+// getRange downloads the bytes start to end of an object, retrying if the
+// body is cut short. The ETag makes the request fail if the object was
+// replaced since the first part was read.
+func (r *Repository) getRange(ctx context.Context, key string, start, end int64, etag string) ([]byte, error) {
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := r.do(ctx, request{method: http.MethodGet, key: key, header: header, sse: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to download bytes %d-%d: %w", start, end, err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && int64(len(data)) != end-start+1 {
+			err = fmt.Errorf("got %d of %d bytes", len(data), end-start+1)
+		}
+		if err == nil {
+			return data, nil
+		}
+		if attempt >= r.cfg.MaxRetries || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to download bytes %d-%d: %w", start, end, err)
+		}
+	}
+}
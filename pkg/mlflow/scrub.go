@@ -0,0 +1,338 @@
+package mlflow
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ScrubAction is what the scrubber does with a value that looks like a secret
+type ScrubAction string
+
+const (
+	// ScrubRedact replaces the value with [REDACTED]
+	ScrubRedact ScrubAction = "redact"
+	// ScrubHash replaces the value with its SHA-256 digest, so equal values can
+	// still be compared across runs
+	ScrubHash ScrubAction = "hash"
+	// ScrubReject fails the call with a *ScrubError
+	ScrubReject ScrubAction = "reject"
+)
+
+// SecretPattern detects a credential format in a value
+type SecretPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultSecretKeyPatterns are the key globs treated as secrets when
+// Scrubber.KeyPatterns is nil. Matching is case insensitive and * matches any
+// sequence of characters.
+var DefaultSecretKeyPatterns = []string{
+	"*password*", "*passwd*", "*token*", "*secret*", "*api_key*", "*apikey*",
+	"*access_key*", "*private_key*", "*credential*", "*authorization*",
+}
+
+// DefaultAllowedKeyPatterns are key globs that are never treated as secret
+// keys when Scrubber.AllowedKeyPatterns is nil, because they commonly appear
+// in model configurations. Their values are still checked.
+var DefaultAllowedKeyPatterns = []string{"*tokenizer*", "*_tokens", "*tokens_*"}
+
+// DefaultSecretValuePatterns are the credential formats detected in values
+// when Scrubber.ValuePatterns is nil
+var DefaultSecretValuePatterns = []SecretPattern{
+	{Name: "AWS access key", Pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "JWT", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{Name: "connection string with credentials", Pattern: regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:[^/\s@]+@`)},
+	{Name: "Azure storage key", Pattern: regexp.MustCompile(`(?i)(AccountKey|SharedAccessSignature|sig)=[A-Za-z0-9%+/=]{20,}`)},
+	{Name: "private key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{Name: "GitHub token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "Slack token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+}
+
+// Default entropy detection settings used by NewScrubber. Hex digests such as
+// git commits and dataset hashes stay below the threshold.
+const (
+	DefaultScrubEntropyThreshold = 4.5
+	DefaultScrubMinEntropyLength = 32
+)
+
+// ScrubFinding reports a value the scrubber detected as a secret
+type ScrubFinding struct {
+	Operation string
+	Key       string
+	Reason    string
+	Action    ScrubAction
+}
+
+// ScrubError is returned when a call is rejected because it would log a secret
+type ScrubError struct {
+	Findings []ScrubFinding
+}
+
+// Error implements the error interface
+func (e *ScrubError) Error() string {
+	reasons := make([]string, 0, len(e.Findings))
+	for _, finding := range e.Findings {
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", finding.Key, finding.Reason))
+	}
+	return fmt.Sprintf("refusing to log secret-looking values: %s", strings.Join(reasons, ", "))
+}
+
+// IsScrubError checks if an error is a ScrubError and returns it
+func IsScrubError(err error) (*ScrubError, bool) {
+	var scrubErr *ScrubError
+	if errors.As(err, &scrubErr) {
+		return scrubErr, true
+	}
+	return nil, false
+}
+
+// Scrubber detects secrets in logged params and tags and redacts, hashes or
+// rejects them
+type Scrubber struct {
+	// Action applied to detected secrets
+	Action ScrubAction
+	// KeyPatterns are key globs whose values are always secret.
+	// DefaultSecretKeyPatterns is used when nil.
+	KeyPatterns []string
+	// AllowedKeyPatterns are key globs exempt from KeyPatterns.
+	// DefaultAllowedKeyPatterns is used when nil.
+	AllowedKeyPatterns []string
+	// ValuePatterns detect credential formats inside values.
+	// DefaultSecretValuePatterns is used when nil.
+	ValuePatterns []SecretPattern
+	// EntropyThreshold is the Shannon entropy in bits per character above which
+	// a value in the base64 or hex alphabet that is not a path is treated as a
+	// secret. Zero disables the check.
+	EntropyThreshold float64
+	// MinEntropyLength is the minimum length of values checked for entropy
+	MinEntropyLength int
+	// HashKey, when set, makes ScrubHash use HMAC-SHA256 so short secrets
+	// cannot be recovered by brute force
+	HashKey []byte
+	// OnFinding is called for every detected secret
+	OnFinding func(finding ScrubFinding)
+}
+
+// NewScrubber creates a scrubber with the default detectors and the given action
+func NewScrubber(action ScrubAction) *Scrubber {
+	return &Scrubber{
+		Action:           action,
+		EntropyThreshold: DefaultScrubEntropyThreshold,
+		MinEntropyLength: DefaultScrubMinEntropyLength,
+	}
+}
+
+// SetScrubber installs a scrubber applied to LogParam, LogBatch, SetTag,
+// SetExperimentTag, SetRegisteredModelTag, SetModelVersionTag and SetTraceTag.
+// Passing nil disables scrubbing.
+func (c *Client) SetScrubber(s *Scrubber) {
+	c.Scrubber = s
+}
+
+// Detect returns why the key/value pair looks like a secret, or an empty
+// string if it does not
+func (s *Scrubber) Detect(key, value string) string {
+	keyPatterns := s.KeyPatterns
+	if keyPatterns == nil {
+		keyPatterns = DefaultSecretKeyPatterns
+	}
+	allowed := s.AllowedKeyPatterns
+	if allowed == nil {
+		allowed = DefaultAllowedKeyPatterns
+	}
+	lowerKey := strings.ToLower(key)
+	if matchesAnyGlob(keyPatterns, lowerKey) && !matchesAnyGlob(allowed, lowerKey) && value != "" {
+		return "secret key name"
+	}
+
+	valuePatterns := s.ValuePatterns
+	if valuePatterns == nil {
+		valuePatterns = DefaultSecretValuePatterns
+	}
+	for _, pattern := range valuePatterns {
+		if pattern.Pattern.MatchString(value) {
+			return pattern.Name
+		}
+	}
+
+	if s.EntropyThreshold > 0 && len(value) >= s.MinEntropyLength && tokenLike(value) {
+		if shannonEntropy(value) > s.EntropyThreshold {
+			return "high entropy value"
+		}
+	}
+	return ""
+}
+
+// Scrub checks a single key/value pair and returns the value to log. The
+// finding is nil when the value is not a secret. For ScrubReject the
+// original value is returned together with the finding.
+func (s *Scrubber) Scrub(operation, key, value string) (string, *ScrubFinding) {
+	reason := s.Detect(key, value)
+	if reason == "" {
+		return value, nil
+	}
+	action := s.Action
+	if action == "" {
+		action = ScrubRedact
+	}
+	finding := &ScrubFinding{Operation: operation, Key: key, Reason: reason, Action: action}
+	if s.OnFinding != nil {
+		s.OnFinding(*finding)
+	}
+	switch action {
+	case ScrubHash:
+		return s.hash(value), finding
+	case ScrubReject:
+		return value, finding
+	default:
+		return redactedValue, finding
+	}
+}
+
+func (s *Scrubber) hash(value string) string {
+	if len(s.HashKey) > 0 {
+		mac := hmac.New(sha256.New, s.HashKey)
+		mac.Write([]byte(value))
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// scrubValue applies the client's scrubber to a single value
+func (c *Client) scrubValue(operation, key, value string) (string, error) {
+	if c.Scrubber == nil {
+		return value, nil
+	}
+	scrubbed, finding := c.Scrubber.Scrub(operation, key, value)
+	if finding != nil && finding.Action == ScrubReject {
+		return value, &ScrubError{Findings: []ScrubFinding{*finding}}
+	}
+	return scrubbed, nil
+}
+
+// scrubBatch applies the client's scrubber to the params and tags of a batch.
+// The input slices are not modified. A rejected value fails the whole batch.
+func (c *Client) scrubBatch(params []Param, tags []RunTag) ([]Param, []RunTag, error) {
+	if c.Scrubber == nil {
+		return params, tags, nil
+	}
+	var rejected []ScrubFinding
+	scrubbedParams := make([]Param, len(params))
+	for i, param := range params {
+		value, finding := c.Scrubber.Scrub("LogBatch", param.Key, param.Value)
+		if finding != nil && finding.Action == ScrubReject {
+			rejected = append(rejected, *finding)
+		}
+		scrubbedParams[i] = Param{Key: param.Key, Value: value}
+	}
+	scrubbedTags := make([]RunTag, len(tags))
+	for i, tag := range tags {
+		value, finding := c.Scrubber.Scrub("LogBatch", tag.Key, tag.Value)
+		if finding != nil && finding.Action == ScrubReject {
+			rejected = append(rejected, *finding)
+		}
+		scrubbedTags[i] = RunTag{Key: tag.Key, Value: value}
+	}
+	if len(rejected) > 0 {
+		return nil, nil, &ScrubError{Findings: rejected}
+	}
+	if params == nil {
+		scrubbedParams = nil
+	}
+	if tags == nil {
+		scrubbedTags = nil
+	}
+	return scrubbedParams, scrubbedTags, nil
+}
+
+// tokenLike reports whether a value could be an encoded key or token: it
+// uses only the base64, base64url or hex alphabet and is not a path. Paths
+// and URIs mix letters, digits and separators enough to look random.
+func tokenLike(value string) bool {
+	for _, r := range value {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9',
+			r == '+', r == '/', r == '=', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	if !strings.Contains(value, "/") {
+		return true
+	}
+	// Slashes are base64, which has no - or _, unless they separate path
+	// segments
+	return !strings.HasPrefix(value, "/") && !strings.Contains(value, "//") && !strings.ContainsAny(value, "-_")
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// matchesAnyGlob reports whether s matches any of the patterns
+func matchesAnyGlob(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if globMatch(strings.ToLower(pattern), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches s against a pattern where * matches any sequence of
+// characters (including separators) and ? matches a single character
+func globMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	nextPx, nextSx := -1, -1
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch pattern[px] {
+			case '*':
+				nextPx, nextSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(s) && s[sx] == pattern[px] {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+		if nextSx > 0 && nextSx <= len(s) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+		return false
+	}
+	return true
+}
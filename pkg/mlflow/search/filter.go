@@ -0,0 +1,484 @@
+// Package search builds MLflow search filters and order_by clauses, checking
+// them against the identifiers and comparators each search endpoint supports
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/internal/filterquote"
+)
+
+// Entity is the kind of object a search filter or order_by applies to.
+// Each entity supports its own set of identifiers and comparators.
+type Entity string
+
+const (
+	EntityRuns             Entity = "runs"
+	EntityExperiments      Entity = "experiments"
+	EntityRegisteredModels Entity = "registered_models"
+	EntityModelVersions    Entity = "model_versions"
+)
+
+// IdentifierType is the namespace of a search identifier
+type IdentifierType string
+
+const (
+	IdentifierMetric    IdentifierType = "metrics"
+	IdentifierParam     IdentifierType = "params"
+	IdentifierTag       IdentifierType = "tags"
+	IdentifierAttribute IdentifierType = "attributes"
+	IdentifierDataset   IdentifierType = "datasets"
+)
+
+// Comparators supported by MLflow search filters
+const (
+	OpEq    = "="
+	OpNe    = "!="
+	OpGt    = ">"
+	OpGe    = ">="
+	OpLt    = "<"
+	OpLe    = "<="
+	OpLike  = "LIKE"
+	OpILike = "ILIKE"
+	OpIn    = "IN"
+	OpNotIn = "NOT IN"
+)
+
+var (
+	numericComparators = []string{OpEq, OpNe, OpGt, OpGe, OpLt, OpLe}
+	stringComparators  = []string{OpEq, OpNe, OpLike, OpILike}
+	setComparators     = []string{OpEq, OpNe, OpLike, OpILike, OpIn, OpNotIn}
+)
+
+// valueKind is the type of value an identifier is compared with
+type valueKind int
+
+const (
+	stringValue valueKind = iota
+	numericValue
+)
+
+// attributeSpec describes a searchable attribute of an entity
+type attributeSpec struct {
+	kind        valueKind
+	comparators []string
+}
+
+// searchSpec describes what an entity's search endpoint accepts
+type searchSpec struct {
+	// attributes are the searchable attributes
+	attributes map[string]attributeSpec
+	// orderBy are the attributes results can be ordered by
+	orderBy map[string]bool
+	// types are the identifier types other than attributes that can be
+	// filtered on, with their comparators
+	types map[IdentifierType][]string
+	// orderByTypes are the identifier types other than attributes that
+	// results can be ordered by
+	orderByTypes map[IdentifierType]bool
+	// attributePrefix is written before attribute names
+	attributePrefix string
+}
+
+// searchSpecs mirrors the identifiers accepted by the MLflow search endpoints
+var searchSpecs = map[Entity]searchSpec{
+	EntityRuns: {
+		attributes: map[string]attributeSpec{
+			"run_id":          {stringValue, setComparators},
+			"run_name":        {stringValue, setComparators},
+			"status":          {stringValue, setComparators},
+			"user_id":         {stringValue, setComparators},
+			"artifact_uri":    {stringValue, setComparators},
+			"lifecycle_stage": {stringValue, setComparators},
+			"start_time":      {numericValue, numericComparators},
+			"end_time":        {numericValue, numericComparators},
+			"created":         {numericValue, numericComparators},
+		},
+		orderBy: map[string]bool{
+			"run_id": true, "run_name": true, "status": true, "user_id": true, "artifact_uri": true,
+			"lifecycle_stage": true, "start_time": true, "end_time": true, "created": true,
+		},
+		types: map[IdentifierType][]string{
+			IdentifierMetric:  numericComparators,
+			IdentifierParam:   stringComparators,
+			IdentifierTag:     stringComparators,
+			IdentifierDataset: setComparators,
+		},
+		orderByTypes: map[IdentifierType]bool{
+			IdentifierMetric: true,
+			IdentifierParam:  true,
+			IdentifierTag:    true,
+		},
+		attributePrefix: "attributes.",
+	},
+	EntityExperiments: {
+		attributes: map[string]attributeSpec{
+			"name":             {stringValue, stringComparators},
+			"creation_time":    {numericValue, numericComparators},
+			"last_update_time": {numericValue, numericComparators},
+		},
+		orderBy: map[string]bool{
+			"name": true, "experiment_id": true, "creation_time": true, "last_update_time": true,
+		},
+		types: map[IdentifierType][]string{
+			IdentifierTag: stringComparators,
+		},
+	},
+	EntityRegisteredModels: {
+		attributes: map[string]attributeSpec{
+			"name": {stringValue, stringComparators},
+		},
+		orderBy: map[string]bool{
+			"name": true, "creation_timestamp": true, "last_updated_timestamp": true, "timestamp": true,
+		},
+		types: map[IdentifierType][]string{
+			IdentifierTag: stringComparators,
+		},
+	},
+	EntityModelVersions: {
+		attributes: map[string]attributeSpec{
+			"name":           {stringValue, stringComparators},
+			"run_id":         {stringValue, setComparators},
+			"source_path":    {stringValue, stringComparators},
+			"version_number": {numericValue, numericComparators},
+		},
+		orderBy: map[string]bool{
+			"name": true, "version_number": true, "creation_timestamp": true, "last_updated_timestamp": true,
+		},
+		types: map[IdentifierType][]string{
+			IdentifierTag: stringComparators,
+		},
+	},
+}
+
+// datasetFields are the dataset fields runs can be filtered on
+var datasetFields = map[string]bool{"name": true, "digest": true, "context": true}
+
+// Identifier names a metric, param, tag, attribute or dataset field in a
+// search filter or order_by clause
+type Identifier struct {
+	Type IdentifierType
+	Key  string
+}
+
+// Metric identifies a run metric
+func Metric(key string) Identifier {
+	return Identifier{Type: IdentifierMetric, Key: key}
+}
+
+// Param identifies a run param
+func Param(key string) Identifier {
+	return Identifier{Type: IdentifierParam, Key: key}
+}
+
+// Tag identifies a tag of a run, experiment, registered model or model version
+func Tag(key string) Identifier {
+	return Identifier{Type: IdentifierTag, Key: key}
+}
+
+// Attribute identifies a built-in attribute, such as status or start_time
+// for runs, or name for registered models
+func Attribute(name string) Identifier {
+	return Identifier{Type: IdentifierAttribute, Key: name}
+}
+
+// Dataset identifies a field (name, digest or context) of a run's input datasets
+func Dataset(field string) Identifier {
+	return Identifier{Type: IdentifierDataset, Key: field}
+}
+
+// Comparison is a single condition of a search filter
+type Comparison struct {
+	Identifier Identifier
+	Op         string
+	// Values holds one value, or the list for IN and NOT IN
+	Values []any
+}
+
+// Filter is a conjunction of comparisons. The zero Filter matches everything.
+type Filter struct {
+	Comparisons []Comparison
+}
+
+func (id Identifier) compare(op string, values ...any) Filter {
+	return Filter{Comparisons: []Comparison{{Identifier: id, Op: op, Values: values}}}
+}
+
+// Eq matches values equal to value
+func (id Identifier) Eq(value any) Filter { return id.compare(OpEq, value) }
+
+// Ne matches values not equal to value
+func (id Identifier) Ne(value any) Filter { return id.compare(OpNe, value) }
+
+// Gt matches values greater than value
+func (id Identifier) Gt(value any) Filter { return id.compare(OpGt, value) }
+
+// Ge matches values greater than or equal to value
+func (id Identifier) Ge(value any) Filter { return id.compare(OpGe, value) }
+
+// Lt matches values less than value
+func (id Identifier) Lt(value any) Filter { return id.compare(OpLt, value) }
+
+// Le matches values less than or equal to value
+func (id Identifier) Le(value any) Filter { return id.compare(OpLe, value) }
+
+// Like matches values against a case-sensitive SQL LIKE pattern
+func (id Identifier) Like(pattern string) Filter { return id.compare(OpLike, pattern) }
+
+// ILike matches values against a case-insensitive SQL LIKE pattern
+func (id Identifier) ILike(pattern string) Filter { return id.compare(OpILike, pattern) }
+
+// In matches any of values
+func (id Identifier) In(values ...any) Filter { return id.compare(OpIn, values...) }
+
+// NotIn matches none of values
+func (id Identifier) NotIn(values ...any) Filter { return id.compare(OpNotIn, values...) }
+
+// Asc orders results by the identifier, ascending
+func (id Identifier) Asc() OrderBy { return OrderBy{Identifier: id} }
+
+// Desc orders results by the identifier, descending
+func (id Identifier) Desc() OrderBy { return OrderBy{Identifier: id, Descending: true} }
+
+// And combines the filter with others. MLflow search filters only support AND.
+func (f Filter) And(others ...Filter) Filter {
+	comparisons := append([]Comparison{}, f.Comparisons...)
+	for _, other := range others {
+		comparisons = append(comparisons, other.Comparisons...)
+	}
+	return Filter{Comparisons: comparisons}
+}
+
+// And combines filters
+func And(filters ...Filter) Filter {
+	return Filter{}.And(filters...)
+}
+
+// Build validates the filter against what the entity's search endpoint
+// supports and renders it as a filter string. Every problem found is
+// reported in the returned error.
+func (f Filter) Build(entity Entity) (string, error) {
+	spec, ok := searchSpecs[entity]
+	if !ok {
+		return "", fmt.Errorf("unknown search entity %q", entity)
+	}
+	clauses := make([]string, 0, len(f.Comparisons))
+	var errs []error
+	for _, comparison := range f.Comparisons {
+		clause, err := spec.renderComparison(entity, comparison)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(errs) > 0 {
+		return "", fmt.Errorf("invalid %s filter: %w", entity, errors.Join(errs...))
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// OrderBy is a single order_by clause
+type OrderBy struct {
+	Identifier Identifier
+	Descending bool
+}
+
+// BuildOrderBy validates order_by clauses against what the entity's search
+// endpoint supports and renders them as OrderBy values
+func BuildOrderBy(entity Entity, clauses ...OrderBy) ([]string, error) {
+	spec, ok := searchSpecs[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown search entity %q", entity)
+	}
+	var orderBy []string
+	var errs []error
+	for _, clause := range clauses {
+		id := clause.Identifier
+		switch {
+		case id.Type == IdentifierAttribute && !spec.orderBy[id.Key]:
+			errs = append(errs, fmt.Errorf("cannot order %s by attribute %q", entity, id.Key))
+			continue
+		case id.Type != IdentifierAttribute && !spec.orderByTypes[id.Type]:
+			errs = append(errs, fmt.Errorf("cannot order %s by %s", entity, id.Type))
+			continue
+		}
+		name, err := spec.renderIdentifier(id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if clause.Descending {
+			name += " DESC"
+		} else {
+			name += " ASC"
+		}
+		orderBy = append(orderBy, name)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid %s order_by: %w", entity, errors.Join(errs...))
+	}
+	return orderBy, nil
+}
+
+// comparators returns the comparators and value kind for an identifier
+func (spec searchSpec) comparators(entity Entity, id Identifier) ([]string, valueKind, error) {
+	switch id.Type {
+	case IdentifierAttribute:
+		attr, ok := spec.attributes[id.Key]
+		if !ok {
+			return nil, 0, fmt.Errorf("%s cannot be filtered by attribute %q", entity, id.Key)
+		}
+		return attr.comparators, attr.kind, nil
+	case IdentifierDataset:
+		if !datasetFields[id.Key] {
+			return nil, 0, fmt.Errorf("unknown dataset field %q", id.Key)
+		}
+	}
+	comparators, ok := spec.types[id.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("%s cannot be filtered by %s", entity, id.Type)
+	}
+	if id.Type == IdentifierMetric {
+		return comparators, numericValue, nil
+	}
+	return comparators, stringValue, nil
+}
+
+func (spec searchSpec) renderComparison(entity Entity, c Comparison) (string, error) {
+	comparators, kind, err := spec.comparators(entity, c.Identifier)
+	if err != nil {
+		return "", err
+	}
+	if !containsString(comparators, c.Op) {
+		return "", fmt.Errorf("%s does not support %s (supported: %s)", c.Identifier, c.Op, strings.Join(comparators, ", "))
+	}
+	name, err := spec.renderIdentifier(c.Identifier)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Op == OpIn || c.Op == OpNotIn {
+		if len(c.Values) == 0 {
+			return "", fmt.Errorf("%s %s needs at least one value", c.Identifier, c.Op)
+		}
+		values := make([]string, len(c.Values))
+		for i, value := range c.Values {
+			if values[i], err = renderValue(c.Identifier, kind, value); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s %s (%s)", name, c.Op, strings.Join(values, ", ")), nil
+	}
+	if len(c.Values) != 1 {
+		return "", fmt.Errorf("%s %s needs exactly one value", c.Identifier, c.Op)
+	}
+	if c.Op == OpLike || c.Op == OpILike {
+		kind = stringValue
+	}
+	value, err := renderValue(c.Identifier, kind, c.Values[0])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", name, c.Op, value), nil
+}
+
+// plainKey matches keys that can be written without backticks
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (spec searchSpec) renderIdentifier(id Identifier) (string, error) {
+	if id.Key == "" {
+		return "", fmt.Errorf("%s key is empty", id.Type)
+	}
+	if id.Type == IdentifierAttribute {
+		return spec.attributePrefix + id.Key, nil
+	}
+	key, err := QuoteKey(id.Key)
+	if err != nil {
+		return "", err
+	}
+	return string(id.Type) + "." + key, nil
+}
+
+// QuoteKey returns a metric, param or tag key as written in a filter,
+// wrapping it in backticks when it contains characters such as dots or
+// spaces. Keys containing a backtick cannot be written in a filter.
+func QuoteKey(key string) (string, error) {
+	if plainKey.MatchString(key) {
+		return key, nil
+	}
+	if strings.Contains(key, "`") {
+		return "", fmt.Errorf("key %q cannot be used in a filter: it contains a backtick", key)
+	}
+	return "`" + key + "`", nil
+}
+
+// QuoteValue returns a string value as written in a filter. Values are
+// single-quoted, or double-quoted when they contain a single quote, because
+// MLflow does not unescape quotes inside string literals. Values containing
+// both kinds of quote cannot be written in a filter.
+func QuoteValue(value string) (string, error) {
+	return filterquote.Quote(value)
+}
+
+func renderValue(id Identifier, kind valueKind, value any) (string, error) {
+	if kind == numericValue {
+		number, ok := numericFilterValue(value)
+		if !ok {
+			return "", fmt.Errorf("%s must be compared with a number, got %T", id, value)
+		}
+		return number, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be compared with a string, got %T", id, value)
+	}
+	return QuoteValue(s)
+}
+
+// numericFilterValue formats numbers, and times as milliseconds since the epoch
+func numericFilterValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case time.Time:
+		return strconv.FormatInt(v.UnixMilli(), 10), true
+	}
+	return "", false
+}
+
+// String returns the identifier as used in error messages, e.g. metrics.accuracy
+func (id Identifier) String() string {
+	if id.Type == IdentifierAttribute {
+		return id.Key
+	}
+	return string(id.Type) + "." + id.Key
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
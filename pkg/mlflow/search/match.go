@@ -0,0 +1,336 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// DatasetContextTag is the dataset tag holding the context (such as
+// "training") that datasets.context filters compare against
+const DatasetContextTag = "mlflow.data.context"
+
+// Match evaluates the filter against a Run, Experiment, RegisteredModel or
+// ModelVersion (or a pointer to one) with the server's semantics:
+//
+//   - a metric is compared by its latest value
+//   - a missing metric, param or tag, or an unset attribute (an empty string
+//     or a zero timestamp), is null and satisfies no comparison, not even !=
+//   - LIKE is case sensitive and ILIKE is not; % matches any sequence of
+//     characters and _ matches one character
+//   - a datasets. comparison matches if any of the run's input datasets does
+//
+// The filter is validated against the entity first, so an identifier the
+// server would reject is an error rather than a mismatch.
+func (f Filter) Match(value any) (bool, error) {
+	entity, lookup, err := lookupFor(value)
+	if err != nil {
+		return false, err
+	}
+	if err := f.Validate(entity); err != nil {
+		return false, err
+	}
+	spec := searchSpecs[entity]
+	for _, c := range f.Comparisons {
+		_, kind, _ := spec.comparators(entity, c.Identifier)
+		candidates := lookup(c.Identifier)
+		matched := false
+		for _, candidate := range candidates {
+			if ok, err := c.matches(kind, candidate); err != nil {
+				return false, err
+			} else if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fieldValue is a value an identifier resolves to, either a string or a number
+type fieldValue struct {
+	str string
+	num float64
+}
+
+func stringField(s string) []fieldValue {
+	if s == "" {
+		return nil
+	}
+	return []fieldValue{{str: s}}
+}
+
+func numberField(n int64) []fieldValue {
+	if n == 0 {
+		return nil
+	}
+	return []fieldValue{{num: float64(n)}}
+}
+
+// lookupFunc resolves an identifier to the values it compares against. No
+// values means null.
+type lookupFunc func(id Identifier) []fieldValue
+
+func lookupFor(value any) (Entity, lookupFunc, error) {
+	switch v := value.(type) {
+	case mlflow.Run:
+		return EntityRuns, runLookup(&v), nil
+	case *mlflow.Run:
+		if v != nil {
+			return EntityRuns, runLookup(v), nil
+		}
+	case mlflow.Experiment:
+		return EntityExperiments, experimentLookup(&v), nil
+	case *mlflow.Experiment:
+		if v != nil {
+			return EntityExperiments, experimentLookup(v), nil
+		}
+	case mlflow.RegisteredModel:
+		return EntityRegisteredModels, registeredModelLookup(&v), nil
+	case *mlflow.RegisteredModel:
+		if v != nil {
+			return EntityRegisteredModels, registeredModelLookup(v), nil
+		}
+	case mlflow.ModelVersion:
+		return EntityModelVersions, modelVersionLookup(&v), nil
+	case *mlflow.ModelVersion:
+		if v != nil {
+			return EntityModelVersions, modelVersionLookup(v), nil
+		}
+	default:
+		return "", nil, fmt.Errorf("cannot match a filter against %T", value)
+	}
+	return "", nil, fmt.Errorf("cannot match a filter against a nil %T", value)
+}
+
+// keyValue is a param or any kind of tag
+type keyValue interface {
+	mlflow.Param | mlflow.RunTag | mlflow.ExperimentTag | mlflow.RegisteredModelTag | mlflow.ModelVersionTag | mlflow.DatasetTag
+}
+
+func tagValue[T keyValue](tags []T, key string) []fieldValue {
+	for _, tag := range tags {
+		t := struct{ Key, Value string }(tag)
+		if t.Key == key {
+			return []fieldValue{{str: t.Value}}
+		}
+	}
+	return nil
+}
+
+func runLookup(run *mlflow.Run) lookupFunc {
+	return func(id Identifier) []fieldValue {
+		switch id.Type {
+		case IdentifierMetric:
+			for _, metric := range run.Data.Metrics {
+				if metric.Key == id.Key {
+					return []fieldValue{{num: metric.Value}}
+				}
+			}
+			return nil
+		case IdentifierParam:
+			return tagValue(run.Data.Params, id.Key)
+		case IdentifierTag:
+			return tagValue(run.Data.Tags, id.Key)
+		case IdentifierDataset:
+			var values []fieldValue
+			for _, dataset := range run.Inputs.Datasets {
+				switch id.Key {
+				case "name":
+					values = append(values, stringField(dataset.Name)...)
+				case "digest":
+					values = append(values, stringField(dataset.Digest)...)
+				case "context":
+					values = append(values, tagValue(dataset.Tags, DatasetContextTag)...)
+				}
+			}
+			return values
+		}
+		info := run.Info
+		switch id.Key {
+		case "run_id":
+			return stringField(info.RunID)
+		case "run_name":
+			return stringField(info.RunName)
+		case "status":
+			return stringField(string(info.Status))
+		case "user_id":
+			return stringField(info.UserID)
+		case "artifact_uri":
+			return stringField(info.ArtifactURI)
+		case "lifecycle_stage":
+			return stringField(string(info.LifecycleStage))
+		case "start_time", "created":
+			return numberField(info.StartTime)
+		case "end_time":
+			return numberField(info.EndTime)
+		}
+		return nil
+	}
+}
+
+func experimentLookup(experiment *mlflow.Experiment) lookupFunc {
+	return func(id Identifier) []fieldValue {
+		if id.Type == IdentifierTag {
+			return tagValue(experiment.Tags, id.Key)
+		}
+		switch id.Key {
+		case "name":
+			return stringField(experiment.Name)
+		case "creation_time":
+			return numberField(experiment.CreationTime)
+		case "last_update_time":
+			return numberField(experiment.LastUpdateTime)
+		}
+		return nil
+	}
+}
+
+func registeredModelLookup(model *mlflow.RegisteredModel) lookupFunc {
+	return func(id Identifier) []fieldValue {
+		if id.Type == IdentifierTag {
+			return tagValue(model.Tags, id.Key)
+		}
+		if id.Key == "name" {
+			return stringField(model.Name)
+		}
+		return nil
+	}
+}
+
+func modelVersionLookup(version *mlflow.ModelVersion) lookupFunc {
+	return func(id Identifier) []fieldValue {
+		if id.Type == IdentifierTag {
+			return tagValue(version.Tags, id.Key)
+		}
+		switch id.Key {
+		case "name":
+			return stringField(version.Name)
+		case "run_id":
+			return stringField(version.RunID)
+		case "source_path":
+			return stringField(version.Source)
+		case "version_number":
+			number, err := strconv.ParseInt(version.Version, 10, 64)
+			if err != nil {
+				return nil
+			}
+			return numberField(number)
+		}
+		return nil
+	}
+}
+
+// matches compares one resolved value with the comparison
+func (c Comparison) matches(kind valueKind, field fieldValue) (bool, error) {
+	switch c.Op {
+	case OpIn, OpNotIn:
+		found := false
+		for _, value := range c.Values {
+			if s, ok := value.(string); ok && s == field.str {
+				found = true
+				break
+			}
+		}
+		return found == (c.Op == OpIn), nil
+	case OpLike, OpILike:
+		pattern, _ := c.Values[0].(string)
+		re, err := likePattern(pattern, c.Op == OpILike)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(field.str), nil
+	}
+
+	if kind == numericValue {
+		rhs, ok := numericOperand(c.Values[0])
+		if !ok {
+			return false, fmt.Errorf("%s must be compared with a number, got %T", c.Identifier, c.Values[0])
+		}
+		return compareNumbers(c.Op, field.num, rhs), nil
+	}
+	rhs, _ := c.Values[0].(string)
+	return compareStrings(c.Op, field.str, rhs), nil
+}
+
+func numericOperand(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case time.Time:
+		return float64(v.UnixMilli()), true
+	}
+	return 0, false
+}
+
+// compareNumbers follows IEEE 754, so NaN only satisfies !=, as on the server
+func compareNumbers(op string, lhs, rhs float64) bool {
+	switch op {
+	case OpEq:
+		return lhs == rhs
+	case OpNe:
+		return lhs != rhs
+	case OpGt:
+		return lhs > rhs
+	case OpGe:
+		return lhs >= rhs
+	case OpLt:
+		return lhs < rhs
+	case OpLe:
+		return lhs <= rhs
+	}
+	return false
+}
+
+func compareStrings(op string, lhs, rhs string) bool {
+	switch op {
+	case OpEq:
+		return lhs == rhs
+	case OpNe:
+		return lhs != rhs
+	}
+	return false
+}
+
+// likePattern converts a SQL LIKE pattern to an anchored regular expression
+func likePattern(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?s)")
+	if caseInsensitive {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
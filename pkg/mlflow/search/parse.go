@@ -0,0 +1,254 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// identifierPrefixes maps the prefixes MLflow accepts in filter identifiers
+// to identifier types
+var identifierPrefixes = map[string]IdentifierType{
+	"metric":     IdentifierMetric,
+	"metrics":    IdentifierMetric,
+	"param":      IdentifierParam,
+	"params":     IdentifierParam,
+	"parameter":  IdentifierParam,
+	"parameters": IdentifierParam,
+	"tag":        IdentifierTag,
+	"tags":       IdentifierTag,
+	"attr":       IdentifierAttribute,
+	"attribute":  IdentifierAttribute,
+	"attributes": IdentifierAttribute,
+	"run":        IdentifierAttribute,
+	"dataset":    IdentifierDataset,
+	"datasets":   IdentifierDataset,
+}
+
+// Parse parses a filter string in MLflow's search syntax, e.g.
+//
+//	metrics.accuracy > 0.9 AND tags.team = 'vision' AND attributes.status IN ('RUNNING', 'FINISHED')
+//
+// and validates it against what the entity's search endpoint supports.
+// Identifiers without a prefix are attributes. Quoted values are parsed as
+// strings, integers as int64 and other numbers as float64. An empty filter
+// parses to the zero Filter, which matches everything.
+func Parse(entity Entity, filter string) (Filter, error) {
+	p := &filterParser{src: filter}
+	f, err := p.parse()
+	if err != nil {
+		return Filter{}, err
+	}
+	if err := f.Validate(entity); err != nil {
+		return Filter{}, err
+	}
+	return f, nil
+}
+
+// Validate reports an error if the entity's search endpoint does not support
+// the filter
+func (f Filter) Validate(entity Entity) error {
+	_, err := f.Build(entity)
+	return err
+}
+
+type filterParser struct {
+	src string
+	pos int
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid filter at position %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) done() bool {
+	p.skipSpace()
+	return p.pos >= len(p.src)
+}
+
+// keyword consumes a case-insensitive keyword followed by a non-word character
+func (p *filterParser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.src) || !strings.EqualFold(p.src[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.src) && isWordChar(p.src[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *filterParser) symbol(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func (p *filterParser) parse() (Filter, error) {
+	var f Filter
+	if p.done() {
+		return f, nil
+	}
+	for {
+		comparison, err := p.comparison()
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Comparisons = append(f.Comparisons, comparison)
+		if p.done() {
+			return f, nil
+		}
+		if !p.keyword("AND") {
+			if p.keyword("OR") {
+				return Filter{}, p.errorf("OR is not supported, only AND")
+			}
+			return Filter{}, p.errorf("expected AND")
+		}
+	}
+}
+
+func (p *filterParser) comparison() (Comparison, error) {
+	id, err := p.identifier()
+	if err != nil {
+		return Comparison{}, err
+	}
+	p.skipSpace()
+
+	switch {
+	case p.keyword("NOT"):
+		if !p.keyword("IN") {
+			return Comparison{}, p.errorf("expected IN after NOT")
+		}
+		values, err := p.valueList()
+		return Comparison{Identifier: id, Op: OpNotIn, Values: values}, err
+	case p.keyword("IN"):
+		values, err := p.valueList()
+		return Comparison{Identifier: id, Op: OpIn, Values: values}, err
+	case p.keyword("LIKE"):
+		value, err := p.value()
+		return Comparison{Identifier: id, Op: OpLike, Values: []any{value}}, err
+	case p.keyword("ILIKE"):
+		value, err := p.value()
+		return Comparison{Identifier: id, Op: OpILike, Values: []any{value}}, err
+	}
+	for _, op := range []string{OpGe, OpLe, OpNe, OpEq, OpGt, OpLt} {
+		if p.symbol(op) {
+			value, err := p.value()
+			return Comparison{Identifier: id, Op: op, Values: []any{value}}, err
+		}
+	}
+	return Comparison{}, p.errorf("expected a comparator")
+}
+
+func (p *filterParser) identifier() (Identifier, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && (isWordChar(p.src[p.pos]) || p.src[p.pos] == '.') {
+		p.pos++
+	}
+	word := p.src[start:p.pos]
+	if word == "" {
+		if p.pos < len(p.src) && (p.src[p.pos] == '`' || p.src[p.pos] == '"') {
+			// A quoted attribute name
+			key, err := p.quoted()
+			return Identifier{Type: IdentifierAttribute, Key: key}, err
+		}
+		return Identifier{}, p.errorf("expected an identifier")
+	}
+
+	prefix, key, dotted := strings.Cut(word, ".")
+	if !dotted {
+		return Identifier{Type: IdentifierAttribute, Key: word}, nil
+	}
+	idType, ok := identifierPrefixes[strings.ToLower(prefix)]
+	if !ok {
+		p.pos = start
+		return Identifier{}, p.errorf("unknown identifier type %q", prefix)
+	}
+	if key == "" && p.pos < len(p.src) && (p.src[p.pos] == '`' || p.src[p.pos] == '"') {
+		quoted, err := p.quoted()
+		if err != nil {
+			return Identifier{}, err
+		}
+		key = quoted
+	}
+	if key == "" {
+		return Identifier{}, p.errorf("missing key after %q", word)
+	}
+	return Identifier{Type: idType, Key: key}, nil
+}
+
+// quoted consumes a string delimited by the quote character at the current
+// position. MLflow does not unescape quotes, so the string ends at the next
+// occurrence of the delimiter.
+func (p *filterParser) quoted() (string, error) {
+	quote := p.src[p.pos]
+	end := strings.IndexByte(p.src[p.pos+1:], quote)
+	if end < 0 {
+		return "", p.errorf("unterminated %c", quote)
+	}
+	s := p.src[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+func (p *filterParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected a value")
+	}
+	if c := p.src[p.pos]; c == '\'' || c == '"' {
+		return p.quoted()
+	}
+	start := p.pos
+	for p.pos < len(p.src) && (isWordChar(p.src[p.pos]) || strings.IndexByte("+-.", p.src[p.pos]) >= 0) {
+		p.pos++
+	}
+	literal := p.src[start:p.pos]
+	if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return f, nil
+	}
+	p.pos = start
+	if literal == "" {
+		return nil, p.errorf("expected a value")
+	}
+	return nil, p.errorf("expected a quoted string or a number, got %s", literal)
+}
+
+func (p *filterParser) valueList() ([]any, error) {
+	if !p.symbol("(") {
+		return nil, p.errorf("expected ( after IN")
+	}
+	var values []any
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.symbol(")") {
+			return values, nil
+		}
+		if !p.symbol(",") {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package mlflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+)
+
+// System tags MLflow clients set on new runs
+const (
+	TagSourceName      = "mlflow.source.name"
+	TagSourceType      = "mlflow.source.type"
+	TagSourceGitCommit = "mlflow.source.git.commit"
+	TagUser            = "mlflow.user"
+)
+
+// Tags for build facts MLflow has no system tag for
+const (
+	TagSourceGitModified   = "mlflow-go.source.git.modified"
+	TagSourceGitCommitTime = "mlflow-go.source.git.commitTime"
+	TagHostname            = "mlflow-go.hostname"
+)
+
+// SourceTypeLocal is the mlflow.source.type of a program run on a machine
+const SourceTypeLocal = "LOCAL"
+
+// EnvironmentArtifactPath is where CreateRun logs the Go environment of a run
+// created with Source set
+const EnvironmentArtifactPath = "environment/go-environment.json"
+
+// SourceInfo describes the program that creates a run: the code it was built
+// from, where it runs and the exact module versions it was built with
+type SourceInfo struct {
+	// Name is the import path of the main package
+	Name string `json:"name"`
+	// Module and ModuleVersion are the main module and its version
+	Module        string `json:"module,omitempty"`
+	ModuleVersion string `json:"module_version,omitempty"`
+	// GitCommit, GitCommitTime and GitModified come from the VCS stamp the
+	// go command embeds when building inside a repository
+	GitCommit     string `json:"git_commit,omitempty"`
+	GitCommitTime string `json:"git_commit_time,omitempty"`
+	GitModified   *bool  `json:"git_modified,omitempty"`
+	User          string `json:"user,omitempty"`
+	Hostname      string `json:"hostname,omitempty"`
+	GoVersion     string `json:"go_version"`
+	GOOS          string `json:"goos"`
+	GOARCH        string `json:"goarch"`
+	// Dependencies are the modules the program was built with, after
+	// replacements
+	Dependencies []ModuleDependency `json:"dependencies,omitempty"`
+}
+
+// ModuleDependency is a module a program was built with
+type ModuleDependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	Sum     string `json:"sum,omitempty"`
+	// Replace is the module that replaced this one, if any
+	Replace *ModuleDependency `json:"replace,omitempty"`
+}
+
+// DetectSource describes the running program from the build info embedded in
+// the binary, the current OS user and the hostname. Facts that are not
+// available, such as the VCS stamp of a binary built outside a repository,
+// are left empty.
+func DetectSource() SourceInfo {
+	info := SourceInfo{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+	if len(os.Args) > 0 {
+		info.Name = os.Args[0]
+	}
+	if u, err := user.Current(); err == nil {
+		info.User = u.Username
+	} else {
+		info.User = os.Getenv("USER")
+	}
+	info.Hostname, _ = os.Hostname()
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if bi.Path != "" {
+		info.Name = bi.Path
+	}
+	info.GoVersion = bi.GoVersion
+	info.Module = bi.Main.Path
+	info.ModuleVersion = bi.Main.Version
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.GitCommit = setting.Value
+		case "vcs.time":
+			info.GitCommitTime = setting.Value
+		case "vcs.modified":
+			if modified, err := strconv.ParseBool(setting.Value); err == nil {
+				info.GitModified = &modified
+			}
+		}
+	}
+	for _, dep := range bi.Deps {
+		info.Dependencies = append(info.Dependencies, moduleDependency(dep))
+	}
+	return info
+}
+
+// detectSource is DetectSource for runs created with Source set, which only
+// detects the source once
+var detectSource = sync.OnceValue(DetectSource)
+
+func moduleDependency(m *debug.Module) ModuleDependency {
+	dep := ModuleDependency{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := moduleDependency(m.Replace)
+		dep.Replace = &replace
+	}
+	return dep
+}
+
+// Tags returns the system tags for the source
+func (s SourceInfo) Tags() []RunTag {
+	tags := []RunTag{{Key: TagSourceType, Value: SourceTypeLocal}}
+	add := func(key, value string) {
+		if value != "" {
+			tags = append(tags, RunTag{Key: key, Value: value})
+		}
+	}
+	add(TagSourceName, s.Name)
+	add(TagSourceGitCommit, s.GitCommit)
+	add(TagSourceGitCommitTime, s.GitCommitTime)
+	if s.GitModified != nil {
+		add(TagSourceGitModified, strconv.FormatBool(*s.GitModified))
+	}
+	add(TagUser, s.User)
+	add(TagHostname, s.Hostname)
+	return tags
+}
+
+// LogEnvironment logs the source as a JSON artifact of the run at
+// EnvironmentArtifactPath
+func (c *Client) LogEnvironment(runID string, source SourceInfo) error {
+	data, err := json.MarshalIndent(source, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal environment: %w", err)
+	}
+	return c.putArtifact(runID, EnvironmentArtifactPath, append(data, '\n'))
+}
+
+// withSourceTags adds the source tags the request does not already set
+func withSourceTags(tags []RunTag, source SourceInfo) []RunTag {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag.Key] = true
+	}
+	for _, tag := range source.Tags() {
+		if !set[tag.Key] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,290 @@
+package mlflow
+
+import (
+	"fmt"
+	"math"
+	"runtime/metrics"
+	"sort"
+	"sync"
+	"time"
+)
+
+// System metric names. The first group are the names MLflow's system metrics
+// monitor logs; the rest cover the process and the Go runtime.
+const (
+	SystemMetricCPUUtilization     = "system/cpu_utilization_percentage"
+	SystemMetricMemoryUsage        = "system/system_memory_usage_megabytes"
+	SystemMetricMemoryUsagePercent = "system/system_memory_usage_percentage"
+	SystemMetricDiskUsagePercent   = "system/disk_usage_percentage"
+	SystemMetricDiskUsage          = "system/disk_usage_megabytes"
+	SystemMetricDiskAvailable      = "system/disk_available_megabytes"
+	SystemMetricNetworkReceive     = "system/network_receive_megabytes"
+	SystemMetricNetworkTransmit    = "system/network_transmit_megabytes"
+
+	SystemMetricProcessRSS       = "system/process_memory_rss_megabytes"
+	SystemMetricProcessDiskRead  = "system/process_disk_read_megabytes"
+	SystemMetricProcessDiskWrite = "system/process_disk_write_megabytes"
+	SystemMetricGoroutines       = "system/go_goroutines"
+	SystemMetricGoHeapAlloc      = "system/go_heap_alloc_megabytes"
+	SystemMetricGoHeapGoal       = "system/go_heap_goal_megabytes"
+	SystemMetricGoGCCycles       = "system/go_gc_cycles"
+	SystemMetricGoGCPauseMax     = "system/go_gc_pause_max_milliseconds"
+)
+
+const (
+	defaultSystemMetricsInterval = 10 * time.Second
+	defaultSystemMetricsDiskPath = "/"
+	bytesPerMegabyte             = 1024 * 1024
+)
+
+// SystemMetricsOptions configures a SystemMetricsMonitor
+type SystemMetricsOptions struct {
+	// SamplingInterval is the time between samples. Defaults to 10 seconds,
+	// like MLflow.
+	SamplingInterval time.Duration
+	// SamplesBeforeLogging is the number of samples averaged into each
+	// logged value. Defaults to 1.
+	SamplesBeforeLogging int
+	// DiskPath is a path on the filesystem whose usage is reported. Defaults
+	// to /.
+	DiskPath string
+	// OnError is called when a metric source is unavailable or logging
+	// fails. The monitor keeps running either way.
+	OnError func(error)
+}
+
+// SystemMetricsMonitor samples system, process and Go runtime metrics in the
+// background and logs them to a run with LogBatch. CPU, memory, disk and
+// network metrics are read from /proc and are only available on Linux.
+type SystemMetricsMonitor struct {
+	client   *Client
+	runID    string
+	opts     SystemMetricsOptions
+	samplers []systemSampler
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	sums    map[string]float64
+	samples int
+	step    int64
+	err     error
+}
+
+// systemSampler adds the current value of one or more metrics to values
+type systemSampler func(values map[string]float64) error
+
+// StartSystemMetrics starts logging system metrics to the run until the
+// monitor is stopped. The metric sources that cannot be read on this machine
+// are reported to opts.OnError and skipped.
+func (c *Client) StartSystemMetrics(runID string, opts SystemMetricsOptions) (*SystemMetricsMonitor, error) {
+	if runID == "" {
+		return nil, fmt.Errorf("run ID is required")
+	}
+	if opts.SamplingInterval < 0 {
+		return nil, fmt.Errorf("sampling interval must not be negative, got %v", opts.SamplingInterval)
+	}
+	if opts.SamplesBeforeLogging < 0 {
+		return nil, fmt.Errorf("samples before logging must not be negative, got %d", opts.SamplesBeforeLogging)
+	}
+	if opts.SamplingInterval == 0 {
+		opts.SamplingInterval = defaultSystemMetricsInterval
+	}
+	if opts.SamplesBeforeLogging == 0 {
+		opts.SamplesBeforeLogging = 1
+	}
+	if opts.DiskPath == "" {
+		opts.DiskPath = defaultSystemMetricsDiskPath
+	}
+
+	m := &SystemMetricsMonitor{
+		client: c,
+		runID:  runID,
+		opts:   opts,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		sums:   map[string]float64{},
+	}
+	m.samplers = append(platformSamplers(opts.DiskPath, m.reportError), newGoRuntimeSampler())
+	go m.run()
+	return m, nil
+}
+
+// Stop stops sampling, logs the samples taken since the last logged values
+// and returns the first error that occurred while logging, if any. A run
+// stopped before its first interval gets one sample. Stop can be called more
+// than once.
+func (m *SystemMetricsMonitor) Stop() error {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+	return m.err
+}
+
+func (m *SystemMetricsMonitor) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.opts.SamplingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.sample()
+			if m.samples >= m.opts.SamplesBeforeLogging {
+				m.flush()
+			}
+		case <-m.stop:
+			if m.samples == 0 && m.step == 0 {
+				m.sample()
+			}
+			if m.samples > 0 {
+				m.flush()
+			}
+			return
+		}
+	}
+}
+
+func (m *SystemMetricsMonitor) sample() {
+	values := map[string]float64{}
+	for _, sampler := range m.samplers {
+		if err := sampler(values); err != nil {
+			m.reportError(err)
+		}
+	}
+	for name, value := range values {
+		m.sums[name] += value
+	}
+	m.samples++
+}
+
+// flush logs the average of the samples since the last flush
+func (m *SystemMetricsMonitor) flush() {
+	names := make([]string, 0, len(m.sums))
+	for name := range m.sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	timestamp := time.Now().UnixMilli()
+	batch := make([]Metric, 0, len(names))
+	for _, name := range names {
+		batch = append(batch, Metric{Key: name, Value: m.sums[name] / float64(m.samples), Timestamp: timestamp, Step: m.step})
+	}
+	m.sums = map[string]float64{}
+	m.samples = 0
+	m.step++
+
+	if err := m.client.LogBatch(m.runID, batch, nil, nil); err != nil {
+		err = fmt.Errorf("failed to log system metrics to run %s: %w", m.runID, err)
+		if m.err == nil {
+			m.err = err
+		}
+		m.reportError(err)
+	}
+}
+
+func (m *SystemMetricsMonitor) reportError(err error) {
+	if m.opts.OnError != nil {
+		m.opts.OnError(err)
+	}
+}
+
+// StartSystemMetrics starts logging system metrics to the run. End stops the
+// monitor before ending the run.
+func (r *ActiveRun) StartSystemMetrics(opts SystemMetricsOptions) error {
+	if r.monitor != nil {
+		return fmt.Errorf("system metrics are already being logged to run %s", r.RunID)
+	}
+	monitor, err := r.Client.StartSystemMetrics(r.RunID, opts)
+	if err != nil {
+		return err
+	}
+	r.monitor = monitor
+	return nil
+}
+
+// stopSystemMetrics stops the run's system metrics monitor, if it has one
+func (r *ActiveRun) stopSystemMetrics() error {
+	if r.monitor == nil {
+		return nil
+	}
+	err := r.monitor.Stop()
+	r.monitor = nil
+	return err
+}
+
+// Go runtime metrics read by newGoRuntimeSampler
+const (
+	goMetricGoroutines = "/sched/goroutines:goroutines"
+	goMetricHeapAlloc  = "/memory/classes/heap/objects:bytes"
+	goMetricHeapGoal   = "/gc/heap/goal:bytes"
+	goMetricGCCycles   = "/gc/cycles/total:gc-cycles"
+	goMetricGCPauses   = "/gc/pauses:seconds"
+)
+
+// newGoRuntimeSampler samples the Go runtime. GC cycles and the longest GC
+// pause are counted since the previous sample.
+func newGoRuntimeSampler() systemSampler {
+	samples := []metrics.Sample{
+		{Name: goMetricGoroutines},
+		{Name: goMetricHeapAlloc},
+		{Name: goMetricHeapGoal},
+		{Name: goMetricGCCycles},
+		{Name: goMetricGCPauses},
+	}
+	metrics.Read(samples)
+	lastCycles := uint64Value(samples[3])
+	lastPauses := histogramCounts(samples[4].Value)
+
+	return func(values map[string]float64) error {
+		metrics.Read(samples)
+		values[SystemMetricGoroutines] = float64(uint64Value(samples[0]))
+		values[SystemMetricGoHeapAlloc] = float64(uint64Value(samples[1])) / bytesPerMegabyte
+		values[SystemMetricGoHeapGoal] = float64(uint64Value(samples[2])) / bytesPerMegabyte
+
+		cycles := uint64Value(samples[3])
+		values[SystemMetricGoGCCycles] = float64(cycles - lastCycles)
+		lastCycles = cycles
+
+		if samples[4].Value.Kind() == metrics.KindFloat64Histogram {
+			histogram := samples[4].Value.Float64Histogram()
+			values[SystemMetricGoGCPauseMax] = maxNewPause(histogram, lastPauses) * 1000
+			lastPauses = histogramCounts(samples[4].Value)
+		}
+		return nil
+	}
+}
+
+// uint64Value returns the value of a sample, or 0 if this Go version does not
+// support the metric
+func uint64Value(sample metrics.Sample) uint64 {
+	if sample.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample.Value.Uint64()
+}
+
+func histogramCounts(value metrics.Value) []uint64 {
+	if value.Kind() != metrics.KindFloat64Histogram {
+		return nil
+	}
+	return append([]uint64(nil), value.Float64Histogram().Counts...)
+}
+
+// maxNewPause returns the upper bound of the highest histogram bucket that
+// gained pauses since the previous counts, in seconds
+func maxNewPause(histogram *metrics.Float64Histogram, previous []uint64) float64 {
+	for i := len(histogram.Counts) - 1; i >= 0; i-- {
+		var before uint64
+		if i < len(previous) {
+			before = previous[i]
+		}
+		if histogram.Counts[i] > before {
+			upper := histogram.Buckets[i+1]
+			if math.IsInf(upper, 1) {
+				return histogram.Buckets[i]
+			}
+			return upper
+		}
+	}
+	return 0
+}
@@ -0,0 +1,245 @@
+//go:build linux
+
+package mlflow
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// platformSamplers returns the samplers for the metrics read from /proc and
+// the filesystem. A sampler whose source cannot be read is reported and left
+// out.
+func platformSamplers(diskPath string, report func(error)) []systemSampler {
+	constructors := []struct {
+		name string
+		new  func() (systemSampler, error)
+	}{
+		{"CPU", newCPUSampler},
+		{"memory", newMemorySampler},
+		{"disk", func() (systemSampler, error) { return newDiskSampler(diskPath) }},
+		{"network", newNetworkSampler},
+		{"process memory", newProcessMemorySampler},
+		{"process disk I/O", newProcessIOSampler},
+	}
+	var samplers []systemSampler
+	for _, c := range constructors {
+		sampler, err := c.new()
+		if err != nil {
+			report(fmt.Errorf("system metrics: %s metrics are disabled: %w", c.name, err))
+			continue
+		}
+		samplers = append(samplers, sampler)
+	}
+	return samplers
+}
+
+// newCPUSampler reports the share of CPU time spent not idle since the
+// previous sample, from the cpu line of /proc/stat
+func newCPUSampler() (systemSampler, error) {
+	lastBusy, lastTotal, err := readCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	return func(values map[string]float64) error {
+		busy, total, err := readCPUTimes()
+		if err != nil {
+			return err
+		}
+		if total > lastTotal {
+			values[SystemMetricCPUUtilization] = 100 * float64(busy-lastBusy) / float64(total-lastTotal)
+		}
+		lastBusy, lastTotal = busy, total
+		return nil
+	}, nil
+}
+
+func readCPUTimes() (busy, total uint64, err error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("unexpected /proc/stat format")
+	}
+	var idle uint64
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unexpected /proc/stat format: %w", err)
+		}
+		total += v
+		// idle and iowait
+		if i == 3 || i == 4 {
+			idle += v
+		}
+	}
+	return total - idle, total, nil
+}
+
+// newMemorySampler reports the memory in use from /proc/meminfo, counting
+// memory the kernel could reclaim as available
+func newMemorySampler() (systemSampler, error) {
+	sampler := func(values map[string]float64) error {
+		info, err := readKeyValueFile("/proc/meminfo", "MemTotal:", "MemAvailable:")
+		if err != nil {
+			return err
+		}
+		total, available := info["MemTotal:"], info["MemAvailable:"]
+		if total == 0 {
+			return fmt.Errorf("unexpected /proc/meminfo format")
+		}
+		used := total - available
+		// /proc/meminfo reports kB
+		values[SystemMetricMemoryUsage] = float64(used) / 1024
+		values[SystemMetricMemoryUsagePercent] = 100 * float64(used) / float64(total)
+		return nil
+	}
+	return sampler, sampler(map[string]float64{})
+}
+
+// newDiskSampler reports the usage of the filesystem that holds path
+func newDiskSampler(path string) (systemSampler, error) {
+	sampler := func(values map[string]float64) error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return fmt.Errorf("failed to stat filesystem of %s: %w", path, err)
+		}
+		blockSize := float64(stat.Bsize)
+		total := float64(stat.Blocks) * blockSize
+		used := total - float64(stat.Bfree)*blockSize
+		available := float64(stat.Bavail) * blockSize
+		values[SystemMetricDiskUsage] = used / bytesPerMegabyte
+		values[SystemMetricDiskAvailable] = available / bytesPerMegabyte
+		if used+available > 0 {
+			values[SystemMetricDiskUsagePercent] = 100 * used / (used + available)
+		}
+		return nil
+	}
+	return sampler, sampler(map[string]float64{})
+}
+
+// newNetworkSampler reports the bytes received and transmitted on all
+// interfaces but loopback since the monitor started, like MLflow
+func newNetworkSampler() (systemSampler, error) {
+	startRx, startTx, err := readNetworkBytes()
+	if err != nil {
+		return nil, err
+	}
+	return func(values map[string]float64) error {
+		rx, tx, err := readNetworkBytes()
+		if err != nil {
+			return err
+		}
+		values[SystemMetricNetworkReceive] = float64(rx-min(rx, startRx)) / bytesPerMegabyte
+		values[SystemMetricNetworkTransmit] = float64(tx-min(tx, startTx)) / bytesPerMegabyte
+		return nil
+	}, nil
+}
+
+func readNetworkBytes() (rx, tx uint64, err error) {
+	f, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 9 {
+			return 0, 0, fmt.Errorf("unexpected /proc/net/dev format")
+		}
+		r, errRx := strconv.ParseUint(fields[0], 10, 64)
+		t, errTx := strconv.ParseUint(fields[8], 10, 64)
+		if errRx != nil || errTx != nil {
+			return 0, 0, fmt.Errorf("unexpected /proc/net/dev format")
+		}
+		rx += r
+		tx += t
+	}
+	return rx, tx, scanner.Err()
+}
+
+// newProcessMemorySampler reports the resident set size of this process from
+// /proc/self/statm
+func newProcessMemorySampler() (systemSampler, error) {
+	pageSize := float64(os.Getpagesize())
+	sampler := func(values map[string]float64) error {
+		data, err := os.ReadFile("/proc/self/statm")
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) < 2 {
+			return fmt.Errorf("unexpected /proc/self/statm format")
+		}
+		pages, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected /proc/self/statm format: %w", err)
+		}
+		values[SystemMetricProcessRSS] = float64(pages) * pageSize / bytesPerMegabyte
+		return nil
+	}
+	return sampler, sampler(map[string]float64{})
+}
+
+// newProcessIOSampler reports the bytes this process read from and wrote to
+// storage since the monitor started, from /proc/self/io
+func newProcessIOSampler() (systemSampler, error) {
+	start, err := readKeyValueFile("/proc/self/io", "read_bytes:", "write_bytes:")
+	if err != nil {
+		return nil, err
+	}
+	return func(values map[string]float64) error {
+		io, err := readKeyValueFile("/proc/self/io", "read_bytes:", "write_bytes:")
+		if err != nil {
+			return err
+		}
+		read, write := io["read_bytes:"], io["write_bytes:"]
+		values[SystemMetricProcessDiskRead] = float64(read-min(read, start["read_bytes:"])) / bytesPerMegabyte
+		values[SystemMetricProcessDiskWrite] = float64(write-min(write, start["write_bytes:"])) / bytesPerMegabyte
+		return nil
+	}, nil
+}
+
+// readKeyValueFile reads the numeric values of the given keys from a /proc
+// file with one "key: value" pair per line
+func readKeyValueFile(path string, keys ...string) (map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]uint64, len(keys))
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, key := range keys {
+			if fields[0] == key {
+				v, err := strconv.ParseUint(fields[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("unexpected %s format: %w", path, err)
+				}
+				values[key] = v
+			}
+		}
+	}
+	for _, key := range keys {
+		if _, ok := values[key]; !ok {
+			return nil, fmt.Errorf("%s has no %s", path, strings.TrimSuffix(key, ":"))
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package mlflow
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errSamplerUnavailable marks a metric source that cannot be read on this
+// platform
+var errSamplerUnavailable = errors.New("not available on this platform")
+
+// platformSamplers reports that system metrics other than the Go runtime's
+// are only read on Linux
+func platformSamplers(diskPath string, report func(error)) []systemSampler {
+	report(fmt.Errorf("system metrics: CPU, memory, disk and network metrics are disabled: %w", errSamplerUnavailable))
+	return nil
+}
@@ -0,0 +1,169 @@
+package features
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Typed accessors and enums step implementations
+
+func (tc *testContext) enumValidity(kind, value, validity string) error {
+	var err error
+	switch kind {
+	case "run status":
+		err = mlflow.RunStatus(value).Validate()
+	case "view type":
+		err = mlflow.ViewType(value).Validate()
+	case "lifecycle stage":
+		err = mlflow.LifecycleStage(value).Validate()
+	case "model stage":
+		err = mlflow.ModelStage(value).Validate()
+	case "model version status":
+		err = mlflow.ModelVersionStatus(value).Validate()
+	default:
+		return fmt.Errorf("unknown enum %q", kind)
+	}
+	if validity == "valid" && err != nil {
+		return fmt.Errorf("expected %s %q to be valid, got %w", kind, value, err)
+	}
+	if validity == "invalid" && err == nil {
+		return fmt.Errorf("expected %s %q to be invalid", kind, value)
+	}
+	return nil
+}
+
+// accessorRun returns the run the cached or fetched accessor steps inspect
+func (tc *testContext) accessorRun(source string) (mlflow.Run, error) {
+	if source == "cached" {
+		run, ok := tc.cachedValue.(*mlflow.Run)
+		if !ok {
+			return mlflow.Run{}, fmt.Errorf("no cached run")
+		}
+		return *run, nil
+	}
+	resp, err := tc.client.GetRun(tc.runID)
+	if err != nil {
+		return mlflow.Run{}, err
+	}
+	return resp.Run, nil
+}
+
+func (tc *testContext) runHasParamValue(source, key, value string) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	got, ok := run.Param(key)
+	if !ok || got != value {
+		return fmt.Errorf("expected param %s = %q, got %q (present: %v)", key, value, got, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) runHasMetricValue(source, key string, value float64) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	got, ok := run.Metric(key)
+	if !ok || got != value {
+		return fmt.Errorf("expected metric %s = %v, got %v (present: %v)", key, value, got, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) runHasTagValue(source, key, value string) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	got, ok := run.Tag(key)
+	if !ok || got != value {
+		return fmt.Errorf("expected tag %s = %q, got %q (present: %v)", key, value, got, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) runLacksParam(source, key string) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	if got, ok := run.Param(key); ok {
+		return fmt.Errorf("expected no param %s, got %q", key, got)
+	}
+	return nil
+}
+
+func (tc *testContext) runMapSizes(source string, params, metrics, tags int) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	if len(run.ParamMap()) != params || len(run.MetricMap()) != metrics || len(run.TagMap()) != tags {
+		return fmt.Errorf("expected %d params, %d metrics and %d tags, got %v, %v and %v",
+			params, metrics, tags, run.ParamMap(), run.MetricMap(), run.TagMap())
+	}
+	return nil
+}
+
+func (tc *testContext) runStartedAndRanFor(source string, startMillis int64, duration string) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	want, err := time.ParseDuration(duration)
+	if err != nil {
+		return err
+	}
+	if !run.StartedAt().Equal(time.UnixMilli(startMillis)) {
+		return fmt.Errorf("expected the run to start at %v, got %v", time.UnixMilli(startMillis), run.StartedAt())
+	}
+	if run.Duration() != want {
+		return fmt.Errorf("expected the run to run for %v, got %v", want, run.Duration())
+	}
+	return nil
+}
+
+func (tc *testContext) runTerminal(source, not string) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	if run.IsTerminal() != (not == "") {
+		return fmt.Errorf("expected IsTerminal to be %v for status %s", not == "", run.Info.Status)
+	}
+	return nil
+}
+
+func (tc *testContext) runNotEnded(source string) error {
+	run, err := tc.accessorRun(source)
+	if err != nil {
+		return err
+	}
+	if !run.EndedAt().IsZero() {
+		return fmt.Errorf("expected no end time, got %v", run.EndedAt())
+	}
+	if run.Duration() <= 0 {
+		return fmt.Errorf("expected a running run to have a positive duration, got %v", run.Duration())
+	}
+	return nil
+}
+
+func (tc *testContext) attemptSearchRunsWithViewType(viewType string) error {
+	_, tc.lastError = tc.client.SearchRuns(mlflow.SearchRunsRequest{
+		ExperimentIDs: []string{"0"},
+		RunViewType:   mlflow.ViewType(viewType),
+	})
+	return nil
+}
+
+func (tc *testContext) attemptSetRunStatus(status string) error {
+	_, tc.lastError = tc.client.UpdateRun(mlflow.UpdateRunRequest{
+		RunID:  "0",
+		Status: mlflow.RunStatus(status),
+	})
+	return nil
+}
@@ -0,0 +1,194 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Active run step implementations
+
+var mlflowEnvVars = []string{
+	mlflow.EnvTrackingURI,
+	mlflow.EnvTrackingToken,
+	mlflow.EnvRunID,
+	mlflow.EnvExperimentID,
+	mlflow.EnvExperimentName,
+}
+
+// setEnv sets an environment variable for the rest of the scenario; cleanup
+// restores its original value
+func (tc *testContext) setEnv(key, value string, unset bool) {
+	if tc.savedEnv == nil {
+		tc.savedEnv = map[string]*string{}
+	}
+	if _, saved := tc.savedEnv[key]; !saved {
+		if original, ok := os.LookupEnv(key); ok {
+			tc.savedEnv[key] = &original
+		} else {
+			tc.savedEnv[key] = nil
+		}
+	}
+	if unset {
+		_ = os.Unsetenv(key)
+	} else {
+		_ = os.Setenv(key, value)
+	}
+}
+
+func (tc *testContext) restoreEnv() {
+	for key, value := range tc.savedEnv {
+		if value == nil {
+			_ = os.Unsetenv(key)
+		} else {
+			_ = os.Setenv(key, *value)
+		}
+	}
+	tc.savedEnv = nil
+}
+
+func (tc *testContext) noMLflowEnv() error {
+	for _, key := range mlflowEnvVars {
+		tc.setEnv(key, "", true)
+	}
+	return nil
+}
+
+func (tc *testContext) envSetToRun() error {
+	tc.setEnv(mlflow.EnvRunID, tc.runID, false)
+	return nil
+}
+
+func (tc *testContext) envSetToExperimentName() error {
+	tc.setEnv(mlflow.EnvExperimentName, tc.experimentName, false)
+	return nil
+}
+
+func (tc *testContext) envSetToExperimentID() error {
+	tc.setEnv(mlflow.EnvExperimentID, tc.experimentID, false)
+	return nil
+}
+
+func (tc *testContext) envExperimentNameSetTo(name string) error {
+	tc.setEnv(mlflow.EnvExperimentName, name, false)
+	return nil
+}
+
+func (tc *testContext) startActiveRun(experimentID string) error {
+	run, err := tc.client.StartRun(mlflow.CreateRunRequest{ExperimentID: experimentID, RunName: "active-run"})
+	if err != nil {
+		return err
+	}
+	tc.activeRun = run
+	tc.createdResources = append(tc.createdResources, resource{Type: "run", ID: run.RunID})
+	return nil
+}
+
+func (tc *testContext) startRunInExperiment() error {
+	return tc.startActiveRun(tc.experimentID)
+}
+
+func (tc *testContext) startRunWithoutExperiment() error {
+	return tc.startActiveRun("")
+}
+
+func (tc *testContext) attemptStartRunWithoutExperiment() error {
+	_, tc.lastError = tc.client.StartRun(mlflow.CreateRunRequest{})
+	return nil
+}
+
+// evaluateBatch stands in for library code that only has a context
+func evaluateBatch(ctx context.Context, key string, value float64) error {
+	run, ok := mlflow.RunFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no active run in the context")
+	}
+	return run.LogMetric(key, value, 0)
+}
+
+func (tc *testContext) libraryLogsMetricToContextRun(key string, value float64) error {
+	ctx := mlflow.ContextWithRun(context.Background(), tc.activeRun)
+	return evaluateBatch(ctx, key, value)
+}
+
+func (tc *testContext) contextWithoutRunHasNoActiveRun() error {
+	if run, ok := mlflow.RunFromContext(context.Background()); ok {
+		return fmt.Errorf("expected no active run, got %s", run.RunID)
+	}
+	if _, ok := mlflow.RunFromContext(mlflow.ContextWithRun(context.Background(), nil)); ok {
+		return fmt.Errorf("expected a nil run not to be an active run")
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunHasMetric(key string, value float64) error {
+	run, err := tc.activeRun.Run()
+	if err != nil {
+		return err
+	}
+	if got, ok := run.Metric(key); !ok || got != value {
+		return fmt.Errorf("expected metric %s = %v, got %v (present: %v)", key, value, got, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunHasParam(key, value string) error {
+	run, err := tc.activeRun.Run()
+	if err != nil {
+		return err
+	}
+	if got, ok := run.Param(key); !ok || got != value {
+		return fmt.Errorf("expected param %s = %q, got %q (present: %v)", key, value, got, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunIsExistingRun() error {
+	if tc.activeRun.RunID != tc.runID {
+		return fmt.Errorf("expected to attach to run %s, got %s", tc.runID, tc.activeRun.RunID)
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunInExperiment() error {
+	if tc.activeRun.ExperimentID != tc.experimentID {
+		return fmt.Errorf("expected the run to be in experiment %s, got %s", tc.experimentID, tc.activeRun.ExperimentID)
+	}
+	return nil
+}
+
+func (tc *testContext) experimentHasRuns(count int) error {
+	resp, err := tc.client.SearchRuns(mlflow.SearchRunsRequest{ExperimentIDs: []string{tc.experimentID}})
+	if err != nil {
+		return err
+	}
+	if len(resp.Runs) != count {
+		return fmt.Errorf("expected %d runs in the experiment, got %d", count, len(resp.Runs))
+	}
+	return nil
+}
+
+// subprocessLogsParam runs what a subprocess started with the active run's
+// environment would do: create a client from the environment, attach to the
+// run and log to it
+func (tc *testContext) subprocessLogsParam(key, value string) error {
+	for _, kv := range tc.activeRun.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		tc.setEnv(k, v, false)
+	}
+	client, err := mlflow.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+	run, err := client.StartRun(mlflow.CreateRunRequest{RunName: "subprocess-run"})
+	if err != nil {
+		return err
+	}
+	if run.RunID != tc.activeRun.RunID {
+		return fmt.Errorf("expected the subprocess to attach to run %s, got %s", tc.activeRun.RunID, run.RunID)
+	}
+	return run.LogParam(key, value)
+}
@@ -0,0 +1,253 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Content-addressed artifact step implementations
+
+// requestCounter is a server that counts the requests it gets
+type requestCounter struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests int
+}
+
+func (tc *testContext) logLocalDirectoryContentAddressed(artifactPath string) error {
+	client, err := tc.proxiedClient()
+	if err != nil {
+		return err
+	}
+	return client.LogArtifactsContentAddressed(tc.runID, tc.uploadDir, artifactPath)
+}
+
+func (tc *testContext) logLocalFileContentAddressed(file, artifactPath string) error {
+	client, err := tc.proxiedClient()
+	if err != nil {
+		return err
+	}
+	return client.LogArtifactContentAddressed(tc.runID, filepath.Join(tc.uploadDir, filepath.FromSlash(file)), artifactPath)
+}
+
+// secondRunLogsContentAddressed logs the local directory from another run
+// of the experiment, leaving the scenario's run as it is
+func (tc *testContext) secondRunLogsContentAddressed(artifactPath string) error {
+	firstRunID := tc.runID
+	if err := tc.createRun(); err != nil {
+		return err
+	}
+	tc.secondRunID = tc.runID
+	err := tc.logLocalDirectoryContentAddressed(artifactPath)
+	tc.runID = firstRunID
+	return err
+}
+
+// otherExperimentRunLogsContentAddressed logs the local directory from a
+// run of a new experiment with the experiment's artifact location
+func (tc *testContext) otherExperimentRunLogsContentAddressed(artifactPath string) error {
+	exp, err := tc.client.GetExperiment(tc.experimentID)
+	if err != nil {
+		return err
+	}
+	name := "shared-store-" + uuid.NewString()
+	created, err := tc.client.CreateExperiment(mlflow.CreateExperimentRequest{Name: name, ArtifactLocation: exp.Experiment.ArtifactLocation})
+	if err != nil {
+		return err
+	}
+	tc.createdResources = append(tc.createdResources, resource{Type: "experiment", ID: created.ExperimentID, Name: name})
+	firstExperimentID, firstRunID := tc.experimentID, tc.runID
+	defer func() { tc.experimentID, tc.runID = firstExperimentID, firstRunID }()
+	tc.experimentID = created.ExperimentID
+	if err := tc.createRun(); err != nil {
+		return err
+	}
+	tc.secondRunID = tc.runID
+	return tc.logLocalDirectoryContentAddressed(artifactPath)
+}
+
+func (tc *testContext) secondRunLogsFileContentAddressed(file, artifactPath string) error {
+	firstRunID := tc.runID
+	tc.runID = tc.secondRunID
+	err := tc.logLocalFileContentAddressed(file, artifactPath)
+	tc.runID = firstRunID
+	return err
+}
+
+func (tc *testContext) deleteSecondRunArtifacts(artifactPath string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.secondRunID)
+	if err != nil {
+		return err
+	}
+	return repo.Delete(context.Background(), artifactPath)
+}
+
+// experimentBlobStore returns the repository of the experiment's artifact
+// location
+func (tc *testContext) experimentBlobStore() (mlflow.ArtifactRepository, error) {
+	exp, err := tc.client.GetExperiment(tc.experimentID)
+	if err != nil {
+		return nil, err
+	}
+	return tc.client.ArtifactRepository(exp.Experiment.ArtifactLocation)
+}
+
+func (tc *testContext) blobStoreShouldHold(count int) error {
+	store, err := tc.experimentBlobStore()
+	if err != nil {
+		return err
+	}
+	blobs := 0
+	dirs := []string{path.Join(mlflow.ArtifactBlobDir, "sha256")}
+	for len(dirs) > 0 {
+		files, err := store.List(context.Background(), dirs[0])
+		if err != nil {
+			return err
+		}
+		dirs = dirs[1:]
+		for _, file := range files {
+			if file.IsDir {
+				dirs = append(dirs, file.Path)
+			} else {
+				blobs++
+			}
+		}
+	}
+	if blobs != count {
+		return fmt.Errorf("expected %d blobs in the experiment's blob store, got %d", count, blobs)
+	}
+	return nil
+}
+
+func (tc *testContext) trackingServerShouldHaveReceivedBlobs(count int) error {
+	tc.fsProxy.mu.Lock()
+	defer tc.fsProxy.mu.Unlock()
+	if tc.fsProxy.blobUploads != count {
+		return fmt.Errorf("expected %d blob uploads, got %d", count, tc.fsProxy.blobUploads)
+	}
+	return nil
+}
+
+// runShouldStorePointerFiles checks that the run stores each of files under
+// dir as a pointer file and not as the file itself
+func (tc *testContext) runShouldStorePointerFiles(files, dir string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	for _, file := range strings.Split(files, ", ") {
+		artifactPath := path.Join(dir, file)
+		if _, err := repo.Stat(context.Background(), artifactPath+mlflow.ArtifactPointerSuffix); err != nil {
+			return err
+		}
+		if _, err := repo.Stat(context.Background(), artifactPath); !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("expected only a pointer file for %s, got %v", artifactPath, err)
+		}
+	}
+	return nil
+}
+
+// overwriteBlob replaces the content of the blob an artifact's pointer file
+// refers to
+func (tc *testContext) overwriteBlob(artifactPath, content string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	body, err := repo.Download(context.Background(), artifactPath+mlflow.ArtifactPointerSuffix)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	var pointer struct {
+		SHA256 string `json:"sha256"`
+	}
+	if err := json.NewDecoder(body).Decode(&pointer); err != nil {
+		return err
+	}
+	store, err := tc.experimentBlobStore()
+	if err != nil {
+		return err
+	}
+	blob := path.Join(mlflow.ArtifactBlobDir, "sha256", pointer.SHA256[:2], pointer.SHA256)
+	return store.Upload(context.Background(), blob, bytes.NewReader([]byte(content)), int64(len(content)))
+}
+
+// pointerToOtherHost replaces the pointer file of an artifact with one
+// naming a blob store on another host, which holds a blob of the content
+// and counts the requests it gets
+func (tc *testContext) pointerToOtherHost(artifactPath, content string) error {
+	tc.otherHost = &requestCounter{}
+	tc.otherHost.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc.otherHost.mu.Lock()
+		tc.otherHost.requests++
+		tc.otherHost.mu.Unlock()
+		_, _ = io.WriteString(w, content)
+	}))
+	digest := sha256.Sum256([]byte(content))
+	data, err := json.Marshal(map[string]any{
+		"store":  tc.otherHost.server.URL + "/api/2.0/mlflow-artifacts/artifacts/stolen",
+		"sha256": hex.EncodeToString(digest[:]),
+		"size":   len(content),
+	})
+	if err != nil {
+		return err
+	}
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	return repo.Upload(context.Background(), artifactPath+mlflow.ArtifactPointerSuffix, bytes.NewReader(data), int64(len(data)))
+}
+
+func (tc *testContext) otherHostShouldHaveReceivedNoRequests() error {
+	tc.otherHost.mu.Lock()
+	defer tc.otherHost.mu.Unlock()
+	if tc.otherHost.requests != 0 {
+		return fmt.Errorf("expected no requests to the other host, got %d", tc.otherHost.requests)
+	}
+	return nil
+}
+
+func (tc *testContext) collectArtifactBlobs(dryRun string) error {
+	result, err := tc.client.CollectArtifactBlobs(context.Background(), tc.experimentID, mlflow.ArtifactBlobGCOptions{DryRun: dryRun != ""})
+	tc.blobGCResult = result
+	return err
+}
+
+func (tc *testContext) collectionShouldHaveFound(deleted, bytes, referenced int) error {
+	result := tc.blobGCResult
+	if len(result.Deleted) != deleted || result.DeletedBytes != int64(bytes) || result.Referenced != referenced {
+		return fmt.Errorf("expected %d unreferenced blobs of %d bytes and %d referenced, got %d of %d and %d",
+			deleted, bytes, referenced, len(result.Deleted), result.DeletedBytes, result.Referenced)
+	}
+	return nil
+}
+
+func (tc *testContext) readingSecondRunArtifactShouldFail(artifactPath, text string) error {
+	body, err := tc.client.OpenArtifact(tc.secondRunID, artifactPath)
+	if err == nil {
+		_, err = io.ReadAll(body)
+		body.Close()
+	}
+	if err == nil || !strings.Contains(err.Error(), text) {
+		return fmt.Errorf("expected reading %s of the second run to fail with %q, got %v", artifactPath, text, err)
+	}
+	return nil
+}
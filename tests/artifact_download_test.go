@@ -0,0 +1,118 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Recursive artifact download step implementations
+
+// downloadRunArtifactsWith downloads the run's artifacts through the paging
+// proxy into the download directory, reusing it between downloads
+func (tc *testContext) downloadRunArtifactsWith(ctx context.Context, artifactPath string, concurrency int) error {
+	client, err := tc.proxiedClient()
+	if err != nil {
+		return err
+	}
+	if tc.downloadDir == "" {
+		dir, err := tc.newTempDir("mlflow-download-")
+		if err != nil {
+			return err
+		}
+		tc.downloadDir = dir
+	}
+	tc.downloadProgress = 0
+	manifest, err := client.DownloadRunArtifacts(ctx, tc.runID, artifactPath, tc.downloadDir, mlflow.DownloadRunArtifactsOptions{
+		Concurrency: concurrency,
+		Progress:    func(mlflow.ArtifactManifestFile) { tc.downloadProgress++ },
+	})
+	tc.artifactManifest = manifest
+	return err
+}
+
+func (tc *testContext) downloadRunArtifacts(artifactPath string, concurrency int) error {
+	return tc.downloadRunArtifactsWith(context.Background(), artifactPath, concurrency)
+}
+
+func (tc *testContext) attemptDownloadRunArtifacts(artifactPath string) error {
+	tc.lastError = tc.downloadRunArtifactsWith(context.Background(), artifactPath, 0)
+	return nil
+}
+
+func (tc *testContext) attemptDownloadRunArtifactsCancelled(artifactPath string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tc.lastError = tc.downloadRunArtifactsWith(ctx, artifactPath, 0)
+	return nil
+}
+
+func (tc *testContext) trackingServerReportsSize(artifactPath string, size int) error {
+	if _, err := tc.proxiedClient(); err != nil {
+		return err
+	}
+	tc.fsProxy.mu.Lock()
+	defer tc.fsProxy.mu.Unlock()
+	tc.fsProxy.sizes[artifactPath] = int64(size)
+	return nil
+}
+
+func (tc *testContext) changeDownloadedFile(file, content string) error {
+	return os.WriteFile(filepath.Join(tc.downloadDir, filepath.FromSlash(file)), []byte(content), 0o644)
+}
+
+// manifestShouldList compares the manifest's files, formatted as
+// "path (size)" or "path (size, skipped)", with listing
+func (tc *testContext) manifestShouldList(listing string) error {
+	var got []string
+	for _, file := range tc.artifactManifest.Files {
+		if want := filepath.Join(tc.downloadDir, filepath.FromSlash(file.Path)); file.LocalPath != want {
+			return fmt.Errorf("expected %s at %s, got %s", file.Path, want, file.LocalPath)
+		}
+		if file.Skipped {
+			got = append(got, fmt.Sprintf("%s (%d, skipped)", file.Path, file.Size))
+		} else {
+			got = append(got, fmt.Sprintf("%s (%d)", file.Path, file.Size))
+		}
+	}
+	if strings.Join(got, ", ") != listing {
+		return fmt.Errorf("expected the manifest to list %q, got %q", listing, strings.Join(got, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) manifestShouldCount(files, bytes int) error {
+	gotFiles, gotBytes := tc.artifactManifest.Downloaded()
+	if gotFiles != files || gotBytes != int64(bytes) {
+		return fmt.Errorf("expected %d files of %d bytes downloaded, got %d of %d", files, bytes, gotFiles, gotBytes)
+	}
+	if tc.downloadProgress != len(tc.artifactManifest.Files) {
+		return fmt.Errorf("expected progress for %d files, got %d", len(tc.artifactManifest.Files), tc.downloadProgress)
+	}
+	return nil
+}
+
+func (tc *testContext) manifestLocalPathShouldBe(localPath string) error {
+	want := filepath.Join(tc.downloadDir, filepath.FromSlash(localPath))
+	if tc.artifactManifest.LocalPath != want {
+		return fmt.Errorf("expected the manifest's local path to be %s, got %s", want, tc.artifactManifest.LocalPath)
+	}
+	return nil
+}
+
+func (tc *testContext) downloadDirShouldHaveNoFile(file string) error {
+	entries, err := os.ReadDir(filepath.Dir(filepath.Join(tc.downloadDir, filepath.FromSlash(file))))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), filepath.Base(file)) {
+			return fmt.Errorf("expected no %s in the download directory, found %s", file, entry.Name())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Artifact encryption step implementations
+
+// fakeKMS is an in-memory KMS with an AES-256-GCM key per key ID
+type fakeKMS struct {
+	mu       sync.Mutex
+	keys     map[string]cipher.AEAD
+	disabled map[string]bool
+}
+
+func newFakeKMS() *fakeKMS {
+	return &fakeKMS{keys: map[string]cipher.AEAD{}, disabled: map[string]bool{}}
+}
+
+func (k *fakeKMS) key(keyID string) (cipher.AEAD, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.disabled[keyID] {
+		return nil, fmt.Errorf("KMS key %s is disabled", keyID)
+	}
+	if aead, ok := k.keys[keyID]; ok {
+		return aead, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	k.keys[keyID] = aead
+	return aead, nil
+}
+
+func (k *fakeKMS) Encrypt(_ context.Context, keyID string, plaintext, associatedData []byte) ([]byte, error) {
+	aead, err := k.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func (k *fakeKMS) Decrypt(_ context.Context, keyID string, ciphertext, associatedData []byte) ([]byte, error) {
+	aead, err := k.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], associatedData)
+}
+
+// newKeyFile writes a random base64 key to a new key file
+func (tc *testContext) newKeyFile() (string, error) {
+	dir, err := tc.newTempDir("mlflow-keys-")
+	if err != nil {
+		return "", err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, "artifact.key")
+	return file, os.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600)
+}
+
+func (tc *testContext) encryptionUsesKeyFile() error {
+	file, err := tc.newKeyFile()
+	if err != nil {
+		return err
+	}
+	tc.keyFiles = append(tc.keyFiles, file)
+	tc.client.ArtifactKeys, err = mlflow.KeyFileProvider(file)
+	return err
+}
+
+// encryptionRotatesKeyFile makes a new key file current, keeping the
+// previous ones for unwrapping
+func (tc *testContext) encryptionRotatesKeyFile() error {
+	file, err := tc.newKeyFile()
+	if err != nil {
+		return err
+	}
+	previous := tc.keyFiles
+	tc.keyFiles = append([]string{file}, previous...)
+	tc.client.ArtifactKeys, err = mlflow.KeyFileProvider(file, previous...)
+	return err
+}
+
+func (tc *testContext) encryptionUsesOnlyNewestKeyFile() error {
+	var err error
+	tc.client.ArtifactKeys, err = mlflow.KeyFileProvider(tc.keyFiles[0])
+	return err
+}
+
+func (tc *testContext) encryptionUsesEnvKey(name string) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	tc.setEnv(name, base64.StdEncoding.EncodeToString(key), false)
+	var err error
+	tc.client.ArtifactKeys, err = mlflow.EnvKeyProvider(name)
+	return err
+}
+
+func (tc *testContext) encryptionUsesKMSKey(keyID string) error {
+	if tc.kms == nil {
+		tc.kms = newFakeKMS()
+	}
+	tc.client.ArtifactKeys = mlflow.NewKMSKeyProvider(tc.kms, keyID)
+	return nil
+}
+
+func (tc *testContext) kmsKeyDisabled(keyID string) error {
+	tc.kms.mu.Lock()
+	defer tc.kms.mu.Unlock()
+	tc.kms.disabled[keyID] = true
+	return nil
+}
+
+func (tc *testContext) encryptionTurnedOff() error {
+	tc.client.ArtifactKeys = nil
+	return nil
+}
+
+func (tc *testContext) localFileOfRandomBytes(file string, size int) error {
+	if tc.uploadDir == "" {
+		dir, err := tc.newTempDir("mlflow-upload-")
+		if err != nil {
+			return err
+		}
+		tc.uploadDir = dir
+	}
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(tc.uploadDir, filepath.FromSlash(file)), data, 0o644)
+}
+
+func (tc *testContext) logLocalDirectoryEncrypted(artifactPath string) error {
+	return tc.client.LogArtifactsEncrypted(tc.runID, tc.uploadDir, artifactPath)
+}
+
+func (tc *testContext) logLocalFileEncrypted(file, artifactPath string) error {
+	return tc.client.LogArtifactEncrypted(tc.runID, filepath.Join(tc.uploadDir, filepath.FromSlash(file)), artifactPath)
+}
+
+func (tc *testContext) attemptLogLocalDirectoryEncrypted(artifactPath string) error {
+	tc.lastError = tc.logLocalDirectoryEncrypted(artifactPath)
+	return nil
+}
+
+func (tc *testContext) artifactShouldMatchLocalFile(artifactPath, file string) error {
+	want, err := os.ReadFile(filepath.Join(tc.uploadDir, filepath.FromSlash(file)))
+	if err != nil {
+		return err
+	}
+	body, err := tc.client.OpenArtifact(tc.runID, artifactPath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	got, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("expected %s to match %s, got %d bytes for %d", artifactPath, file, len(got), len(want))
+	}
+	return nil
+}
+
+// storedCiphertext reads what the run stores for an encrypted artifact
+func (tc *testContext) storedCiphertext(artifactPath string) (mlflow.ArtifactRepository, []byte, error) {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := repo.Download(context.Background(), artifactPath+mlflow.EncryptedArtifactSuffix)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	return repo, data, err
+}
+
+func (tc *testContext) runShouldStoreEncrypted(artifactPath, plaintext string) error {
+	repo, ciphertext, err := tc.storedCiphertext(artifactPath)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(ciphertext, []byte(plaintext)) {
+		return fmt.Errorf("expected the stored %s not to contain %q", artifactPath, plaintext)
+	}
+	if _, err := repo.Stat(context.Background(), artifactPath+mlflow.EncryptionSidecarSuffix); err != nil {
+		return err
+	}
+	if _, err := repo.Stat(context.Background(), artifactPath); err == nil {
+		return fmt.Errorf("expected no plaintext %s in the run's artifacts", artifactPath)
+	}
+	return nil
+}
+
+// tamperWithCiphertext flips a byte of an encrypted artifact, or drops its
+// last byte
+func (tc *testContext) tamperWithCiphertext(artifactPath, how string) error {
+	repo, ciphertext, err := tc.storedCiphertext(artifactPath)
+	if err != nil {
+		return err
+	}
+	if how == "altered" {
+		ciphertext[len(ciphertext)/2] ^= 1
+	} else {
+		ciphertext = ciphertext[:len(ciphertext)-1]
+	}
+	return repo.Upload(context.Background(), artifactPath+mlflow.EncryptedArtifactSuffix, bytes.NewReader(ciphertext), int64(len(ciphertext)))
+}
+
+// rewriteSidecar changes a field of an encrypted artifact's sidecar
+func (tc *testContext) rewriteSidecar(artifactPath, field string, value any) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	body, err := repo.Download(context.Background(), artifactPath+mlflow.EncryptionSidecarSuffix)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	sidecar := map[string]any{}
+	if err := json.NewDecoder(body).Decode(&sidecar); err != nil {
+		return err
+	}
+	sidecar[field] = value
+	data, err := json.Marshal(sidecar)
+	if err != nil {
+		return err
+	}
+	return repo.Upload(context.Background(), artifactPath+mlflow.EncryptionSidecarSuffix, bytes.NewReader(data), int64(len(data)))
+}
+
+func (tc *testContext) sidecarSegmentSize(artifactPath string, size int64) error {
+	return tc.rewriteSidecar(artifactPath, "segment_size", size)
+}
+
+// copyEncryptedArtifact copies the ciphertext and sidecar of an encrypted
+// artifact to another path of the run, or of a new second run
+func (tc *testContext) copyEncryptedArtifact(artifactPath, target, secondRun string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	targetRepo := repo
+	if secondRun != "" {
+		firstRunID := tc.runID
+		if err := tc.createRun(); err != nil {
+			return err
+		}
+		tc.secondRunID, tc.runID = tc.runID, firstRunID
+		if targetRepo, err = tc.client.RunArtifactRepository(tc.secondRunID); err != nil {
+			return err
+		}
+	}
+	return copyStoredFiles(repo, targetRepo, artifactPath, target, mlflow.EncryptedArtifactSuffix, mlflow.EncryptionSidecarSuffix)
+}
+
+// copyStoredFiles copies the stored files of an artifact with the given
+// suffixes from one repository to another
+func copyStoredFiles(repo, targetRepo mlflow.ArtifactRepository, artifactPath, target string, suffixes ...string) error {
+	for _, suffix := range suffixes {
+		body, err := repo.Download(context.Background(), artifactPath+suffix)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		if err := targetRepo.Upload(context.Background(), target+suffix, bytes.NewReader(data), int64(len(data))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interruptedUpload leaves what an encrypted upload to target that stopped
+// part way would, by copying only the ciphertext or only the sidecar of an
+// encrypted artifact
+func (tc *testContext) interruptedUpload(part, artifactPath, target string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	suffix := mlflow.EncryptedArtifactSuffix
+	if part == "sidecar" {
+		suffix = mlflow.EncryptionSidecarSuffix
+	}
+	return copyStoredFiles(repo, repo, artifactPath, target, suffix)
+}
+
+func (tc *testContext) noteCiphertext(artifactPath string) error {
+	_, ciphertext, err := tc.storedCiphertext(artifactPath)
+	tc.notedCiphertext = ciphertext
+	return err
+}
+
+func (tc *testContext) ciphertextShouldBeUnchanged(artifactPath string) error {
+	_, ciphertext, err := tc.storedCiphertext(artifactPath)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(ciphertext, tc.notedCiphertext) {
+		return fmt.Errorf("expected the stored %s to be unchanged", artifactPath)
+	}
+	return nil
+}
+
+func (tc *testContext) rotateArtifactKeys() error {
+	var err error
+	tc.rotatedKeys, err = tc.client.RotateArtifactKeys(context.Background(), tc.runID)
+	return err
+}
+
+func (tc *testContext) rotatedKeysShouldBe(count int) error {
+	if tc.rotatedKeys != count {
+		return fmt.Errorf("expected %d re-wrapped artifacts, got %d", count, tc.rotatedKeys)
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package features
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Artifact file system step implementations
+
+// artifactPagingProxy sits between a client and the tracking server,
+// splitting artifact listings into pages, misreporting the sizes of files in
+// sizes and counting listings, downloads and content-addressed blob uploads
+type artifactPagingProxy struct {
+	server   *httptest.Server
+	upstream string
+	proxy    *httputil.ReverseProxy
+
+	mu          sync.Mutex
+	pageSize    int
+	sizes       map[string]int64
+	lists       int
+	downloads   int
+	blobUploads int
+}
+
+func newArtifactPagingProxy(upstream string, pageSize int) (*artifactPagingProxy, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+	p := &artifactPagingProxy{upstream: upstream, proxy: httputil.NewSingleHostReverseProxy(target), pageSize: pageSize, sizes: map[string]int64{}}
+	p.server = httptest.NewServer(http.HandlerFunc(p.serve))
+	return p, nil
+}
+
+func (p *artifactPagingProxy) serve(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/api/2.0/mlflow/artifacts/list":
+		p.list(w, r)
+		return
+	case strings.HasPrefix(r.URL.Path, "/api/2.0/mlflow-artifacts/artifacts/") && r.Method == http.MethodGet:
+		p.mu.Lock()
+		p.downloads++
+		p.mu.Unlock()
+	case strings.Contains(r.URL.Path, "/"+mlflow.ArtifactBlobDir+"/") && r.Method == http.MethodPut:
+		p.mu.Lock()
+		p.blobUploads++
+		p.mu.Unlock()
+	}
+	p.proxy.ServeHTTP(w, r)
+}
+
+// list fetches the whole listing and returns the page the token asks for
+func (p *artifactPagingProxy) list(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.lists++
+	pageSize := p.pageSize
+	sizes := maps.Clone(p.sizes)
+	p.mu.Unlock()
+
+	var req mlflow.ListArtifactsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		stubError(w, http.StatusBadRequest, "INVALID_PARAMETER_VALUE", err.Error())
+		return
+	}
+	offset, _ := strconv.Atoi(req.PageToken)
+	req.PageToken = ""
+	body, _ := json.Marshal(req)
+	upstreamReq, err := http.NewRequest(r.Method, p.upstream+r.URL.Path, bytes.NewReader(body))
+	if err != nil {
+		stubError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	upstreamReq.Header = r.Header.Clone()
+	resp, err := http.DefaultClient.Do(upstreamReq)
+	if err != nil {
+		stubError(w, http.StatusBadGateway, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	var listing mlflow.ListArtifactsResponse
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&listing) != nil {
+		w.WriteHeader(resp.StatusCode)
+		return
+	}
+	for i, file := range listing.Files {
+		if size, ok := sizes[file.Path]; ok {
+			listing.Files[i].FileSize = size
+		}
+	}
+	if pageSize > 0 {
+		files := listing.Files[min(offset, len(listing.Files)):]
+		listing.NextPageToken = ""
+		if len(files) > pageSize {
+			files = files[:pageSize]
+			listing.NextPageToken = strconv.Itoa(offset + pageSize)
+		}
+		listing.Files = files
+	}
+	writeStubJSON(w, listing)
+}
+
+// proxiedClient returns a client of the tracking server that goes through
+// the paging proxy, starting one without paging if there is none
+func (tc *testContext) proxiedClient() (*mlflow.Client, error) {
+	if tc.fsProxy == nil {
+		proxy, err := newArtifactPagingProxy(tc.client.BaseURL, 0)
+		if err != nil {
+			return nil, err
+		}
+		tc.fsProxy = proxy
+	}
+	client := mlflow.NewClient(tc.fsProxy.server.URL)
+	client.ArtifactKeys = tc.client.ArtifactKeys
+	return client, nil
+}
+
+// artifactFSFor returns a file system of the run's artifacts through the
+// paging proxy, with a cache if cached is set
+func (tc *testContext) artifactFSFor(cached bool) error {
+	client, err := tc.proxiedClient()
+	if err != nil {
+		return err
+	}
+	opts := mlflow.ArtifactFSOptions{}
+	if cached {
+		if tc.fsCacheDir == "" {
+			dir, err := tc.newTempDir("mlflow-artifact-cache-")
+			if err != nil {
+				return err
+			}
+			tc.fsCacheDir = dir
+		}
+		opts.CacheDir = tc.fsCacheDir
+	}
+	if cached {
+		tc.artifactFS = mlflow.NewArtifactFS(client, tc.runID, opts)
+	} else {
+		tc.artifactFS = client.ArtifactFS(tc.runID)
+	}
+	return nil
+}
+
+func (tc *testContext) artifactsListedInPages(pageSize int) error {
+	proxy, err := newArtifactPagingProxy(tc.client.BaseURL, pageSize)
+	tc.fsProxy = proxy
+	return err
+}
+
+func (tc *testContext) openArtifactFS() error {
+	return tc.artifactFSFor(false)
+}
+
+func (tc *testContext) openCachedArtifactFS() error {
+	return tc.artifactFSFor(true)
+}
+
+// describeFSEntry formats a walked entry like repositoryListingShouldBe
+func describeFSEntry(name string, entry fs.DirEntry) (string, error) {
+	if entry.IsDir() {
+		return name + "/", nil
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%d)", name, info.Size()), nil
+}
+
+func (tc *testContext) walkingArtifactFSShouldGive(root, listing string) error {
+	var got []string
+	err := fs.WalkDir(tc.artifactFS, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root {
+			return nil
+		}
+		described, err := describeFSEntry(name, entry)
+		got = append(got, described)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if strings.Join(got, ", ") != listing {
+		return fmt.Errorf("expected walking %s to give %q, got %q", root, listing, strings.Join(got, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) readingArtifactFSShouldGive(name, content string) error {
+	data, err := fs.ReadFile(tc.artifactFS, name)
+	if err != nil {
+		return err
+	}
+	if string(data) != content {
+		return fmt.Errorf("expected %s to contain %q, got %q", name, content, data)
+	}
+	return nil
+}
+
+func (tc *testContext) artifactFSStatShouldBeFile(name string, size int) error {
+	info, err := fs.Stat(tc.artifactFS, name)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() || info.Size() != int64(size) || info.Mode() != 0o444 || info.Name() != filepath.Base(name) {
+		return fmt.Errorf("expected %s to be a file of %d bytes, got %s %v of %d bytes", name, size, info.Name(), info.Mode(), info.Size())
+	}
+	if _, ok := info.Sys().(mlflow.FileInfo); !ok {
+		return fmt.Errorf("expected the Sys of %s to be an mlflow.FileInfo, got %T", name, info.Sys())
+	}
+	return nil
+}
+
+func (tc *testContext) artifactFSStatShouldBeDir(name string) error {
+	info, err := fs.Stat(tc.artifactFS, name)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || !info.Mode().IsDir() {
+		return fmt.Errorf("expected %s to be a directory, got mode %v", name, info.Mode())
+	}
+	return nil
+}
+
+func (tc *testContext) artifactFSOpenShouldFail(name, reason string) error {
+	_, err := tc.artifactFS.Open(name)
+	expected := map[string]error{"not exist": fs.ErrNotExist, "be invalid": fs.ErrInvalid}[reason]
+	var pathErr *fs.PathError
+	if !errors.Is(err, expected) || !errors.As(err, &pathErr) {
+		return fmt.Errorf("expected opening %q to fail with %v, got %v", name, expected, err)
+	}
+	return nil
+}
+
+func (tc *testContext) openInArtifactFS(name string) error {
+	file, err := tc.artifactFS.Open(name)
+	tc.fsFile = file
+	return err
+}
+
+func (tc *testContext) readingOpenArtifactShouldGive(content string) error {
+	defer tc.fsFile.Close()
+	data, err := io.ReadAll(tc.fsFile)
+	if err != nil {
+		return err
+	}
+	if string(data) != content {
+		return fmt.Errorf("expected %q, got %q", content, data)
+	}
+	return nil
+}
+
+func (tc *testContext) trackingServerShouldHaveServed(lists, downloads int) error {
+	tc.fsProxy.mu.Lock()
+	defer tc.fsProxy.mu.Unlock()
+	if tc.fsProxy.lists != lists || tc.fsProxy.downloads != downloads {
+		return fmt.Errorf("expected %d artifact listings and %d downloads, got %d and %d", lists, downloads, tc.fsProxy.lists, tc.fsProxy.downloads)
+	}
+	return nil
+}
+
+func (tc *testContext) artifactFSShouldPassFSTest(files string) error {
+	return fstest.TestFS(tc.artifactFS, strings.Split(files, ", ")...)
+}
+
+func (tc *testContext) cacheShouldHaveArtifact(artifactPath string) error {
+	_, err := os.Stat(filepath.Join(tc.fsCacheDir, tc.runID, filepath.FromSlash(artifactPath)))
+	return err
+}
+
+func (tc *testContext) serveArtifactFSOverHTTP() error {
+	tc.fsServer = httptest.NewServer(http.FileServer(http.FS(tc.artifactFS)))
+	return nil
+}
+
+func (tc *testContext) httpGetShouldReturn(urlPath, byteRange string, status int, content string) error {
+	req, err := http.NewRequest(http.MethodGet, tc.fsServer.URL+urlPath, nil)
+	if err != nil {
+		return err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", "bytes="+byteRange)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != status || !strings.Contains(string(body), content) {
+		return fmt.Errorf("expected status %d with %q, got %d with %q", status, content, resp.StatusCode, body)
+	}
+	return nil
+}
+
+func (tc *testContext) httpGetShouldReturnContent(urlPath string, status int, content string) error {
+	return tc.httpGetShouldReturn(urlPath, "", status, content)
+}
+
+func (tc *testContext) httpRangeGetShouldReturn(urlPath, byteRange string, status int, content string) error {
+	return tc.httpGetShouldReturn(urlPath, byteRange, status, content)
+}
+
+func (tc *testContext) executingArtifactTemplateShouldGive(name, pattern, data, expected string) error {
+	templates, err := template.ParseFS(tc.artifactFS, pattern)
+	if err != nil {
+		return err
+	}
+	var out strings.Builder
+	if err := templates.ExecuteTemplate(&out, name, data); err != nil {
+		return err
+	}
+	if out.String() != expected {
+		return fmt.Errorf("expected %q, got %q", expected, out.String())
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/azure"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/s3"
+)
+
+// Artifact repository step implementations
+
+// memoryArtifactRepository is a custom backend keeping artifacts in memory
+type memoryArtifactRepository struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+var memoryArtifactStores = struct {
+	sync.Mutex
+	repos map[string]*memoryArtifactRepository
+}{repos: map[string]*memoryArtifactRepository{}}
+
+// memoryArtifactRepositoryFor returns the repository of a memory:// URI,
+// the same one each time the URI is resolved
+func memoryArtifactRepositoryFor(_ *mlflow.Client, artifactURI string) (mlflow.ArtifactRepository, error) {
+	memoryArtifactStores.Lock()
+	defer memoryArtifactStores.Unlock()
+	if memoryArtifactStores.repos[artifactURI] == nil {
+		memoryArtifactStores.repos[artifactURI] = &memoryArtifactRepository{files: map[string][]byte{}}
+	}
+	return memoryArtifactStores.repos[artifactURI], nil
+}
+
+func (r *memoryArtifactRepository) List(_ context.Context, dir string) ([]mlflow.FileInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := map[string]mlflow.FileInfo{}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	if dir == "" {
+		prefix = ""
+	}
+	for name, data := range r.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if first, _, nested := strings.Cut(rest, "/"); nested {
+			entries[first] = mlflow.FileInfo{Path: prefix + first, IsDir: true}
+		} else {
+			entries[rest] = mlflow.FileInfo{Path: name, FileSize: int64(len(data))}
+		}
+	}
+	files := make([]mlflow.FileInfo, 0, len(entries))
+	for _, file := range entries {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (r *memoryArtifactRepository) Upload(_ context.Context, artifactPath string, content io.Reader, _ int64) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[artifactPath] = data
+	return nil
+}
+
+func (r *memoryArtifactRepository) Download(_ context.Context, artifactPath string) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.files[artifactPath]
+	if !ok {
+		return nil, fmt.Errorf("artifact %s: %w", artifactPath, fs.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (r *memoryArtifactRepository) Delete(_ context.Context, artifactPath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.files {
+		if artifactPath == "" || name == artifactPath || strings.HasPrefix(name, artifactPath+"/") {
+			delete(r.files, name)
+		}
+	}
+	return nil
+}
+
+func (r *memoryArtifactRepository) Stat(ctx context.Context, artifactPath string) (mlflow.FileInfo, error) {
+	if artifactPath == "" {
+		return mlflow.FileInfo{IsDir: true}, nil
+	}
+	dir := path.Dir(artifactPath)
+	if dir == "." {
+		dir = ""
+	}
+	files, _ := r.List(ctx, dir)
+	for _, file := range files {
+		if file.Path == artifactPath {
+			return file, nil
+		}
+	}
+	return mlflow.FileInfo{}, fmt.Errorf("artifact %s: %w", artifactPath, fs.ErrNotExist)
+}
+
+func (tc *testContext) resolveArtifactRepository(artifactURI string) error {
+	tc.artifactRepo, tc.lastError = tc.client.ArtifactRepository(artifactURI)
+	return nil
+}
+
+func (tc *testContext) resolvedRepositoryShouldBe(backend string) error {
+	if tc.lastError != nil {
+		return tc.lastError
+	}
+	var got string
+	switch tc.artifactRepo.(type) {
+	case *mlflow.LocalArtifactRepository:
+		got = "local"
+	case *mlflow.ProxyArtifactRepository:
+		got = "proxy"
+	case *memoryArtifactRepository:
+		got = "memory"
+	case *s3.Repository:
+		got = "s3"
+	case *azure.Repository:
+		got = "azure"
+	default:
+		got = fmt.Sprintf("%T", tc.artifactRepo)
+	}
+	if got != backend {
+		return fmt.Errorf("expected a %s repository, got %s", backend, got)
+	}
+	return nil
+}
+
+func (tc *testContext) localRepositoryRootShouldBe(root string) error {
+	local, ok := tc.artifactRepo.(*mlflow.LocalArtifactRepository)
+	if !ok {
+		return fmt.Errorf("expected a local repository, got %T", tc.artifactRepo)
+	}
+	if local.Root != filepath.FromSlash(root) {
+		return fmt.Errorf("expected root %s, got %s", root, local.Root)
+	}
+	return nil
+}
+
+// artifactRepositoryBackend sets up a repository of the backend: a local
+// directory, a run's artifacts on the tracking server's artifact proxy, a
+// prefix of a bucket on the S3 stub or a MinIO server, or a path of a
+// container on the Azure stub or Azurite
+func (tc *testContext) artifactRepositoryBackend(backend string) error {
+	switch backend {
+	case "local directory":
+		dir, err := tc.newTempDir("mlflow-artifact-repo-")
+		if err != nil {
+			return err
+		}
+		tc.artifactRepo, err = tc.client.ArtifactRepository("file://" + filepath.ToSlash(dir))
+		return err
+	case "tracking server proxy":
+		if err := tc.runExists(); err != nil {
+			return err
+		}
+		repo, err := tc.client.RunArtifactRepository(tc.runID)
+		tc.artifactRepo = repo
+		return err
+	case "S3 bucket":
+		repo, err := tc.newS3Repository(s3.Config{})
+		tc.artifactRepo = repo
+		return err
+	case "MinIO bucket":
+		repo, err := tc.minioRepository()
+		tc.artifactRepo = repo
+		return err
+	case "Azure container":
+		repo, err := tc.newAzureRepository(azure.Config{})
+		tc.artifactRepo = repo
+		return err
+	case "Azurite container":
+		repo, err := tc.azuriteRepository()
+		tc.artifactRepo = repo
+		return err
+	}
+	return fmt.Errorf("unknown backend %q", backend)
+}
+
+func (tc *testContext) uploadToRepository(content, artifactPath string) error {
+	return tc.artifactRepo.Upload(context.Background(), artifactPath, strings.NewReader(content), int64(len(content)))
+}
+
+func (tc *testContext) repositoryListingShouldBe(dir, listing string) error {
+	files, err := tc.artifactRepo.List(context.Background(), dir)
+	if err != nil {
+		return err
+	}
+	var got []string
+	for _, file := range files {
+		entry := file.Path
+		if file.IsDir {
+			entry += "/"
+		} else {
+			entry += fmt.Sprintf(" (%d)", file.FileSize)
+		}
+		got = append(got, entry)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ", ") != listing {
+		return fmt.Errorf("expected %q to list %q, got %q", dir, listing, strings.Join(got, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) repositoryStatShouldBe(artifactPath, kind string) error {
+	info, err := tc.artifactRepo.Stat(context.Background(), artifactPath)
+	if err != nil {
+		return err
+	}
+	if got := map[bool]string{true: "directory", false: "file"}[info.IsDir]; got != kind {
+		return fmt.Errorf("expected %s to be a %s, got %+v", artifactPath, kind, info)
+	}
+	return nil
+}
+
+func (tc *testContext) repositoryFileShouldContain(artifactPath, content string) error {
+	body, err := tc.artifactRepo.Download(context.Background(), artifactPath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if string(data) != content {
+		return fmt.Errorf("expected %s to contain %q, got %q", artifactPath, content, data)
+	}
+	return nil
+}
+
+func (tc *testContext) deleteFromRepository(artifactPath string) error {
+	return tc.artifactRepo.Delete(context.Background(), artifactPath)
+}
+
+func (tc *testContext) repositoryPathShouldNotExist(artifactPath string) error {
+	if _, err := tc.artifactRepo.Stat(context.Background(), artifactPath); !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("expected Stat of %s to fail with fs.ErrNotExist, got %v", artifactPath, err)
+	}
+	body, err := tc.artifactRepo.Download(context.Background(), artifactPath)
+	if err == nil {
+		body.Close()
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("expected Download of %s to fail with fs.ErrNotExist, got %v", artifactPath, err)
+	}
+	return nil
+}
+
+func (tc *testContext) experimentWithLocalArtifactStore() error {
+	dir, err := tc.newTempDir("mlflow-artifact-store-")
+	if err != nil {
+		return err
+	}
+	tc.artifactStoreDir = dir
+	return tc.createExperimentWithArtifactLocation("file://" + filepath.ToSlash(dir))
+}
+
+func (tc *testContext) experimentWithMemoryArtifactStore() error {
+	mlflow.RegisterArtifactRepository("memory", memoryArtifactRepositoryFor)
+	return tc.createExperimentWithArtifactLocation("memory://" + uuid.NewString())
+}
+
+func (tc *testContext) createExperimentWithArtifactLocation(location string) error {
+	name := "artifact-store-" + uuid.NewString()
+	resp, err := tc.client.CreateExperiment(mlflow.CreateExperimentRequest{Name: name, ArtifactLocation: location})
+	if err != nil {
+		return err
+	}
+	tc.experimentID = resp.ExperimentID
+	tc.experimentName = name
+	tc.createdResources = append(tc.createdResources, resource{Type: "experiment", ID: resp.ExperimentID})
+	return nil
+}
+
+func (tc *testContext) localArtifactStoreShouldHaveFile(artifactPath, content string) error {
+	data, err := os.ReadFile(filepath.Join(tc.artifactStoreDir, tc.runID, "artifacts", filepath.FromSlash(artifactPath)))
+	if err != nil {
+		return err
+	}
+	if string(data) != content {
+		return fmt.Errorf("expected %s to contain %q, got %q", artifactPath, content, data)
+	}
+	return nil
+}
+
+func (tc *testContext) localArtifactStoreFileShouldHaveMode(artifactPath, mode string) error {
+	info, err := os.Stat(filepath.Join(tc.artifactStoreDir, tc.runID, "artifacts", filepath.FromSlash(artifactPath)))
+	if err != nil {
+		return err
+	}
+	if got := fmt.Sprintf("%04o", info.Mode().Perm()); got != mode {
+		return fmt.Errorf("expected %s to have mode %s, got %s", artifactPath, mode, got)
+	}
+	return nil
+}
+
+func (tc *testContext) runArtifactRepositoryShouldBe(backend string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	tc.artifactRepo, tc.lastError = repo, nil
+	return tc.resolvedRepositoryShouldBe(backend)
+}
+
+// authRecorder is an artifact proxy that records the Authorization header of
+// each request and lists every directory as empty. It is reached as the
+// tracking host on 127.0.0.1 and as another host on localhost.
+type authRecorder struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	headers []string
+}
+
+func (tc *testContext) clientWithTokenForAuthRecorder(token string) error {
+	recorder := &authRecorder{}
+	recorder.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.mu.Lock()
+		recorder.headers = append(recorder.headers, r.Header.Get("Authorization"))
+		recorder.mu.Unlock()
+		writeStubJSON(w, map[string]any{})
+	}))
+	tc.authRecorder = recorder
+	tc.client = mlflow.NewClient(recorder.server.URL)
+	tc.client.SetAuthToken(token)
+	return nil
+}
+
+// listRecordedArtifactRepository lists the root of the repository of an
+// artifact URI whose {tracking host} and {other host} name the recorder
+func (tc *testContext) listRecordedArtifactRepository(artifactURI string) error {
+	port := tc.authRecorder.server.Listener.Addr().(*net.TCPAddr).Port
+	artifactURI = strings.NewReplacer(
+		"{tracking host}", fmt.Sprintf("127.0.0.1:%d", port),
+		"{other host}", fmt.Sprintf("localhost:%d", port),
+	).Replace(artifactURI)
+	repo, err := tc.client.ArtifactRepository(artifactURI)
+	if err != nil {
+		return err
+	}
+	_, err = repo.List(context.Background(), "")
+	return err
+}
+
+func (tc *testContext) authRecorderShouldHaveReceivedToken(token string) error {
+	return tc.authRecorderShouldHaveReceived("Bearer " + token)
+}
+
+func (tc *testContext) authRecorderShouldHaveReceivedNoToken() error {
+	return tc.authRecorderShouldHaveReceived("")
+}
+
+func (tc *testContext) authRecorderShouldHaveReceived(want string) error {
+	tc.authRecorder.mu.Lock()
+	defer tc.authRecorder.mu.Unlock()
+	if len(tc.authRecorder.headers) == 0 {
+		return fmt.Errorf("expected a request to the artifact proxy")
+	}
+	for _, header := range tc.authRecorder.headers {
+		if header != want {
+			return fmt.Errorf("expected Authorization %q, got %q", want, header)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package features
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// Artifact transfer step implementations
+
+func (tc *testContext) newTempDir(prefix string) (string, error) {
+	dir, err := os.MkdirTemp("", prefix)
+	if err != nil {
+		return "", err
+	}
+	tc.tempDirs = append(tc.tempDirs, dir)
+	return dir, nil
+}
+
+func (tc *testContext) localDirectoryWithFiles(table *godog.Table) error {
+	dir, err := tc.newTempDir("mlflow-upload-")
+	if err != nil {
+		return err
+	}
+	for _, row := range table.Rows[1:] {
+		file := filepath.Join(dir, filepath.FromSlash(row.Cells[0].Value))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, []byte(row.Cells[1].Value), 0o644); err != nil {
+			return err
+		}
+	}
+	tc.uploadDir = dir
+	return nil
+}
+
+func (tc *testContext) logLocalFile(file, artifactPath string) error {
+	return tc.client.LogArtifact(tc.runID, filepath.Join(tc.uploadDir, filepath.FromSlash(file)), artifactPath)
+}
+
+func (tc *testContext) logLocalDirectory(artifactPath string) error {
+	return tc.client.LogArtifacts(tc.runID, tc.uploadDir, artifactPath)
+}
+
+func (tc *testContext) downloadArtifacts(artifactPath string) error {
+	dir, err := tc.newTempDir("mlflow-download-")
+	if err != nil {
+		return err
+	}
+	tc.downloadDir = dir
+	tc.downloadedPath, err = tc.client.DownloadArtifacts(tc.runID, artifactPath, dir)
+	return err
+}
+
+func (tc *testContext) downloadArtifact(artifactPath, localPath string) error {
+	dir, err := tc.newTempDir("mlflow-download-")
+	if err != nil {
+		return err
+	}
+	tc.downloadDir = dir
+	return tc.client.DownloadArtifact(tc.runID, artifactPath, filepath.Join(dir, filepath.FromSlash(localPath)))
+}
+
+func (tc *testContext) attemptDownloadArtifact(artifactPath string) error {
+	if err := tc.downloadArtifact(artifactPath, "artifact"); err != nil {
+		tc.lastError = err
+	}
+	return nil
+}
+
+func (tc *testContext) attemptDownloadArtifacts(artifactPath string) error {
+	if err := tc.downloadArtifacts(artifactPath); err != nil {
+		tc.lastError = err
+	}
+	return nil
+}
+
+func (tc *testContext) attemptDownloadArtifactsOfRun(artifactPath, runID string) error {
+	_, tc.lastError = tc.client.DownloadArtifacts(runID, artifactPath, os.TempDir())
+	return nil
+}
+
+func (tc *testContext) attemptLogLocalFileToRun(artifactPath, runID string) error {
+	file, err := os.CreateTemp("", "mlflow-upload-*.txt")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	tc.lastError = tc.client.LogArtifact(runID, file.Name(), artifactPath)
+	return nil
+}
+
+// downloadedFilesShouldBe compares every file under the download directory
+// with the table of slash separated paths and contents
+func (tc *testContext) downloadedFilesShouldBe(table *godog.Table) error {
+	got := map[string]string{}
+	err := filepath.WalkDir(tc.downloadDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(tc.downloadDir, file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		got[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, row := range table.Rows[1:] {
+		file, content := row.Cells[0].Value, row.Cells[1].Value
+		if data, ok := got[file]; !ok || data != content {
+			return fmt.Errorf("expected %s to contain %q, got %q (present: %v)", file, content, data, ok)
+		}
+		delete(got, file)
+	}
+	if len(got) > 0 {
+		var extra []string
+		for file := range got {
+			extra = append(extra, file)
+		}
+		sort.Strings(extra)
+		return fmt.Errorf("unexpected downloaded files: %s", strings.Join(extra, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) downloadedPathShouldBe(localPath string) error {
+	want := filepath.Join(tc.downloadDir, filepath.FromSlash(localPath))
+	if tc.downloadedPath != want {
+		return fmt.Errorf("expected the download at %s, got %s", want, tc.downloadedPath)
+	}
+	return nil
+}
+
+func (tc *testContext) downloadedFileShouldHaveMode(file, mode string) error {
+	info, err := os.Stat(filepath.Join(tc.downloadDir, filepath.FromSlash(file)))
+	if err != nil {
+		return err
+	}
+	if got := fmt.Sprintf("%04o", info.Mode().Perm()); got != mode {
+		return fmt.Errorf("expected %s to have mode %s, got %s", file, mode, got)
+	}
+	return nil
+}
+
+func (tc *testContext) openedArtifactShouldContain(artifactPath, content string) error {
+	body, err := tc.client.OpenArtifact(tc.runID, artifactPath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if string(data) != content {
+		return fmt.Errorf("expected %s to contain %q, got %q", artifactPath, content, data)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package features
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Audit step implementations
+
+func (tc *testContext) clientForUnreachableServer() error {
+	// Nothing listens on port 1, so every request fails fast
+	tc.client = mlflow.NewClient("http://127.0.0.1:1")
+	return nil
+}
+
+func (tc *testContext) auditLoggingEnabled(actor string) error {
+	if err := tc.clientConnected(); err != nil {
+		return err
+	}
+	tc.auditBuffer = &bytes.Buffer{}
+	tc.client.SetAudit(&mlflow.AuditConfig{
+		Sink:  mlflow.NewWriterAuditSink(tc.auditBuffer),
+		Actor: actor,
+	})
+	return nil
+}
+
+func (tc *testContext) auditRecords() ([]mlflow.AuditRecord, error) {
+	if tc.auditBuffer == nil {
+		return nil, fmt.Errorf("audit logging is not enabled")
+	}
+	var records []mlflow.AuditRecord
+	scanner := bufio.NewScanner(bytes.NewReader(tc.auditBuffer.Bytes()))
+	for scanner.Scan() {
+		var record mlflow.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid audit line %q: %w", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func (tc *testContext) createExperimentWithUniqueName() error {
+	return tc.experimentUniqueNameExists()
+}
+
+func (tc *testContext) attemptDeleteExperiment(experimentID string) error {
+	tc.lastError = tc.client.DeleteExperiment(experimentID)
+	return nil
+}
+
+func (tc *testContext) attemptLogParam(key, value, runID string) error {
+	tc.lastError = tc.client.LogParam(mlflow.LogParamRequest{
+		RunID: runID,
+		Key:   key,
+		Value: value,
+	})
+	return nil
+}
+
+func (tc *testContext) auditLogContainsRecord(operation, outcome string) error {
+	records, err := tc.auditRecords()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.Operation == operation && record.Outcome == outcome {
+			return nil
+		}
+	}
+	return fmt.Errorf("no %s record with outcome %s in %d audit records", operation, outcome, len(records))
+}
+
+func (tc *testContext) everyAuditRecordHasActor(actor string) error {
+	records, err := tc.auditRecords()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("audit log is empty")
+	}
+	for _, record := range records {
+		if record.Actor != actor {
+			return fmt.Errorf("expected actor %s, got %s for %s", actor, record.Actor, record.Operation)
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) lastAuditRecord() (*mlflow.AuditRecord, error) {
+	records, err := tc.auditRecords()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("audit log is empty")
+	}
+	return &records[len(records)-1], nil
+}
+
+func (tc *testContext) lastAuditRecordHasErrorCode(code string) error {
+	record, err := tc.lastAuditRecord()
+	if err != nil {
+		return err
+	}
+	if record.ErrorCode != code {
+		return fmt.Errorf("expected error code %s, got %s", code, record.ErrorCode)
+	}
+	return nil
+}
+
+func (tc *testContext) lastAuditRecordTargets(entity, id string) error {
+	record, err := tc.lastAuditRecord()
+	if err != nil {
+		return err
+	}
+	if string(record.Target.Entity) != entity || record.Target.ID != id {
+		return fmt.Errorf("expected target %s %s, got %s %s", entity, id, record.Target.Entity, record.Target.ID)
+	}
+	return nil
+}
+
+func (tc *testContext) auditLogDoesNotContain(text string) error {
+	if tc.auditBuffer == nil {
+		return fmt.Errorf("audit logging is not enabled")
+	}
+	if strings.Contains(tc.auditBuffer.String(), text) {
+		return fmt.Errorf("audit log contains %q", text)
+	}
+	return nil
+}
+
+func (tc *testContext) fileAuditSinkLimited(maxBytes, maxBackups int) error {
+	dir, err := os.MkdirTemp("", "mlflow-audit-")
+	if err != nil {
+		return err
+	}
+	tc.tempDirs = append(tc.tempDirs, dir)
+	tc.auditDir = dir
+	sink, err := mlflow.NewFileAuditSink(filepath.Join(dir, "audit.jsonl"), int64(maxBytes), maxBackups)
+	if err != nil {
+		return err
+	}
+	tc.fileAuditSink = sink
+	return nil
+}
+
+func (tc *testContext) writeAuditRecordsToFileSink(count int) error {
+	if tc.fileAuditSink == nil {
+		return fmt.Errorf("file audit sink not created")
+	}
+	for i := 0; i < count; i++ {
+		record := mlflow.AuditRecord{
+			Operation: "SetTag",
+			Target:    mlflow.AuditTarget{Entity: mlflow.AuditEntityRun, ID: fmt.Sprintf("run-%d", i)},
+			Outcome:   mlflow.AuditOutcomeSuccess,
+		}
+		if err := tc.fileAuditSink.WriteAuditRecord(record); err != nil {
+			return err
+		}
+	}
+	return tc.fileAuditSink.Close()
+}
+
+func (tc *testContext) auditDirContainsFiles(count int) error {
+	entries, err := os.ReadDir(tc.auditDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) != count {
+		return fmt.Errorf("expected %d audit files, got %d", count, len(entries))
+	}
+	return nil
+}
+
+func (tc *testContext) everyAuditFileHasValidJSONLines() error {
+	entries, err := os.ReadDir(tc.auditDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(tc.auditDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if !json.Valid([]byte(line)) {
+				return fmt.Errorf("invalid JSON line in %s: %q", entry.Name(), line)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,568 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+	"github.com/google/uuid"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/azure"
+)
+
+// Azure step implementations
+
+// azureStubContainer is the only container of the Azure stub
+const azureStubContainer = "mlflow"
+
+// azureStubListPage is how many entries the stub lists per page, small so
+// that listings take several pages
+const azureStubListPage = 3
+
+// azureStubSAS is the shared access signature the stub accepts
+const azureStubSAS = "sv=2020-10-02&ss=b&srt=co&sp=rwdlac&se=2030-01-01T00:00:00Z&spr=https,http&sig=c3R1Yi1zaWduYXR1cmU%3D"
+
+// azureEnvironment are the variables that configure Azure access, cleared
+// for scenarios against the Azure stub
+var azureEnvironment = []string{"AZURE_STORAGE_CONNECTION_STRING", "AZURE_STORAGE_ACCESS_KEY"}
+
+type azureStubBlob struct {
+	data []byte
+	etag string
+}
+
+// azureStub is an Azurite-like blob service for the development account,
+// with one container. It checks the Shared Key signature or SAS token of
+// every request.
+type azureStub struct {
+	server *httptest.Server
+
+	mu    sync.Mutex
+	blobs map[string]*azureStubBlob
+	// staged are the uncommitted blocks of each blob by block ID
+	staged     map[string]map[string][]byte
+	nextETag   int
+	puts       int
+	blocks     int
+	blockLists int
+	ranged     int
+	failures   map[int]int
+	status     int
+	attempts   map[int]int
+	// auth is how the last request was authorized
+	auth string
+}
+
+func newAzureStub() *azureStub {
+	s := &azureStub{
+		blobs:    map[string]*azureStubBlob{},
+		staged:   map[string]map[string][]byte{},
+		failures: map[int]int{},
+		attempts: map[int]int{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// endpoint returns the stub's blob service URL of the development account
+func (s *azureStub) endpoint() string {
+	return s.server.URL + "/" + azure.DevelopmentAccountName
+}
+
+func azureStubError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("X-Ms-Error-Code", code)
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: message + "\nRequestId:stub\nTime:" + time.Now().UTC().Format(time.RFC3339)})
+}
+
+func (s *azureStub) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if code, msg := s.authenticate(r, body); code != "" {
+		azureStubError(w, r, http.StatusForbidden, code, msg)
+		return
+	}
+	account, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	container, name, _ := strings.Cut(rest, "/")
+	if account != azure.DevelopmentAccountName || container != azureStubContainer {
+		azureStubError(w, r, http.StatusNotFound, "ContainerNotFound", "The specified container does not exist.")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case name == "" && r.Method == http.MethodGet && query.Get("restype") == "container" && query.Get("comp") == "list":
+		s.list(w, query)
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		s.putBlock(w, r, name, query.Get("blockid"), body)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		s.putBlockList(w, r, name, body)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("X-Ms-Blob-Type") != "BlockBlob" {
+			azureStubError(w, r, http.StatusBadRequest, "MissingRequiredHeader", "An HTTP header that's mandatory for this request is not specified.")
+			return
+		}
+		s.puts++
+		s.store(w, name, body)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		s.getBlob(w, r, name)
+	case r.Method == http.MethodDelete:
+		if _, ok := s.blobs[name]; !ok {
+			azureStubError(w, r, http.StatusNotFound, "BlobNotFound", "The specified blob does not exist.")
+			return
+		}
+		delete(s.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		azureStubError(w, r, http.StatusNotImplemented, "NotImplemented", r.Method+" "+r.URL.String())
+	}
+}
+
+// authenticate checks the Shared Key signature or SAS token of a request,
+// returning the error code if it is wrong
+func (s *azureStub) authenticate(r *http.Request, body []byte) (string, string) {
+	if r.Header.Get("X-Ms-Version") == "" {
+		return "MissingRequiredHeader", "x-ms-version is required"
+	}
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		expected, _ := url.ParseQuery(azureStubSAS)
+		if sig := r.URL.Query().Get("sig"); sig == "" || sig != expected.Get("sig") {
+			return "AuthenticationFailed", "the SAS signature is missing or wrong"
+		}
+		s.auth = "a SAS token"
+		return "", ""
+	}
+	signedAt, err := time.Parse(http.TimeFormat, r.Header.Get("X-Ms-Date"))
+	if err != nil {
+		return "AuthenticationFailed", "bad x-ms-date"
+	}
+
+	// Sign the request as received and compare
+	check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), bytes.NewReader(body))
+	for name, values := range r.Header {
+		if name != "Authorization" {
+			check.Header[name] = values
+		}
+	}
+	key, _ := base64.StdEncoding.DecodeString(azure.DevelopmentAccountKey)
+	azure.Sign(check, azure.DevelopmentAccountName, key, signedAt)
+	if check.Header.Get("Authorization") != auth {
+		return "AuthenticationFailed", "the MAC signature does not match"
+	}
+	s.auth = "Shared Key"
+	return "", ""
+}
+
+// store commits a blob, replacing any blocks staged for it
+func (s *azureStub) store(w http.ResponseWriter, name string, data []byte) {
+	s.nextETag++
+	s.blobs[name] = &azureStubBlob{data: data, etag: fmt.Sprintf(`"0x8DC%012X"`, s.nextETag)}
+	delete(s.staged, name)
+	w.Header().Set("ETag", s.blobs[name].etag)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// blockNumber returns the number at the end of a block ID of the client
+func blockNumber(blockID string) int {
+	decoded, _ := base64.StdEncoding.DecodeString(blockID)
+	_, number, _ := strings.Cut(string(decoded), "-")
+	n, _ := strconv.Atoi(number)
+	return n
+}
+
+func (s *azureStub) putBlock(w http.ResponseWriter, r *http.Request, name, blockID string, body []byte) {
+	if blockID == "" {
+		azureStubError(w, r, http.StatusBadRequest, "InvalidQueryParameterValue", "blockid is required")
+		return
+	}
+	number := blockNumber(blockID)
+	s.attempts[number]++
+	if s.failures[number] != 0 {
+		if s.failures[number] > 0 {
+			s.failures[number]--
+		}
+		azureStubError(w, r, s.status, http.StatusText(s.status), "injected failure")
+		return
+	}
+	if s.staged[name] == nil {
+		s.staged[name] = map[string][]byte{}
+	}
+	for id := range s.staged[name] {
+		if len(id) != len(blockID) {
+			azureStubError(w, r, http.StatusBadRequest, "InvalidBlobOrBlock", "The specified blob or block content is invalid.")
+			return
+		}
+	}
+	s.blocks++
+	s.staged[name][blockID] = body
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *azureStub) putBlockList(w http.ResponseWriter, r *http.Request, name string, body []byte) {
+	var list struct {
+		Latest []string `xml:"Latest"`
+	}
+	if err := xml.Unmarshal(body, &list); err != nil || len(list.Latest) == 0 {
+		azureStubError(w, r, http.StatusBadRequest, "InvalidXmlDocument", "XML specified is not syntactically valid.")
+		return
+	}
+	var data []byte
+	for _, id := range list.Latest {
+		block, ok := s.staged[name][id]
+		if !ok {
+			azureStubError(w, r, http.StatusBadRequest, "InvalidBlockList", "The specified block list is invalid.")
+			return
+		}
+		data = append(data, block...)
+	}
+	s.blockLists++
+	s.store(w, name, data)
+}
+
+func (s *azureStub) getBlob(w http.ResponseWriter, r *http.Request, name string) {
+	blob, ok := s.blobs[name]
+	if !ok {
+		azureStubError(w, r, http.StatusNotFound, "BlobNotFound", "The specified blob does not exist.")
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && match != blob.etag {
+		azureStubError(w, r, http.StatusPreconditionFailed, "ConditionNotMet", "The condition specified using HTTP conditional header(s) is not met.")
+		return
+	}
+	w.Header().Set("ETag", blob.etag)
+	w.Header().Set("X-Ms-Blob-Type", "BlockBlob")
+	data := blob.data
+	status := http.StatusOK
+	if ranges := r.Header.Get("X-Ms-Range"); ranges != "" && r.Method == http.MethodGet {
+		s.ranged++
+		var start, end int
+		if _, err := fmt.Sscanf(ranges, "bytes=%d-%d", &start, &end); err != nil || start >= len(data) {
+			azureStubError(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The range specified is invalid for the current size of the resource.")
+			return
+		}
+		end = min(end, len(data)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data = data[start : end+1]
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+func (s *azureStub) list(w http.ResponseWriter, query url.Values) {
+	prefix, delimiter, marker := query.Get("prefix"), query.Get("delimiter"), query.Get("marker")
+	limit := azureStubListPage
+	if maxResults, err := strconv.Atoi(query.Get("maxresults")); err == nil && maxResults < limit {
+		limit = maxResults
+	}
+
+	// Entries are blobs, or prefixes up to the delimiter
+	entries := map[string]bool{}
+	for name := range s.blobs {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			entries[prefix+rest[:i+len(delimiter)]] = true
+		} else {
+			entries[name] = false
+		}
+	}
+	sorted := make([]string, 0, len(entries))
+	for entry := range entries {
+		if entry > marker {
+			sorted = append(sorted, entry)
+		}
+	}
+	sort.Strings(sorted)
+
+	type blob struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			ContentLength int    `xml:"Content-Length"`
+			Etag          string `xml:"Etag"`
+			BlobType      string `xml:"BlobType"`
+		} `xml:"Properties"`
+	}
+	type blobPrefix struct {
+		Name string `xml:"Name"`
+	}
+	result := struct {
+		XMLName         xml.Name `xml:"EnumerationResults"`
+		ServiceEndpoint string   `xml:"ServiceEndpoint,attr"`
+		ContainerName   string   `xml:"ContainerName,attr"`
+		Prefix          string   `xml:"Prefix"`
+		Marker          string   `xml:"Marker"`
+		Delimiter       string   `xml:"Delimiter"`
+		Blobs           struct {
+			Blob       []blob       `xml:"Blob"`
+			BlobPrefix []blobPrefix `xml:"BlobPrefix"`
+		} `xml:"Blobs"`
+		NextMarker string `xml:"NextMarker"`
+	}{ServiceEndpoint: s.endpoint(), ContainerName: azureStubContainer, Prefix: prefix, Marker: marker, Delimiter: delimiter}
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+		result.NextMarker = sorted[limit-1]
+	}
+	for _, entry := range sorted {
+		if entries[entry] {
+			result.Blobs.BlobPrefix = append(result.Blobs.BlobPrefix, blobPrefix{Name: entry})
+			continue
+		}
+		b := blob{Name: entry}
+		b.Properties.ContentLength = len(s.blobs[entry].data)
+		b.Properties.Etag = s.blobs[entry].etag
+		b.Properties.BlobType = "BlockBlob"
+		result.Blobs.Blob = append(result.Blobs.Blob, b)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+// connectionString returns a connection string of the stub with
+// the development account's key, or with azureStubSAS if sas is set
+func (s *azureStub) connectionString(sas bool) string {
+	if sas {
+		return "BlobEndpoint=" + s.endpoint() + ";SharedAccessSignature=" + azureStubSAS
+	}
+	return "DefaultEndpointsProtocol=http;AccountName=" + azure.DevelopmentAccountName +
+		";AccountKey=" + azure.DevelopmentAccountKey + ";BlobEndpoint=" + s.endpoint() + ";"
+}
+
+func (tc *testContext) azureStore() error {
+	tc.azureStub = newAzureStub()
+	for _, name := range azureEnvironment {
+		tc.setEnv(name, "", true)
+	}
+	return nil
+}
+
+// newAzureRepository returns a repository of a unique path of the stub's
+// container with the development account's key
+func (tc *testContext) newAzureRepository(cfg azure.Config) (*azure.Repository, error) {
+	if tc.azureStub == nil {
+		if err := tc.azureStore(); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Endpoint = tc.azureStub.endpoint()
+	cfg.AccountKey, _ = base64.StdEncoding.DecodeString(azure.DevelopmentAccountKey)
+	return azure.New("wasbs://"+azureStubContainer+"@"+azure.DevelopmentAccountName+".blob.core.windows.net/repos/"+uuid.NewString(), cfg)
+}
+
+// azuriteRepository returns a repository of a unique path of a container
+// of the Azurite emulator or storage account of
+// MLFLOW_TEST_AZURE_CONNECTION_STRING, skipping the scenario if it is not
+// set
+func (tc *testContext) azuriteRepository() (*azure.Repository, error) {
+	connectionString := os.Getenv("MLFLOW_TEST_AZURE_CONNECTION_STRING")
+	if connectionString == "" {
+		debugLog("MLFLOW_TEST_AZURE_CONNECTION_STRING is not set, skipping the Azurite scenario")
+		return nil, godog.ErrSkip
+	}
+	container := os.Getenv("MLFLOW_TEST_AZURE_CONTAINER")
+	if container == "" {
+		container = "mlflow"
+	}
+	cfg, err := azure.ParseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	cfg.BlockSize = 1 << 20
+	account := cfg.AccountName
+	if account == "" {
+		account = azure.DevelopmentAccountName
+	}
+	repo, err := azure.New("wasbs://"+container+"@"+account+".blob.core.windows.net/mlflow-go-client-test/"+uuid.NewString(), cfg)
+	tc.azuriteRepo = repo
+	return repo, err
+}
+
+func (tc *testContext) azureRepositoryWithBlocks(blockSize, concurrency int) error {
+	repo, err := tc.newAzureRepository(azure.Config{BlockSize: int64(blockSize), Concurrency: concurrency})
+	tc.artifactRepo = repo
+	return err
+}
+
+func (tc *testContext) azureCredentialsFrom(source string) error {
+	switch source {
+	case "a connection string with an account key":
+		tc.setEnv("AZURE_STORAGE_CONNECTION_STRING", tc.azureStub.connectionString(false), false)
+	case "a connection string with a SAS token":
+		tc.setEnv("AZURE_STORAGE_CONNECTION_STRING", tc.azureStub.connectionString(true), false)
+	case "an account key":
+		tc.setEnv("AZURE_STORAGE_ACCESS_KEY", azure.DevelopmentAccountKey, false)
+	default:
+		return fmt.Errorf("unknown credential source %q", source)
+	}
+	return nil
+}
+
+// openAzureRepository resolves an artifact URI with the environment's
+// configuration, sending requests to the stub when the environment names
+// no endpoint, as for AZURE_STORAGE_ACCESS_KEY
+func (tc *testContext) openAzureRepository(uri string) error {
+	cfg, err := azure.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = tc.azureStub.endpoint()
+	}
+	repo, err := azure.New(uri, cfg)
+	tc.artifactRepo = repo
+	return err
+}
+
+func (tc *testContext) azureStoreShouldHaveSeenAuth(auth string) error {
+	tc.azureStub.mu.Lock()
+	defer tc.azureStub.mu.Unlock()
+	if tc.azureStub.auth != auth {
+		return fmt.Errorf("expected requests authorized with %s, got %q", auth, tc.azureStub.auth)
+	}
+	return nil
+}
+
+func (tc *testContext) azureStoreShouldHaveReceived(puts, blocks, blockLists int) error {
+	tc.azureStub.mu.Lock()
+	defer tc.azureStub.mu.Unlock()
+	if tc.azureStub.puts != puts || tc.azureStub.blocks != blocks || tc.azureStub.blockLists != blockLists {
+		return fmt.Errorf("expected %d single uploads and %d blocks in %d block lists, got %d, %d and %d",
+			puts, blocks, blockLists, tc.azureStub.puts, tc.azureStub.blocks, tc.azureStub.blockLists)
+	}
+	return nil
+}
+
+func (tc *testContext) azureStoreShouldHaveServedRanges(ranged int) error {
+	tc.azureStub.mu.Lock()
+	defer tc.azureStub.mu.Unlock()
+	if tc.azureStub.ranged != ranged {
+		return fmt.Errorf("expected %d ranged requests, got %d", ranged, tc.azureStub.ranged)
+	}
+	return nil
+}
+
+func (tc *testContext) azureBlockFails(number, times, status int) error {
+	tc.azureStub.mu.Lock()
+	defer tc.azureStub.mu.Unlock()
+	tc.azureStub.failures[number] = times
+	tc.azureStub.status = status
+	return nil
+}
+
+func (tc *testContext) azureBlockAlwaysFails(number, status int) error {
+	return tc.azureBlockFails(number, -1, status)
+}
+
+func (tc *testContext) azureBlockAttempted(number, times int) error {
+	tc.azureStub.mu.Lock()
+	defer tc.azureStub.mu.Unlock()
+	if tc.azureStub.attempts[number] != times {
+		return fmt.Errorf("expected block %d to be attempted %d times, got %d", number, times, tc.azureStub.attempts[number])
+	}
+	return nil
+}
+
+func (tc *testContext) azureRepositoryShouldHave(account, container, prefix string) error {
+	if tc.lastError != nil {
+		return tc.lastError
+	}
+	repo, ok := tc.artifactRepo.(*azure.Repository)
+	if !ok {
+		return fmt.Errorf("expected an Azure repository, got %T", tc.artifactRepo)
+	}
+	if repo.Account != account || repo.Container != container || repo.Prefix != prefix {
+		return fmt.Errorf("expected account %q, container %q and prefix %q, got %q, %q and %q",
+			account, container, prefix, repo.Account, repo.Container, repo.Prefix)
+	}
+	return nil
+}
+
+func (tc *testContext) experimentWithAzureArtifactStore() error {
+	tc.azurePrefix = "experiments/" + uuid.NewString()
+	return tc.createExperimentWithArtifactLocation("wasbs://" + azureStubContainer + "@" + azure.DevelopmentAccountName + ".blob.core.windows.net/" + tc.azurePrefix)
+}
+
+func (tc *testContext) azureStoreShouldHaveRunArtifact(artifactPath, content string) error {
+	name := tc.azurePrefix + "/" + tc.runID + "/artifacts/" + artifactPath
+	tc.azureStub.mu.Lock()
+	defer tc.azureStub.mu.Unlock()
+	blob, ok := tc.azureStub.blobs[name]
+	if !ok {
+		return fmt.Errorf("the container has no blob %s", name)
+	}
+	if string(blob.data) != content {
+		return fmt.Errorf("expected %s to contain %q, got %q", name, content, blob.data)
+	}
+	return nil
+}
+
+// signAzureTestRequest signs a ranged, conditional download with the
+// development account's key at a fixed time
+func (tc *testContext) signAzureTestRequest() error {
+	req, err := http.NewRequest(http.MethodGet, "https://acct.blob.core.windows.net/mlflow/x", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Ms-Version", azure.APIVersion)
+	req.Header.Set("X-Ms-Range", "bytes=0-9")
+	req.Header.Set("If-Match", `"0x8D"`)
+	key, _ := base64.StdEncoding.DecodeString(azure.DevelopmentAccountKey)
+	azure.Sign(req, "acct", key, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	tc.signature = req.Header.Get("Authorization")
+	return nil
+}
+
+// uploadWithWrongKeyFails uploads to the repository's path with another
+// account key
+func (tc *testContext) uploadWithWrongKeyFails(artifactPath, message string) error {
+	repo, ok := tc.artifactRepo.(*azure.Repository)
+	if !ok {
+		return fmt.Errorf("expected an Azure repository, got %T", tc.artifactRepo)
+	}
+	wrong, err := azure.New("wasbs://"+repo.Container+"@"+repo.Account+".blob.core.windows.net/"+repo.Prefix, azure.Config{
+		Endpoint:   tc.azureStub.endpoint(),
+		AccountKey: []byte("not the account key"),
+		MaxRetries: -1,
+	})
+	if err != nil {
+		return err
+	}
+	err = wrong.Upload(context.Background(), artifactPath, strings.NewReader("weights"), 7)
+	if err == nil {
+		return fmt.Errorf("expected uploading %s with the wrong key to fail", artifactPath)
+	}
+	if !strings.Contains(err.Error(), message) {
+		return fmt.Errorf("expected error containing %q, got %v", message, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/internal/protogen"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Codegen step implementations
+
+func (tc *testContext) generatorConfig(path string) error {
+	cfg, err := protogen.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	tc.protogenConfig = cfg
+	return nil
+}
+
+// copyOfGeneratorInputs copies the vendored protos, the generator config and
+// the client package to a temporary tree so they can be modified safely
+func (tc *testContext) copyOfGeneratorInputs() error {
+	dir, err := os.MkdirTemp("", "mlflow-protogen-")
+	if err != nil {
+		return err
+	}
+	tc.tempDirs = append(tc.tempDirs, dir)
+	for _, sub := range []string{"protos/mlflow", "pkg/mlflow"} {
+		if err := copyDir(filepath.Join("..", sub), filepath.Join(dir, sub)); err != nil {
+			return err
+		}
+	}
+	config, err := os.ReadFile("../protos/protogen.json")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "protos", "protogen.json"), config, 0o644); err != nil {
+		return err
+	}
+	tc.protogenDir = dir
+	return tc.generatorConfig(filepath.Join(dir, "protos", "protogen.json"))
+}
+
+func copyDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dst, entry.Name()), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendToFile(path, text string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (tc *testContext) appendToVendoredProto(text, name string) error {
+	return appendToFile(filepath.Join(tc.protogenDir, "protos", "mlflow", name), text)
+}
+
+func (tc *testContext) appendToGeneratedAPI(text string) error {
+	return appendToFile(tc.protogenConfig.OutputPath(), text)
+}
+
+func (tc *testContext) checkGeneratedAPI() error {
+	tc.protogenResult, tc.lastError = protogen.Check(tc.protogenConfig)
+	return nil
+}
+
+func (tc *testContext) generatedAPIUpToDate() error {
+	if tc.lastError != nil {
+		return fmt.Errorf("expected no drift, got: %v", tc.lastError)
+	}
+	return nil
+}
+
+func (tc *testContext) checkReportsDrift(text string) error {
+	var drift *protogen.DriftError
+	if !errors.As(tc.lastError, &drift) {
+		return fmt.Errorf("expected a drift error, got %v", tc.lastError)
+	}
+	if !strings.Contains(drift.Error(), text) {
+		return fmt.Errorf("expected drift error to mention %q, got: %v", text, drift)
+	}
+	return nil
+}
+
+func (tc *testContext) generatedAPIProvidesMethod(method string) error {
+	if tc.protogenResult == nil {
+		return fmt.Errorf("the generated API has not been checked")
+	}
+	for _, m := range tc.protogenResult.Methods {
+		if m == method {
+			return nil
+		}
+	}
+	return fmt.Errorf("method %s is not generated (generated: %s)", method, strings.Join(tc.protogenResult.Methods, ", "))
+}
+
+func (tc *testContext) generatedAPIDoesNotProvideMethod(method string) error {
+	if err := tc.generatedAPIProvidesMethod(method); err == nil {
+		return fmt.Errorf("method %s should be hand-written, not generated", method)
+	}
+	return nil
+}
+
+func (tc *testContext) attemptGetLoggedModel(modelID string) error {
+	_, tc.lastError = tc.client.GetLoggedModel(mlflow.GetLoggedModelRequest{ModelID: modelID})
+	return nil
+}
+
+func (tc *testContext) attemptDeleteLoggedModel(modelID string) error {
+	tc.lastError = tc.client.DeleteLoggedModel(mlflow.DeleteLoggedModelRequest{ModelID: modelID})
+	return nil
+}
+
+func (tc *testContext) callFailsWith(text string) error {
+	if tc.lastError == nil {
+		return fmt.Errorf("expected the call to fail")
+	}
+	if !strings.Contains(tc.lastError.Error(), text) {
+		return fmt.Errorf("expected error to contain %q, got: %v", text, tc.lastError)
+	}
+	return nil
+}
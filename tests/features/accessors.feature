@@ -0,0 +1,75 @@
+Feature: Typed accessors and enums
+  As an engineer reading runs and registry entities
+  I want typed accessors and enum values that are checked before a request is sent
+  So that I do not scan tag slices by hand or send a misspelled status to the server
+
+  Scenario Outline: Enum values are validated
+    Then the <kind> "<value>" should be <validity>
+
+    Examples:
+      | kind                 | value                | validity |
+      | run status           | FINISHED             | valid    |
+      | run status           | finished             | invalid  |
+      | run status           | DONE                 | invalid  |
+      | view type            | ACTIVE_ONLY          | valid    |
+      | view type            | ACTIVE               | invalid  |
+      | lifecycle stage      | deleted              | valid    |
+      | lifecycle stage      | archived             | invalid  |
+      | model stage          | Production           | valid    |
+      | model stage          | production           | valid    |
+      | model stage          | Prod                 | invalid  |
+      | model version status | READY                | valid    |
+      | model version status | PENDING_REGISTRATION | valid    |
+      | model version status | DELETED              | invalid  |
+
+  Scenario: Run accessors read params, metrics, tags and timing
+    Given a cached run with:
+      | attributes.status     | FINISHED      |
+      | attributes.start_time | 1700000000000 |
+      | attributes.end_time   | 1700000090000 |
+      | metrics.accuracy      | 0.93          |
+      | params.lr             | 0.01          |
+      | tags.team             | vision        |
+    Then the cached run should have param "lr" = "0.01"
+    And the cached run should have metric "accuracy" = 0.93
+    And the cached run should have tag "team" = "vision"
+    And the cached run should not have param "epochs"
+    And the cached run should have 1 params, 1 metrics and 1 tags in its maps
+    And the cached run should have started at 1700000000000 and run for 1m30s
+    And the cached run should be terminal
+
+  Scenario: A run that has not ended is not terminal
+    Given a cached run with:
+      | attributes.status     | RUNNING       |
+      | attributes.start_time | 1700000000000 |
+    Then the cached run should not be terminal
+    And the cached run should not have ended
+
+  Scenario: Invalid enum values are rejected before a request is sent
+    Given an MLflow client for an unreachable server
+    When I attempt to search runs with view type "EVERYTHING"
+    Then the call should fail with "invalid view type"
+    When I attempt to transition the model version to stage "Prod"
+    Then the call should fail with "valid: None, Staging, Production, Archived"
+    When I attempt to set the run status to "DONE"
+    Then the call should fail with "invalid run status"
+
+  Scenario: Run accessors read a run fetched from the server
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    When I log parameter "lr" with value "0.01" to the run
+    And I log metric "accuracy" with value 0.93 to the run
+    And I update the run status to "FINISHED"
+    Then the fetched run should have param "lr" = "0.01"
+    And the fetched run should have metric "accuracy" = 0.93
+    And the fetched run should be terminal
+
+  Scenario: Model stages are sent with the server's capitalization
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    When I create a registered model named "accessor-stage-model"
+    And a model version exists for model "accessor-stage-model"
+    And I transition the model version to stage "production"
+    Then the model version stage should be "Production"
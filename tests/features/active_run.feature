@@ -0,0 +1,61 @@
+Feature: Active run propagation
+  As an engineer writing data loaders and evaluators
+  I want to reach the current run through a context or the MLflow environment variables
+  So that deep library code and subprocesses log to the same run without threading run IDs
+
+  Scenario: Library code logs to the run carried by the context
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    When I start a run in the experiment
+    And library code logs metric "batch_loss" with value 0.25 to the run in the context
+    Then the active run should have metric "batch_loss" = 0.25
+
+  Scenario: A context without a run has no active run
+    Then a context without a run should have no active run
+
+  Scenario: A process attaches to the run in MLFLOW_RUN_ID instead of creating one
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And MLFLOW_RUN_ID is set to the run
+    When I start a run in the experiment
+    Then the active run should be the existing run
+    And the experiment should have 1 run
+
+  Scenario: A run is created in the experiment named by MLFLOW_EXPERIMENT_NAME
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    And MLFLOW_EXPERIMENT_NAME is set to the experiment name
+    When I start a run without an experiment ID
+    Then the active run should be in the experiment
+
+  Scenario: MLFLOW_EXPERIMENT_ID takes precedence over MLFLOW_EXPERIMENT_NAME
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    And MLFLOW_EXPERIMENT_ID is set to the experiment ID
+    And MLFLOW_EXPERIMENT_NAME is set to "no-such-experiment"
+    When I start a run without an experiment ID
+    Then the active run should be in the experiment
+
+  Scenario: A run is handed to a subprocess through its environment
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    When I start a run in the experiment
+    And a subprocess started with the active run's environment logs param "fold" = "3"
+    Then the active run should have param "fold" = "3"
+    And the experiment should have 1 run
+
+  Scenario: Starting a run without an experiment fails before a request is sent
+    Given an MLflow client for an unreachable server
+    And no MLflow environment variables are set
+    When I attempt to start a run without an experiment ID
+    Then the call should fail with "MLFLOW_EXPERIMENT_NAME"
@@ -0,0 +1,80 @@
+Feature: Recursive artifact download
+  As a developer pulling model directories from runs
+  I want to download an artifact tree concurrently with its sizes verified
+  So that I get a complete copy and a manifest of it without writing a walker
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path                   | content    |
+      | README.md              | read me    |
+      | model.bin              | weights    |
+      | config/train.yaml      | epochs: 10 |
+      | config/eval/split.yaml | test: 0.2  |
+    And I log the local directory to artifact directory "bundle"
+
+  Scenario: A directory tree is downloaded across listing pages
+    Given the tracking server lists artifacts in pages of 2
+    When I download the run's artifacts "bundle" with 3 workers
+    Then the downloaded files should be:
+      | path                          | content    |
+      | bundle/README.md              | read me    |
+      | bundle/model.bin              | weights    |
+      | bundle/config/train.yaml      | epochs: 10 |
+      | bundle/config/eval/split.yaml | test: 0.2  |
+    And the manifest should list "bundle/README.md (7), bundle/config/eval/split.yaml (9), bundle/config/train.yaml (10), bundle/model.bin (7)"
+    And the manifest should count 4 downloaded files of 33 bytes
+    And the manifest's local path should be "bundle"
+    And the tracking server should have served 5 artifact listings and 4 downloads
+
+  Scenario: All of the run's artifacts are downloaded for an empty path
+    When I download the run's artifacts "" with 2 workers
+    Then the manifest should list "bundle/README.md (7), bundle/config/eval/split.yaml (9), bundle/config/train.yaml (10), bundle/model.bin (7)"
+    And the manifest's local path should be ""
+
+  Scenario: Files already present with the listed size are skipped
+    When I download the run's artifacts "bundle" with 4 workers
+    And I download the run's artifacts "bundle" with 4 workers
+    Then the manifest should list "bundle/README.md (7, skipped), bundle/config/eval/split.yaml (9, skipped), bundle/config/train.yaml (10, skipped), bundle/model.bin (7, skipped)"
+    And the manifest should count 0 downloaded files of 0 bytes
+    And the tracking server should have served 8 artifact listings and 4 downloads
+    When I change the downloaded file "bundle/model.bin" to "w"
+    And I download the run's artifacts "bundle" with 4 workers
+    Then the manifest should count 1 downloaded file of 7 bytes
+    And the downloaded files should be:
+      | path                          | content    |
+      | bundle/README.md              | read me    |
+      | bundle/model.bin              | weights    |
+      | bundle/config/train.yaml      | epochs: 10 |
+      | bundle/config/eval/split.yaml | test: 0.2  |
+
+  Scenario: A single file is downloaded
+    When I download the run's artifacts "bundle/config/train.yaml" with 1 workers
+    Then the downloaded files should be:
+      | path                     | content    |
+      | bundle/config/train.yaml | epochs: 10 |
+    And the manifest should list "bundle/config/train.yaml (10)"
+    And the manifest's local path should be "bundle/config/train.yaml"
+
+  Scenario: A file of another size than listed is not put in place
+    Given the tracking server reports "bundle/model.bin" as 5 bytes
+    When I attempt to download the run's artifacts "bundle"
+    Then the call should fail with "downloaded 7 bytes of artifact bundle/model.bin, expected 5"
+    And the download directory should have no "bundle/model.bin"
+
+  Scenario Outline: Downloads that cannot start fail
+    When I attempt to download the run's artifacts "<path>"
+    Then the call should fail with "<error>"
+
+    Examples:
+      | path           | error                 |
+      | bundle/missing | file does not exist   |
+      | missing/x.bin  | file does not exist   |
+      | ../bundle      | invalid artifact path |
+
+  Scenario: A cancelled download stops
+    When I attempt to download the run's artifacts "bundle" with a cancelled context
+    Then the call should fail with "context canceled"
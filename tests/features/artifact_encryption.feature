@@ -0,0 +1,148 @@
+Feature: Encrypted artifacts
+  As a developer logging artifacts that hold sensitive data
+  I want them encrypted before they leave my machine
+  So that neither the tracking server nor the artifact store can read them
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path             | content        |
+      | patients.csv     | id,diagnosis   |
+      | weights/head.bin | secret weights |
+      | notes.txt        | do not share   |
+
+  Scenario: Artifacts are stored encrypted and read as plaintext
+    Given artifact encryption uses a key file
+    When I log the local directory encrypted to artifact directory "data"
+    Then the run should store "data/patients.csv" encrypted without "diagnosis"
+    And the run should store "data/weights/head.bin" encrypted without "secret"
+    And the artifact "data/patients.csv" should contain "id,diagnosis"
+    When I download artifacts "data" of the run
+    Then the downloaded files should be:
+      | path                  | content        |
+      | data/patients.csv     | id,diagnosis   |
+      | data/weights/head.bin | secret weights |
+      | data/notes.txt        | do not share   |
+
+  Scenario: A single file is encrypted with a key from the environment
+    Given artifact encryption uses the key in "MLFLOW_ARTIFACT_ENCRYPTION_KEY"
+    When I log the local file "notes.txt" encrypted to artifact directory ""
+    Then the run should store "notes.txt" encrypted without "share"
+    And the artifact "notes.txt" should contain "do not share"
+
+  Scenario: Recursive downloads and the artifact file system decrypt artifacts
+    Given artifact encryption uses a key file
+    And I log the local directory encrypted to artifact directory "data"
+    When I download the run's artifacts "data" with 2 workers
+    Then the manifest should list "data/notes.txt (12), data/patients.csv (12), data/weights/head.bin (14)"
+    When I open the run's artifact file system
+    Then walking "data" in the artifact file system should give "data/notes.txt (12), data/patients.csv (12), data/weights/, data/weights/head.bin (14)"
+    And reading "data/weights/head.bin" from the artifact file system should give "secret weights"
+    And "data/notes.txt" should be a file of 12 bytes in the artifact file system
+
+  Scenario: Files spanning several segments round trip
+    Given artifact encryption uses a key file
+    And a local file "large.bin" of 200000 random bytes
+    When I log the local file "large.bin" encrypted to artifact directory "model"
+    Then the artifact "model/large.bin" should match the local file "large.bin"
+
+  Scenario: Encrypting without a key provider fails
+    When I attempt to log the local directory encrypted to artifact directory "data"
+    Then the call should fail with "no key provider"
+
+  Scenario: Encrypted artifacts cannot be read without their key
+    Given artifact encryption uses a key file
+    And I log the local directory encrypted to artifact directory "data"
+    When artifact encryption is turned off
+    And I attempt to download artifacts "data" of the run
+    Then the call should fail with "is encrypted"
+    When artifact encryption uses a key file
+    And I attempt to download artifacts "data" of the run
+    Then the call should fail with "failed to decrypt"
+    And the download directory should have no "data/patients.csv"
+
+  Scenario Outline: Tampered ciphertext is rejected
+    Given artifact encryption uses a key file
+    And I log the local directory encrypted to artifact directory "data"
+    And the stored ciphertext of "data/patients.csv" is <change>
+    When I attempt to download artifacts "data" of the run
+    Then the call should fail with "<error>"
+    And the download directory should have no "data/patients.csv"
+
+    Examples:
+      | change    | error             |
+      | altered   | failed to decrypt |
+      | truncated | is truncated      |
+
+  Scenario Outline: Sidecars with another segment size are rejected
+    Given artifact encryption uses a key file
+    And I log the local directory encrypted to artifact directory "data"
+    And the encryption sidecar of "data/patients.csv" gives a segment size of <size> bytes
+    When I attempt to download artifacts "data" of the run
+    Then the call should fail with "unsupported segment size"
+    And the download directory should have no "data/patients.csv"
+
+    Examples:
+      | size             |
+      | 1099511627776    |
+      | 4611686018427387 |
+      | 0                |
+      | -1               |
+      | 1024             |
+
+  Scenario Outline: Encrypted artifacts do not decrypt at another path or run
+    Given <provider>
+    And I log the local directory encrypted to artifact directory "data"
+    And the encrypted artifact "data/patients.csv" is copied to "data/notes.txt"
+    And the encrypted artifact "data/patients.csv" is copied to "data/patients.csv" of a second run
+    When I attempt to download artifacts "data/notes.txt" of the run
+    Then the call should fail with "failed to decrypt artifact data/notes.txt"
+    And reading "data/patients.csv" of the second run should fail with "failed to decrypt"
+    And the artifact "data/patients.csv" should contain "id,diagnosis"
+
+    Examples:
+      | provider                                     |
+      | artifact encryption uses a key file          |
+      | artifact encryption uses KMS key "artifacts" |
+
+  Scenario Outline: An interrupted upload does not break the directory
+    Given artifact encryption uses a key file
+    And I log the local directory encrypted to artifact directory "data"
+    And only the <part> of "data/patients.csv" is copied to "data/partial.csv"
+    When I download artifacts "data" of the run
+    Then the downloaded files should be:
+      | path                  | content        |
+      | data/patients.csv     | id,diagnosis   |
+      | data/weights/head.bin | secret weights |
+      | data/notes.txt        | do not share   |
+    And the download directory should have no "data/partial.csv"
+
+    Examples:
+      | part       |
+      | ciphertext |
+      | sidecar    |
+
+  Scenario: Rotating a key file re-wraps data keys without re-encrypting
+    Given artifact encryption uses a key file
+    And I log the local directory encrypted to artifact directory "data"
+    And I note the stored ciphertext of "data/patients.csv"
+    When artifact encryption rotates to a new key file
+    And I rotate the run's artifact keys
+    Then 3 artifacts should have been re-wrapped
+    And the stored ciphertext of "data/patients.csv" should be unchanged
+    When artifact encryption uses only the newest key file
+    Then the artifact "data/patients.csv" should contain "id,diagnosis"
+    When I rotate the run's artifact keys
+    Then 0 artifacts should have been re-wrapped
+
+  Scenario: Rotating to a new KMS key
+    Given artifact encryption uses KMS key "k1"
+    And I log the local directory encrypted to artifact directory "data"
+    When artifact encryption uses KMS key "k2"
+    And I rotate the run's artifact keys
+    Then 3 artifacts should have been re-wrapped
+    When KMS key "k1" is disabled
+    Then the artifact "data/weights/head.bin" should contain "secret weights"
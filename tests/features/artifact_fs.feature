@@ -0,0 +1,81 @@
+Feature: Run artifacts as a file system
+  As a developer using standard Go tooling on run artifacts
+  I want a run's artifacts as an io/fs.FS
+  So that fs.WalkDir, template.ParseFS and http.FileServer work on them directly
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path                    | content      |
+      | model.bin               | weights      |
+      | config/train.yaml       | epochs: 10   |
+      | config/eval/split.yaml  | test: 0.2    |
+      | templates/greeting.tmpl | Hello {{.}}! |
+    And I log the local directory to artifact directory "bundle"
+
+  Scenario: Walking the file system lists the artifacts recursively
+    When I open the run's artifact file system
+    Then walking "." in the artifact file system should give "bundle/, bundle/config/, bundle/config/eval/, bundle/config/eval/split.yaml (9), bundle/config/train.yaml (10), bundle/model.bin (7), bundle/templates/, bundle/templates/greeting.tmpl (12)"
+    And walking "bundle/config" in the artifact file system should give "bundle/config/eval/, bundle/config/eval/split.yaml (9), bundle/config/train.yaml (10)"
+    And reading "bundle/config/train.yaml" from the artifact file system should give "epochs: 10"
+
+  Scenario: Listings take every page and are fetched once
+    Given a local directory with files:
+      | path  | content |
+      | a.txt | 1       |
+      | b.txt | 22      |
+      | c.txt | 333     |
+      | d.txt | 4444    |
+      | e.txt | 55555   |
+    And I log the local directory to artifact directory "metrics"
+    And the tracking server lists artifacts in pages of 2
+    When I open the run's artifact file system
+    Then walking "metrics" in the artifact file system should give "metrics/a.txt (1), metrics/b.txt (2), metrics/c.txt (3), metrics/d.txt (4), metrics/e.txt (5)"
+    And the tracking server should have served 4 artifact listings and 0 downloads
+    And walking "metrics" in the artifact file system should give "metrics/a.txt (1), metrics/b.txt (2), metrics/c.txt (3), metrics/d.txt (4), metrics/e.txt (5)"
+    And the tracking server should have served 4 artifact listings and 0 downloads
+
+  Scenario: Artifacts are described as files and directories
+    When I open the run's artifact file system
+    Then "bundle/config/train.yaml" should be a file of 10 bytes in the artifact file system
+    And "bundle/config" should be a directory in the artifact file system
+    And "." should be a directory in the artifact file system
+    And opening "bundle/missing.yaml" in the artifact file system should not exist
+    And opening "missing/model.bin" in the artifact file system should not exist
+    And opening "../secrets" in the artifact file system should be invalid
+    And opening "/bundle" in the artifact file system should be invalid
+    And opening "bundle/" in the artifact file system should be invalid
+
+  Scenario: Files are downloaded when first read
+    When I open the run's artifact file system
+    And I open "bundle/model.bin" in the artifact file system
+    Then the tracking server should have served 1 artifact listing and 0 downloads
+    And reading the open artifact file should give "weights"
+    And the tracking server should have served 1 artifact listing and 1 download
+
+  Scenario: Downloads are kept in the local cache
+    When I open the run's artifact file system with a local cache
+    Then reading "bundle/model.bin" from the artifact file system should give "weights"
+    And the artifact cache should have "bundle/model.bin" for the run
+    When I open the run's artifact file system with a local cache
+    Then reading "bundle/model.bin" from the artifact file system should give "weights"
+    And the tracking server should have served 2 artifact listings and 1 download
+
+  Scenario: The file system passes the standard library's checks
+    When I open the run's artifact file system
+    Then the artifact file system should pass fstest with "bundle/model.bin, bundle/config/train.yaml, bundle/config/eval/split.yaml"
+
+  Scenario: Artifacts can be served with http.FileServer
+    When I open the run's artifact file system
+    And I serve the artifact file system over HTTP
+    Then a GET of "/bundle/config/train.yaml" should return 200 with "epochs: 10"
+    And a GET of "/bundle/" should return 200 with "model.bin"
+    And a GET of "/bundle/model.bin" for bytes "2-4" should return 206 with "igh"
+    And a GET of "/bundle/missing.yaml" should return 404 with "not found"
+
+  Scenario: Templates can be parsed from the artifacts
+    When I open the run's artifact file system
+    Then executing template "greeting.tmpl" parsed from "bundle/templates/*.tmpl" in the artifact file system with "MLflow" should give "Hello MLflow!"
@@ -0,0 +1,101 @@
+Feature: Artifact repositories
+  As an engineer whose runs store artifacts in different places
+  I want one interface over every artifact store, picked from the artifact URI
+  So that the same code works whether artifacts live on disk, behind the tracking server or in our own store
+
+  Scenario Outline: Artifact URIs resolve to the backend for their scheme
+    Given an MLflow client for an unreachable server
+    When I resolve the artifact repository of "<uri>"
+    Then the artifact repository should be a <backend> repository
+
+    Examples:
+      | uri                                                              | backend |
+      | file:///var/mlruns/1/abc/artifacts                               | local   |
+      | /var/mlruns/1/abc/artifacts                                      | local   |
+      | mlflow-artifacts:/1/abc/artifacts                                | proxy   |
+      | mlflow-artifacts://artifacts.internal/1/abc/artifacts            | proxy   |
+      | http://mlflow.internal:5000/api/2.0/mlflow-artifacts/artifacts/1 | proxy   |
+
+  Scenario Outline: The client's token is only sent to the tracking server's host
+    Given an MLflow client with token "s3cret" for an artifact proxy recording authorization
+    When I list the artifact repository of "<uri>"
+    Then the artifact proxy should have received <authorization>
+
+    Examples:
+      | uri                                                             | authorization  |
+      | mlflow-artifacts:/1/abc/artifacts                               | token "s3cret" |
+      | mlflow-artifacts://{tracking host}/1/abc/artifacts              | token "s3cret" |
+      | mlflow-artifacts://{other host}/1/abc/artifacts                 | no token       |
+      | http://{tracking host}/api/2.0/mlflow-artifacts/artifacts/1/abc | token "s3cret" |
+      | http://{other host}/api/2.0/mlflow-artifacts/artifacts/1/abc    | no token       |
+
+  Scenario: A file URI is a local directory
+    Given an MLflow client for an unreachable server
+    When I resolve the artifact repository of "file:///var/mlruns/1/abc%20def/artifacts"
+    Then the local artifact repository root should be "/var/mlruns/1/abc def/artifacts"
+
+  Scenario Outline: Artifact URIs without a backend are rejected
+    Given an MLflow client for an unreachable server
+    When I resolve the artifact repository of "<uri>"
+    Then the call should fail with "<error>"
+
+    Examples:
+      | uri                             | error                                    |
+      | hdfs://namenode/mlruns/1        | no artifact repository for scheme        |
+      | https://files.internal/mlruns/1 | is not under the artifact proxy endpoint |
+      | file://fileserver/mlruns/1      | names a remote host                      |
+
+  Scenario Outline: Every backend lists, uploads, downloads, stats and deletes
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And an artifact repository on a <backend>
+    When I upload "epochs: 10" to "config/train.yaml" in the repository
+    And I upload "weights" to "model.bin" in the repository
+    Then listing "" in the repository should give "config/, model.bin (7)"
+    And listing "config" in the repository should give "config/train.yaml (10)"
+    And "config" should be a directory in the repository
+    And "config/train.yaml" should be a file in the repository
+    And "config/train.yaml" should contain "epochs: 10" in the repository
+    And "config/missing.yaml" should not exist in the repository
+    And listing "missing" in the repository should give ""
+    When I delete "config" from the repository
+    Then listing "" in the repository should give "model.bin (7)"
+    And "config/train.yaml" should not exist in the repository
+
+    Examples:
+      | backend               |
+      | local directory       |
+      | tracking server proxy |
+      | S3 bucket             |
+      | MinIO bucket          |
+      | Azure container       |
+      | Azurite container     |
+
+  Scenario: Run artifact helpers work with a local artifact store
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment storing artifacts in a local directory exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path              | content    |
+      | model.bin         | weights    |
+      | config/train.yaml | epochs: 10 |
+    Then the run's artifact repository should be a local repository
+    When I log the local directory to artifact directory "bundle"
+    Then the local artifact store should have file "bundle/config/train.yaml" with "epochs: 10"
+    And the local artifact store file "bundle/config/train.yaml" should have mode "0644"
+    When I download artifacts "bundle" of the run
+    Then the downloaded files should be:
+      | path                     | content    |
+      | bundle/model.bin         | weights    |
+      | bundle/config/train.yaml | epochs: 10 |
+
+  Scenario: A registered backend serves its scheme
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment storing artifacts in a custom "memory" store exists
+    And a run exists in the experiment
+    Then the run's artifact repository should be a memory repository
+    When I log the text "epochs: 10" to artifact "config/notes.txt"
+    Then the artifact "config/notes.txt" should contain "epochs: 10"
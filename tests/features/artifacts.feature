@@ -0,0 +1,93 @@
+Feature: Artifact upload and download
+  As an engineer who keeps models, plots and configs with a run
+  I want to upload and download run artifacts through the tracking server
+  So that Go jobs can share files with runs logged from any other client
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path                  | content     |
+      | model.bin             | weights     |
+      | config/train.yaml     | epochs: 10  |
+      | config/data/split.txt | train=0.8   |
+      | plots/loss 1.svg      | <svg></svg> |
+
+  Scenario: A file is logged under an artifact directory with its own name
+    When I log the local file "config/train.yaml" to artifact directory "configs"
+    Then the run should have artifact "configs/train.yaml"
+    And the artifact "configs/train.yaml" should contain "epochs: 10"
+
+  Scenario: A file is logged at the artifact root
+    When I log the local file "model.bin" to artifact directory ""
+    Then the run should have artifact "model.bin"
+
+  Scenario: A directory round-trips with its layout
+    When I log the local directory to artifact directory "bundle"
+    And I download artifacts "bundle" of the run
+    Then the downloaded files should be:
+      | path                         | content     |
+      | bundle/model.bin             | weights     |
+      | bundle/config/train.yaml     | epochs: 10  |
+      | bundle/config/data/split.txt | train=0.8   |
+      | bundle/plots/loss 1.svg      | <svg></svg> |
+    And the download should be at "bundle"
+
+  Scenario: All artifacts of a run are downloaded
+    When I log the local directory to artifact directory ""
+    And I download artifacts "" of the run
+    Then the downloaded files should be:
+      | path                  | content     |
+      | model.bin             | weights     |
+      | config/train.yaml     | epochs: 10  |
+      | config/data/split.txt | train=0.8   |
+      | plots/loss 1.svg      | <svg></svg> |
+
+  Scenario: A single artifact file is downloaded to a chosen path
+    When I log the local directory to artifact directory ""
+    And I download artifact "config/train.yaml" of the run to "nested/train.yaml"
+    Then the downloaded files should be:
+      | path              | content    |
+      | nested/train.yaml | epochs: 10 |
+
+  Scenario: DownloadArtifacts of a file downloads just that file
+    When I log the local directory to artifact directory ""
+    And I download artifacts "config/data/split.txt" of the run
+    Then the downloaded files should be:
+      | path                  | content   |
+      | config/data/split.txt | train=0.8 |
+    And the download should be at "config/data/split.txt"
+
+  Scenario: Downloaded files are readable like any other file
+    When I log the local directory to artifact directory ""
+    And I download artifacts "config" of the run
+    Then the downloaded file "config/train.yaml" should have mode "0644"
+    When I download artifact "model.bin" of the run to "nested/model.bin"
+    Then the downloaded file "nested/model.bin" should have mode "0644"
+
+  Scenario: File names with consecutive dots are valid artifact paths
+    When I log the local file "model.bin" to artifact directory "v1..v2"
+    And I download artifacts "v1..v2" of the run
+    Then the downloaded files should be:
+      | path             | content |
+      | v1..v2/model.bin | weights |
+
+  Scenario: Downloading a missing artifact fails
+    When I attempt to download artifact "missing.txt" of the run
+    Then the call should fail with "RESOURCE_DOES_NOT_EXIST"
+    When I attempt to download artifacts "missing/dir" of the run
+    Then the call should fail with "does not exist"
+
+  Scenario Outline: Artifact paths cannot leave the run's artifact root
+    Given an MLflow client for an unreachable server
+    When I attempt to <transfer> "<path>" of run "abc"
+    Then the call should fail with "invalid artifact path"
+
+    Examples:
+      | transfer                         | path                   |
+      | log a file to artifact directory | ../other-run           |
+      | download artifacts               | ../other-run/notes.txt |
+      | download artifacts               | a/../../b              |
+      | download artifacts               | a\\..\\..\\b           |
@@ -0,0 +1,36 @@
+Feature: Audit log of mutating operations
+  As a platform owner
+  I want every mutating call to be recorded in an append-only audit log
+  So that I can tell who changed what in the tracking server and model registry
+
+  Scenario: Successful mutations are recorded with the actor
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And audit logging is enabled with actor "ci-bot"
+    When I create an experiment with a unique name
+    And I create a run in the experiment
+    Then the audit log should contain a "CreateExperiment" record with outcome "success"
+    And the audit log should contain a "CreateRun" record with outcome "success"
+    And every audit record should have actor "ci-bot"
+
+  Scenario: Failed mutations are recorded with the API error code
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And audit logging is enabled with actor "ci-bot"
+    When I attempt to delete the experiment with ID "999999999"
+    Then the audit log should contain a "DeleteExperiment" record with outcome "failure"
+    And the last audit record should have error code "RESOURCE_DOES_NOT_EXIST"
+
+  Scenario: Sensitive values are redacted from the audit payload
+    Given an MLflow client for an unreachable server
+    And audit logging is enabled with actor "ci-bot"
+    When I attempt to log parameter "db_password" with value "hunter2" to run "run-1"
+    Then the audit log should contain a "LogParam" record with outcome "failure"
+    And the last audit record should target "run" "run-1"
+    And the audit log should not contain "hunter2"
+
+  Scenario: File audit sink rotates when it grows too large
+    Given a file audit sink limited to 512 bytes with 2 backups
+    When I write 20 audit records to the file sink
+    Then the audit log directory should contain 3 files
+    And every audit file should contain valid JSON lines
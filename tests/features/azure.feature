@@ -0,0 +1,151 @@
+Feature: Azure Blob Storage artifact storage
+  As an engineer whose experiments store artifacts in Azure Blob Storage
+  I want the client to read and write wasbs:// artifact locations directly
+  So that artifacts go straight between my code and the storage account, or the Azurite emulator
+
+  Scenario: Requests are signed with Shared Key
+    When I sign an Azure test request with Shared Key
+    Then the Authorization header should be "SharedKey acct:fGQJYoLrOQpzxq8bS8BBN1myN5Kb5fq/dhKOWKJsQlk="
+
+  Scenario Outline: Azure artifact URIs resolve to an account, container and path
+    Given an MLflow client for an unreachable server
+    And an Azure store
+    And Azure credentials from a connection string with an account key
+    When I resolve the artifact repository of "<uri>"
+    Then the artifact repository should be a azure repository
+    And the Azure repository should have account "devstoreaccount1", container "<container>" and prefix "<prefix>"
+
+    Examples:
+      | uri                                                                      | container | prefix          |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1/abc/artifacts    | mlflow    | 1/abc/artifacts |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1/abc/artifacts/   | mlflow    | 1/abc/artifacts |
+      | wasbs://models@devstoreaccount1.blob.core.windows.net                    | models    |                 |
+      | wasbs://mlflow@devstoreaccount1.blob.core.chinacloudapi.cn/experiments/7 | mlflow    | experiments/7   |
+
+  Scenario Outline: Invalid Azure configuration is rejected
+    Given an MLflow client for an unreachable server
+    And an Azure store
+    And the environment variable "<variable>" is "<value>"
+    When I resolve the artifact repository of "<uri>"
+    Then the call should fail with "<error>"
+
+    Examples:
+      | uri                                                     | variable                        | value                                                                                                   | error                                                             |
+      | wasbs://devstoreaccount1.blob.core.windows.net/1        | AZURE_STORAGE_CONNECTION_STRING | UseDevelopmentStorage=true                                                                              | is not a wasbs://container@account.blob.core.windows.net/path URI |
+      | wasb://mlflow@devstoreaccount1.blob.core.windows.net/1  | AZURE_STORAGE_CONNECTION_STRING | UseDevelopmentStorage=true                                                                              | no artifact repository for scheme                                 |
+      | wasbs://mlflow@storage.example.com/1                    | AZURE_STORAGE_CONNECTION_STRING | UseDevelopmentStorage=true                                                                              | is not a wasbs://container@account.blob.core.windows.net/path URI |
+      | wasbs://mlflow@team.blob.core.windows.net/1             | AZURE_STORAGE_CONNECTION_STRING | UseDevelopmentStorage=true                                                                              | are for account devstoreaccount1, not team                        |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | AccountName=devstoreaccount1                                                                            | neither AccountKey nor SharedAccessSignature                      |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | AccountName=devstoreaccount1;AccountKey=not base64!                                                     | invalid account key                                               |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | garbage                                                                                                 | invalid connection string setting                                 |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_ACCESS_KEY        | not base64!                                                                                             | invalid AZURE_STORAGE_ACCESS_KEY                                  |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | BlobEndpoint=https://team.blob.core.windows.net;SharedAccessSignature=sv=1                              | are for account team, not devstoreaccount1                        |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | BlobEndpoint=http://127.0.0.1:10000/team;SharedAccessSignature=sv=1                                     | are for account team, not devstoreaccount1                        |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | BlobEndpoint=https://storage.example.com;SharedAccessSignature=sv=1                                     | cannot tell the account of Azure blob endpoint                    |
+      | wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1 | AZURE_STORAGE_CONNECTION_STRING | AccountName=devstoreaccount1;SharedAccessSignature=sv=1;BlobEndpoint=https://team.blob.core.windows.net | is for account team, not devstoreaccount1                         |
+
+  Scenario: Repositories fail clearly without credentials
+    Given an MLflow client for an unreachable server
+    And an Azure store
+    When I resolve the artifact repository of "wasbs://mlflow@devstoreaccount1.blob.core.windows.net/1"
+    Then the call should fail with "no Azure credentials for account devstoreaccount1"
+
+  Scenario: Runs of an experiment with an Azure artifact location log and download artifacts directly
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an Azure store
+    And Azure credentials from a connection string with an account key
+    And an experiment storing artifacts in the Azure store exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path              | content    |
+      | model.bin         | weights    |
+      | config/train.yaml | epochs: 10 |
+    Then the run's artifact repository should be a azure repository
+    When I log the local directory to artifact directory "bundle"
+    Then the Azure store should have run artifact "bundle/config/train.yaml" with "epochs: 10"
+    When I download artifacts "bundle" of the run
+    Then the downloaded files should be:
+      | path                     | content    |
+      | bundle/model.bin         | weights    |
+      | bundle/config/train.yaml | epochs: 10 |
+
+  Scenario Outline: Requests are authorized the way MLflow configures them
+    Given an MLflow client for an unreachable server
+    And an Azure store
+    And Azure credentials from <source>
+    When I open the Azure repository of "wasbs://mlflow@devstoreaccount1.blob.core.windows.net/credentials" with the environment's credentials
+    And I upload "weights" to "model.bin" in the repository
+    Then "model.bin" should contain "weights" in the repository
+    And the Azure store should have seen requests authorized with <authorization>
+
+    Examples:
+      | source                                  | authorization |
+      | a connection string with an account key | Shared Key    |
+      | a connection string with a SAS token    | a SAS token   |
+      | an account key                          | Shared Key    |
+
+  Scenario: Requests signed with the wrong account key are rejected
+    Given an Azure artifact repository with blocks of 1024 bytes, 2 at a time
+    Then uploading "model.bin" with the wrong account key should fail with "AuthenticationFailed"
+    And "model.bin" should not exist in the repository
+
+  Scenario: Directories list across pages
+    Given an Azure artifact repository with blocks of 1024 bytes, 2 at a time
+    When I upload "1" to "metrics/a.txt" in the repository
+    And I upload "22" to "metrics/b.txt" in the repository
+    And I upload "333" to "metrics/c.txt" in the repository
+    And I upload "4444" to "metrics/d.txt" in the repository
+    And I upload "55555" to "metrics/plots/e.png" in the repository
+    Then listing "metrics" in the repository should give "metrics/a.txt (1), metrics/b.txt (2), metrics/c.txt (3), metrics/d.txt (4), metrics/plots/"
+    When I delete "metrics" from the repository
+    Then listing "" in the repository should give ""
+
+  Scenario: Small artifacts take a single request each way
+    Given an Azure artifact repository with blocks of 1024 bytes, 2 at a time
+    When I upload 1024 random bytes to "model.bin" in the repository
+    Then the Azure store should have received 1 single upload and 0 blocks in 0 block lists
+    And "model.bin" should download intact from the repository
+    And the Azure store should have served 1 ranged request
+
+  Scenario: Empty artifacts are uploaded and downloaded
+    Given an Azure artifact repository with blocks of 1024 bytes, 2 at a time
+    When I upload "" to "empty.txt" in the repository
+    Then "empty.txt" should be a file in the repository
+    And "empty.txt" should contain "" in the repository
+
+  Scenario Outline: Large artifacts are uploaded in blocks and downloaded in concurrent ranges
+    Given an Azure artifact repository with blocks of 1024 bytes, <concurrency> at a time
+    When I upload <size> random bytes of <known> size to "checkpoints/model.bin" in the repository
+    Then the Azure store should have received 0 single uploads and <blocks> blocks in 1 block list
+    And "checkpoints/model.bin" should download intact from the repository
+    And the Azure store should have served <blocks> ranged requests
+    And "checkpoints/model.bin" should be a file in the repository
+
+    Examples:
+      | size  | known   | concurrency | blocks |
+      | 10000 | known   | 3           | 10     |
+      | 10240 | known   | 1           | 10     |
+      | 2049  | known   | 8           | 3      |
+      | 5000  | unknown | 2           | 5      |
+
+  Scenario: Failing blocks are retried
+    Given an Azure artifact repository with blocks of 1024 bytes, 2 at a time
+    And Azure block 2 fails 2 times with status 503
+    When I upload 5000 random bytes to "model.bin" in the repository
+    Then Azure block 2 should have been attempted 3 times
+    And "model.bin" should download intact from the repository
+
+  Scenario: An upload with a block that keeps failing is not committed
+    Given an Azure artifact repository with blocks of 1024 bytes, 2 at a time
+    And Azure block 3 always fails with status 403
+    When I attempt to upload 5000 random bytes to "model.bin" in the repository
+    Then the call should fail with "failed to upload part 3"
+    And Azure block 3 should have been attempted 1 times
+    And "model.bin" should not exist in the repository
+
+  Scenario: Large artifacts round trip through Azurite
+    Given an artifact repository on a Azurite container
+    When I upload 3670016 random bytes to "checkpoints/model.bin" in the repository
+    Then "checkpoints/model.bin" should download intact from the repository
+    And listing "checkpoints" in the repository should give "checkpoints/model.bin (3670016)"
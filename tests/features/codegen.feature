@@ -0,0 +1,38 @@
+Feature: Client API generated from the MLflow protos
+  As a maintainer of the client
+  I want the REST surface generated from MLflow's vendored protobuf definitions
+  So that the client keeps pace with MLflow releases and drift is caught
+
+  Scenario: The generated API matches the vendored protos
+    Given the generator config "../protos/protogen.json"
+    When I check the generated API
+    Then the generated API should be up to date
+    And the generated API should provide method "CreateLoggedModel"
+    And the generated API should provide method "SearchTraces"
+    And the generated API should provide method "DeleteRuns"
+    And the generated API should provide method "CreateWebhook"
+    And the generated API should not provide method "CreateExperiment"
+
+  Scenario: A modified vendored proto is reported as drift
+    Given a copy of the vendored protos and generated API
+    And I append "// local edit" to the vendored "service.proto"
+    When I check the generated API
+    Then the check should report drift mentioning "service.proto does not match the checksum recorded for MLflow"
+
+  Scenario: A stale generated file is reported as drift
+    Given a copy of the vendored protos and generated API
+    And I append "// local edit" to the generated API
+    When I check the generated API
+    Then the check should report drift mentioning "is out of date with the MLflow"
+
+  Scenario: Generated methods validate path parameters before sending
+    Given an MLflow client for an unreachable server
+    When I attempt to get the logged model with ID ""
+    Then the call should fail with "model_id is required"
+
+  Scenario: Generated mutations are audited
+    Given an MLflow client for an unreachable server
+    And audit logging is enabled with actor "ci-bot"
+    When I attempt to delete the logged model with ID "m-123"
+    Then the audit log should contain a "DeleteLoggedModel" record with outcome "failure"
+    And the last audit record should target "logged_model" "m-123"
@@ -0,0 +1,103 @@
+Feature: Content-addressed artifacts
+  As a developer logging the same tokenizers and base weights from many runs
+  I want artifact content stored once per experiment
+  So that repeated files cost neither storage nor upload time
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path                | content      |
+      | tokenizer.json      | {"vocab": 3} |
+      | weights/base.bin    | base weights |
+      | config.yaml         | lr: 0.1      |
+      | copy/tokenizer.json | {"vocab": 3} |
+
+  Scenario: Identical content is uploaded and stored once
+    When I log the local directory content-addressed to artifact directory "model"
+    Then the experiment's blob store should hold 3 blobs
+    And the tracking server should have received 3 blob uploads
+    And the run should store pointer files for "config.yaml, copy/tokenizer.json, tokenizer.json, weights/base.bin" under "model"
+    When a second run in the experiment logs the local directory content-addressed to artifact directory "model"
+    Then the experiment's blob store should hold 3 blobs
+    And the tracking server should have received 3 blob uploads
+
+  Scenario: A single file is logged content-addressed
+    When I log the local file "config.yaml" content-addressed to artifact directory ""
+    Then the run should store pointer files for "config.yaml" under ""
+    And the artifact "config.yaml" should contain "lr: 0.1"
+
+  Scenario: Downloads read pointer files as their content
+    Given I log the local directory content-addressed to artifact directory "model"
+    And I log the local file "config.yaml" to artifact directory "plain"
+    Then the artifact "model/config.yaml" should contain "lr: 0.1"
+    When I download artifacts "" of the run
+    Then the downloaded files should be:
+      | path                      | content      |
+      | model/tokenizer.json      | {"vocab": 3} |
+      | model/weights/base.bin    | base weights |
+      | model/config.yaml         | lr: 0.1      |
+      | model/copy/tokenizer.json | {"vocab": 3} |
+      | plain/config.yaml         | lr: 0.1      |
+
+  Scenario: Recursive downloads and the artifact file system read pointer files as their content
+    Given I log the local directory content-addressed to artifact directory "model"
+    When I download the run's artifacts "model" with 2 workers
+    Then the manifest should list "model/config.yaml (7), model/copy/tokenizer.json (12), model/tokenizer.json (12), model/weights/base.bin (12)"
+    When I open the run's artifact file system
+    Then walking "model" in the artifact file system should give "model/config.yaml (7), model/copy/, model/copy/tokenizer.json (12), model/tokenizer.json (12), model/weights/, model/weights/base.bin (12)"
+    And reading "model/weights/base.bin" from the artifact file system should give "base weights"
+    And "model/config.yaml" should be a file of 7 bytes in the artifact file system
+
+  Scenario: A blob that does not match its digest is rejected
+    Given I log the local directory content-addressed to artifact directory "model"
+    And the blob of "model/config.yaml" is overwritten with "lr: 9.9"
+    When I attempt to download artifacts "model" of the run
+    Then the call should fail with "does not match its SHA-256"
+    And the download directory should have no "model/config.yaml"
+
+  Scenario: Pointer files naming another blob store are rejected
+    Given I log the local directory content-addressed to artifact directory "model"
+    And the pointer file of "model/config.yaml" names a blob store on another host holding "lr: 9.9"
+    When I attempt to download artifacts "model" of the run
+    Then the call should fail with "not the artifact location of run"
+    And the other host should have received no requests
+    And the download directory should have no "model/config.yaml"
+
+  Scenario: Blobs no run refers to are collected
+    Given I log the local directory content-addressed to artifact directory "model"
+    And a second run in the experiment logs the local directory content-addressed to artifact directory "model"
+    And a local directory with files:
+      | path      | content   |
+      | extra.txt | only here |
+    And the second run logs the local file "extra.txt" content-addressed to artifact directory "extra"
+    Then the experiment's blob store should hold 4 blobs
+    When I collect the experiment's artifact blobs as a dry run
+    Then the collection should have found 0 unreferenced blobs of 0 bytes and 4 referenced
+    When I delete "extra" from the second run's artifacts
+    And I collect the experiment's artifact blobs as a dry run
+    Then the collection should have found 1 unreferenced blob of 9 bytes and 3 referenced
+    And the experiment's blob store should hold 4 blobs
+    When I collect the experiment's artifact blobs
+    Then the collection should have found 1 unreferenced blob of 9 bytes and 3 referenced
+    And the experiment's blob store should hold 3 blobs
+    And the artifact "model/config.yaml" should contain "lr: 0.1"
+    And reading "extra/extra.txt" of the second run should fail with "file does not exist"
+
+  Scenario: Blobs of deleted runs are kept
+    Given I log the local directory content-addressed to artifact directory "model"
+    And a second run in the experiment logs the local directory content-addressed to artifact directory "model"
+    When I delete "model" from the second run's artifacts
+    And I delete the run
+    And I collect the experiment's artifact blobs
+    Then the collection should have found 0 unreferenced blobs of 0 bytes and 3 referenced
+    And the experiment's blob store should hold 3 blobs
+
+  Scenario: Blobs that another experiment with the same artifact location refers to are kept
+    Given a run of a second experiment with the same artifact location logs the local directory content-addressed to artifact directory "model"
+    Then the experiment's blob store should hold 3 blobs
+    When I collect the experiment's artifact blobs
+    Then the collection should have found 0 unreferenced blobs of 0 bytes and 3 referenced
+    And the experiment's blob store should hold 3 blobs
@@ -0,0 +1,67 @@
+Feature: Idempotent experiments and resumable runs
+  As an engineer running jobs that restart on preemption
+  I want to get or create experiments and resume runs by a key
+  So that a restarted job lands in the same experiment and run
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+
+  Scenario: An experiment is created once and then reused
+    When I get or create an experiment with a unique name
+    Then the experiment should have been created
+    When I get or create the experiment again
+    Then the experiment should not have been created
+    And both calls should return the same experiment
+
+  Scenario: Concurrent get-or-create calls agree on one experiment
+    When 8 workers get or create an experiment with the same unique name at once
+    Then every worker should get the same experiment
+    And exactly 1 worker should have created it
+
+  Scenario: A deleted experiment is only reused when restoring is allowed
+    Given an experiment with a unique name exists
+    And I delete the experiment
+    When I attempt to get or create the experiment again
+    Then the call should fail with "is deleted"
+    When I get or create the experiment again, restoring it if deleted
+    Then the experiment should not have been created
+    And the experiment should be restored
+
+  Scenario: A run is resumed by its idempotency key
+    Given an experiment with a unique name exists
+    When I resume or start the run with key "train-job-42"
+    Then the run should have been started
+    When the resumed run ends with status "FAILED"
+    And I resume or start the run with key "train-job-42"
+    Then the run should have been resumed
+    And the resumed run should be the first one
+    And the resumed run should have status "RUNNING"
+
+  Scenario: Different keys get different runs
+    Given an experiment with a unique name exists
+    When I resume or start the run with key "fold-1"
+    And I resume or start the run with key "fold-2"
+    Then the experiment should have 2 runs
+
+  Scenario: Concurrent starts with one key all get a run that later starts resume
+    Given an experiment with a unique name exists
+    When 5 workers resume or start the run with key "sweep-7" at once
+    And I resume or start the run with key "sweep-7"
+    Then the run should have been resumed
+    And the resumed run should be one the workers got
+
+  Scenario: Duplicate runs are cleaned up despite a policy on run deletion
+    Given an experiment with a unique name exists
+    And a policy rule "keep-runs" blocking "DeleteRun" for names matching "*"
+    And the policy is installed on the client
+    And another process started a run with key "sweep-8" that the next search misses
+    When I resume or start the run with key "sweep-8"
+    Then the run should have been resumed
+    And the resumed run should be the first one
+    And the experiment should have 1 run
+
+  Scenario: An idempotency key is required
+    Given an MLflow client for an unreachable server
+    When I attempt to resume or start a run with an empty key
+    Then the call should fail with "idempotency key is required"
@@ -0,0 +1,138 @@
+Feature: Go test and benchmark results
+  As an engineer tracking the performance of Go inference code
+  I want go test and benchmark results recorded as MLflow runs
+  So that CI can compare ns/op and allocations across commits without custom scripts
+
+  Scenario: go test -json output is parsed into a report
+    Given go test printed:
+      """
+      {"Action":"start","Package":"example.com/infer"}
+      {"Action":"run","Package":"example.com/infer","Test":"TestPredict"}
+      {"Action":"output","Package":"example.com/infer","Test":"TestPredict","Output":"=== RUN   TestPredict\n"}
+      {"Action":"run","Package":"example.com/infer","Test":"TestPredict/batch=1"}
+      {"Action":"pass","Package":"example.com/infer","Test":"TestPredict/batch=1","Elapsed":0}
+      {"Action":"pass","Package":"example.com/infer","Test":"TestPredict","Elapsed":0}
+      {"Action":"skip","Package":"example.com/infer","Test":"TestGPU","Elapsed":0}
+      {"Action":"output","Package":"example.com/infer","Output":"goos: linux\n"}
+      {"Action":"output","Package":"example.com/infer","Output":"goarch: amd64\n"}
+      {"Action":"output","Package":"example.com/infer","Output":"cpu: Intel(R) Xeon(R) Processor\n"}
+      {"Action":"output","Package":"example.com/infer","Test":"BenchmarkPredict/batch=64","Output":"BenchmarkPredict/batch=64-8   \t"}
+      {"Action":"output","Package":"example.com/infer","Test":"BenchmarkPredict/batch=64","Output":"    5000\t    213.2 ns/op\t   3.500 rows/op\t   112 B/op\t   1 allocs/op\n"}
+      {"Action":"output","Package":"example.com/infer","Output":"BenchmarkPredict/batch=64-8   \t    5000\t    209.0 ns/op\t   3.500 rows/op\t   112 B/op\t   1 allocs/op\n"}
+      {"Action":"pass","Package":"example.com/infer","Elapsed":1.5}
+      {"Action":"run","Package":"example.com/tokenize","Test":"TestSplit"}
+      {"Action":"fail","Package":"example.com/tokenize","Test":"TestSplit","Elapsed":0.01}
+      {"Action":"fail","Package":"example.com/tokenize","Elapsed":0.5}
+      """
+    When I parse the go test output
+    Then the report should count 2 passed, 1 failed and 1 skipped tests
+    And the failed tests should be "example.com/tokenize.TestSplit"
+    And the failed packages should be "example.com/tokenize"
+    And the report should have benchmarks:
+      | name                      | procs | iterations | unit      | value |
+      | BenchmarkPredict/batch=64 | 8     | 5000       | ns/op     | 213.2 |
+      | BenchmarkPredict/batch=64 | 8     | 5000       | rows/op   | 3.5   |
+      | BenchmarkPredict/batch=64 | 8     | 5000       | allocs/op | 1     |
+      | BenchmarkPredict/batch=64 | 8     | 5000       | ns/op     | 209   |
+    And the report should be from "linux" "amd64" on "Intel(R) Xeon(R) Processor"
+
+  Scenario: Plain go test output is parsed into a report
+    Given go test printed:
+      """
+      === RUN   TestPredict
+      --- PASS: TestPredict (0.00s)
+      === RUN   TestSplit
+          split_test.go:12: got 3 tokens, want 4
+      --- FAIL: TestSplit (0.00s)
+          --- FAIL: TestSplit/unicode (0.00s)
+      --- SKIP: TestGPU (0.00s)
+      goos: linux
+      goarch: arm64
+      pkg: example.com/tokenize
+      BenchmarkSplit	    1000	      1200 ns/op	     64 B/op	       2 allocs/op
+      FAIL
+      FAIL	example.com/tokenize	0.250s
+      """
+    When I parse the go test output
+    Then the report should count 1 passed, 2 failed and 1 skipped tests
+    And the failed tests should be "example.com/tokenize.TestSplit, example.com/tokenize.TestSplit/unicode"
+    And the failed packages should be "example.com/tokenize"
+    And the report should have benchmarks:
+      | name           | procs | iterations | unit      | value |
+      | BenchmarkSplit | 1     | 1000       | ns/op     | 1200  |
+      | BenchmarkSplit | 1     | 1000       | B/op      | 64    |
+      | BenchmarkSplit | 1     | 1000       | allocs/op | 2     |
+
+  Scenario: Benchmarks run with testing.Benchmark are added to a report
+    When I record a benchmark "BenchmarkSum" that reports 42 "items/op" with testing.Benchmark
+    Then the report should have a positive "ns/op" for "BenchmarkSum"
+    And the report should have 42 "items/op" for "BenchmarkSum"
+    And the report should have 0 "allocs/op" for "BenchmarkSum"
+
+  Scenario Outline: Benchmark values get valid MLflow metric keys
+    Then the metric key of "<benchmark>" in "<unit>" should be "<key>"
+
+    Examples:
+      | benchmark                 | unit      | key                                     |
+      | BenchmarkPredict          | ns/op     | BenchmarkPredict/ns_per_op              |
+      | BenchmarkPredict/batch=64 | allocs/op | BenchmarkPredict/batch_64/allocs_per_op |
+      | BenchmarkCopy             | MB/s      | BenchmarkCopy/MB_per_s                  |
+
+  Scenario: A report is logged as a run tagged with the commit
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And go test printed:
+      """
+      {"Action":"start","Package":"example.com/infer"}
+      {"Action":"run","Package":"example.com/infer","Test":"TestPredict"}
+      {"Action":"output","Package":"example.com/infer","Test":"TestPredict","Output":"=== RUN   TestPredict\n"}
+      {"Action":"run","Package":"example.com/infer","Test":"TestPredict/batch=1"}
+      {"Action":"pass","Package":"example.com/infer","Test":"TestPredict/batch=1","Elapsed":0}
+      {"Action":"pass","Package":"example.com/infer","Test":"TestPredict","Elapsed":0}
+      {"Action":"skip","Package":"example.com/infer","Test":"TestGPU","Elapsed":0}
+      {"Action":"output","Package":"example.com/infer","Output":"goos: linux\n"}
+      {"Action":"output","Package":"example.com/infer","Output":"goarch: amd64\n"}
+      {"Action":"output","Package":"example.com/infer","Output":"cpu: Intel(R) Xeon(R) Processor\n"}
+      {"Action":"output","Package":"example.com/infer","Test":"BenchmarkPredict/batch=64","Output":"BenchmarkPredict/batch=64-8   \t"}
+      {"Action":"output","Package":"example.com/infer","Test":"BenchmarkPredict/batch=64","Output":"    5000\t    213.2 ns/op\t   3.500 rows/op\t   112 B/op\t   1 allocs/op\n"}
+      {"Action":"output","Package":"example.com/infer","Output":"BenchmarkPredict/batch=64-8   \t    5000\t    209.0 ns/op\t   3.500 rows/op\t   112 B/op\t   1 allocs/op\n"}
+      {"Action":"pass","Package":"example.com/infer","Elapsed":1.5}
+      {"Action":"run","Package":"example.com/tokenize","Test":"TestSplit"}
+      {"Action":"fail","Package":"example.com/tokenize","Test":"TestSplit","Elapsed":0.01}
+      {"Action":"fail","Package":"example.com/tokenize","Elapsed":0.5}
+      """
+    When I parse the go test output
+    And I log the go test report to the experiment with commit "3f9c2d1"
+    Then the run should have status "FAILED"
+    And the run should have tag "mlflow.source.git.commit" with value "3f9c2d1"
+    And the run should have tag "mlflow-go.test.failed" with value "example.com/tokenize.TestSplit"
+    And the run should have tag "mlflow-go.bench.cpu" with value "Intel(R) Xeon(R) Processor"
+    And the fetched run should have metric "tests/passed" = 2
+    And the fetched run should have metric "tests/failed" = 1
+    And the metric "BenchmarkPredict/batch_64/ns_per_op" should have been logged as 213.2, 209
+
+  Scenario: A passing report ends its run as FINISHED
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    When I record a benchmark "BenchmarkSum" that reports 42 "items/op" with testing.Benchmark
+    And I log the go test report to the experiment with commit "3f9c2d1"
+    Then the run should have status "FINISHED"
+    And the fetched run should have metric "BenchmarkSum/items_per_op" = 42
+
+  Scenario: Benchmarks run with several GOMAXPROCS values keep them in their keys
+    Given go test printed:
+      """
+      BenchmarkJoin-2	1000	200.0 ns/op
+      BenchmarkJoin-4	1000	120.0 ns/op
+      BenchmarkSplit	1000	90.0 ns/op
+      """
+    When I parse the go test output
+    Then the report should have metric "BenchmarkJoin-2/ns_per_op" = 200 at step 0
+    And the report should have metric "BenchmarkJoin-4/ns_per_op" = 120 at step 0
+    And the report should have metric "BenchmarkSplit/ns_per_op" = 90 at step 0
+
+  Scenario: Failed test names are cut short to fit in a tag
+    When 2000 tests named "example.com/very/long/package/path.TestCase" failed
+    Then the failed tests tag should be at most 8000 characters and end with "more"
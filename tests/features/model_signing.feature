@@ -0,0 +1,112 @@
+Feature: Model version signing
+  As an operator of a model serving layer
+  I want proof that a model version came from our CI pipeline unmodified
+  So that the serving layer never loads a model that was tampered with
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path           | content     |
+      | MLmodel        | flavors: {} |
+      | model.pkl      | weights v1  |
+      | data/vocab.txt | a b c       |
+    And I log the local directory to artifact directory "model"
+    And a registered model with a unique name exists
+    And a signing key for "ci@example.com"
+    And a signing key for "laptop@example.com"
+
+  Scenario: A signed model version verifies
+    Given a version of the model registers the run's artifacts "model" by run URI
+    When I sign the model version as "ci@example.com"
+    Then the signed manifest should list "MLmodel (11), data/vocab.txt (5), model.pkl (10)"
+    And the model version should be signed by "ci@example.com"
+    When I verify the model version trusting "ci@example.com"
+    Then the signed manifest should list "MLmodel (11), data/vocab.txt (5), model.pkl (10)"
+    And the verified files should be in the download directory
+    And the downloaded files should be:
+      | path           | content     |
+      | MLmodel        | flavors: {} |
+      | model.pkl      | weights v1  |
+      | data/vocab.txt | a b c       |
+
+  Scenario: Verification needs an empty directory
+    Given a version of the model registers the run's artifacts "model" by run URI
+    And I sign the model version as "ci@example.com"
+    When I attempt to verify the model version into a directory holding "model.pkl" trusting "ci@example.com"
+    Then the call should fail with "is not empty"
+
+  Scenario: Signatures are stored as written when a scrubber is installed
+    Given a scrubber that will "redact" secrets is installed on the client
+    And a version of the model registers the run's artifacts "model" by run URI
+    When I sign the model version as "ci@example.com"
+    Then the model version should be signed by "ci@example.com"
+    When I verify the model version trusting "ci@example.com"
+    Then the signed manifest should list "MLmodel (11), data/vocab.txt (5), model.pkl (10)"
+
+  Scenario: A model version registered by artifact URI verifies
+    Given I log the local file "model.pkl" content-addressed to artifact directory "model/shared"
+    And a version of the model registers the run's artifacts "model" by artifact URI
+    When I sign the model version as "ci@example.com"
+    And I verify the model version trusting "laptop@example.com, ci@example.com"
+    Then the signed manifest should list "MLmodel (11), data/vocab.txt (5), model.pkl (10), shared/model.pkl (10)"
+
+  Scenario Outline: Changed artifacts fail verification
+    Given a version of the model registers the run's artifacts "model" by run URI
+    And I sign the model version as "ci@example.com"
+    And <change>
+    When I attempt to verify the model version trusting "ci@example.com"
+    Then the call should fail with "do not match its signature by ci@example.com"
+    And the download directory should be empty
+
+    Examples:
+      | change                                                                |
+      | the run's artifact "model/model.pkl" is replaced with "weights v2"    |
+      | the run's artifact "model/data/extra.txt" is replaced with "injected" |
+      | the run's artifact "model/data/vocab.txt" is deleted                  |
+
+  Scenario: Unsigned model versions fail verification
+    Given a version of the model registers the run's artifacts "model" by run URI
+    When I attempt to verify the model version trusting "ci@example.com"
+    Then the call should fail with "is not signed"
+
+  Scenario: Signatures by untrusted signers fail verification
+    Given a version of the model registers the run's artifacts "model" by run URI
+    And I sign the model version as "laptop@example.com"
+    When I attempt to verify the model version trusting "ci@example.com"
+    Then the call should fail with "is not trusted"
+    When I attempt to verify the model version trusting ""
+    Then the call should fail with "is not trusted"
+
+  Scenario: Signatures are bound to their signer and model version
+    Given a version of the model registers the run's artifacts "model" by run URI
+    And I sign the model version as "laptop@example.com"
+    When I set tag "mlflow-go.signature.signer" with value "ci@example.com" on the model version
+    And I attempt to verify the model version trusting "ci@example.com"
+    Then the call should fail with "do not match its signature"
+    When I set tag "mlflow-go.signature" with value "not base64" on the model version
+    And I attempt to verify the model version trusting "ci@example.com"
+    Then the call should fail with "has an invalid signature"
+
+  Scenario: Signing with a key held by a signing service
+    Given a signing key for "release@example.com" held by a signing service
+    And a version of the model registers the run's artifacts "model" by run URI
+    When I sign the model version as "release@example.com"
+    Then the model version should be signed by "release@example.com"
+    And I verify the model version trusting "release@example.com"
+    When the signing service of "release@example.com" is down
+    And I attempt to sign the model version as "release@example.com"
+    Then the call should fail with "signing service is unavailable"
+
+  Scenario: Content-addressed and encrypted artifacts are signed as their content
+    Given artifact encryption uses a key file
+    And I log the local directory content-addressed to artifact directory "shared"
+    And I log the local file "model.pkl" encrypted to artifact directory "shared/secret"
+    And a version of the model registers the run's artifacts "shared" by run URI
+    When I sign the model version as "ci@example.com"
+    Then the signed manifest should list "MLmodel (11), data/vocab.txt (5), model.pkl (10), secret/model.pkl (10)"
+    When the blob of "shared/model.pkl" is overwritten with "weights v2"
+    And I attempt to verify the model version trusting "ci@example.com"
+    Then the call should fail with "does not match its SHA-256"
@@ -0,0 +1,70 @@
+Feature: Multipart upload of large artifacts
+  As an engineer who saves checkpoints of tens of gigabytes
+  I want large artifacts uploaded in parts that are retried and can be resumed
+  So that one dropped connection doesn't cost me the whole upload
+
+  Scenario: A file is uploaded in concurrent parts
+    Given an artifact proxy that supports multipart upload
+    And a local file of 10000 bytes
+    When I upload the file in parts of 1024 bytes, 4 at a time
+    Then the proxy should store the file at "checkpoints/checkpoint.bin"
+    And 10 parts should have been uploaded
+    And progress should have been reported 10 times, ending at 10000 bytes
+
+  Scenario: A failed part is retried
+    Given an artifact proxy that supports multipart upload
+    And a local file of 5000 bytes
+    And part 3 fails 2 times with status 503
+    When I upload the file in parts of 1024 bytes, 2 at a time
+    Then the proxy should store the file at "checkpoints/checkpoint.bin"
+    And part 3 should have been attempted 3 times
+
+  Scenario: A part rejected by the artifact store is not retried
+    Given an artifact proxy that supports multipart upload
+    And a local file of 5000 bytes
+    And part 2 fails 1 times with status 403
+    When I attempt to upload the file in parts of 1024 bytes without retries
+    Then the call should fail with "part 2"
+    And part 2 should have been attempted 1 times
+
+  Scenario: Cancelling an upload aborts it
+    Given an artifact proxy that supports multipart upload
+    And a local file of 8000 bytes
+    When I attempt to upload the file in parts of 1024 bytes, cancelling after 2 parts
+    Then the call should fail with "context canceled"
+    And the upload should be aborted
+    And the upload state file should not exist
+    And the proxy should store no files
+
+  Scenario: An interrupted upload resumes from its state file
+    Given an artifact proxy that supports multipart upload
+    And a local file of 8000 bytes
+    And part 4 always fails with status 500
+    When I attempt to upload the file in parts of 1024 bytes without retries
+    Then the call should fail with "part 4"
+    And the upload should not be aborted
+    And the upload state file should exist
+    When the failing parts recover
+    And I upload the file in parts of 1024 bytes with a state file
+    Then the proxy should store the file at "checkpoints/checkpoint.bin"
+    And 5 parts should have been uploaded
+    And 1 multipart uploads should have been created
+    And the upload state file should not exist
+
+  Scenario: An upload the store no longer knows starts over
+    Given an artifact proxy that supports multipart upload
+    And a local file of 8000 bytes
+    And part 4 always fails with status 500
+    When I attempt to upload the file in parts of 1024 bytes without retries
+    And the failing parts recover
+    And the proxy forgets its open uploads
+    And I upload the file in parts of 1024 bytes with a state file
+    Then the proxy should store the file at "checkpoints/checkpoint.bin"
+    And 2 multipart uploads should have been created
+
+  Scenario: Stores without multipart support get a single upload
+    Given an artifact proxy that does not support multipart upload
+    And a local file of 3000 bytes
+    When I upload the file in parts of 1024 bytes, 4 at a time
+    Then the proxy should store the file at "checkpoints/checkpoint.bin"
+    And 0 parts should have been uploaded
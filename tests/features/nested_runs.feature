@@ -0,0 +1,64 @@
+Feature: Nested runs
+  As an engineer running hyperparameter searches and cross-validation folds
+  I want to start child runs under a parent and retrieve the whole run tree
+  So that I can follow and finish nested runs as one unit
+
+  Background:
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+
+  Scenario: A child run is nested under its parent
+    When I start a child run "fold-1" under the run
+    Then child run "fold-1" should be nested under the run
+    And child run "fold-1" should be in the experiment
+
+  Scenario: A child run cannot be started in another experiment
+    When I attempt to start a child run under the run in experiment "elsewhere"
+    Then the call should fail with "must be in experiment"
+
+  Scenario: A run tree is retrieved with its nesting
+    Given the run has 3 child runs
+    And child run "child-2" has 2 child runs
+    When I get the run tree
+    Then the run tree should have 6 runs
+    And the run tree should be:
+      | depth | run       |
+      | 0     | root      |
+      | 1     | child-1   |
+      | 1     | child-2   |
+      | 2     | child-2-1 |
+      | 2     | child-2-2 |
+      | 1     | child-3   |
+    And the run tree status should be "RUNNING"
+
+  Scenario: Ending a run tree ends children before parents
+    Given the run has 2 child runs
+    And child run "child-1" has 2 child runs
+    When I end the run tree with status "FINISHED"
+    And I get the run tree
+    Then every run in the run tree should have status "FINISHED"
+    And the run tree status should be "FINISHED"
+
+  Scenario: Runs that already ended keep their status
+    Given the run has 2 child runs
+    And child run "child-2" has ended with status "FAILED"
+    When I end the run tree with status "KILLED"
+    And I get the run tree
+    Then child run "child-1" in the run tree should have status "KILLED"
+    And child run "child-2" in the run tree should have status "FAILED"
+    And the run tree status should be "FAILED"
+
+  Scenario: Failing a subtree leaves the rest of the tree running
+    Given the run has 2 child runs
+    And child run "child-1" has 2 child runs
+    When I end the run tree under child run "child-1" with status "FAILED"
+    And I get the run tree
+    Then child run "child-1-2" in the run tree should have status "FAILED"
+    And child run "child-2" in the run tree should have status "RUNNING"
+    And the run tree status should be "RUNNING"
+
+  Scenario: A run tree can only be ended with a terminal status
+    When I attempt to end the run tree with status "RUNNING"
+    Then the call should fail with "non-terminal status RUNNING"
@@ -0,0 +1,50 @@
+Feature: Struct-based parameter logging
+  As an engineer with nested Go training configs
+  I want to log a config struct as params and load it back from a run
+  So that any run can be reproduced from its logged configuration
+
+  Scenario: A nested config is flattened into dot-separated keys
+    Given a training config with learning rate 0.001 and 2 layers
+    And the last layer has dropout 0.5
+    When I flatten the training config
+    Then the flattened params should be:
+      | key                 | value   |
+      | epochs              | 10      |
+      | layers.0.activation | relu    |
+      | layers.0.units      | 64      |
+      | layers.1.activation | relu    |
+      | layers.1.dropout    | 0.5     |
+      | layers.1.units      | 32      |
+      | optimizer.betas.0   | 0.9     |
+      | optimizer.betas.1   | 0.999   |
+      | optimizer.lr        | 0.001   |
+      | optimizer.name      | adam    |
+      | seed                | 42      |
+      | timeout             | 1h30m0s |
+
+  Scenario: A logged config round-trips through a run
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a training config with learning rate 0.0003 and 3 layers
+    And the last layer has dropout 0.25
+    And the training config has 3 labels
+    When I log the training config to the run
+    And I load the run params into a new training config
+    Then the loaded training config should equal the logged one
+
+  Scenario: Large configs are logged in batches
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And a training config with learning rate 0.01 and 1 layers
+    And the training config has 150 labels
+    When I log the training config to the run
+    Then the run should have 159 parameters
+
+  Scenario: A param that does not parse is reported with its key
+    Given a run with param "optimizer.lr" = "fast"
+    When I attempt to load the run params into a new training config
+    Then the call should fail with "optimizer.lr"
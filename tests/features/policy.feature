@@ -0,0 +1,92 @@
+Feature: Guardrail policies for destructive operations
+  As a platform owner
+  I want destructive calls on protected resources to be blocked by the client
+  So that a script bug cannot delete or archive production models
+
+  Scenario: Name patterns select the protected experiments
+    Given a policy rule "no-prod-experiments" blocking "DeleteExperiment" for names matching "prod-*"
+    Then "DeleteExperiment" on experiment "prod-churn" should be blocked by rule "no-prod-experiments"
+    And "DeleteExperiment" on experiment "dev-churn" should be allowed
+
+  Scenario: Rules requiring an override token accept only the matching token
+    Given a policy rule "break-glass" blocking "DeleteRun" for tag "protected" equals "true" with override token "s3cret"
+    Then "DeleteRun" on a run tagged "protected" = "true" should be blocked by rule "break-glass"
+    And "DeleteRun" on a run tagged "protected" = "true" with override token "wrong" should be blocked by rule "break-glass"
+    And "DeleteRun" on a run tagged "protected" = "true" with override token "s3cret" should be allowed
+
+  Scenario: Deleting a protected registered model is blocked unless overridden
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And a registered model with a unique name exists
+    And the model has tag "protected" with value "true"
+    And a policy rule "break-glass" blocking "DeleteRegisteredModel" for tag "protected" equals "true" with override token "s3cret"
+    And the policy is installed on the client
+    When I attempt to delete the registered model
+    Then the call should fail with a policy error from rule "break-glass"
+    And the registered model should still exist
+    When I attempt to delete the registered model with override token "s3cret"
+    Then the call should succeed
+    And the model should be deleted
+
+  Scenario: A model version holding the champion alias cannot be deleted
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And a registered model with a unique name exists
+    And I create a model version with source "runs:/test-run/model"
+    And I set alias "champion" pointing to the model version
+    And a policy rule "keep-champion" blocking "DeleteModelVersion" for versions with alias "champion"
+    And the policy is installed on the client
+    When I attempt to delete the model version
+    Then the call should fail with a policy error from rule "keep-champion"
+
+  Scenario: Archiving a production model version is blocked
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And a registered model with a unique name exists
+    And I create a model version with source "runs:/test-run/model"
+    And I transition the model version to stage "Production"
+    And a policy rule "keep-production" blocking "TransitionModelVersionStage" for versions in stage "Production"
+    And the policy is installed on the client
+    When I attempt to transition the model version to stage "Archived"
+    Then the call should fail with a policy error from rule "keep-production"
+    When I attempt to transition the model version to stage "Staging"
+    Then the call should succeed
+
+  Scenario: Archiving the existing versions of a stage checks the versions it archives
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And a registered model with a unique name exists
+    And I create a model version with source "runs:/test-run/model"
+    And I transition the model version to stage "Production"
+    And I create a model version with source "runs:/test-run/model"
+    And a policy rule "keep-production" blocking "TransitionModelVersionStage" for versions in stage "Production"
+    And the policy is installed on the client
+    When I attempt to transition the model version to stage "Production" archiving existing versions
+    Then the call should fail with a policy error from rule "keep-production"
+    When I attempt to transition the model version to stage "Production"
+    Then the call should succeed
+    When I attempt to transition the model version to stage "Staging" archiving existing versions
+    Then the call should succeed
+
+  Scenario: A protected run survives bulk run deletion
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And I set tag "protected" with value "true" on the run
+    And a policy rule "break-glass" blocking "DeleteRun" for tag "protected" equals "true" with override token "s3cret"
+    And the policy is installed on the client
+    When I attempt to delete the experiment's runs created until now
+    Then the call should fail with a policy error from rule "break-glass"
+    And the call should fail with "DeleteRuns"
+    And the run should still be active
+
+  Scenario: Deleting the traces of a protected experiment is blocked
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And the experiment has tag "protected" with value "true"
+    And a policy rule "keep-traces" blocking "DeleteTraces" for tag "protected" equals "true" with override token "s3cret"
+    And the policy is installed on the client
+    When I attempt to delete the experiment's traces
+    Then the call should fail with a policy error from rule "keep-traces"
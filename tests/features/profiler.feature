@@ -0,0 +1,52 @@
+Feature: Continuous profiling
+  As an engineer investigating a run that got slower
+  I want CPU and heap profiles of that exact run stored with it
+  So that I can compare where two runs spent their time
+
+  Scenario: A CPU profile is summarized by its top functions
+    When I capture a CPU profile while burning CPU for 300 milliseconds
+    And I summarize the profile by "cpu" keeping the top 3 functions
+    Then the summary should total "cpu" in "nanoseconds"
+    And the top function should be "burnCPU"
+    And the summary should have at most 3 top functions
+
+  Scenario: A profile without the requested sample type is rejected
+    When I capture a CPU profile while burning CPU for 50 milliseconds
+    And I summarize the profile by "inuse_space" keeping the top 3 functions
+    Then the call should fail with "profile has no sample type"
+
+  Scenario: A profiled run gets profile artifacts, metrics and top-function tags
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    When I start a run in the experiment
+    And I start profiling the active run every 250 milliseconds with a CPU window of 200 milliseconds
+    And the job burns CPU for 600 milliseconds
+    And I end the active run with status "FINISHED"
+    Then the active run should have status "FINISHED"
+    And the active run should have at least 2 profiles under "profiles/cpu"
+    And the active run should have at least 2 profiles under "profiles/heap"
+    And the active run should have at least 2 profiles under "profiles/goroutine"
+    And the active run should have metric "profile/goroutines" logged at least 2 times
+    And the active run should have metric "profile/heap_alloc_rate_megabytes_per_second" logged at least 2 times
+    And the active run should have logged a positive "profile/cpu_seconds"
+    And the active run should have tag "mlflow-go.profile.cpu.top" containing "burnCPU"
+
+  Scenario Outline: Invalid profiler options are rejected
+    Given an MLflow client for an unreachable server
+    When I attempt to start a profiler every <interval> milliseconds with a CPU window of <window> milliseconds and snapshot "<snapshot>"
+    Then the call should fail with "<error>"
+
+    Examples:
+      | interval | window | snapshot | error                                 |
+      | -1       | 0      |          | profile interval must not be negative |
+      | 100      | 200    |          | must not be longer than the interval  |
+      | 100      | 0      | cpu      | invalid snapshot profile              |
+      | 100      | 0      | nope     | invalid snapshot profile              |
+
+  Scenario: Upload failures are reported without stopping the job
+    Given an MLflow client for an unreachable server
+    When a profiler for run "run-1" runs for 50 milliseconds
+    Then the call should fail with "failed to upload goroutine profile to run run-1"
+    And the monitor should have reported an error containing "failed to log profile summaries"
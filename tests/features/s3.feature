@@ -0,0 +1,242 @@
+Feature: S3 artifact storage
+  As an engineer whose experiments store artifacts in S3 with the artifact proxy disabled
+  I want the client to read and write s3:// artifact locations directly
+  So that artifacts go straight between my code and the bucket, on AWS or on-premises stores like MinIO
+
+  Scenario: Requests are signed with AWS Signature Version 4
+    When I sign the AWS get-vanilla test request
+    Then the Authorization header should be "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+
+  Scenario Outline: S3 artifact URIs resolve to a bucket and prefix
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    When I resolve the artifact repository of "<uri>"
+    Then the artifact repository should be a s3 repository
+    And the S3 repository should have bucket "<bucket>" and prefix "<prefix>"
+
+    Examples:
+      | uri                               | bucket         | prefix          |
+      | s3://mlflow/1/abc/artifacts       | mlflow         | 1/abc/artifacts |
+      | s3://mlflow/1/abc/artifacts/      | mlflow         | 1/abc/artifacts |
+      | s3://team.artifacts/experiments/7 | team.artifacts | experiments/7   |
+      | s3://mlflow                       | mlflow         |                 |
+
+  Scenario Outline: Invalid S3 configuration is rejected
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And the environment variable "<variable>" is "<value>"
+    When I resolve the artifact repository of "<uri>"
+    Then the call should fail with "<error>"
+
+    Examples:
+      | uri                         | variable               | value               | error                            |
+      | s3:///1/abc/artifacts       | AWS_REGION             | eu-west-2           | is not an s3://bucket/prefix URI |
+      | s3://mlflow/1/abc/artifacts | MLFLOW_S3_IGNORE_TLS   | sometimes           | invalid MLFLOW_S3_IGNORE_TLS     |
+      | s3://mlflow/1/abc/artifacts | MLFLOW_S3_ENDPOINT_URL | minio.internal:9000 | invalid S3 endpoint              |
+
+  Scenario Outline: Unsupported upload arguments are rejected
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And the environment variable "MLFLOW_S3_UPLOAD_EXTRA_ARGS" is:
+      """
+      <extra args>
+      """
+    When I resolve the artifact repository of "s3://mlflow/1/abc/artifacts"
+    Then the call should fail with "<error>"
+
+    Examples:
+      | extra args                                                                               | error                                       |
+      | {"ACL": "public-read"}                                                                   | unsupported MLFLOW_S3_UPLOAD_EXTRA_ARGS key |
+      | {"ServerSideEncryption": "x"}                                                            | unsupported server-side encryption          |
+      | {"SSECustomerKey": "too short"}                                                          | SSE-C keys must be 32 bytes                 |
+      | {"SSECustomerKey": "0123456789abcdef0123456789abcdef", "ServerSideEncryption": "AES256"} | SSE-C cannot be combined                    |
+      | not json                                                                                 | invalid MLFLOW_S3_UPLOAD_EXTRA_ARGS         |
+
+  Scenario: Runs of an experiment with an S3 artifact location log and download artifacts directly
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an S3 store
+    And AWS credentials from environment variables
+    And an experiment storing artifacts in the S3 store exists
+    And a run exists in the experiment
+    And a local directory with files:
+      | path              | content    |
+      | model.bin         | weights    |
+      | config/train.yaml | epochs: 10 |
+    Then the run's artifact repository should be a s3 repository
+    When I log the local directory to artifact directory "bundle"
+    Then the S3 store should have run artifact "bundle/config/train.yaml" with "epochs: 10"
+    And the S3 store should have seen requests signed for region "eu-west-2"
+    When I download artifacts "bundle" of the run
+    Then the downloaded files should be:
+      | path                     | content    |
+      | bundle/model.bin         | weights    |
+      | bundle/config/train.yaml | epochs: 10 |
+
+  Scenario: Directories list across pages
+    Given an S3 artifact repository with parts of 1024 bytes, 2 at a time
+    When I upload "1" to "metrics/a.txt" in the repository
+    And I upload "22" to "metrics/b.txt" in the repository
+    And I upload "333" to "metrics/c.txt" in the repository
+    And I upload "4444" to "metrics/d.txt" in the repository
+    And I upload "55555" to "metrics/plots/e.png" in the repository
+    Then listing "metrics" in the repository should give "metrics/a.txt (1), metrics/b.txt (2), metrics/c.txt (3), metrics/d.txt (4), metrics/plots/"
+    When I delete "metrics" from the repository
+    Then listing "" in the repository should give ""
+
+  Scenario: Small artifacts take a single request each way
+    Given an S3 artifact repository with parts of 1024 bytes, 2 at a time
+    When I upload 1024 random bytes to "model.bin" in the repository
+    Then the S3 store should have received 1 single upload and 0 parts
+    And "model.bin" should download intact from the repository
+    And the S3 store should have served 1 ranged request
+
+  Scenario: Empty artifacts are uploaded and downloaded
+    Given an S3 artifact repository with parts of 1024 bytes, 2 at a time
+    When I upload "" to "empty.txt" in the repository
+    Then "empty.txt" should be a file in the repository
+    And "empty.txt" should contain "" in the repository
+
+  Scenario Outline: Large artifacts are uploaded in parts and downloaded in concurrent ranges
+    Given an S3 artifact repository with parts of 1024 bytes, <concurrency> at a time
+    When I upload <size> random bytes of <known> size to "checkpoints/model.bin" in the repository
+    Then the S3 store should have received 0 single uploads and <parts> parts
+    And "checkpoints/model.bin" should download intact from the repository
+    And the S3 store should have served <parts> ranged requests
+    And "checkpoints/model.bin" should be a file in the repository
+
+    Examples:
+      | size  | known   | concurrency | parts |
+      | 10000 | known   | 3           | 10    |
+      | 10240 | known   | 1           | 10    |
+      | 2049  | known   | 8           | 3     |
+      | 5000  | unknown | 2           | 5     |
+
+  Scenario: Failing parts are retried
+    Given an S3 artifact repository with parts of 1024 bytes, 2 at a time
+    And S3 part 2 fails 2 times with status 503
+    When I upload 5000 random bytes to "model.bin" in the repository
+    Then S3 part 2 should have been attempted 3 times
+    And "model.bin" should download intact from the repository
+
+  Scenario: An upload with a part that keeps failing is aborted
+    Given an S3 artifact repository with parts of 1024 bytes, 2 at a time
+    And S3 part 3 always fails with status 403
+    When I attempt to upload 5000 random bytes to "model.bin" in the repository
+    Then the call should fail with "failed to upload part 3"
+    And S3 part 3 should have been attempted 1 times
+    And the S3 store should have aborted 1 upload and have none open
+    And "model.bin" should not exist in the repository
+
+  Scenario Outline: Uploads are encrypted as configured
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And AWS credentials from environment variables
+    And the environment variable "MLFLOW_S3_UPLOAD_EXTRA_ARGS" is:
+      """
+      <extra args>
+      """
+    When I resolve the artifact repository of "s3://mlflow/sse"
+    And I upload "weights" to "small.bin" in the repository
+    Then the S3 object of "small.bin" should have header "<header>" set to "<value>"
+
+    Examples:
+      | extra args                                                                               | header                                          | value        |
+      | {"ServerSideEncryption": "AES256"}                                                       | X-Amz-Server-Side-Encryption                    | AES256       |
+      | {"ServerSideEncryption": "aws:kms", "SSEKMSKeyId": "alias/mlflow"}                       | X-Amz-Server-Side-Encryption                    | aws:kms      |
+      | {"ServerSideEncryption": "aws:kms", "SSEKMSKeyId": "alias/mlflow"}                       | X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id     | alias/mlflow |
+      | {"SSECustomerAlgorithm": "AES256", "SSECustomerKey": "0123456789abcdef0123456789abcdef"} | X-Amz-Server-Side-Encryption-Customer-Algorithm | AES256       |
+
+  Scenario Outline: Multipart uploads are encrypted too
+    Given an S3 artifact repository with parts of 1024 bytes, 2 at a time, encrypted with <encryption>
+    When I upload 5000 random bytes to "large.bin" in the repository
+    Then the S3 store should have received 0 single uploads and 5 parts
+    And the S3 object of "large.bin" should have header "<header>" set to "<value>"
+    And "large.bin" should download intact from the repository
+
+    Examples:
+      | encryption     | header                                          | value        |
+      | a KMS key      | X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id     | alias/mlflow |
+      | a customer key | X-Amz-Server-Side-Encryption-Customer-Algorithm | AES256       |
+
+  Scenario: Objects encrypted with a customer key need the key to download
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And AWS credentials from environment variables
+    And the environment variable "MLFLOW_S3_UPLOAD_EXTRA_ARGS" is:
+      """
+      {"SSECustomerKey": "0123456789abcdef0123456789abcdef"}
+      """
+    When I resolve the artifact repository of "s3://mlflow/sse-c"
+    And I upload "weights" to "model.bin" in the repository
+    Then "model.bin" should contain "weights" in the repository
+    And "model.bin" should be a file in the repository
+    And downloading "model.bin" without the customer key should fail with "InvalidRequest"
+
+  Scenario: TLS verification can be skipped for on-premises stores
+    Given an MLflow client for an unreachable server
+    And an S3 store with a self-signed certificate
+    And AWS credentials from environment variables
+    When I resolve the artifact repository of "s3://mlflow/tls"
+    And I attempt to upload "weights" to "model.bin" in the repository
+    Then the call should fail with "certificate"
+    Given the environment variable "MLFLOW_S3_IGNORE_TLS" is "true"
+    When I resolve the artifact repository of "s3://mlflow/tls"
+    And I upload "weights" to "model.bin" in the repository
+    Then "model.bin" should contain "weights" in the repository
+
+  Scenario Outline: Credentials are resolved in the order the AWS SDKs use
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And AWS credentials from <source>
+    When I resolve the artifact repository of "s3://mlflow/credentials"
+    And I upload "weights" to "model.bin" in the repository
+    Then the S3 store should have seen access key "<access key>" and session token "<session token>"
+
+    Examples:
+      | source                                     | access key    | session token     |
+      | environment variables                      | AKIDENV       |                   |
+      | environment variables with a session token | AKIDENV       | env-session       |
+      | a shared credentials file profile          | AKIDFILE      |                   |
+      | a shared config file profile               | AKIDCONFIG    |                   |
+      | a web identity token                       | AKIDWEB       | web-session       |
+      | the container credentials endpoint         | AKIDCONTAINER | container-session |
+      | the instance metadata service              | AKIDINSTANCE  | instance-session  |
+
+  Scenario: Environment credentials take precedence over shared files
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And AWS credentials from a shared credentials file profile
+    And AWS credentials from environment variables
+    When I resolve the artifact repository of "s3://mlflow/credentials"
+    And I upload "weights" to "model.bin" in the repository
+    Then the S3 store should have seen access key "AKIDENV" and session token ""
+
+  Scenario Outline: Shared profiles without static keys fail rather than fall through
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    And AWS credentials from the instance metadata service
+    And a shared AWS profile using "<key>"
+    When I resolve the artifact repository of "s3://mlflow/credentials"
+    And I attempt to upload "weights" to "model.bin" in the repository
+    Then the call should fail with "shared profile"
+    And the call should fail with "sets <key>, which is not supported"
+
+    Examples:
+      | key                |
+      | role_arn           |
+      | credential_process |
+      | sso_session        |
+
+  Scenario: Transfers fail clearly without credentials
+    Given an MLflow client for an unreachable server
+    And an S3 store
+    When I resolve the artifact repository of "s3://mlflow/credentials"
+    And I attempt to upload "weights" to "model.bin" in the repository
+    Then the call should fail with "no AWS credentials"
+
+  Scenario: Large artifacts round trip through a MinIO server
+    Given an artifact repository on a MinIO bucket
+    When I upload 12582912 random bytes to "checkpoints/model.bin" in the repository
+    Then "checkpoints/model.bin" should download intact from the repository
+    And listing "checkpoints" in the repository should give "checkpoints/model.bin (12582912)"
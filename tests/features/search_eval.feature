@@ -0,0 +1,97 @@
+Feature: Client-side evaluation of search filters
+  As an engineer caching runs and model versions locally
+  I want to apply the MLflow filter language to in-memory data
+  So that cached data is filtered exactly like a server search, and bad filters are caught before sending
+
+  Scenario Outline: Filters are parsed into comparisons and rendered back
+    When the filter "<filter>" is parsed for runs
+    Then parsing should succeed
+    And the runs filter should be <rendered>
+
+    Examples:
+      | filter                                        | rendered                                        |
+      | metrics.accuracy>0.9 and tags.team = "vision" | metrics.accuracy > 0.9 AND tags.team = 'vision' |
+      | params.`model.depth` = '12'                   | params.`model.depth` = '12'                     |
+      | status IN ('RUNNING','FINISHED')              | attributes.status IN ('RUNNING', 'FINISHED')    |
+      | attribute.start_time >= 1700000000000         | attributes.start_time >= 1700000000000          |
+      | run.run_name not in ('a', 'b')                | attributes.run_name NOT IN ('a', 'b')           |
+      | tags."release notes" ILIKE '%BETA%'           | tags.`release notes` ILIKE '%BETA%'             |
+      | datasets.context = 'training'                 | datasets.context = 'training'                   |
+
+  Scenario Outline: Invalid filters are rejected before sending
+    When the filter "<filter>" is parsed for <entity>
+    Then parsing should fail mentioning "<error>"
+
+    Examples:
+      | entity            | filter                                 | error                                        |
+      | runs              | metrics.accuracy > 0.9 OR tags.a = 'b' | OR is not supported                          |
+      | runs              | metrics.accuracy > 'high'              | must be compared with a number               |
+      | runs              | params.lr = 0.01                       | must be compared with a string               |
+      | runs              | tags.team = 'vision                    | unterminated '                               |
+      | runs              | foo.bar = 'x'                          | unknown identifier type "foo"                |
+      | runs              | attributes.color = 'red'               | runs cannot be filtered by attribute "color" |
+      | runs              | status IN 'RUNNING'                    | expected ( after IN                          |
+      | experiments       | metrics.accuracy > 0.9                 | experiments cannot be filtered by metrics    |
+      | registered_models | name = 'a' AND version_number = 1      | cannot be filtered by attribute              |
+
+  Scenario Outline: Filters are evaluated against a cached run
+    Given a cached run with:
+      | attributes.run_id     | 7f3a              |
+      | attributes.status     | FINISHED          |
+      | attributes.start_time | 1700000000000     |
+      | metrics.accuracy      | 0.93              |
+      | metrics.loss          | NaN               |
+      | params.lr             | 0.01              |
+      | tags.team             | Vision            |
+      | tags.data.version     | v2                |
+      | datasets              | imagenet/training |
+      | datasets              | imagenet-val/eval |
+    Then the filter "<filter>" should <outcome> the cached runs entry
+
+    Examples:
+      | filter                                                       | outcome   |
+      | metrics.accuracy > 0.9                                       | match     |
+      | metrics.accuracy > 0.9 AND tags.team = 'Vision'              | match     |
+      | metrics.accuracy > 0.9 AND tags.team = 'vision'              | not match |
+      | metrics.accuracy <= 0.93                                     | match     |
+      | metrics.f1 != 0.5                                            | not match |
+      | tags.owner != 'alice'                                        | not match |
+      | metrics.loss = 0                                             | not match |
+      | metrics.loss != 0                                            | match     |
+      | tags.team LIKE 'Vis%'                                        | match     |
+      | tags.team LIKE 'vis%'                                        | not match |
+      | tags.team ILIKE 'vis%'                                       | match     |
+      | tags.team LIKE 'V_sion'                                      | match     |
+      | tags.`data.version` = 'v2'                                   | match     |
+      | params.lr = '0.01'                                           | match     |
+      | attributes.status IN ('RUNNING', 'FINISHED')                 | match     |
+      | attributes.status NOT IN ('RUNNING', 'FINISHED')             | not match |
+      | attributes.start_time > 1600000000000                        | match     |
+      | attributes.end_time > 0                                      | not match |
+      | run_id = '7f3a'                                              | match     |
+      | datasets.context = 'eval'                                    | match     |
+      | datasets.name = 'imagenet' AND datasets.context = 'training' | match     |
+      | datasets.name = 'coco'                                       | not match |
+
+  Scenario Outline: Filters are evaluated against cached experiments and model versions
+    Given a cached experiment named "fraud-detection" tagged "owner" = "risk"
+    Then the filter "<experiment_filter>" should <outcome> the cached experiments entry
+    Given a cached model version "<model>" version "3" from run "r-1"
+    Then the filter "<version_filter>" should <outcome> the cached model_versions entry
+
+    Examples:
+      | model | experiment_filter                           | version_filter                                  | outcome   |
+      | fraud | name LIKE 'fraud-%' AND tags.owner = 'risk' | name = 'fraud' AND version_number >= 3          | match     |
+      | churn | creation_time < 1600000000000               | name = 'fraud'                                  | not match |
+      | fraud | tags.owner ILIKE 'RISK'                     | run_id IN ('r-1', 'r-2') AND version_number < 4 | match     |
+
+  Scenario: Filters are validated against the entity they are matched with
+    Given a cached model version "fraud" version "3" from run "r-1"
+    Then matching the filter "metrics.accuracy > 0.9" for runs against the cached entry should fail mentioning "model_versions cannot be filtered by metrics"
+
+  Scenario: Local evaluation agrees with the server
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And 4 runs exist tagged "member" with increasing accuracy
+    Then the filter "metrics.accuracy >= 0.6 AND tags.member != 'member-3'" selects the same runs locally as on the server
@@ -0,0 +1,72 @@
+Feature: Type-safe search filters and order_by
+  As an engineer searching runs, experiments and models
+  I want filters built from typed identifiers instead of raw strings
+  So that values are quoted correctly and unsupported identifiers are caught before the call
+
+  Scenario Outline: Run filter comparisons are quoted and escaped
+    Given the filter includes <type> "<key>" <op> <value>
+    Then the runs filter should be <filter>
+
+    Examples:
+      | type      | key           | op    | value         | filter                                 |
+      | metric    | accuracy      | >     | 0.9           | metrics.accuracy > 0.9                 |
+      | tag       | team          | =     | "vision"      | tags.team = 'vision'                   |
+      | tag       | owner         | =     | "O'Brien"     | tags.owner = "O'Brien"                 |
+      | param     | model.depth   | =     | "12"          | params.`model.depth` = '12'            |
+      | tag       | release notes | LIKE  | "%beta%"      | tags.`release notes` LIKE '%beta%'     |
+      | attribute | start_time    | >=    | 1700000000000 | attributes.start_time >= 1700000000000 |
+      | dataset   | digest        | ILIKE | "ABC%"        | datasets.digest ILIKE 'ABC%'           |
+
+  Scenario: Comparisons are combined with AND
+    Given the filter includes metric "accuracy" > 0.9
+    And the filter includes tag "team" = "vision"
+    And the filter includes attribute "status" IN "RUNNING, FINISHED"
+    Then the runs filter should be metrics.accuracy > 0.9 AND tags.team = 'vision' AND attributes.status IN ('RUNNING', 'FINISHED')
+
+  Scenario Outline: Identifiers and comparators an endpoint does not support are rejected
+    Given the filter includes <type> "<key>" <op> <value>
+    Then building the <entity> filter should fail mentioning "<error>"
+
+    Examples:
+      | entity            | type      | key            | op   | value  | error                                              |
+      | experiments       | metric    | accuracy       | >    | 0.9    | experiments cannot be filtered by metrics          |
+      | registered_models | attribute | run_id         | =    | "abc"  | registered_models cannot be filtered by attribute  |
+      | runs              | metric    | accuracy       | LIKE | "%9"   | metrics.accuracy does not support LIKE             |
+      | runs              | tag       | team           | >    | "a"    | tags.team does not support >                       |
+      | runs              | metric    | accuracy       | >    | "high" | metrics.accuracy must be compared with a number    |
+      | model_versions    | attribute | version_number | =    | "3"    | version_number must be compared with a number      |
+      | runs              | tag       | a`b            | =    | "x"    | cannot be used in a filter: it contains a backtick |
+      | runs              | tag       | note           | =    | "'\""  | contains both single and double quotes             |
+
+  Scenario Outline: Order by clauses are validated per endpoint
+    Given results are ordered by <type> "<key>" <direction>
+    Then the <entity> order_by should be <order_by>
+
+    Examples:
+      | entity            | type      | key                    | direction | order_by                   |
+      | runs              | metric    | val loss               | ASC       | metrics.`val loss` ASC     |
+      | runs              | attribute | start_time             | DESC      | attributes.start_time DESC |
+      | experiments       | attribute | last_update_time       | DESC      | last_update_time DESC      |
+      | registered_models | attribute | last_updated_timestamp | ASC       | last_updated_timestamp ASC |
+      | model_versions    | attribute | version_number         | DESC      | version_number DESC        |
+
+  Scenario Outline: Unsupported order by clauses are rejected
+    Given results are ordered by <type> "<key>" ASC
+    Then building the <entity> order_by should fail mentioning "<error>"
+
+    Examples:
+      | entity            | type      | key            | error                                       |
+      | experiments       | metric    | accuracy       | cannot order experiments by metrics         |
+      | registered_models | attribute | version_number | cannot order registered_models by attribute |
+
+  Scenario: Built filters find runs with quotes and dots in tags
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    And I set tag "owner" with value "O'Brien" on the run
+    And I set tag "data.version" with value "v2" on the run
+    And the filter includes tag "owner" = "O'Brien"
+    And the filter includes tag "data.version" = "v2"
+    When I search runs in the experiment with the built filter
+    Then the search should return 1 run
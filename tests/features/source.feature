@@ -0,0 +1,52 @@
+Feature: Source and reproducibility tags
+  As an engineer who needs to trace a run back to exact code
+  I want runs created from Go to carry the same source tags as the Python client
+  So that any run records the commit, user, host and module versions it ran with
+
+  Scenario: Source facts become system tags
+    Given a source built from commit "3f9c2d1" at "2026-10-01T12:00:00Z" with uncommitted changes
+    Then the source tags should be:
+      | key                             | value                             |
+      | mlflow.source.type              | LOCAL                             |
+      | mlflow.source.name              | github.com/acme/trainer/cmd/train |
+      | mlflow.source.git.commit        | 3f9c2d1                           |
+      | mlflow-go.source.git.commitTime | 2026-10-01T12:00:00Z              |
+      | mlflow-go.source.git.modified   | true                              |
+      | mlflow.user                     | ada                               |
+      | mlflow-go.hostname              | gpu-node-7                        |
+
+  Scenario: Source detection reads the build info of the running binary
+    When I detect the source of the running program
+    Then the detected main module should be "github.com/julpayne/mlflow-go-client"
+    And the detected source should have the running Go version, GOOS and GOARCH
+    And the detected dependencies should include "github.com/cucumber/godog"
+
+  Scenario: A run created with Source is tagged and gets an environment artifact
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    When I create a run with source tracking
+    Then the run should have tag "mlflow.source.type" with value "LOCAL"
+    And the run should have tag "mlflow.source.name" with value "github.com/julpayne/mlflow-go-client/tests.test"
+    And the run should have a tag "mlflow.user"
+    And the run should have artifact "environment/go-environment.json"
+
+  Scenario: Tags set on the request take precedence over detected ones
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    When I create a run with source tracking and tag "mlflow.source.name" = "train.go"
+    Then the run should have tag "mlflow.source.name" with value "train.go"
+
+  Scenario: Text is logged as a run artifact
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And an experiment with a unique name exists
+    And a run exists in the experiment
+    When I log the text "epochs: 10" to artifact "config/notes.txt"
+    Then the run should have artifact "config/notes.txt"
+
+  Scenario: Artifact paths cannot leave the run's artifact root
+    Given an MLflow client for an unreachable server
+    When I attempt to log text to artifact "../other-run/notes.txt" of run "abc"
+    Then the call should fail with "invalid artifact path"
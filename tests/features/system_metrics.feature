@@ -0,0 +1,52 @@
+Feature: System metrics
+  As an engineer training models on shared machines
+  I want a run to record the CPU, memory, disk, network and Go runtime usage of the job
+  So that I can see in the MLflow UI what a run cost and why it was slow
+
+  Scenario: A run logs system metrics until it ends
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    When I start a run in the experiment
+    And I start logging system metrics to the active run every 50 milliseconds
+    And I wait 300 milliseconds
+    And I end the active run with status "FINISHED"
+    Then the active run should have status "FINISHED"
+    And the active run should have metric "system/go_goroutines" logged at least 2 times
+    And the active run should have metric "system/go_heap_alloc_megabytes" logged at least 2 times
+    And the active run should have a positive "system/go_goroutines"
+
+  Scenario: A run that ends before the first interval gets one sample
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    When I start a run in the experiment
+    And I start logging system metrics to the active run every 3600000 milliseconds
+    And I end the active run with status "FINISHED"
+    Then the active run should have metric "system/go_goroutines" logged once
+
+  Scenario: System metrics cannot be started twice on a run
+    Given an MLflow server is running at "http://localhost:5000"
+    And I have an MLflow client connected to the server
+    And no MLflow environment variables are set
+    And an experiment with a unique name exists
+    When I start a run in the experiment
+    And I start logging system metrics to the active run every 3600000 milliseconds
+    And I attempt to start logging system metrics to the active run again
+    Then the call should fail with "system metrics are already being logged"
+    And I end the active run with status "FINISHED"
+
+  Scenario: Invalid options are rejected
+    Given an MLflow client for an unreachable server
+    When I attempt to start system metrics for run "run-1" every -1 milliseconds
+    Then the call should fail with "sampling interval must not be negative"
+    When I attempt to start system metrics for run "" every 10 milliseconds
+    Then the call should fail with "run ID is required"
+
+  Scenario: Logging failures are reported without stopping the job
+    Given an MLflow client for an unreachable server
+    When a system metrics monitor for run "run-1" runs for 50 milliseconds
+    Then the call should fail with "failed to log system metrics to run run-1"
+    And the monitor should have reported an error containing "failed to log system metrics"
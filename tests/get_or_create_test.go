@@ -0,0 +1,253 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Get-or-create step implementations
+
+func (tc *testContext) getOrCreateExperimentNamed(name string, opts mlflow.ExperimentOptions) error {
+	experiment, created, err := tc.client.GetOrCreateExperiment(name, opts)
+	if err != nil {
+		return err
+	}
+	if tc.experimentID != "" {
+		tc.previousID = tc.experimentID
+	}
+	tc.experimentID = experiment.ExperimentID
+	tc.experimentName = name
+	tc.experimentCreated = created
+	if created {
+		tc.createdResources = append(tc.createdResources, resource{Type: "experiment", ID: experiment.ExperimentID, Name: name})
+	}
+	return nil
+}
+
+func (tc *testContext) getOrCreateUniqueExperiment() error {
+	return tc.getOrCreateExperimentNamed(fmt.Sprintf("test-experiment-%s", uuid.New().String()), mlflow.ExperimentOptions{})
+}
+
+func (tc *testContext) getOrCreateExperimentAgain() error {
+	return tc.getOrCreateExperimentNamed(tc.experimentName, mlflow.ExperimentOptions{})
+}
+
+func (tc *testContext) getOrCreateExperimentRestoring() error {
+	return tc.getOrCreateExperimentNamed(tc.experimentName, mlflow.ExperimentOptions{RestoreDeleted: true})
+}
+
+func (tc *testContext) attemptGetOrCreateExperimentAgain() error {
+	_, _, tc.lastError = tc.client.GetOrCreateExperiment(tc.experimentName, mlflow.ExperimentOptions{})
+	return nil
+}
+
+func (tc *testContext) experimentWasCreated(not string) error {
+	if tc.experimentCreated != (not == "") {
+		return fmt.Errorf("expected created to be %v", not == "")
+	}
+	return nil
+}
+
+func (tc *testContext) sameExperimentReturned() error {
+	if tc.previousID != tc.experimentID {
+		return fmt.Errorf("expected experiment %s, got %s", tc.previousID, tc.experimentID)
+	}
+	return nil
+}
+
+// runWorkers runs fn in n goroutines at once and records the IDs they return
+func (tc *testContext) runWorkers(n int, fn func() (string, bool, error)) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	tc.workerIDs = nil
+	tc.workerFlags = 0
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			id, flag, err := fn()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			tc.workerIDs = append(tc.workerIDs, id)
+			if flag {
+				tc.workerFlags++
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (tc *testContext) workersGetOrCreateExperiment(n int) error {
+	name := fmt.Sprintf("test-experiment-%s", uuid.New().String())
+	err := tc.runWorkers(n, func() (string, bool, error) {
+		experiment, created, err := tc.client.GetOrCreateExperiment(name, mlflow.ExperimentOptions{})
+		if err != nil {
+			return "", false, err
+		}
+		return experiment.ExperimentID, created, nil
+	})
+	if len(tc.workerIDs) > 0 {
+		tc.createdResources = append(tc.createdResources, resource{Type: "experiment", ID: tc.workerIDs[0], Name: name})
+	}
+	return err
+}
+
+func (tc *testContext) everyWorkerGetsSameID() error {
+	if len(tc.workerIDs) == 0 {
+		return fmt.Errorf("no worker returned an ID")
+	}
+	for _, id := range tc.workerIDs {
+		if id != tc.workerIDs[0] {
+			return fmt.Errorf("expected every worker to get %s, got %v", tc.workerIDs[0], tc.workerIDs)
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) workersCreated(count int) error {
+	if tc.workerFlags != count {
+		return fmt.Errorf("expected %d workers to create the experiment, got %d", count, tc.workerFlags)
+	}
+	return nil
+}
+
+func (tc *testContext) resumeOrStartRun(key string) error {
+	run, resumed, err := tc.client.ResumeOrStartRun(tc.experimentID, key)
+	if err != nil {
+		return err
+	}
+	if tc.activeRun != nil {
+		tc.previousID = tc.activeRun.RunID
+	}
+	tc.activeRun = run
+	tc.runResumed = resumed
+	if !resumed {
+		tc.createdResources = append(tc.createdResources, resource{Type: "run", ID: run.RunID})
+	}
+	return nil
+}
+
+func (tc *testContext) attemptResumeOrStartWithEmptyKey() error {
+	_, _, tc.lastError = tc.client.ResumeOrStartRun("0", "")
+	return nil
+}
+
+func (tc *testContext) runWasStarted() error {
+	if tc.runResumed {
+		return fmt.Errorf("expected a new run, got resumed run %s", tc.activeRun.RunID)
+	}
+	return nil
+}
+
+func (tc *testContext) runWasResumed() error {
+	if !tc.runResumed {
+		return fmt.Errorf("expected run to be resumed, got new run %s", tc.activeRun.RunID)
+	}
+	return nil
+}
+
+func (tc *testContext) resumedRunIsFirst() error {
+	if tc.activeRun.RunID != tc.previousID {
+		return fmt.Errorf("expected run %s to be resumed, got %s", tc.previousID, tc.activeRun.RunID)
+	}
+	return nil
+}
+
+func (tc *testContext) resumedRunIsAWorkers() error {
+	for _, id := range tc.workerIDs {
+		if id == tc.activeRun.RunID {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected one of the workers' runs %v to be resumed, got %s", tc.workerIDs, tc.activeRun.RunID)
+}
+
+func (tc *testContext) resumedRunEnds(status string) error {
+	return tc.activeRun.End(mlflow.RunStatus(status))
+}
+
+func (tc *testContext) resumedRunHasStatus(status string) error {
+	run, err := tc.activeRun.Run()
+	if err != nil {
+		return err
+	}
+	if run.Info.Status != mlflow.RunStatus(status) {
+		return fmt.Errorf("expected status %s, got %s", status, run.Info.Status)
+	}
+	return nil
+}
+
+func (tc *testContext) workersResumeOrStartRun(n int, key string) error {
+	return tc.runWorkers(n, func() (string, bool, error) {
+		run, resumed, err := tc.client.ResumeOrStartRun(tc.experimentID, key)
+		if err != nil {
+			return "", false, err
+		}
+		return run.RunID, resumed, nil
+	})
+}
+
+// missFirstRunSearch answers the first run search with no runs, as a server
+// would before another process's run is visible, and passes everything else
+// through
+type missFirstRunSearch struct {
+	base   http.RoundTripper
+	mu     sync.Mutex
+	missed bool
+}
+
+func (m *missFirstRunSearch) RoundTrip(r *http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	miss := !m.missed && strings.HasSuffix(r.URL.Path, "/mlflow/runs/search")
+	m.missed = m.missed || miss
+	m.mu.Unlock()
+	if !miss {
+		return m.base.RoundTrip(r)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    r,
+	}, nil
+}
+
+// otherProcessStartedRun creates an earlier run with the key and hides it
+// from the client's next search, so the client creates a duplicate
+func (tc *testContext) otherProcessStartedRun(key string) error {
+	resp, err := tc.client.CreateRun(mlflow.CreateRunRequest{
+		ExperimentID: tc.experimentID,
+		StartTime:    time.Now().Add(-time.Minute).UnixMilli(),
+		Tags:         []mlflow.RunTag{{Key: mlflow.TagIdempotencyKey, Value: key}},
+	})
+	if err != nil {
+		return err
+	}
+	tc.previousID = resp.Run.Info.RunID
+	tc.createdResources = append(tc.createdResources, resource{Type: "run", ID: resp.Run.Info.RunID})
+	base := tc.client.HTTPClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	tc.client.HTTPClient.Transport = &missFirstRunSearch{base: base}
+	return nil
+}
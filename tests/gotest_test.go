@@ -0,0 +1,206 @@
+package features
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cucumber/godog"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/gotest"
+)
+
+// Go test result step implementations
+
+func (tc *testContext) goTestPrinted(output *godog.DocString) error {
+	tc.goTestOutput = output.Content
+	return nil
+}
+
+func (tc *testContext) parseGoTestOutput() error {
+	report, err := gotest.Parse(strings.NewReader(tc.goTestOutput))
+	if err != nil {
+		return err
+	}
+	tc.goTestReport = report
+	return nil
+}
+
+func (tc *testContext) reportCounts(passed, failed, skipped int) error {
+	r := tc.goTestReport
+	if r.Passed != passed || r.Failed != failed || r.Skipped != skipped {
+		return fmt.Errorf("expected %d passed, %d failed and %d skipped, got %d, %d and %d", passed, failed, skipped, r.Passed, r.Failed, r.Skipped)
+	}
+	return nil
+}
+
+func (tc *testContext) failedTestsShouldBe(names string) error {
+	if got := strings.Join(tc.goTestReport.FailedTests, ", "); got != names {
+		return fmt.Errorf("expected failed tests %q, got %q", names, got)
+	}
+	return nil
+}
+
+func (tc *testContext) failedPackagesShouldBe(names string) error {
+	if got := strings.Join(tc.goTestReport.FailedPackages, ", "); got != names {
+		return fmt.Errorf("expected failed packages %q, got %q", names, got)
+	}
+	return nil
+}
+
+// reportBenchmarksShouldBe checks the report's benchmarks in order. Each
+// benchmark spans consecutive rows with its name, procs and iterations, one
+// per listed unit; a unit listed again starts the next benchmark.
+func (tc *testContext) reportBenchmarksShouldBe(table *godog.Table) error {
+	benchmarks := tc.goTestReport.Benchmarks
+	index := -1
+	var group string
+	units := map[string]bool{}
+	for _, row := range table.Rows[1:] {
+		name, procs, iterations, unit, value := row.Cells[0].Value, row.Cells[1].Value, row.Cells[2].Value, row.Cells[3].Value, row.Cells[4].Value
+		if key := name + " " + procs + " " + iterations; key != group || units[unit] {
+			index++
+			group = key
+			units = map[string]bool{}
+		}
+		units[unit] = true
+		if index >= len(benchmarks) {
+			return fmt.Errorf("expected at least %d benchmarks, got %d", index+1, len(benchmarks))
+		}
+		b := benchmarks[index]
+		if b.Name != name || strconv.Itoa(b.Procs) != procs || strconv.FormatInt(b.Iterations, 10) != iterations {
+			return fmt.Errorf("expected benchmark %d to be %s with %s procs and %s iterations, got %+v", index, name, procs, iterations, b)
+		}
+		want, _ := strconv.ParseFloat(value, 64)
+		if got, ok := b.Values[unit]; !ok || got != want {
+			return fmt.Errorf("expected %s %s = %v, got %v (present: %v)", name, unit, want, got, ok)
+		}
+	}
+	if index+1 != len(benchmarks) {
+		return fmt.Errorf("expected %d benchmarks, got %d", index+1, len(benchmarks))
+	}
+	return nil
+}
+
+func (tc *testContext) reportFromMachine(goos, goarch, cpu string) error {
+	r := tc.goTestReport
+	if r.GOOS != goos || r.GOARCH != goarch || r.CPU != cpu {
+		return fmt.Errorf("expected %s %s %s, got %s %s %s", goos, goarch, cpu, r.GOOS, r.GOARCH, r.CPU)
+	}
+	return nil
+}
+
+func (tc *testContext) recordBenchmark(name string, value float64, unit string) error {
+	if tc.goTestReport == nil {
+		tc.goTestReport = &gotest.Report{}
+	}
+	tc.goTestReport.Benchmark(name, func(b *testing.B) {
+		sum := 0
+		for i := 0; i < b.N; i++ {
+			sum += i
+		}
+		b.ReportMetric(value, unit)
+	})
+	return nil
+}
+
+func (tc *testContext) benchmarkValue(name, unit string) (float64, error) {
+	for _, b := range tc.goTestReport.Benchmarks {
+		if b.Name == name {
+			if value, ok := b.Values[unit]; ok {
+				return value, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("expected %s for %s in %+v", unit, name, tc.goTestReport.Benchmarks)
+}
+
+func (tc *testContext) reportHasPositiveValue(unit, name string) error {
+	value, err := tc.benchmarkValue(name, unit)
+	if err != nil {
+		return err
+	}
+	if value <= 0 {
+		return fmt.Errorf("expected a positive %s for %s, got %v", unit, name, value)
+	}
+	return nil
+}
+
+func (tc *testContext) reportHasValue(want float64, unit, name string) error {
+	value, err := tc.benchmarkValue(name, unit)
+	if err != nil {
+		return err
+	}
+	if value != want {
+		return fmt.Errorf("expected %s = %v for %s, got %v", unit, want, name, value)
+	}
+	return nil
+}
+
+func (tc *testContext) metricKeyShouldBe(benchmark, unit, key string) error {
+	if got := gotest.MetricKey(benchmark, unit); got != key {
+		return fmt.Errorf("expected key %q, got %q", key, got)
+	}
+	return nil
+}
+
+func (tc *testContext) logGoTestReport(commit string) error {
+	runID, err := gotest.Log(tc.client, tc.goTestReport, gotest.Options{ExperimentID: tc.experimentID, GitCommit: commit})
+	if runID != "" {
+		tc.runID = runID
+		tc.createdResources = append(tc.createdResources, resource{Type: "run", ID: runID})
+	}
+	return err
+}
+
+func (tc *testContext) reportHasMetric(key string, value float64, step int64) error {
+	for _, m := range tc.goTestReport.Metrics(time.Now()) {
+		if m.Key == key && m.Value == value && m.Step == step {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected metric %s = %v at step %d in %+v", key, value, step, tc.goTestReport.Metrics(time.Now()))
+}
+
+func (tc *testContext) metricLoggedAs(key, values string) error {
+	resp, err := tc.client.GetMetricHistory(mlflow.GetMetricHistoryRequest{RunID: tc.runID, MetricKey: key, MaxResults: 100})
+	if err != nil {
+		return err
+	}
+	var got []string
+	for i, m := range resp.Metrics {
+		if m.Step != int64(i) {
+			return fmt.Errorf("expected %s to be logged at steps 0..%d, got step %d at %d", key, len(resp.Metrics)-1, m.Step, i)
+		}
+		got = append(got, strconv.FormatFloat(m.Value, 'f', -1, 64))
+	}
+	if strings.Join(got, ", ") != values {
+		return fmt.Errorf("expected %s to be logged as %s, got %s", key, values, strings.Join(got, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) manyTestsFailed(count int, name string) error {
+	tc.goTestReport = &gotest.Report{}
+	for i := 0; i < count; i++ {
+		tc.goTestReport.Failed++
+		tc.goTestReport.FailedTests = append(tc.goTestReport.FailedTests, fmt.Sprintf("%s%d", name, i))
+	}
+	return nil
+}
+
+func (tc *testContext) failedTestsTagLimited(limit int, suffix string) error {
+	for _, tag := range tc.goTestReport.Tags() {
+		if tag.Key != gotest.TagFailedTests {
+			continue
+		}
+		if len(tag.Value) > limit || !strings.HasSuffix(tag.Value, suffix) {
+			return fmt.Errorf("expected at most %d characters ending with %q, got %d ending with %q", limit, suffix, len(tag.Value), tag.Value[max(0, len(tag.Value)-40):])
+		}
+		return nil
+	}
+	return fmt.Errorf("expected a %s tag", gotest.TagFailedTests)
+}
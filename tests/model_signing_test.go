@@ -0,0 +1,243 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Model signing step implementations
+
+// remoteSigner stands in for a signer whose private key is held by a KMS
+// or an HSM
+type remoteSigner struct {
+	identity string
+	key      ed25519.PrivateKey
+	down     bool
+}
+
+func (s *remoteSigner) Identity() string {
+	return s.identity
+}
+
+func (s *remoteSigner) Sign(_ context.Context, message []byte) ([]byte, error) {
+	if s.down {
+		return nil, errors.New("signing service is unavailable")
+	}
+	return ed25519.Sign(s.key, message), nil
+}
+
+func (tc *testContext) signingKeyFor(identity string) error {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if tc.signers == nil {
+		tc.signers = map[string]mlflow.ModelSigner{}
+		tc.signerKeys = map[string]ed25519.PublicKey{}
+	}
+	signer, err := mlflow.NewEd25519Signer(identity, key)
+	if err != nil {
+		return err
+	}
+	tc.signers[identity] = signer
+	tc.signerKeys[identity] = signer.PublicKey()
+	return nil
+}
+
+func (tc *testContext) remoteSigningKeyFor(identity string) error {
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if tc.signers == nil {
+		tc.signers = map[string]mlflow.ModelSigner{}
+		tc.signerKeys = map[string]ed25519.PublicKey{}
+	}
+	tc.signers[identity] = &remoteSigner{identity: identity, key: key}
+	tc.signerKeys[identity] = public
+	return nil
+}
+
+func (tc *testContext) signingServiceDown(identity string) error {
+	signer, ok := tc.signers[identity].(*remoteSigner)
+	if !ok {
+		return fmt.Errorf("no remote signer %s", identity)
+	}
+	signer.down = true
+	return nil
+}
+
+// modelVersionOfRunArtifacts registers the run's artifacts at artifactPath
+// as a new version of the scenario's model, with a runs:/ source or the
+// artifact URI itself and the run's ID
+func (tc *testContext) modelVersionOfRunArtifacts(artifactPath, source string) error {
+	if source == "run URI" {
+		return tc.createModelVersion("runs:/" + tc.runID + "/" + artifactPath)
+	}
+	run, err := tc.client.GetRun(tc.runID)
+	if err != nil {
+		return err
+	}
+	resp, err := tc.client.CreateModelVersion(mlflow.CreateModelVersionRequest{
+		Name:   tc.modelName,
+		Source: run.Run.Info.ArtifactURI + "/" + artifactPath,
+		RunID:  tc.runID,
+	})
+	if err != nil {
+		return err
+	}
+	tc.modelVersion = resp.ModelVersion.Version
+	return nil
+}
+
+func (tc *testContext) signModelVersion(identity string) error {
+	signer, ok := tc.signers[identity]
+	if !ok {
+		return fmt.Errorf("no signing key for %s", identity)
+	}
+	var err error
+	tc.signedManifest, err = tc.client.SignModelVersion(tc.modelName, tc.modelVersion, signer)
+	return err
+}
+
+func (tc *testContext) attemptSignModelVersion(identity string) error {
+	tc.lastError = tc.signModelVersion(identity)
+	return nil
+}
+
+// trustedKeys returns the public keys of the comma separated identities
+func (tc *testContext) trustedKeys(identities string) map[string]ed25519.PublicKey {
+	trusted := map[string]ed25519.PublicKey{}
+	for _, identity := range strings.Split(identities, ",") {
+		identity = strings.TrimSpace(identity)
+		if key, ok := tc.signerKeys[identity]; ok {
+			trusted[identity] = key
+		}
+	}
+	return trusted
+}
+
+// verifyModelVersion verifies the model version into a new download
+// directory
+func (tc *testContext) verifyModelVersion(identities string) error {
+	dir, err := tc.newTempDir("mlflow-verified-")
+	if err != nil {
+		return err
+	}
+	tc.downloadDir = dir
+	manifest, err := tc.client.VerifyModelVersion(tc.modelName, tc.modelVersion, dir, tc.trustedKeys(identities))
+	if err != nil {
+		return err
+	}
+	tc.signedManifest = manifest
+	return nil
+}
+
+func (tc *testContext) attemptVerifyModelVersion(identities string) error {
+	tc.lastError = tc.verifyModelVersion(identities)
+	return nil
+}
+
+func (tc *testContext) attemptVerifyModelVersionIntoDirectoryHolding(file, identities string) error {
+	dir, err := tc.newTempDir("mlflow-verified-")
+	if err != nil {
+		return err
+	}
+	tc.downloadDir = dir
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(file)), []byte("stale"), 0o644); err != nil {
+		return err
+	}
+	_, tc.lastError = tc.client.VerifyModelVersion(tc.modelName, tc.modelVersion, dir, tc.trustedKeys(identities))
+	return nil
+}
+
+// verifiedFilesShouldBeDownloaded checks that each file of the verified
+// manifest is at its local path in the download directory
+func (tc *testContext) verifiedFilesShouldBeDownloaded() error {
+	for _, file := range tc.signedManifest.Files {
+		if want := filepath.Join(tc.downloadDir, filepath.FromSlash(file.Path)); file.LocalPath != want {
+			return fmt.Errorf("expected %s at %s, got %s", file.Path, want, file.LocalPath)
+		}
+		info, err := os.Stat(file.LocalPath)
+		if err != nil {
+			return err
+		}
+		if info.Size() != file.Size {
+			return fmt.Errorf("expected %s to have %d bytes, got %d", file.LocalPath, file.Size, info.Size())
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) downloadDirectoryShouldBeEmpty() error {
+	entries, err := os.ReadDir(tc.downloadDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("expected the download directory to be empty, got %d entries", len(entries))
+	}
+	return nil
+}
+
+func (tc *testContext) signedManifestShouldList(want string) error {
+	if tc.signedManifest == nil {
+		return fmt.Errorf("no signed manifest")
+	}
+	var got []string
+	for _, file := range tc.signedManifest.Files {
+		if len(file.SHA256) != 64 {
+			return fmt.Errorf("invalid SHA-256 %q of %s", file.SHA256, file.Path)
+		}
+		got = append(got, fmt.Sprintf("%s (%d)", file.Path, file.Size))
+	}
+	if strings.Join(got, ", ") != want {
+		return fmt.Errorf("expected the signed manifest to list %q, got %q", want, strings.Join(got, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) modelVersionShouldBeSignedBy(identity string) error {
+	resp, err := tc.client.GetModelVersion(tc.modelName, tc.modelVersion)
+	if err != nil {
+		return err
+	}
+	tags := map[string]string{}
+	for _, tag := range resp.ModelVersion.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	if tags[mlflow.TagModelSigner] != identity {
+		return fmt.Errorf("expected the model version to be signed by %q, got %q", identity, tags[mlflow.TagModelSigner])
+	}
+	if tags[mlflow.TagModelSignature] == "" {
+		return fmt.Errorf("expected the model version to have a signature")
+	}
+	return nil
+}
+
+// replaceRunArtifact stores content at artifactPath of the run, adding the
+// file if it is not there
+func (tc *testContext) replaceRunArtifact(artifactPath, content string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	return repo.Upload(context.Background(), artifactPath, bytes.NewReader([]byte(content)), int64(len(content)))
+}
+
+func (tc *testContext) deleteRunArtifact(artifactPath string) error {
+	repo, err := tc.client.RunArtifactRepository(tc.runID)
+	if err != nil {
+		return err
+	}
+	return repo.Delete(context.Background(), artifactPath)
+}
@@ -334,7 +334,7 @@ func (tc *testContext) transitionModelVersionStage(stage string) error {
 	if tc.modelName == "" || tc.modelVersion == "" {
 		return fmt.Errorf("model name or version not set")
 	}
-	_, err := tc.client.TransitionModelVersionStage(tc.modelName, tc.modelVersion, stage, "")
+	_, err := tc.client.TransitionModelVersionStage(tc.modelName, tc.modelVersion, mlflow.ModelStage(stage), "")
 	if err != nil {
 		return err
 	}
@@ -346,7 +346,7 @@ func (tc *testContext) modelVersionStageShouldBe(stage string) error {
 	if err != nil {
 		return err
 	}
-	if version.ModelVersion.CurrentStage != stage {
+	if version.ModelVersion.CurrentStage != mlflow.ModelStage(stage) {
 		return fmt.Errorf("expected stage %s, got %s", stage, version.ModelVersion.CurrentStage)
 	}
 	return nil
@@ -0,0 +1,353 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Multipart upload step implementations
+
+// multipartStub is an artifact proxy whose artifact store supports multipart
+// uploads, as with an S3 store. It serves a single run whose parts are
+// uploaded to the stub itself.
+type multipartStub struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+	// unsupported makes create fail as for a local artifact store
+	unsupported bool
+	// failures is how often each part number fails before succeeding, -1
+	// for always, with failStatus
+	failures   map[int]int
+	failStatus int
+	uploads    map[string]map[int][]byte
+	nextID     int
+	attempts   map[int]int
+	aborted    []string
+	files      map[string][]byte
+	creates    int
+}
+
+const multipartStubRunID = "run-1"
+
+func newMultipartStub() *multipartStub {
+	s := &multipartStub{
+		failures: map[int]int{},
+		uploads:  map[string]map[int][]byte{},
+		attempts: map[int]int{},
+		files:    map[string][]byte{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *multipartStub) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	const root = "/api/2.0/mlflow-artifacts/"
+	const runRoot = "1/" + multipartStubRunID + "/artifacts"
+	p := r.URL.Path
+	switch {
+	case p == "/api/2.0/mlflow/runs/get":
+		writeStubJSON(w, map[string]any{"run": map[string]any{"info": map[string]any{
+			"run_id":       multipartStubRunID,
+			"artifact_uri": "mlflow-artifacts:/" + runRoot,
+		}}})
+	case strings.HasPrefix(p, root+"mpu/"):
+		action, dir, _ := strings.Cut(strings.TrimPrefix(p, root+"mpu/"), "/")
+		dir = strings.Trim(strings.TrimPrefix(dir, runRoot), "/")
+		var req struct {
+			Path     string `json:"path"`
+			NumParts int    `json:"num_parts"`
+			UploadID string `json:"upload_id"`
+			Parts    []mlflow.MultipartUploadPart
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			stubError(w, http.StatusBadRequest, "INVALID_PARAMETER_VALUE", err.Error())
+			return
+		}
+		s.multipart(w, action, strings.TrimPrefix(dir+"/"+req.Path, "/"), req.NumParts, req.UploadID, req.Parts)
+	case strings.HasPrefix(p, root+"artifacts/"+runRoot+"/") && r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.files[strings.TrimPrefix(p, root+"artifacts/"+runRoot+"/")] = data
+		writeStubJSON(w, map[string]any{})
+	case strings.HasPrefix(p, "/parts/") && r.Method == http.MethodPut:
+		uploadID, number, _ := strings.Cut(strings.TrimPrefix(p, "/parts/"), "/")
+		n, _ := strconv.Atoi(number)
+		data, _ := io.ReadAll(r.Body)
+		s.attempts[n]++
+		if s.failures[n] != 0 {
+			if s.failures[n] > 0 {
+				s.failures[n]--
+			}
+			w.WriteHeader(s.failStatus)
+			return
+		}
+		parts, ok := s.uploads[uploadID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		parts[n] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"%s-%d"`, uploadID, n))
+	default:
+		stubError(w, http.StatusNotFound, "ENDPOINT_NOT_FOUND", p)
+	}
+}
+
+func (s *multipartStub) multipart(w http.ResponseWriter, action, artifactPath string, numParts int, uploadID string, parts []mlflow.MultipartUploadPart) {
+	switch action {
+	case "create":
+		if s.unsupported {
+			stubError(w, http.StatusNotImplemented, mlflow.ErrorCodeNotImplemented, "Multipart upload is not supported for the current artifact repository")
+			return
+		}
+		s.creates++
+		s.nextID++
+		id := fmt.Sprintf("upload-%d", s.nextID)
+		s.uploads[id] = map[int][]byte{}
+		var credentials []mlflow.MultipartUploadCredential
+		for n := 1; n <= numParts; n++ {
+			credentials = append(credentials, mlflow.MultipartUploadCredential{
+				URL:        fmt.Sprintf("%s/parts/%s/%d", s.server.URL, id, n),
+				PartNumber: int64(n),
+			})
+		}
+		writeStubJSON(w, mlflow.CreateMultipartUploadResponse{UploadID: id, Credentials: credentials})
+	case "complete":
+		stored, ok := s.uploads[uploadID]
+		if !ok {
+			stubError(w, http.StatusNotFound, mlflow.ErrorCodeResourceDoesNotExist, "no upload "+uploadID)
+			return
+		}
+		var data []byte
+		for i, part := range parts {
+			if part.PartNumber != int64(i+1) || part.ETag != fmt.Sprintf(`"%s-%d"`, uploadID, i+1) {
+				stubError(w, http.StatusBadRequest, "INVALID_PARAMETER_VALUE", fmt.Sprintf("unexpected part %+v", part))
+				return
+			}
+			data = append(data, stored[i+1]...)
+		}
+		s.files[artifactPath] = data
+		delete(s.uploads, uploadID)
+		writeStubJSON(w, map[string]any{})
+	case "abort":
+		s.aborted = append(s.aborted, uploadID)
+		delete(s.uploads, uploadID)
+		writeStubJSON(w, map[string]any{})
+	default:
+		stubError(w, http.StatusNotFound, "ENDPOINT_NOT_FOUND", action)
+	}
+}
+
+func writeStubJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func stubError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(mlflow.ErrorResponse{ErrorCode: code, Message: message})
+}
+
+func (tc *testContext) multipartArtifactProxy(supported string) error {
+	tc.mpuStub = newMultipartStub()
+	tc.mpuStub.unsupported = supported == "does not support"
+	tc.client = mlflow.NewClient(tc.mpuStub.server.URL)
+	tc.runID = multipartStubRunID
+	return nil
+}
+
+func (tc *testContext) localFileOfBytes(size int) error {
+	dir, err := tc.newTempDir("mlflow-multipart-")
+	if err != nil {
+		return err
+	}
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+	tc.mpuFile = filepath.Join(dir, "checkpoint.bin")
+	tc.mpuStateFile = filepath.Join(dir, "checkpoint.upload.json")
+	return os.WriteFile(tc.mpuFile, data, 0o644)
+}
+
+func (tc *testContext) partFails(number, times, status int) error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	tc.mpuStub.failures[number] = times
+	tc.mpuStub.failStatus = status
+	return nil
+}
+
+func (tc *testContext) partAlwaysFails(number, status int) error {
+	return tc.partFails(number, -1, status)
+}
+
+func (tc *testContext) partsRecover() error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	tc.mpuStub.failures = map[int]int{}
+	tc.mpuStub.attempts = map[int]int{}
+	return nil
+}
+
+func (tc *testContext) proxyForgetsUploads() error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	tc.mpuStub.uploads = map[string]map[int][]byte{}
+	return nil
+}
+
+// uploadMultipart uploads the local file to the checkpoints directory with
+// the options of a step. cancelAfter cancels the upload once that many parts
+// are done, if positive.
+func (tc *testContext) uploadMultipart(partSize, concurrency, retries int, stateFile bool, cancelAfter int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := mlflow.MultipartUploadOptions{
+		PartSize:     int64(partSize),
+		Concurrency:  concurrency,
+		MaxRetries:   retries,
+		RetryBackoff: time.Millisecond,
+		Progress: func(p mlflow.MultipartProgress) {
+			tc.mpuProgress = append(tc.mpuProgress, p)
+			if cancelAfter > 0 && p.PartsDone >= cancelAfter {
+				cancel()
+			}
+		},
+	}
+	if stateFile {
+		opts.StateFile = tc.mpuStateFile
+	}
+	tc.mpuProgress = nil
+	tc.lastError = tc.client.LogArtifactMultipart(ctx, tc.runID, tc.mpuFile, "checkpoints", opts)
+}
+
+func (tc *testContext) uploadInParts(partSize, concurrency int) error {
+	tc.uploadMultipart(partSize, concurrency, 0, false, 0)
+	return tc.lastError
+}
+
+func (tc *testContext) uploadInPartsWithState(partSize int) error {
+	tc.uploadMultipart(partSize, 1, 0, true, 0)
+	return tc.lastError
+}
+
+func (tc *testContext) attemptUploadWithoutRetries(partSize int) error {
+	tc.uploadMultipart(partSize, 1, -1, true, 0)
+	return nil
+}
+
+func (tc *testContext) attemptUploadCancelledAfter(partSize, parts int) error {
+	tc.uploadMultipart(partSize, 1, 0, true, parts)
+	return nil
+}
+
+func (tc *testContext) proxyShouldStoreFile(artifactPath string) error {
+	want, err := os.ReadFile(tc.mpuFile)
+	if err != nil {
+		return err
+	}
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	got, ok := tc.mpuStub.files[artifactPath]
+	if !ok {
+		return fmt.Errorf("expected %s to be stored, got %d files", artifactPath, len(tc.mpuStub.files))
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("expected %s to have the %d bytes of the local file, got %d different bytes", artifactPath, len(want), len(got))
+	}
+	return nil
+}
+
+func (tc *testContext) proxyShouldStoreNothing() error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	if len(tc.mpuStub.files) > 0 {
+		return fmt.Errorf("expected no stored files, got %d", len(tc.mpuStub.files))
+	}
+	return nil
+}
+
+func (tc *testContext) partsShouldBeUploaded(count int) error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	uploaded := 0
+	for _, attempts := range tc.mpuStub.attempts {
+		uploaded += attempts
+	}
+	if uploaded != count {
+		return fmt.Errorf("expected %d part uploads, got %d: %v", count, uploaded, tc.mpuStub.attempts)
+	}
+	return nil
+}
+
+func (tc *testContext) partAttemptedTimes(number, times int) error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	if got := tc.mpuStub.attempts[number]; got != times {
+		return fmt.Errorf("expected part %d to be attempted %d times, got %d", number, times, got)
+	}
+	return nil
+}
+
+func (tc *testContext) uploadsShouldBeCreated(count int) error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	if tc.mpuStub.creates != count {
+		return fmt.Errorf("expected %d multipart uploads to be created, got %d", count, tc.mpuStub.creates)
+	}
+	return nil
+}
+
+func (tc *testContext) uploadShouldBeAborted(aborted string) error {
+	tc.mpuStub.mu.Lock()
+	defer tc.mpuStub.mu.Unlock()
+	if got := len(tc.mpuStub.aborted) > 0; got != (aborted == "be") {
+		return fmt.Errorf("expected the upload to %s aborted, got aborted uploads %v", aborted, tc.mpuStub.aborted)
+	}
+	return nil
+}
+
+func (tc *testContext) stateFileShouldExist(exists string) error {
+	_, err := os.Stat(tc.mpuStateFile)
+	switch {
+	case exists == "exist" && err != nil:
+		return fmt.Errorf("expected the state file to exist: %w", err)
+	case exists == "not exist" && err == nil:
+		return fmt.Errorf("expected the state file to be removed")
+	}
+	return nil
+}
+
+func (tc *testContext) progressShouldBeReported(times int, bytes int64) error {
+	if len(tc.mpuProgress) != times {
+		return fmt.Errorf("expected %d progress reports, got %d", times, len(tc.mpuProgress))
+	}
+	last := tc.mpuProgress[len(tc.mpuProgress)-1]
+	if last.BytesDone != bytes || last.Bytes != bytes || last.PartsDone != last.Parts {
+		return fmt.Errorf("expected the last report to be complete at %d bytes, got %+v", bytes, last)
+	}
+	for i := 1; i < len(tc.mpuProgress); i++ {
+		if tc.mpuProgress[i].BytesDone <= tc.mpuProgress[i-1].BytesDone {
+			return fmt.Errorf("expected progress to increase, got %+v", tc.mpuProgress)
+		}
+	}
+	return nil
+}
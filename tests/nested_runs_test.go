@@ -0,0 +1,206 @@
+package features
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cucumber/godog"
+	"github.com/google/uuid"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Nested runs step implementations
+
+func (tc *testContext) startChildRun(name, parentRunID string) error {
+	resp, err := tc.client.StartChildRun(parentRunID, mlflow.CreateRunRequest{RunName: name})
+	if err != nil {
+		return err
+	}
+	if tc.childRunIDs == nil {
+		tc.childRunIDs = map[string]string{}
+	}
+	tc.childRunIDs[name] = resp.Run.Info.RunID
+	tc.createdResources = append(tc.createdResources, resource{Type: "run", ID: resp.Run.Info.RunID})
+	return nil
+}
+
+// attemptChildRunInOtherExperiment starts a child run under the run in a new
+// experiment
+func (tc *testContext) attemptChildRunInOtherExperiment(name string) error {
+	name = name + "-" + uuid.NewString()
+	resp, err := tc.client.CreateExperiment(mlflow.CreateExperimentRequest{Name: name})
+	if err != nil {
+		return err
+	}
+	tc.createdResources = append(tc.createdResources, resource{Type: "experiment", ID: resp.ExperimentID, Name: name})
+	_, tc.lastError = tc.client.StartChildRun(tc.runID, mlflow.CreateRunRequest{ExperimentID: resp.ExperimentID})
+	return nil
+}
+
+func (tc *testContext) childRunID(name string) (string, error) {
+	runID, ok := tc.childRunIDs[name]
+	if !ok {
+		return "", fmt.Errorf("no child run named %q", name)
+	}
+	return runID, nil
+}
+
+func (tc *testContext) startNamedChildRun(name string) error {
+	return tc.startChildRun(name, tc.runID)
+}
+
+func (tc *testContext) runHasChildRuns(count int) error {
+	for i := 1; i <= count; i++ {
+		if err := tc.startChildRun(fmt.Sprintf("child-%d", i), tc.runID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) childRunHasChildRuns(parent string, count int) error {
+	parentID, err := tc.childRunID(parent)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= count; i++ {
+		if err := tc.startChildRun(fmt.Sprintf("%s-%d", parent, i), parentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) childRunHasEnded(name, status string) error {
+	runID, err := tc.childRunID(name)
+	if err != nil {
+		return err
+	}
+	_, err = tc.client.UpdateRun(mlflow.UpdateRunRequest{RunID: runID, Status: mlflow.RunStatus(status)})
+	return err
+}
+
+func (tc *testContext) childRunNestedUnderRun(name string) error {
+	runID, err := tc.childRunID(name)
+	if err != nil {
+		return err
+	}
+	resp, err := tc.client.GetRun(runID)
+	if err != nil {
+		return err
+	}
+	if parent, ok := resp.Run.ParentRunID(); !ok || parent != tc.runID {
+		return fmt.Errorf("expected %s to be nested under %s, got parent %q", name, tc.runID, parent)
+	}
+	return nil
+}
+
+func (tc *testContext) childRunInExperiment(name string) error {
+	runID, err := tc.childRunID(name)
+	if err != nil {
+		return err
+	}
+	resp, err := tc.client.GetRun(runID)
+	if err != nil {
+		return err
+	}
+	if resp.Run.Info.ExperimentID != tc.experimentID {
+		return fmt.Errorf("expected %s to be in experiment %s, got %s", name, tc.experimentID, resp.Run.Info.ExperimentID)
+	}
+	return nil
+}
+
+func (tc *testContext) getRunTree() error {
+	tree, err := tc.client.GetRunTree(tc.runID)
+	if err != nil {
+		return err
+	}
+	tc.runTree = tree
+	return nil
+}
+
+func (tc *testContext) runTreeHasRuns(count int) error {
+	if tc.runTree.Len() != count {
+		return fmt.Errorf("expected %d runs in the tree, got %d", count, tc.runTree.Len())
+	}
+	return nil
+}
+
+// runTreeName is the name a run tree step uses for a run: root for the run
+// the tree was retrieved for and the run name for its descendants
+func (tc *testContext) runTreeName(run mlflow.Run) string {
+	if run.Info.RunID == tc.runID {
+		return "root"
+	}
+	return run.Info.RunName
+}
+
+func (tc *testContext) runTreeShouldBe(table *godog.Table) error {
+	var got [][2]string
+	_ = tc.runTree.Walk(func(node *mlflow.RunTree, depth int) error {
+		got = append(got, [2]string{strconv.Itoa(depth), tc.runTreeName(node.Run)})
+		return nil
+	})
+	rows := table.Rows[1:]
+	if len(rows) != len(got) {
+		return fmt.Errorf("expected %d runs in the tree, got %v", len(rows), got)
+	}
+	for i, row := range rows {
+		want := [2]string{row.Cells[0].Value, row.Cells[1].Value}
+		if got[i] != want {
+			return fmt.Errorf("expected run %d to be %v, got %v", i, want, got[i])
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) runTreeStatusShouldBe(status string) error {
+	if got := tc.runTree.Status(); got != mlflow.RunStatus(status) {
+		return fmt.Errorf("expected the run tree status to be %s, got %s", status, got)
+	}
+	return nil
+}
+
+func (tc *testContext) endRunTree(status string) error {
+	return tc.client.EndRunTree(tc.runID, mlflow.RunStatus(status))
+}
+
+func (tc *testContext) endRunTreeUnder(name, status string) error {
+	runID, err := tc.childRunID(name)
+	if err != nil {
+		return err
+	}
+	return tc.client.EndRunTree(runID, mlflow.RunStatus(status))
+}
+
+func (tc *testContext) attemptEndRunTree(status string) error {
+	tc.lastError = tc.client.EndRunTree(tc.runID, mlflow.RunStatus(status))
+	return nil
+}
+
+func (tc *testContext) everyRunInTreeHasStatus(status string) error {
+	return tc.runTree.Walk(func(node *mlflow.RunTree, _ int) error {
+		if node.Run.Info.Status != mlflow.RunStatus(status) {
+			return fmt.Errorf("expected %s to have status %s, got %s", tc.runTreeName(node.Run), status, node.Run.Info.Status)
+		}
+		if node.Run.Info.EndTime == 0 {
+			return fmt.Errorf("expected %s to have an end time", tc.runTreeName(node.Run))
+		}
+		return nil
+	})
+}
+
+func (tc *testContext) childRunInTreeHasStatus(name, status string) error {
+	runID, err := tc.childRunID(name)
+	if err != nil {
+		return err
+	}
+	node := tc.runTree.Find(runID)
+	if node == nil {
+		return fmt.Errorf("%s is not in the run tree", name)
+	}
+	if node.Run.Info.Status != mlflow.RunStatus(status) {
+		return fmt.Errorf("expected %s to have status %s, got %s", name, status, node.Run.Info.Status)
+	}
+	return nil
+}
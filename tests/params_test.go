@@ -0,0 +1,121 @@
+package features
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cucumber/godog"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Struct params step implementations
+
+type optimizerConfig struct {
+	Name  string    `mlflow:"name"`
+	LR    float64   `mlflow:"lr"`
+	Betas []float64 `mlflow:"betas,omitempty"`
+}
+
+type layerConfig struct {
+	Units      int      `mlflow:"units"`
+	Activation string   `mlflow:"activation"`
+	Dropout    *float64 `mlflow:"dropout,omitempty"`
+}
+
+type trainingConfig struct {
+	Seed      int64             `mlflow:"seed"`
+	Epochs    int               `mlflow:"epochs"`
+	Timeout   time.Duration     `mlflow:"timeout"`
+	Optimizer optimizerConfig   `mlflow:"optimizer"`
+	Layers    []layerConfig     `mlflow:"layers"`
+	Labels    map[string]string `mlflow:"labels,omitempty"`
+	Notes     string            `mlflow:"notes,omitempty"`
+	APIKey    string            `mlflow:"-"`
+}
+
+func (tc *testContext) trainingConfigWith(lr float64, layers int) error {
+	cfg := &trainingConfig{
+		Seed:    42,
+		Epochs:  10,
+		Timeout: 90 * time.Minute,
+		Optimizer: optimizerConfig{
+			Name:  "adam",
+			LR:    lr,
+			Betas: []float64{0.9, 0.999},
+		},
+		APIKey: "not-logged",
+	}
+	for i := 0; i < layers; i++ {
+		cfg.Layers = append(cfg.Layers, layerConfig{Units: 64 >> i, Activation: "relu"})
+	}
+	tc.trainingConfig = cfg
+	return nil
+}
+
+func (tc *testContext) trainingConfigHasLabels(count int) error {
+	tc.trainingConfig.Labels = map[string]string{}
+	for i := 0; i < count; i++ {
+		tc.trainingConfig.Labels[fmt.Sprintf("label-%03d", i)] = fmt.Sprintf("value %d", i)
+	}
+	return nil
+}
+
+func (tc *testContext) lastLayerHasDropout(dropout float64) error {
+	tc.trainingConfig.Layers[len(tc.trainingConfig.Layers)-1].Dropout = &dropout
+	return nil
+}
+
+func (tc *testContext) flattenTrainingConfig() error {
+	tc.flattenedParams, tc.lastError = mlflow.FlattenParams(tc.trainingConfig)
+	return tc.lastError
+}
+
+func (tc *testContext) flattenedParamsShouldBe(table *godog.Table) error {
+	var want []string
+	for _, row := range table.Rows[1:] {
+		want = append(want, row.Cells[0].Value+"="+row.Cells[1].Value)
+	}
+	var got []string
+	for _, param := range tc.flattenedParams {
+		got = append(got, param.Key+"="+param.Value)
+	}
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("expected params\n  %s\ngot\n  %s", strings.Join(want, "\n  "), strings.Join(got, "\n  "))
+	}
+	return nil
+}
+
+func (tc *testContext) logTrainingConfig() error {
+	return tc.client.LogParamsFromStruct(tc.runID, tc.trainingConfig)
+}
+
+func (tc *testContext) loadRunParamsIntoTrainingConfig() error {
+	resp, err := tc.client.GetRun(tc.runID)
+	if err != nil {
+		return err
+	}
+	tc.loadedConfig = &trainingConfig{}
+	return mlflow.LoadParamsInto(&resp.Run, tc.loadedConfig)
+}
+
+func (tc *testContext) loadedConfigEqualsLogged() error {
+	want := *tc.trainingConfig
+	want.APIKey = ""
+	if !reflect.DeepEqual(*tc.loadedConfig, want) {
+		return fmt.Errorf("expected %+v, got %+v", want, *tc.loadedConfig)
+	}
+	return nil
+}
+
+func (tc *testContext) runWithParam(key, value string) error {
+	tc.offlineRun = &mlflow.Run{Data: mlflow.RunData{Params: []mlflow.Param{{Key: key, Value: value}}}}
+	return nil
+}
+
+func (tc *testContext) attemptLoadOfflineRun() error {
+	tc.loadedConfig = &trainingConfig{}
+	tc.lastError = mlflow.LoadParamsInto(tc.offlineRun, tc.loadedConfig)
+	return nil
+}
@@ -0,0 +1,165 @@
+package features
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Policy step implementations
+
+func (tc *testContext) addPolicyRule(rule mlflow.PolicyRule) error {
+	tc.policyRules = append(tc.policyRules, rule)
+	engine, err := mlflow.NewPolicyEngine(tc.policyRules...)
+	if err != nil {
+		return err
+	}
+	tc.policy = engine
+	return nil
+}
+
+func (tc *testContext) policyRuleForNames(name, operation, pattern string) error {
+	return tc.addPolicyRule(mlflow.PolicyRule{
+		Name:        name,
+		Operations:  []mlflow.PolicyOperation{mlflow.PolicyOperation(operation)},
+		NamePattern: pattern,
+	})
+}
+
+func (tc *testContext) policyRuleForTagWithOverride(name, operation, key, value, token string) error {
+	return tc.addPolicyRule(mlflow.PolicyRule{
+		Name:          name,
+		Operations:    []mlflow.PolicyOperation{mlflow.PolicyOperation(operation)},
+		Tags:          map[string]string{key: value},
+		OverrideToken: token,
+	})
+}
+
+func (tc *testContext) policyRuleForAlias(name, operation, alias string) error {
+	return tc.addPolicyRule(mlflow.PolicyRule{
+		Name:       name,
+		Operations: []mlflow.PolicyOperation{mlflow.PolicyOperation(operation)},
+		Aliases:    []string{alias},
+	})
+}
+
+func (tc *testContext) policyRuleForStage(name, operation, stage string) error {
+	return tc.addPolicyRule(mlflow.PolicyRule{
+		Name:       name,
+		Operations: []mlflow.PolicyOperation{mlflow.PolicyOperation(operation)},
+		Stages:     []string{stage},
+	})
+}
+
+func (tc *testContext) policyInstalled() error {
+	if tc.policy == nil {
+		return fmt.Errorf("no policy rules defined")
+	}
+	tc.client.SetPolicy(tc.policy)
+	return nil
+}
+
+func expectPolicyError(err error, rule string) error {
+	policyErr, ok := mlflow.IsPolicyError(err)
+	if !ok {
+		return fmt.Errorf("expected a policy error from rule %s, got %v", rule, err)
+	}
+	if policyErr.Rule != rule {
+		return fmt.Errorf("expected rule %s, got %s", rule, policyErr.Rule)
+	}
+	return nil
+}
+
+func (tc *testContext) experimentOperationBlocked(operation, name, rule string) error {
+	resource := mlflow.PolicyResource{Entity: mlflow.AuditEntityExperiment, Name: name}
+	return expectPolicyError(tc.policy.Evaluate(mlflow.PolicyOperation(operation), resource, ""), rule)
+}
+
+func (tc *testContext) experimentOperationAllowed(operation, name string) error {
+	resource := mlflow.PolicyResource{Entity: mlflow.AuditEntityExperiment, Name: name}
+	return tc.policy.Evaluate(mlflow.PolicyOperation(operation), resource, "")
+}
+
+func taggedRun(key, value string) mlflow.PolicyResource {
+	return mlflow.PolicyResource{Entity: mlflow.AuditEntityRun, ID: "run-1", Tags: map[string]string{key: value}}
+}
+
+func (tc *testContext) taggedRunOperationBlocked(operation, key, value, rule string) error {
+	return expectPolicyError(tc.policy.Evaluate(mlflow.PolicyOperation(operation), taggedRun(key, value), ""), rule)
+}
+
+func (tc *testContext) taggedRunOperationWithTokenBlocked(operation, key, value, token, rule string) error {
+	return expectPolicyError(tc.policy.Evaluate(mlflow.PolicyOperation(operation), taggedRun(key, value), token), rule)
+}
+
+func (tc *testContext) taggedRunOperationWithTokenAllowed(operation, key, value, token string) error {
+	return tc.policy.Evaluate(mlflow.PolicyOperation(operation), taggedRun(key, value), token)
+}
+
+func (tc *testContext) registeredModelUniqueNameExists() error {
+	return tc.createRegisteredModel(fmt.Sprintf("test-model-%s", uuid.New().String()))
+}
+
+func (tc *testContext) attemptDeleteRegisteredModel() error {
+	tc.lastError = tc.client.DeleteRegisteredModel(tc.modelName)
+	return nil
+}
+
+func (tc *testContext) attemptDeleteRegisteredModelWithOverride(token string) error {
+	tc.lastError = tc.client.WithPolicyOverride(token).DeleteRegisteredModel(tc.modelName)
+	return nil
+}
+
+func (tc *testContext) attemptDeleteModelVersion() error {
+	tc.lastError = tc.client.DeleteModelVersion(tc.modelName, tc.modelVersion)
+	return nil
+}
+
+func (tc *testContext) attemptTransitionModelVersion(stage string) error {
+	_, tc.lastError = tc.client.TransitionModelVersionStage(tc.modelName, tc.modelVersion, mlflow.ModelStage(stage), "")
+	return nil
+}
+
+func (tc *testContext) attemptTransitionModelVersionArchivingExisting(stage string) error {
+	_, tc.lastError = tc.client.TransitionModelVersionStage(tc.modelName, tc.modelVersion, mlflow.ModelStage(stage), "true")
+	return nil
+}
+
+func (tc *testContext) attemptDeleteRunsCreatedUntilNow() error {
+	_, tc.lastError = tc.client.DeleteRuns(mlflow.DeleteRunsRequest{ExperimentID: tc.experimentID, MaxTimestampMillis: time.Now().UnixMilli()})
+	return nil
+}
+
+func (tc *testContext) attemptDeleteTraces() error {
+	_, tc.lastError = tc.client.DeleteTraces(mlflow.DeleteTracesRequest{ExperimentID: tc.experimentID, MaxTimestampMillis: time.Now().UnixMilli()})
+	return nil
+}
+
+func (tc *testContext) runStillActive() error {
+	resp, err := tc.client.GetRun(tc.runID)
+	if err != nil {
+		return err
+	}
+	if resp.Run.Info.LifecycleStage != "active" {
+		return fmt.Errorf("expected the run to be active, got %q", resp.Run.Info.LifecycleStage)
+	}
+	return nil
+}
+
+func (tc *testContext) callFailsWithPolicyError(rule string) error {
+	return expectPolicyError(tc.lastError, rule)
+}
+
+func (tc *testContext) callSucceeds() error {
+	if tc.lastError != nil {
+		return fmt.Errorf("expected the call to succeed, got %w", tc.lastError)
+	}
+	return nil
+}
+
+func (tc *testContext) registeredModelStillExists() error {
+	_, err := tc.client.GetRegisteredModel(tc.modelName)
+	return err
+}
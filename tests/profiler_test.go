@@ -0,0 +1,155 @@
+package features
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Profiler step implementations
+
+// burnCPU keeps a CPU busy for d so that a CPU profile has samples in it
+//
+//go:noinline
+func burnCPU(d time.Duration) float64 {
+	x := 0.0
+	for deadline := time.Now().Add(d); time.Now().Before(deadline); {
+		for i := 0; i < 10000; i++ {
+			x += math.Sqrt(float64(i))
+		}
+	}
+	return x
+}
+
+func (tc *testContext) captureCPUProfileWhileBurning(ms int) error {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return err
+	}
+	burnCPU(time.Duration(ms) * time.Millisecond)
+	pprof.StopCPUProfile()
+	tc.profileData = buf.Bytes()
+	return nil
+}
+
+func (tc *testContext) summarizeProfile(sampleType string, top int) error {
+	tc.profileSummary, tc.lastError = mlflow.SummarizeProfile(tc.profileData, sampleType, top)
+	return nil
+}
+
+func (tc *testContext) profileSummaryHas(sampleType, unit string) error {
+	if tc.lastError != nil {
+		return tc.lastError
+	}
+	s := tc.profileSummary
+	if s.SampleType != sampleType || s.Unit != unit || s.Total <= 0 {
+		return fmt.Errorf("expected a positive total of %s in %s, got %d of %s in %s", sampleType, unit, s.Total, s.SampleType, s.Unit)
+	}
+	return nil
+}
+
+func (tc *testContext) topFunctionShouldBe(name string) error {
+	top := tc.profileSummary.Top
+	if len(top) == 0 || !strings.HasSuffix(top[0].Function, name) {
+		return fmt.Errorf("expected the top function to be %s, got %v", name, top)
+	}
+	if top[0].Fraction <= 0 || top[0].Fraction > 1 {
+		return fmt.Errorf("expected a fraction in (0, 1], got %v", top[0].Fraction)
+	}
+	return nil
+}
+
+func (tc *testContext) summaryHasAtMostTop(count int) error {
+	if len(tc.profileSummary.Top) > count {
+		return fmt.Errorf("expected at most %d top functions, got %d", count, len(tc.profileSummary.Top))
+	}
+	return nil
+}
+
+func (tc *testContext) startProfilerOnActiveRun(interval, window int) error {
+	return tc.activeRun.StartProfiler(mlflow.ProfilerOptions{
+		Interval:  time.Duration(interval) * time.Millisecond,
+		CPUWindow: time.Duration(window) * time.Millisecond,
+	})
+}
+
+func (tc *testContext) jobBurnsCPU(ms int) error {
+	burnCPU(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+func (tc *testContext) activeRunHasArtifactsUnder(count int, dir string) error {
+	resp, err := tc.client.ListArtifacts(tc.activeRun.RunID, dir, "")
+	if err != nil {
+		return err
+	}
+	var profiles int
+	for _, file := range resp.Files {
+		if strings.HasPrefix(file.Path, dir+"/step-") && strings.HasSuffix(file.Path, ".pb.gz") && file.FileSize > 0 {
+			profiles++
+		}
+	}
+	if profiles < count {
+		return fmt.Errorf("expected at least %d profiles under %s, got %v", count, dir, resp.Files)
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunHasTagContaining(key, text string) error {
+	run, err := tc.activeRun.Run()
+	if err != nil {
+		return err
+	}
+	if value, ok := run.Tag(key); !ok || !strings.Contains(value, text) {
+		return fmt.Errorf("expected tag %s to contain %q, got %q (present: %v)", key, text, value, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) attemptStartProfiler(interval, window int, snapshot string) error {
+	opts := mlflow.ProfilerOptions{
+		Interval:  time.Duration(interval) * time.Millisecond,
+		CPUWindow: time.Duration(window) * time.Millisecond,
+	}
+	if snapshot != "" {
+		opts.Snapshots = []string{snapshot}
+	}
+	_, tc.lastError = tc.client.StartProfiler("run-1", opts)
+	return nil
+}
+
+func (tc *testContext) runProfiler(runID string, ms int) error {
+	tc.monitorErrors = nil
+	profiler, err := tc.client.StartProfiler(runID, mlflow.ProfilerOptions{
+		Interval:  20 * time.Millisecond,
+		CPUWindow: -1,
+		Snapshots: []string{"goroutine"},
+		OnError:   func(err error) { tc.monitorErrors = append(tc.monitorErrors, err) },
+	})
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	tc.lastError = profiler.Stop()
+	return nil
+}
+
+// activeRunLoggedPositiveMetric checks every step of a metric, as the last
+// CPU window may end before the job used any CPU
+func (tc *testContext) activeRunLoggedPositiveMetric(key string) error {
+	resp, err := tc.client.GetMetricHistory(mlflow.GetMetricHistoryRequest{RunID: tc.activeRun.RunID, MetricKey: key, MaxResults: 100})
+	if err != nil {
+		return err
+	}
+	for _, m := range resp.Metrics {
+		if m.Value > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a positive %s at some step, got %v", key, resp.Metrics)
+}
@@ -40,7 +40,7 @@ func (tc *testContext) runHasStatus(status string) error {
 	if err != nil {
 		return err
 	}
-	if run.Run.Info.Status != status {
+	if run.Run.Info.Status != mlflow.RunStatus(status) {
 		return fmt.Errorf("expected status %s, got %s", status, run.Run.Info.Status)
 	}
 	return nil
@@ -218,7 +218,7 @@ func (tc *testContext) updateRunStatus(status string) error {
 	}
 	req := mlflow.UpdateRunRequest{
 		RunID:   tc.runID,
-		Status:  status,
+		Status:  mlflow.RunStatus(status),
 		EndTime: time.Now().UnixMilli(),
 	}
 	_, err := tc.client.UpdateRun(req)
@@ -230,7 +230,7 @@ func (tc *testContext) runStatusShouldBe(status string) error {
 	if err != nil {
 		return err
 	}
-	if run.Run.Info.Status != status {
+	if run.Run.Info.Status != mlflow.RunStatus(status) {
 		return fmt.Errorf("expected status %s, got %s", status, run.Run.Info.Status)
 	}
 	return nil
@@ -0,0 +1,865 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+	"github.com/google/uuid"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/s3"
+)
+
+// S3 step implementations
+
+// s3StubBucket is the only bucket of the S3 stub
+const s3StubBucket = "mlflow"
+
+// s3StubListPage is how many entries the stub lists per page, small so
+// that listings take several pages
+const s3StubListPage = 3
+
+// s3StubAccessKey signs requests of repositories with static credentials.
+// The stub accepts any access key whose secret is "secret-" followed by the
+// key.
+const s3StubAccessKey = "AKIDSTATIC"
+
+// s3Environment are the variables that configure S3 access, cleared for
+// scenarios against the S3 stub
+var s3Environment = []string{
+	"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	"AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_CONFIG_FILE",
+	"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME", "AWS_ENDPOINT_URL_STS",
+	"AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+	"AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+	"AWS_EC2_METADATA_DISABLED", "AWS_EC2_METADATA_SERVICE_ENDPOINT",
+	"AWS_REGION", "AWS_DEFAULT_REGION",
+	"MLFLOW_S3_ENDPOINT_URL", "MLFLOW_S3_IGNORE_TLS", "MLFLOW_S3_UPLOAD_EXTRA_ARGS",
+}
+
+type s3StubObject struct {
+	data   []byte
+	etag   string
+	header http.Header
+}
+
+// s3StubUpload is an open multipart upload
+type s3StubUpload struct {
+	key    string
+	header http.Header
+	parts  map[int][]byte
+}
+
+// s3Stub is an S3-compatible store with one bucket, path-style addressing
+// and the endpoints AWS credentials are resolved from. It checks the
+// signature and payload hash of every request.
+type s3Stub struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	objects  map[string]*s3StubObject
+	uploads  map[string]*s3StubUpload
+	nextID   int
+	aborted  int
+	parts    int
+	ranged   int
+	puts     int
+	failures map[int]int
+	status   int
+	attempts map[int]int
+	// accessKey, token and region are those of the last signed request
+	accessKey string
+	token     string
+	region    string
+}
+
+func newS3Stub(useTLS bool) *s3Stub {
+	s := &s3Stub{
+		objects:  map[string]*s3StubObject{},
+		uploads:  map[string]*s3StubUpload{},
+		failures: map[int]int{},
+		attempts: map[int]int{},
+	}
+	if useTLS {
+		s.server = httptest.NewTLSServer(http.HandlerFunc(s.serve))
+	} else {
+		s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	}
+	return s
+}
+
+func s3StubError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: message})
+}
+
+func writeS3StubXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func (s *s3Stub) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/sts":
+		s.serveSTS(w, r)
+		return
+	case "/container-credentials":
+		s.serveContainerCredentials(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/latest/") {
+		s.serveInstanceMetadata(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if code, msg := s.authenticate(r, body); code != "" {
+		s3StubError(w, http.StatusForbidden, code, msg)
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s3StubBucket {
+		s3StubError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case key == "" && r.Method == http.MethodGet && query.Get("list-type") == "2":
+		s.list(w, query)
+	case key == "" && r.Method == http.MethodPost && query.Has("delete"):
+		s.deleteObjects(w, r, body)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.nextID++
+		id := fmt.Sprintf("upload-%d", s.nextID)
+		s.uploads[id] = &s3StubUpload{key: key, header: sseHeaders(r.Header), parts: map[int][]byte{}}
+		writeS3StubXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string   `xml:"Bucket"`
+			Key      string   `xml:"Key"`
+			UploadID string   `xml:"UploadId"`
+		}{Bucket: bucket, Key: key, UploadID: id})
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		s.uploadPart(w, r, query, body)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeUpload(w, key, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		if _, ok := s.uploads[query.Get("uploadId")]; !ok {
+			s3StubError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+			return
+		}
+		delete(s.uploads, query.Get("uploadId"))
+		s.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.puts++
+		sum := md5.Sum(body)
+		s.objects[key] = &s3StubObject{data: body, etag: `"` + hex.EncodeToString(sum[:]) + `"`, header: sseHeaders(r.Header)}
+		w.Header().Set("ETag", s.objects[key].etag)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		s.getObject(w, r, key)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3StubError(w, http.StatusNotImplemented, "NotImplemented", r.Method+" "+r.URL.String())
+	}
+}
+
+// authenticate checks the signature of a request, returning the error code
+// if it is wrong
+func (s *s3Stub) authenticate(r *http.Request, body []byte) (string, string) {
+	auth := r.Header.Get("Authorization")
+	credential, rest, _ := strings.Cut(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 Credential="), ", SignedHeaders=")
+	signedHeaders, signature, _ := strings.Cut(rest, ", Signature=")
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 || scope[3] != "s3" || scope[4] != "aws4_request" {
+		return "AccessDenied", "missing or malformed Authorization header: " + auth
+	}
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		return "XAmzContentSHA256Mismatch", "the payload hash does not match the body"
+	}
+	signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil || signedAt.Format("20060102") != scope[1] {
+		return "AccessDenied", "bad X-Amz-Date"
+	}
+
+	// Sign the request as received and compare
+	check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	for _, name := range strings.Split(signedHeaders, ";") {
+		if name != "host" {
+			check.Header[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+		}
+	}
+	creds := s3.Credentials{AccessKeyID: scope[0], SecretAccessKey: "secret-" + scope[0], SessionToken: r.Header.Get("X-Amz-Security-Token")}
+	s3.Sign(check, creds, scope[2], "s3", r.Header.Get("X-Amz-Content-Sha256"), signedAt)
+	if !strings.HasSuffix(check.Header.Get("Authorization"), "Signature="+signature) {
+		return "SignatureDoesNotMatch", "the request signature does not match"
+	}
+	s.accessKey, s.token, s.region = scope[0], creds.SessionToken, scope[2]
+	return "", ""
+}
+
+// sseHeaders returns the encryption headers of an upload, with the
+// customer key replaced by its MD5
+func sseHeaders(header http.Header) http.Header {
+	kept := http.Header{}
+	for name, values := range header {
+		if strings.HasPrefix(name, "X-Amz-Server-Side-Encryption") && name != "X-Amz-Server-Side-Encryption-Customer-Key" {
+			kept[name] = values
+		}
+	}
+	return kept
+}
+
+// customerKeyMatches reports whether a request has the SSE-C key of an
+// object or upload, if it has one
+func customerKeyMatches(stored http.Header, r *http.Request) bool {
+	const md5Header = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+	if stored.Get(md5Header) == "" {
+		return true
+	}
+	key, _ := base64.StdEncoding.DecodeString(r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+	sum := md5.Sum(key)
+	return base64.StdEncoding.EncodeToString(sum[:]) == stored.Get(md5Header)
+}
+
+func (s *s3Stub) list(w http.ResponseWriter, query map[string][]string) {
+	get := func(name string) string {
+		if values := query[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	prefix, delimiter, after := get("prefix"), get("delimiter"), get("continuation-token")
+	limit := s3StubListPage
+	if maxKeys, err := strconv.Atoi(get("max-keys")); err == nil && maxKeys < limit {
+		limit = maxKeys
+	}
+
+	// Entries are keys, or common prefixes up to the delimiter
+	entries := map[string]bool{}
+	for key := range s.objects {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			entries[prefix+rest[:i+len(delimiter)]] = true
+		} else {
+			entries[key] = false
+		}
+	}
+	sorted := make([]string, 0, len(entries))
+	for entry := range entries {
+		if entry > after {
+			sorted = append(sorted, entry)
+		}
+	}
+	sort.Strings(sorted)
+
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int    `xml:"Size"`
+		ETag string `xml:"ETag"`
+	}
+	type commonPrefix struct {
+		Prefix string `xml:"Prefix"`
+	}
+	result := struct {
+		XMLName               xml.Name       `xml:"ListBucketResult"`
+		Prefix                string         `xml:"Prefix"`
+		KeyCount              int            `xml:"KeyCount"`
+		IsTruncated           bool           `xml:"IsTruncated"`
+		NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+		Contents              []content      `xml:"Contents"`
+		CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+	}{Prefix: prefix}
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+		result.IsTruncated = true
+		result.NextContinuationToken = sorted[limit-1]
+	}
+	for _, entry := range sorted {
+		if entries[entry] {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: entry})
+		} else {
+			object := s.objects[entry]
+			result.Contents = append(result.Contents, content{Key: entry, Size: len(object.data), ETag: object.etag})
+		}
+	}
+	result.KeyCount = len(sorted)
+	writeS3StubXML(w, result)
+}
+
+func (s *s3Stub) deleteObjects(w http.ResponseWriter, r *http.Request, body []byte) {
+	sum := md5.Sum(body)
+	if r.Header.Get("Content-Md5") != base64.StdEncoding.EncodeToString(sum[:]) {
+		s3StubError(w, http.StatusBadRequest, "InvalidDigest", "Content-MD5 does not match the body")
+		return
+	}
+	var req struct {
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	if err := xml.Unmarshal(body, &req); err != nil {
+		s3StubError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	for _, object := range req.Objects {
+		delete(s.objects, object.Key)
+	}
+	writeS3StubXML(w, struct {
+		XMLName xml.Name `xml:"DeleteResult"`
+	}{})
+}
+
+func (s *s3Stub) uploadPart(w http.ResponseWriter, r *http.Request, query map[string][]string, body []byte) {
+	number, _ := strconv.Atoi(query["partNumber"][0])
+	s.attempts[number]++
+	if s.failures[number] != 0 {
+		if s.failures[number] > 0 {
+			s.failures[number]--
+		}
+		s3StubError(w, s.status, http.StatusText(s.status), "injected failure")
+		return
+	}
+	upload, ok := s.uploads[query["uploadId"][0]]
+	if !ok {
+		s3StubError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+	if !customerKeyMatches(upload.header, r) {
+		s3StubError(w, http.StatusBadRequest, "InvalidRequest", "The SSE-C key does not match the upload's")
+		return
+	}
+	s.parts++
+	upload.parts[number] = body
+	sum := md5.Sum(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+}
+
+func (s *s3Stub) completeUpload(w http.ResponseWriter, key, uploadID string, body []byte) {
+	upload, ok := s.uploads[uploadID]
+	if !ok || upload.key != key {
+		s3StubError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+	var req struct {
+		Parts []struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Parts) == 0 {
+		s3StubError(w, http.StatusBadRequest, "MalformedXML", "no parts")
+		return
+	}
+	var data []byte
+	for i, part := range req.Parts {
+		partData, ok := upload.parts[part.PartNumber]
+		sum := md5.Sum(partData)
+		if part.PartNumber != i+1 || !ok || part.ETag != `"`+hex.EncodeToString(sum[:])+`"` {
+			// S3 reports this after the 200 status
+			writeS3StubXML(w, struct {
+				XMLName xml.Name `xml:"Error"`
+				Code    string   `xml:"Code"`
+			}{Code: "InvalidPart"})
+			return
+		}
+		data = append(data, partData...)
+	}
+	delete(s.uploads, uploadID)
+	etag := fmt.Sprintf(`"%x-%d"`, md5.Sum(data), len(req.Parts))
+	s.objects[key] = &s3StubObject{data: data, etag: etag, header: upload.header}
+	writeS3StubXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Key     string   `xml:"Key"`
+		ETag    string   `xml:"ETag"`
+	}{Key: key, ETag: etag})
+}
+
+func (s *s3Stub) getObject(w http.ResponseWriter, r *http.Request, key string) {
+	object, ok := s.objects[key]
+	if !ok {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s3StubError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	if !customerKeyMatches(object.header, r) {
+		s3StubError(w, http.StatusBadRequest, "InvalidRequest", "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.")
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && match != object.etag {
+		s3StubError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+		return
+	}
+	w.Header().Set("ETag", object.etag)
+	for name, values := range object.header {
+		w.Header()[name] = values
+	}
+	data := object.data
+	status := http.StatusOK
+	if ranges := r.Header.Get("Range"); ranges != "" && r.Method == http.MethodGet {
+		s.ranged++
+		var start, end int
+		if _, err := fmt.Sscanf(ranges, "bytes=%d-%d", &start, &end); err != nil || start >= len(data) {
+			s3StubError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable")
+			return
+		}
+		end = min(end, len(data)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data = data[start : end+1]
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+// stubExpiration is the expiry of the temporary credentials the stub
+// issues, close enough that they are never cached between scenarios
+func stubExpiration() string {
+	return time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+}
+
+func (s *s3Stub) serveSTS(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("Action") != "AssumeRoleWithWebIdentity" || r.FormValue("WebIdentityToken") != "web-identity-token" || r.FormValue("RoleArn") == "" {
+		s3StubError(w, http.StatusForbidden, "AccessDenied", "bad web identity request")
+		return
+	}
+	fmt.Fprintf(w, `<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>
+<AccessKeyId>AKIDWEB</AccessKeyId><SecretAccessKey>secret-AKIDWEB</SecretAccessKey>
+<SessionToken>web-session</SessionToken><Expiration>%s</Expiration>
+</Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`, stubExpiration())
+}
+
+func (s *s3Stub) serveContainerCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "container-auth-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeStubJSON(w, map[string]string{
+		"AccessKeyId": "AKIDCONTAINER", "SecretAccessKey": "secret-AKIDCONTAINER",
+		"Token": "container-session", "Expiration": stubExpiration(),
+	})
+}
+
+func (s *s3Stub) serveInstanceMetadata(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/latest/api/token" && r.Method == http.MethodPut:
+		_, _ = io.WriteString(w, "imds-session-token")
+	case r.Header.Get("X-Aws-Ec2-Metadata-Token") != "imds-session-token":
+		w.WriteHeader(http.StatusUnauthorized)
+	case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+		_, _ = io.WriteString(w, "trainer-role")
+	case r.URL.Path == "/latest/meta-data/iam/security-credentials/trainer-role":
+		writeStubJSON(w, map[string]string{
+			"Code": "Success", "AccessKeyId": "AKIDINSTANCE", "SecretAccessKey": "secret-AKIDINSTANCE",
+			"Token": "instance-session", "Expiration": stubExpiration(),
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (tc *testContext) startS3Stub(useTLS bool) error {
+	tc.s3Stub = newS3Stub(useTLS)
+	dir, err := tc.newTempDir("mlflow-aws-")
+	if err != nil {
+		return err
+	}
+	for _, name := range s3Environment {
+		tc.setEnv(name, "", true)
+	}
+	// Keep the shared files and instance metadata of the machine out of it
+	tc.setEnv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"), false)
+	tc.setEnv("AWS_CONFIG_FILE", filepath.Join(dir, "config"), false)
+	tc.setEnv("AWS_EC2_METADATA_DISABLED", "true", false)
+	tc.setEnv("AWS_REGION", "eu-west-2", false)
+	tc.setEnv("MLFLOW_S3_ENDPOINT_URL", tc.s3Stub.server.URL, false)
+	return nil
+}
+
+func (tc *testContext) s3Store() error {
+	return tc.startS3Stub(false)
+}
+
+func (tc *testContext) s3StoreWithSelfSignedCertificate() error {
+	return tc.startS3Stub(true)
+}
+
+// newS3Repository returns a repository of a unique prefix of the stub's
+// bucket with static credentials
+func (tc *testContext) newS3Repository(cfg s3.Config) (*s3.Repository, error) {
+	if tc.s3Stub == nil {
+		if err := tc.s3Store(); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Endpoint = tc.s3Stub.server.URL
+	cfg.Credentials = s3.StaticCredentials{AccessKeyID: s3StubAccessKey, SecretAccessKey: "secret-" + s3StubAccessKey}
+	return s3.New("s3://"+s3StubBucket+"/repos/"+uuid.NewString(), cfg)
+}
+
+// minioRepository returns a repository of a unique prefix of a bucket on
+// the MinIO server at MLFLOW_TEST_S3_ENDPOINT, skipping the scenario if it
+// is not set. Credentials come from the environment as usual.
+func (tc *testContext) minioRepository() (*s3.Repository, error) {
+	endpoint := os.Getenv("MLFLOW_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		debugLog("MLFLOW_TEST_S3_ENDPOINT is not set, skipping the MinIO scenario")
+		return nil, godog.ErrSkip
+	}
+	bucket := os.Getenv("MLFLOW_TEST_S3_BUCKET")
+	if bucket == "" {
+		bucket = "mlflow"
+	}
+	cfg, err := s3.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Endpoint = endpoint
+	// MinIO enforces S3's minimum part size
+	cfg.PartSize = 5 << 20
+	repo, err := s3.New("s3://"+bucket+"/mlflow-go-client-test/"+uuid.NewString(), cfg)
+	tc.minioRepo = repo
+	return repo, err
+}
+
+func (tc *testContext) s3RepositoryWithParts(partSize, concurrency int) error {
+	repo, err := tc.newS3Repository(s3.Config{PartSize: int64(partSize), Concurrency: concurrency})
+	tc.artifactRepo = repo
+	return err
+}
+
+func (tc *testContext) s3RepositoryWithEncryptedParts(partSize, concurrency int, encryption string) error {
+	cfg := s3.Config{PartSize: int64(partSize), Concurrency: concurrency}
+	switch encryption {
+	case "a KMS key":
+		cfg.ServerSideEncryption, cfg.SSEKMSKeyID = s3.EncryptionKMS, "alias/mlflow"
+	case "a customer key":
+		cfg.SSECustomerKey = []byte("0123456789abcdef0123456789abcdef")
+	}
+	repo, err := tc.newS3Repository(cfg)
+	tc.artifactRepo = repo
+	return err
+}
+
+func (tc *testContext) environmentVariableIs(name, value string) error {
+	tc.setEnv(name, value, false)
+	return nil
+}
+
+func (tc *testContext) environmentVariableIsDoc(name string, value *godog.DocString) error {
+	tc.setEnv(name, value.Content, false)
+	return nil
+}
+
+func (tc *testContext) awsCredentialsFrom(source string) error {
+	dir, err := tc.newTempDir("mlflow-aws-credentials-")
+	if err != nil {
+		return err
+	}
+	switch source {
+	case "environment variables":
+		tc.setEnv("AWS_ACCESS_KEY_ID", "AKIDENV", false)
+		tc.setEnv("AWS_SECRET_ACCESS_KEY", "secret-AKIDENV", false)
+	case "environment variables with a session token":
+		tc.setEnv("AWS_ACCESS_KEY_ID", "AKIDENV", false)
+		tc.setEnv("AWS_SECRET_ACCESS_KEY", "secret-AKIDENV", false)
+		tc.setEnv("AWS_SESSION_TOKEN", "env-session", false)
+	case "a shared credentials file profile":
+		tc.setEnv("AWS_PROFILE", "training", false)
+		return os.WriteFile(os.Getenv("AWS_SHARED_CREDENTIALS_FILE"), []byte(
+			"[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = secret-AKIDDEFAULT\n\n"+
+				"# The team's profile\n[training]\naws_access_key_id = AKIDFILE\naws_secret_access_key = secret-AKIDFILE\n"), 0o600)
+	case "a shared config file profile":
+		tc.setEnv("AWS_PROFILE", "training", false)
+		return os.WriteFile(os.Getenv("AWS_CONFIG_FILE"), []byte(
+			"[default]\nregion = us-west-1\n\n"+
+				"[profile training]\nregion = eu-west-2\naws_access_key_id = AKIDCONFIG\naws_secret_access_key = secret-AKIDCONFIG\n"), 0o600)
+	case "a web identity token":
+		tokenFile := filepath.Join(dir, "token")
+		tc.setEnv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile, false)
+		tc.setEnv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/trainer", false)
+		tc.setEnv("AWS_ENDPOINT_URL_STS", tc.s3Stub.server.URL+"/sts", false)
+		return os.WriteFile(tokenFile, []byte("web-identity-token\n"), 0o600)
+	case "the container credentials endpoint":
+		tc.setEnv("AWS_CONTAINER_CREDENTIALS_FULL_URI", tc.s3Stub.server.URL+"/container-credentials", false)
+		tc.setEnv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "container-auth-token", false)
+	case "the instance metadata service":
+		tc.setEnv("AWS_EC2_METADATA_DISABLED", "", true)
+		tc.setEnv("AWS_EC2_METADATA_SERVICE_ENDPOINT", tc.s3Stub.server.URL, false)
+	default:
+		return fmt.Errorf("unknown credential source %q", source)
+	}
+	return nil
+}
+
+// sharedProfileUsing writes a config file profile that sets key, along with
+// what else such a profile needs
+func (tc *testContext) sharedProfileUsing(key string) error {
+	tc.setEnv("AWS_PROFILE", "training", false)
+	settings := map[string]string{
+		"role_arn":           "role_arn = arn:aws:iam::123456789012:role/trainer\nsource_profile = default\n",
+		"credential_process": "credential_process = /usr/local/bin/credentials --profile training\n",
+		"sso_session":        "sso_session = corp\nsso_account_id = 123456789012\nsso_role_name = trainer\n",
+	}
+	setting, ok := settings[key]
+	if !ok {
+		return fmt.Errorf("no profile setting %s", key)
+	}
+	return os.WriteFile(os.Getenv("AWS_CONFIG_FILE"), []byte(
+		"[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = secret-AKIDDEFAULT\n\n"+
+			"[profile training]\nregion = eu-west-2\n"+setting), 0o600)
+}
+
+func (tc *testContext) s3StoreShouldHaveSeen(accessKey, token string) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	if tc.s3Stub.accessKey != accessKey || tc.s3Stub.token != token {
+		return fmt.Errorf("expected access key %q and session token %q, got %q and %q", accessKey, token, tc.s3Stub.accessKey, tc.s3Stub.token)
+	}
+	return nil
+}
+
+func (tc *testContext) s3StoreShouldHaveSeenRegion(region string) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	if tc.s3Stub.region != region {
+		return fmt.Errorf("expected requests signed for region %q, got %q", region, tc.s3Stub.region)
+	}
+	return nil
+}
+
+func (tc *testContext) attemptUploadToRepository(content, artifactPath string) error {
+	tc.lastError = tc.uploadToRepository(content, artifactPath)
+	return nil
+}
+
+// uploadRandomBytes uploads random bytes to the repository, hiding their
+// size if unknown is set
+func (tc *testContext) uploadRandomBytes(size int, artifactPath string, unknown bool) error {
+	tc.s3Data = make([]byte, size)
+	if _, err := rand.Read(tc.s3Data); err != nil {
+		return err
+	}
+	var content io.Reader = bytes.NewReader(tc.s3Data)
+	length := int64(size)
+	if unknown {
+		content, length = io.MultiReader(content), -1
+	}
+	return tc.artifactRepo.Upload(context.Background(), artifactPath, content, length)
+}
+
+func (tc *testContext) uploadRandomBytesToRepository(size int, artifactPath string) error {
+	return tc.uploadRandomBytes(size, artifactPath, false)
+}
+
+func (tc *testContext) uploadRandomBytesOfSize(size int, known, artifactPath string) error {
+	return tc.uploadRandomBytes(size, artifactPath, known == "unknown")
+}
+
+func (tc *testContext) attemptUploadRandomBytes(size int, artifactPath string) error {
+	tc.lastError = tc.uploadRandomBytes(size, artifactPath, false)
+	return nil
+}
+
+func (tc *testContext) repositoryFileShouldDownloadIntact(artifactPath string) error {
+	body, err := tc.artifactRepo.Download(context.Background(), artifactPath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, tc.s3Data) {
+		return fmt.Errorf("expected %s to download the %d bytes uploaded, got %d different bytes", artifactPath, len(tc.s3Data), len(data))
+	}
+	return nil
+}
+
+func (tc *testContext) s3StoreShouldHaveReceived(puts, parts int) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	if tc.s3Stub.puts != puts || tc.s3Stub.parts != parts {
+		return fmt.Errorf("expected %d single uploads and %d parts, got %d and %d", puts, parts, tc.s3Stub.puts, tc.s3Stub.parts)
+	}
+	return nil
+}
+
+func (tc *testContext) s3StoreShouldHaveServedRanges(ranged int) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	if tc.s3Stub.ranged != ranged {
+		return fmt.Errorf("expected %d ranged requests, got %d", ranged, tc.s3Stub.ranged)
+	}
+	return nil
+}
+
+func (tc *testContext) s3PartFails(number, times, status int) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	tc.s3Stub.failures[number] = times
+	tc.s3Stub.status = status
+	return nil
+}
+
+func (tc *testContext) s3PartAlwaysFails(number, status int) error {
+	return tc.s3PartFails(number, -1, status)
+}
+
+func (tc *testContext) s3PartAttempted(number, times int) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	if tc.s3Stub.attempts[number] != times {
+		return fmt.Errorf("expected part %d to be attempted %d times, got %d", number, times, tc.s3Stub.attempts[number])
+	}
+	return nil
+}
+
+func (tc *testContext) s3StoreShouldHaveAborted(aborted int) error {
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	if tc.s3Stub.aborted != aborted || len(tc.s3Stub.uploads) != 0 {
+		return fmt.Errorf("expected %d aborted and no open uploads, got %d aborted and %d open", aborted, tc.s3Stub.aborted, len(tc.s3Stub.uploads))
+	}
+	return nil
+}
+
+// s3Object returns the stub's object of an artifact path of the repository
+func (tc *testContext) s3Object(artifactPath string) (*s3StubObject, error) {
+	repo, ok := tc.artifactRepo.(*s3.Repository)
+	if !ok {
+		return nil, fmt.Errorf("expected an S3 repository, got %T", tc.artifactRepo)
+	}
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	object, ok := tc.s3Stub.objects[repo.Prefix+"/"+artifactPath]
+	if !ok {
+		return nil, fmt.Errorf("the bucket has no object for %s", artifactPath)
+	}
+	return object, nil
+}
+
+func (tc *testContext) s3ObjectShouldHaveHeader(artifactPath, name, value string) error {
+	object, err := tc.s3Object(artifactPath)
+	if err != nil {
+		return err
+	}
+	if got := object.header.Get(name); got != value {
+		return fmt.Errorf("expected %s of %s to be %q, got %q", name, artifactPath, value, got)
+	}
+	return nil
+}
+
+func (tc *testContext) downloadWithoutCustomerKeyFails(artifactPath, message string) error {
+	repo, ok := tc.artifactRepo.(*s3.Repository)
+	if !ok {
+		return fmt.Errorf("expected an S3 repository, got %T", tc.artifactRepo)
+	}
+	plain, err := s3.New("s3://"+repo.Bucket+"/"+repo.Prefix, s3.Config{
+		Endpoint:    tc.s3Stub.server.URL,
+		Credentials: s3.StaticCredentials{AccessKeyID: s3StubAccessKey, SecretAccessKey: "secret-" + s3StubAccessKey},
+	})
+	if err != nil {
+		return err
+	}
+	body, err := plain.Download(context.Background(), artifactPath)
+	if err == nil {
+		body.Close()
+		return fmt.Errorf("expected downloading %s without the customer key to fail", artifactPath)
+	}
+	if !strings.Contains(err.Error(), message) {
+		return fmt.Errorf("expected error containing %q, got %v", message, err)
+	}
+	return nil
+}
+
+func (tc *testContext) s3RepositoryShouldHaveBucketAndPrefix(bucket, prefix string) error {
+	if tc.lastError != nil {
+		return tc.lastError
+	}
+	repo, ok := tc.artifactRepo.(*s3.Repository)
+	if !ok {
+		return fmt.Errorf("expected an S3 repository, got %T", tc.artifactRepo)
+	}
+	if repo.Bucket != bucket || repo.Prefix != prefix {
+		return fmt.Errorf("expected bucket %q and prefix %q, got %q and %q", bucket, prefix, repo.Bucket, repo.Prefix)
+	}
+	return nil
+}
+
+func (tc *testContext) experimentWithS3ArtifactStore() error {
+	tc.s3Prefix = "experiments/" + uuid.NewString()
+	return tc.createExperimentWithArtifactLocation("s3://" + s3StubBucket + "/" + tc.s3Prefix)
+}
+
+func (tc *testContext) s3StoreShouldHaveRunArtifact(artifactPath, content string) error {
+	key := tc.s3Prefix + "/" + tc.runID + "/artifacts/" + artifactPath
+	tc.s3Stub.mu.Lock()
+	defer tc.s3Stub.mu.Unlock()
+	object, ok := tc.s3Stub.objects[key]
+	if !ok {
+		return fmt.Errorf("the bucket has no object %s", key)
+	}
+	if string(object.data) != content {
+		return fmt.Errorf("expected %s to contain %q, got %q", key, content, object.data)
+	}
+	return nil
+}
+
+// signAWSTestRequest signs the get-vanilla request of the AWS Signature
+// Version 4 test suite
+func (tc *testContext) signAWSTestRequest() error {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		return err
+	}
+	signedAt, _ := time.Parse("20060102T150405Z", "20150830T123600Z")
+	creds := s3.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	s3.Sign(req, creds, "us-east-1", "service", s3.EmptyPayloadHash, signedAt)
+	tc.signature = req.Header.Get("Authorization")
+	return nil
+}
+
+func (tc *testContext) authorizationShouldBe(expected string) error {
+	if tc.signature != expected {
+		return fmt.Errorf("expected Authorization %q, got %q", expected, tc.signature)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package features
+
+import (
+	"fmt"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Scrubbing step implementations
+
+func (tc *testContext) scrubberWithAction(action string) error {
+	tc.scrubber = mlflow.NewScrubber(mlflow.ScrubAction(action))
+	tc.scrubber.OnFinding = func(finding mlflow.ScrubFinding) {
+		tc.scrubFindings = append(tc.scrubFindings, finding)
+	}
+	return nil
+}
+
+func (tc *testContext) scrubberInstalled(action string) error {
+	if err := tc.clientConnected(); err != nil {
+		return err
+	}
+	if err := tc.scrubberWithAction(action); err != nil {
+		return err
+	}
+	tc.client.SetScrubber(tc.scrubber)
+	return nil
+}
+
+func (tc *testContext) scrubKeyValue(key, value string) error {
+	tc.scrubbedValue, tc.scrubFinding = tc.scrubber.Scrub("LogParam", key, value)
+	return nil
+}
+
+func (tc *testContext) valueScrubbedBecause(reason string) error {
+	if tc.scrubFinding == nil {
+		return fmt.Errorf("value was not scrubbed")
+	}
+	if tc.scrubFinding.Reason != reason {
+		return fmt.Errorf("expected reason %q, got %q", reason, tc.scrubFinding.Reason)
+	}
+	return nil
+}
+
+func (tc *testContext) scrubbedValueShouldBe(value string) error {
+	if tc.scrubbedValue != value {
+		reason := ""
+		if tc.scrubFinding != nil {
+			reason = tc.scrubFinding.Reason
+		}
+		return fmt.Errorf("expected value %q, got %q (%s)", value, tc.scrubbedValue, reason)
+	}
+	return nil
+}
+
+func (tc *testContext) callFailsWithScrubError(key string) error {
+	scrubErr, ok := mlflow.IsScrubError(tc.lastError)
+	if !ok {
+		return fmt.Errorf("expected a scrub error, got %v", tc.lastError)
+	}
+	for _, finding := range scrubErr.Findings {
+		if finding.Key == key {
+			return nil
+		}
+	}
+	return fmt.Errorf("scrub error does not mention key %s", key)
+}
+
+func (tc *testContext) scrubberReportedFindings(count int) error {
+	if len(tc.scrubFindings) != count {
+		return fmt.Errorf("expected %d findings, got %d", count, len(tc.scrubFindings))
+	}
+	return nil
+}
+
+func (tc *testContext) runHasParam(key, value string) error {
+	run, err := tc.client.GetRun(tc.runID)
+	if err != nil {
+		return err
+	}
+	for _, param := range run.Run.Data.Params {
+		if param.Key == key && param.Value == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("param %s=%s not found", key, value)
+}
@@ -0,0 +1,180 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cucumber/godog"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/search"
+)
+
+// Search filter evaluation step implementations
+
+func (tc *testContext) parseFilter(filter, entity string) error {
+	tc.searchFilter, tc.lastError = search.Parse(search.Entity(entity), filter)
+	return nil
+}
+
+func (tc *testContext) parsingSucceeds() error {
+	return tc.lastError
+}
+
+func (tc *testContext) parsingFails(text string) error {
+	return expectErrorContaining(tc.lastError, text)
+}
+
+// cachedRun builds a run from a table of identifier and value rows, e.g.
+// | metrics.accuracy | 0.93 |
+func (tc *testContext) cachedRun(table *godog.Table) error {
+	run := &mlflow.Run{}
+	for _, row := range table.Rows {
+		field, value := row.Cells[0].Value, row.Cells[1].Value
+		prefix, key, _ := strings.Cut(field, ".")
+		switch prefix {
+		case "metrics":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			run.Data.Metrics = append(run.Data.Metrics, mlflow.Metric{Key: key, Value: number})
+		case "params":
+			run.Data.Params = append(run.Data.Params, mlflow.Param{Key: key, Value: value})
+		case "tags":
+			run.Data.Tags = append(run.Data.Tags, mlflow.RunTag{Key: key, Value: value})
+		case "datasets":
+			name, context, _ := strings.Cut(value, "/")
+			run.Inputs.Datasets = append(run.Inputs.Datasets, mlflow.Dataset{
+				Name: name,
+				Tags: []mlflow.DatasetTag{{Key: search.DatasetContextTag, Value: context}},
+			})
+		case "attributes":
+			if err := setRunAttribute(&run.Info, key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown run field %q", field)
+		}
+	}
+	tc.cachedValue = run
+	return nil
+}
+
+func setRunAttribute(info *mlflow.RunInfo, key, value string) error {
+	var err error
+	switch key {
+	case "run_id":
+		info.RunID = value
+	case "run_name":
+		info.RunName = value
+	case "status":
+		info.Status = mlflow.RunStatus(value)
+	case "user_id":
+		info.UserID = value
+	case "start_time":
+		info.StartTime, err = strconv.ParseInt(value, 10, 64)
+	case "end_time":
+		info.EndTime, err = strconv.ParseInt(value, 10, 64)
+	default:
+		return fmt.Errorf("unknown run attribute %q", key)
+	}
+	return err
+}
+
+func (tc *testContext) cachedExperiment(name, tag, value string) error {
+	tc.cachedValue = &mlflow.Experiment{
+		Name:         name,
+		CreationTime: 1700000000000,
+		Tags:         []mlflow.ExperimentTag{{Key: tag, Value: value}},
+	}
+	return nil
+}
+
+func (tc *testContext) cachedModelVersion(name, version, runID string) error {
+	tc.cachedValue = &mlflow.ModelVersion{
+		Name:    name,
+		Version: version,
+		RunID:   runID,
+		Source:  "models:/" + name + "/" + version,
+	}
+	return nil
+}
+
+func (tc *testContext) filterMatchesCachedEntity(filter, outcome, entity string) error {
+	f, err := search.Parse(search.Entity(entity), filter)
+	if err != nil {
+		return err
+	}
+	matched, err := f.Match(tc.cachedValue)
+	if err != nil {
+		return err
+	}
+	if matched != (outcome == "match") {
+		return fmt.Errorf("expected %q to %s, but it did not", filter, outcome)
+	}
+	return nil
+}
+
+func (tc *testContext) matchingCachedFails(filter, entity, text string) error {
+	f, err := search.Parse(search.Entity(entity), filter)
+	if err != nil {
+		return err
+	}
+	_, err = f.Match(tc.cachedValue)
+	return expectErrorContaining(err, text)
+}
+
+func (tc *testContext) runsTaggedWithMetric(count int, tag string) error {
+	for i := 0; i < count; i++ {
+		if err := tc.createRun(); err != nil {
+			return err
+		}
+		if err := tc.setRunTag(tag, fmt.Sprintf("member-%d", i)); err != nil {
+			return err
+		}
+		if err := tc.logMetric("accuracy", 0.5+float64(i)/10); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) filterSelectsSameRuns(filter string) error {
+	f, err := search.Parse(search.EntityRuns, filter)
+	if err != nil {
+		return err
+	}
+	all, err := tc.client.SearchRuns(mlflow.SearchRunsRequest{ExperimentIDs: []string{tc.experimentID}})
+	if err != nil {
+		return err
+	}
+	var local []string
+	for _, run := range all.Runs {
+		matched, err := f.Match(run)
+		if err != nil {
+			return err
+		}
+		if matched {
+			local = append(local, run.Info.RunID)
+		}
+	}
+	remote, err := tc.client.SearchRuns(mlflow.SearchRunsRequest{
+		ExperimentIDs: []string{tc.experimentID},
+		Filter:        filter,
+	})
+	if err != nil {
+		return err
+	}
+	var server []string
+	for _, run := range remote.Runs {
+		server = append(server, run.Info.RunID)
+	}
+	sort.Strings(local)
+	sort.Strings(server)
+	if len(local) == 0 || strings.Join(local, ",") != strings.Join(server, ",") {
+		return fmt.Errorf("local match selected %v, the server returned %v", local, server)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package features
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/search"
+)
+
+// Search filter builder step implementations
+
+func searchIdentifier(kind, key string) (search.Identifier, error) {
+	switch kind {
+	case "metric":
+		return search.Metric(key), nil
+	case "param":
+		return search.Param(key), nil
+	case "tag":
+		return search.Tag(key), nil
+	case "attribute":
+		return search.Attribute(key), nil
+	case "dataset":
+		return search.Dataset(key), nil
+	}
+	return search.Identifier{}, fmt.Errorf("unknown identifier type %q", kind)
+}
+
+func compareWith(id search.Identifier, op string, value any) search.Filter {
+	switch op {
+	case "=":
+		return id.Eq(value)
+	case "!=":
+		return id.Ne(value)
+	case ">":
+		return id.Gt(value)
+	case ">=":
+		return id.Ge(value)
+	case "<":
+		return id.Lt(value)
+	case "<=":
+		return id.Le(value)
+	case "LIKE":
+		return id.Like(fmt.Sprint(value))
+	}
+	return id.ILike(fmt.Sprint(value))
+}
+
+func (tc *testContext) filterIncludesNumber(kind, key, op, value string) error {
+	id, err := searchIdentifier(kind, key)
+	if err != nil {
+		return err
+	}
+	var number any
+	if strings.Contains(value, ".") {
+		number, err = strconv.ParseFloat(value, 64)
+	} else {
+		number, err = strconv.ParseInt(value, 10, 64)
+	}
+	if err != nil {
+		return err
+	}
+	tc.searchFilter = tc.searchFilter.And(compareWith(id, op, number))
+	return nil
+}
+
+func (tc *testContext) filterIncludesString(kind, key, op, value string) error {
+	id, err := searchIdentifier(kind, key)
+	if err != nil {
+		return err
+	}
+	tc.searchFilter = tc.searchFilter.And(compareWith(id, op, value))
+	return nil
+}
+
+func (tc *testContext) filterIncludesSet(kind, key, op, values string) error {
+	id, err := searchIdentifier(kind, key)
+	if err != nil {
+		return err
+	}
+	var items []any
+	for _, item := range strings.Split(values, ",") {
+		items = append(items, strings.TrimSpace(item))
+	}
+	if op == "IN" {
+		tc.searchFilter = tc.searchFilter.And(id.In(items...))
+	} else {
+		tc.searchFilter = tc.searchFilter.And(id.NotIn(items...))
+	}
+	return nil
+}
+
+func (tc *testContext) builtFilterShouldBe(entity, want string) error {
+	got, err := tc.searchFilter.Build(search.Entity(entity))
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("expected filter %s, got %s", want, got)
+	}
+	return nil
+}
+
+func (tc *testContext) buildingFilterFails(entity, text string) error {
+	_, err := tc.searchFilter.Build(search.Entity(entity))
+	return expectErrorContaining(err, text)
+}
+
+func (tc *testContext) resultsOrderedBy(kind, key, direction string) error {
+	id, err := searchIdentifier(kind, key)
+	if err != nil {
+		return err
+	}
+	if direction == "DESC" {
+		tc.searchOrderBy = append(tc.searchOrderBy, id.Desc())
+	} else {
+		tc.searchOrderBy = append(tc.searchOrderBy, id.Asc())
+	}
+	return nil
+}
+
+func (tc *testContext) builtOrderByShouldBe(entity, want string) error {
+	got, err := search.BuildOrderBy(search.Entity(entity), tc.searchOrderBy...)
+	if err != nil {
+		return err
+	}
+	if strings.Join(got, ", ") != want {
+		return fmt.Errorf("expected order_by %s, got %s", want, strings.Join(got, ", "))
+	}
+	return nil
+}
+
+func (tc *testContext) buildingOrderByFails(entity, text string) error {
+	_, err := search.BuildOrderBy(search.Entity(entity), tc.searchOrderBy...)
+	return expectErrorContaining(err, text)
+}
+
+func expectErrorContaining(err error, text string) error {
+	if err == nil {
+		return fmt.Errorf("expected an error mentioning %q", text)
+	}
+	if !strings.Contains(err.Error(), text) {
+		return fmt.Errorf("expected an error mentioning %q, got: %v", text, err)
+	}
+	return nil
+}
+
+func (tc *testContext) searchRunsWithBuiltFilter() error {
+	filter, err := tc.searchFilter.Build(search.EntityRuns)
+	if err != nil {
+		return err
+	}
+	orderBy, err := search.BuildOrderBy(search.EntityRuns, tc.searchOrderBy...)
+	if err != nil {
+		return err
+	}
+	resp, err := tc.client.SearchRuns(mlflow.SearchRunsRequest{
+		ExperimentIDs: []string{tc.experimentID},
+		Filter:        filter,
+		OrderBy:       orderBy,
+	})
+	if err != nil {
+		return err
+	}
+	tc.lastResponse = resp
+	return nil
+}
+
+func (tc *testContext) searchReturnedRuns(count int) error {
+	resp, ok := tc.lastResponse.(*mlflow.SearchRunsResponse)
+	if !ok {
+		return fmt.Errorf("expected SearchRunsResponse")
+	}
+	if len(resp.Runs) != count {
+		return fmt.Errorf("expected %d runs, got %d", count, len(resp.Runs))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package features
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+
+	"github.com/cucumber/godog"
+	"github.com/google/uuid"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// Source tags step implementations
+
+func (tc *testContext) sourceBuiltFromCommit(commit, commitTime string) error {
+	modified := true
+	tc.sourceInfo = mlflow.SourceInfo{
+		Name:          "github.com/acme/trainer/cmd/train",
+		Module:        "github.com/acme/trainer",
+		GitCommit:     commit,
+		GitCommitTime: commitTime,
+		GitModified:   &modified,
+		User:          "ada",
+		Hostname:      "gpu-node-7",
+	}
+	return nil
+}
+
+func (tc *testContext) sourceTagsShouldBe(table *godog.Table) error {
+	tags := tc.sourceInfo.Tags()
+	rows := table.Rows[1:]
+	if len(tags) != len(rows) {
+		return fmt.Errorf("expected %d tags, got %v", len(rows), tags)
+	}
+	for i, row := range rows {
+		want := mlflow.RunTag{Key: row.Cells[0].Value, Value: row.Cells[1].Value}
+		if tags[i] != want {
+			return fmt.Errorf("expected tag %d to be %v, got %v", i, want, tags[i])
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) detectSource() error {
+	tc.sourceInfo = mlflow.DetectSource()
+	return nil
+}
+
+func (tc *testContext) detectedModuleShouldBe(module string) error {
+	if tc.sourceInfo.Module != module {
+		return fmt.Errorf("expected main module %s, got %s", module, tc.sourceInfo.Module)
+	}
+	return nil
+}
+
+func (tc *testContext) detectedGoEnvironment() error {
+	s := tc.sourceInfo
+	if s.GoVersion != runtime.Version() || s.GOOS != runtime.GOOS || s.GOARCH != runtime.GOARCH {
+		return fmt.Errorf("expected %s %s/%s, got %s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH, s.GoVersion, s.GOOS, s.GOARCH)
+	}
+	return nil
+}
+
+func (tc *testContext) detectedDependenciesInclude(module string) error {
+	for _, dep := range tc.sourceInfo.Dependencies {
+		if dep.Path == module && dep.Version != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected %s in the dependencies, got %v", module, tc.sourceInfo.Dependencies)
+}
+
+func (tc *testContext) createRunWithSource(tags []mlflow.RunTag) error {
+	resp, err := tc.client.CreateRun(mlflow.CreateRunRequest{
+		ExperimentID: tc.experimentID,
+		RunName:      fmt.Sprintf("test-run-%s", uuid.New().String()),
+		Tags:         tags,
+		Source:       true,
+	})
+	if resp != nil {
+		tc.runID = resp.Run.Info.RunID
+		tc.createdResources = append(tc.createdResources, resource{Type: "run", ID: tc.runID})
+	}
+	return err
+}
+
+func (tc *testContext) createRunWithSourceTracking() error {
+	return tc.createRunWithSource(nil)
+}
+
+func (tc *testContext) createRunWithSourceAndTag(key, value string) error {
+	return tc.createRunWithSource([]mlflow.RunTag{{Key: key, Value: value}})
+}
+
+func (tc *testContext) runHasNonEmptyTag(key string) error {
+	resp, err := tc.client.GetRun(tc.runID)
+	if err != nil {
+		return err
+	}
+	if value, ok := resp.Run.Tag(key); !ok || value == "" {
+		return fmt.Errorf("expected the run to have a non-empty tag %s", key)
+	}
+	return nil
+}
+
+func (tc *testContext) runHasArtifact(artifactPath string) error {
+	dir := path.Dir(artifactPath)
+	if dir == "." {
+		dir = ""
+	}
+	resp, err := tc.client.ListArtifacts(tc.runID, dir, "")
+	if err != nil {
+		return err
+	}
+	for _, file := range resp.Files {
+		if file.Path == artifactPath && file.FileSize > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected artifact %s, got %v", artifactPath, resp.Files)
+}
+
+func (tc *testContext) logTextArtifact(text, artifactPath string) error {
+	return tc.client.LogText(tc.runID, artifactPath, text)
+}
+
+func (tc *testContext) attemptLogTextToRun(artifactPath, runID string) error {
+	tc.lastError = tc.client.LogText(runID, artifactPath, "notes")
+	return nil
+}
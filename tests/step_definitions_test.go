@@ -1,28 +1,107 @@
 package features
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/http/httptest"
 	"os"
 
 	"github.com/cucumber/godog"
+	"github.com/julpayne/mlflow-go-client/internal/protogen"
 	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/azure"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/gotest"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/s3"
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow/search"
 )
 
 type testContext struct {
-	client           *mlflow.Client
-	experimentID     string
-	experimentName   string
-	runID            string
-	modelName        string
-	modelVersion     string
-	model            *mlflow.RegisteredModel
-	healthStatus     string
-	serverVersion    string
-	lastError        error
-	lastResponse     interface{}
-	createdResources []resource
+	client            *mlflow.Client
+	experimentID      string
+	experimentName    string
+	runID             string
+	modelName         string
+	modelVersion      string
+	model             *mlflow.RegisteredModel
+	healthStatus      string
+	serverVersion     string
+	lastError         error
+	lastResponse      interface{}
+	createdResources  []resource
+	tempDirs          []string
+	auditBuffer       *bytes.Buffer
+	auditDir          string
+	fileAuditSink     *mlflow.FileAuditSink
+	policyRules       []mlflow.PolicyRule
+	policy            *mlflow.PolicyEngine
+	scrubber          *mlflow.Scrubber
+	scrubFindings     []mlflow.ScrubFinding
+	scrubFinding      *mlflow.ScrubFinding
+	scrubbedValue     string
+	protogenConfig    *protogen.Config
+	protogenDir       string
+	protogenResult    *protogen.Result
+	trainingConfig    *trainingConfig
+	loadedConfig      *trainingConfig
+	flattenedParams   []mlflow.Param
+	offlineRun        *mlflow.Run
+	searchFilter      search.Filter
+	searchOrderBy     []search.OrderBy
+	cachedValue       any
+	childRunIDs       map[string]string
+	runTree           *mlflow.RunTree
+	activeRun         *mlflow.ActiveRun
+	savedEnv          map[string]*string
+	experimentCreated bool
+	runResumed        bool
+	previousID        string
+	workerIDs         []string
+	workerFlags       int
+	sourceInfo        mlflow.SourceInfo
+	monitorErrors     []error
+	profileData       []byte
+	profileSummary    mlflow.ProfileSummary
+	goTestOutput      string
+	goTestReport      *gotest.Report
+	uploadDir         string
+	downloadDir       string
+	downloadedPath    string
+	mpuStub           *multipartStub
+	mpuFile           string
+	mpuStateFile      string
+	mpuProgress       []mlflow.MultipartProgress
+	artifactRepo      mlflow.ArtifactRepository
+	artifactStoreDir  string
+	s3Stub            *s3Stub
+	s3Data            []byte
+	s3Prefix          string
+	minioRepo         *s3.Repository
+	signature         string
+	azureStub         *azureStub
+	azurePrefix       string
+	azuriteRepo       *azure.Repository
+	fsProxy           *artifactPagingProxy
+	artifactFS        fs.FS
+	fsCacheDir        string
+	fsFile            fs.File
+	fsServer          *httptest.Server
+	artifactManifest  *mlflow.ArtifactManifest
+	downloadProgress  int
+	secondRunID       string
+	blobGCResult      *mlflow.ArtifactBlobGCResult
+	otherHost         *requestCounter
+	authRecorder      *authRecorder
+	keyFiles          []string
+	kms               *fakeKMS
+	notedCiphertext   []byte
+	rotatedKeys       int
+	signers           map[string]mlflow.ModelSigner
+	signerKeys        map[string]ed25519.PublicKey
+	signedManifest    *mlflow.ModelVersionManifest
 }
 
 type resource struct {
@@ -44,6 +123,10 @@ func (ctx *testContext) reset() {
 }
 
 func (ctx *testContext) cleanup() {
+	// Policies installed by a scenario must not block the cleanup
+	if ctx.client != nil {
+		ctx.client.SetPolicy(nil)
+	}
 	// Clean up created resources in reverse order
 	for i := len(ctx.createdResources) - 1; i >= 0; i-- {
 		resource := ctx.createdResources[i]
@@ -57,6 +140,47 @@ func (ctx *testContext) cleanup() {
 		}
 	}
 	ctx.createdResources = nil
+	if ctx.mpuStub != nil {
+		ctx.mpuStub.server.Close()
+		ctx.mpuStub = nil
+	}
+	if ctx.s3Stub != nil {
+		ctx.s3Stub.server.Close()
+		ctx.s3Stub = nil
+	}
+	if ctx.minioRepo != nil {
+		_ = ctx.minioRepo.Delete(context.Background(), "")
+		ctx.minioRepo = nil
+	}
+	if ctx.azureStub != nil {
+		ctx.azureStub.server.Close()
+		ctx.azureStub = nil
+	}
+	if ctx.fsServer != nil {
+		ctx.fsServer.Close()
+		ctx.fsServer = nil
+	}
+	if ctx.fsProxy != nil {
+		ctx.fsProxy.server.Close()
+		ctx.fsProxy = nil
+	}
+	if ctx.otherHost != nil {
+		ctx.otherHost.server.Close()
+		ctx.otherHost = nil
+	}
+	if ctx.authRecorder != nil {
+		ctx.authRecorder.server.Close()
+		ctx.authRecorder = nil
+	}
+	if ctx.azuriteRepo != nil {
+		_ = ctx.azuriteRepo.Delete(context.Background(), "")
+		ctx.azuriteRepo = nil
+	}
+	for _, dir := range ctx.tempDirs {
+		_ = os.RemoveAll(dir)
+	}
+	ctx.tempDirs = nil
+	ctx.restoreEnv()
 }
 
 func InitializeScenario(ctx *godog.ScenarioContext) {
@@ -199,6 +323,425 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^I delete the registered model$`, tc.deleteRegisteredModel)
 	ctx.Step(`^the model should be deleted$`, tc.modelDeleted)
 
+	// Audit steps
+	ctx.Step(`^an MLflow client for an unreachable server$`, tc.clientForUnreachableServer)
+	ctx.Step(`^audit logging is enabled with actor "([^"]*)"$`, tc.auditLoggingEnabled)
+	ctx.Step(`^I create an experiment with a unique name$`, tc.createExperimentWithUniqueName)
+	ctx.Step(`^I attempt to delete the experiment with ID "([^"]*)"$`, tc.attemptDeleteExperiment)
+	ctx.Step(`^I attempt to log parameter "([^"]*)" with value "([^"]*)" to run "([^"]*)"$`, tc.attemptLogParam)
+	ctx.Step(`^the audit log should contain a "([^"]*)" record with outcome "([^"]*)"$`, tc.auditLogContainsRecord)
+	ctx.Step(`^every audit record should have actor "([^"]*)"$`, tc.everyAuditRecordHasActor)
+	ctx.Step(`^the last audit record should have error code "([^"]*)"$`, tc.lastAuditRecordHasErrorCode)
+	ctx.Step(`^the last audit record should target "([^"]*)" "([^"]*)"$`, tc.lastAuditRecordTargets)
+	ctx.Step(`^the audit log should not contain "([^"]*)"$`, tc.auditLogDoesNotContain)
+	ctx.Step(`^a file audit sink limited to (\d+) bytes with (\d+) backups$`, tc.fileAuditSinkLimited)
+	ctx.Step(`^I write (\d+) audit records to the file sink$`, tc.writeAuditRecordsToFileSink)
+	ctx.Step(`^the audit log directory should contain (\d+) files$`, tc.auditDirContainsFiles)
+	ctx.Step(`^every audit file should contain valid JSON lines$`, tc.everyAuditFileHasValidJSONLines)
+
+	// Policy steps
+	ctx.Step(`^a policy rule "([^"]*)" blocking "([^"]*)" for names matching "([^"]*)"$`, tc.policyRuleForNames)
+	ctx.Step(`^a policy rule "([^"]*)" blocking "([^"]*)" for tag "([^"]*)" equals "([^"]*)" with override token "([^"]*)"$`, tc.policyRuleForTagWithOverride)
+	ctx.Step(`^a policy rule "([^"]*)" blocking "([^"]*)" for versions with alias "([^"]*)"$`, tc.policyRuleForAlias)
+	ctx.Step(`^a policy rule "([^"]*)" blocking "([^"]*)" for versions in stage "([^"]*)"$`, tc.policyRuleForStage)
+	ctx.Step(`^the policy is installed on the client$`, tc.policyInstalled)
+	ctx.Step(`^"([^"]*)" on experiment "([^"]*)" should be blocked by rule "([^"]*)"$`, tc.experimentOperationBlocked)
+	ctx.Step(`^"([^"]*)" on experiment "([^"]*)" should be allowed$`, tc.experimentOperationAllowed)
+	ctx.Step(`^"([^"]*)" on a run tagged "([^"]*)" = "([^"]*)" should be blocked by rule "([^"]*)"$`, tc.taggedRunOperationBlocked)
+	ctx.Step(`^"([^"]*)" on a run tagged "([^"]*)" = "([^"]*)" with override token "([^"]*)" should be blocked by rule "([^"]*)"$`, tc.taggedRunOperationWithTokenBlocked)
+	ctx.Step(`^"([^"]*)" on a run tagged "([^"]*)" = "([^"]*)" with override token "([^"]*)" should be allowed$`, tc.taggedRunOperationWithTokenAllowed)
+	ctx.Step(`^a registered model with a unique name exists$`, tc.registeredModelUniqueNameExists)
+	ctx.Step(`^I attempt to delete the registered model$`, tc.attemptDeleteRegisteredModel)
+	ctx.Step(`^I attempt to delete the registered model with override token "([^"]*)"$`, tc.attemptDeleteRegisteredModelWithOverride)
+	ctx.Step(`^I attempt to delete the model version$`, tc.attemptDeleteModelVersion)
+	ctx.Step(`^I attempt to transition the model version to stage "([^"]*)"$`, tc.attemptTransitionModelVersion)
+	ctx.Step(`^I attempt to transition the model version to stage "([^"]*)" archiving existing versions$`, tc.attemptTransitionModelVersionArchivingExisting)
+	ctx.Step(`^I attempt to delete the experiment's runs created until now$`, tc.attemptDeleteRunsCreatedUntilNow)
+	ctx.Step(`^I attempt to delete the experiment's traces$`, tc.attemptDeleteTraces)
+	ctx.Step(`^the run should still be active$`, tc.runStillActive)
+	ctx.Step(`^the call should fail with a policy error from rule "([^"]*)"$`, tc.callFailsWithPolicyError)
+	ctx.Step(`^the call should succeed$`, tc.callSucceeds)
+	ctx.Step(`^the registered model should still exist$`, tc.registeredModelStillExists)
+
+	// Scrubbing steps
+	ctx.Step(`^a scrubber that will "([^"]*)" secrets$`, tc.scrubberWithAction)
+	ctx.Step(`^a scrubber that will "([^"]*)" secrets is installed on the client$`, tc.scrubberInstalled)
+	ctx.Step(`^I scrub key "([^"]*)" with value "([^"]*)"$`, tc.scrubKeyValue)
+	ctx.Step(`^the value should be scrubbed because of "([^"]*)"$`, tc.valueScrubbedBecause)
+	ctx.Step(`^the value should be "([^"]*)"$`, tc.scrubbedValueShouldBe)
+	ctx.Step(`^the call should fail with a scrub error for key "([^"]*)"$`, tc.callFailsWithScrubError)
+	ctx.Step(`^the scrubber should have reported (\d+) findings?$`, tc.scrubberReportedFindings)
+	ctx.Step(`^the run should have param "([^"]*)" with value "([^"]*)"$`, tc.runHasParam)
+
+	// Codegen steps
+	ctx.Step(`^the generator config "([^"]*)"$`, tc.generatorConfig)
+	ctx.Step(`^a copy of the vendored protos and generated API$`, tc.copyOfGeneratorInputs)
+	ctx.Step(`^I append "([^"]*)" to the vendored "([^"]*)"$`, tc.appendToVendoredProto)
+	ctx.Step(`^I append "([^"]*)" to the generated API$`, tc.appendToGeneratedAPI)
+	ctx.Step(`^I check the generated API$`, tc.checkGeneratedAPI)
+	ctx.Step(`^the generated API should be up to date$`, tc.generatedAPIUpToDate)
+	ctx.Step(`^the check should report drift mentioning "([^"]*)"$`, tc.checkReportsDrift)
+	ctx.Step(`^the generated API should provide method "([^"]*)"$`, tc.generatedAPIProvidesMethod)
+	ctx.Step(`^the generated API should not provide method "([^"]*)"$`, tc.generatedAPIDoesNotProvideMethod)
+	ctx.Step(`^I attempt to get the logged model with ID "([^"]*)"$`, tc.attemptGetLoggedModel)
+	ctx.Step(`^I attempt to delete the logged model with ID "([^"]*)"$`, tc.attemptDeleteLoggedModel)
+	ctx.Step(`^the call should fail with "([^"]*)"$`, tc.callFailsWith)
+
+	// Struct params steps
+	ctx.Step(`^a training config with learning rate ([\d.]+) and (\d+) layers$`, tc.trainingConfigWith)
+	ctx.Step(`^the training config has (\d+) labels$`, tc.trainingConfigHasLabels)
+	ctx.Step(`^the last layer has dropout ([\d.]+)$`, tc.lastLayerHasDropout)
+	ctx.Step(`^I flatten the training config$`, tc.flattenTrainingConfig)
+	ctx.Step(`^the flattened params should be:$`, tc.flattenedParamsShouldBe)
+	ctx.Step(`^I log the training config to the run$`, tc.logTrainingConfig)
+	ctx.Step(`^I load the run params into a new training config$`, tc.loadRunParamsIntoTrainingConfig)
+	ctx.Step(`^the loaded training config should equal the logged one$`, tc.loadedConfigEqualsLogged)
+	ctx.Step(`^a run with param "([^"]*)" = "([^"]*)"$`, tc.runWithParam)
+	ctx.Step(`^I attempt to load the run params into a new training config$`, tc.attemptLoadOfflineRun)
+
+	// Search filter steps
+	ctx.Step(`^the filter includes (metric|param|tag|attribute|dataset) "([^"]*)" (=|!=|>|>=|<|<=|LIKE|ILIKE) (-?[\d.]+)$`, tc.filterIncludesNumber)
+	ctx.Step(`^the filter includes (metric|param|tag|attribute|dataset) "([^"]*)" (=|!=|>|>=|<|<=|LIKE|ILIKE) "(.*)"$`, tc.filterIncludesString)
+	ctx.Step(`^the filter includes (metric|param|tag|attribute|dataset) "([^"]*)" (IN|NOT IN) "([^"]*)"$`, tc.filterIncludesSet)
+	ctx.Step(`^the (\w+) filter should be (.+)$`, tc.builtFilterShouldBe)
+	ctx.Step(`^building the (\w+) filter should fail mentioning "(.*)"$`, tc.buildingFilterFails)
+	ctx.Step(`^results are ordered by (metric|param|tag|attribute|dataset) "([^"]*)" (ASC|DESC)$`, tc.resultsOrderedBy)
+	ctx.Step(`^the (\w+) order_by should be (.+)$`, tc.builtOrderByShouldBe)
+	ctx.Step(`^building the (\w+) order_by should fail mentioning "(.*)"$`, tc.buildingOrderByFails)
+	ctx.Step(`^I search runs in the experiment with the built filter$`, tc.searchRunsWithBuiltFilter)
+	ctx.Step(`^the search should return (\d+) runs?$`, tc.searchReturnedRuns)
+
+	// Search filter evaluation steps
+	ctx.Step(`^the filter "(.*)" is parsed for (\w+)$`, tc.parseFilter)
+	ctx.Step(`^parsing should succeed$`, tc.parsingSucceeds)
+	ctx.Step(`^parsing should fail mentioning "(.*)"$`, tc.parsingFails)
+	ctx.Step(`^a cached run with:$`, tc.cachedRun)
+	ctx.Step(`^a cached experiment named "([^"]*)" tagged "([^"]*)" = "([^"]*)"$`, tc.cachedExperiment)
+	ctx.Step(`^a cached model version "([^"]*)" version "([^"]*)" from run "([^"]*)"$`, tc.cachedModelVersion)
+	ctx.Step(`^the filter "(.*)" should (match|not match) the cached (\w+) entry$`, tc.filterMatchesCachedEntity)
+	ctx.Step(`^matching the filter "(.*)" for (\w+) against the cached entry should fail mentioning "(.*)"$`, tc.matchingCachedFails)
+	ctx.Step(`^(\d+) runs exist tagged "([^"]*)" with increasing accuracy$`, tc.runsTaggedWithMetric)
+	ctx.Step(`^the filter "(.*)" selects the same runs locally as on the server$`, tc.filterSelectsSameRuns)
+
+	// Typed accessors and enums steps
+	ctx.Step(`^the (run status|view type|lifecycle stage|model stage|model version status) "([^"]*)" should be (valid|invalid)$`, tc.enumValidity)
+	ctx.Step(`^the (cached|fetched) run should have param "([^"]*)" = "([^"]*)"$`, tc.runHasParamValue)
+	ctx.Step(`^the (cached|fetched) run should have metric "([^"]*)" = ([\d.]+)$`, tc.runHasMetricValue)
+	ctx.Step(`^the (cached|fetched) run should have tag "([^"]*)" = "([^"]*)"$`, tc.runHasTagValue)
+	ctx.Step(`^the (cached|fetched) run should not have param "([^"]*)"$`, tc.runLacksParam)
+	ctx.Step(`^the (cached|fetched) run should have (\d+) params, (\d+) metrics and (\d+) tags in its maps$`, tc.runMapSizes)
+	ctx.Step(`^the (cached|fetched) run should have started at (\d+) and run for (\S+)$`, tc.runStartedAndRanFor)
+	ctx.Step(`^the (cached|fetched) run should (not )?be terminal$`, tc.runTerminal)
+	ctx.Step(`^the (cached|fetched) run should not have ended$`, tc.runNotEnded)
+	ctx.Step(`^I attempt to search runs with view type "([^"]*)"$`, tc.attemptSearchRunsWithViewType)
+	ctx.Step(`^I attempt to set the run status to "([^"]*)"$`, tc.attemptSetRunStatus)
+
+	// Nested runs steps
+	ctx.Step(`^I start a child run "([^"]*)" under the run$`, tc.startNamedChildRun)
+	ctx.Step(`^I attempt to start a child run under the run in experiment "([^"]*)"$`, tc.attemptChildRunInOtherExperiment)
+	ctx.Step(`^the run has (\d+) child runs$`, tc.runHasChildRuns)
+	ctx.Step(`^child run "([^"]*)" has (\d+) child runs$`, tc.childRunHasChildRuns)
+	ctx.Step(`^child run "([^"]*)" has ended with status "([^"]*)"$`, tc.childRunHasEnded)
+	ctx.Step(`^child run "([^"]*)" should be nested under the run$`, tc.childRunNestedUnderRun)
+	ctx.Step(`^child run "([^"]*)" should be in the experiment$`, tc.childRunInExperiment)
+	ctx.Step(`^I get the run tree$`, tc.getRunTree)
+	ctx.Step(`^the run tree should have (\d+) runs$`, tc.runTreeHasRuns)
+	ctx.Step(`^the run tree should be:$`, tc.runTreeShouldBe)
+	ctx.Step(`^the run tree status should be "([^"]*)"$`, tc.runTreeStatusShouldBe)
+	ctx.Step(`^I end the run tree with status "([^"]*)"$`, tc.endRunTree)
+	ctx.Step(`^I end the run tree under child run "([^"]*)" with status "([^"]*)"$`, tc.endRunTreeUnder)
+	ctx.Step(`^I attempt to end the run tree with status "([^"]*)"$`, tc.attemptEndRunTree)
+	ctx.Step(`^every run in the run tree should have status "([^"]*)"$`, tc.everyRunInTreeHasStatus)
+	ctx.Step(`^child run "([^"]*)" in the run tree should have status "([^"]*)"$`, tc.childRunInTreeHasStatus)
+
+	// Active run steps
+	ctx.Step(`^no MLflow environment variables are set$`, tc.noMLflowEnv)
+	ctx.Step(`^MLFLOW_RUN_ID is set to the run$`, tc.envSetToRun)
+	ctx.Step(`^MLFLOW_EXPERIMENT_NAME is set to the experiment name$`, tc.envSetToExperimentName)
+	ctx.Step(`^MLFLOW_EXPERIMENT_ID is set to the experiment ID$`, tc.envSetToExperimentID)
+	ctx.Step(`^MLFLOW_EXPERIMENT_NAME is set to "([^"]*)"$`, tc.envExperimentNameSetTo)
+	ctx.Step(`^I start a run in the experiment$`, tc.startRunInExperiment)
+	ctx.Step(`^I start a run without an experiment ID$`, tc.startRunWithoutExperiment)
+	ctx.Step(`^I attempt to start a run without an experiment ID$`, tc.attemptStartRunWithoutExperiment)
+	ctx.Step(`^library code logs metric "([^"]*)" with value ([\d.]+) to the run in the context$`, tc.libraryLogsMetricToContextRun)
+	ctx.Step(`^a context without a run should have no active run$`, tc.contextWithoutRunHasNoActiveRun)
+	ctx.Step(`^the active run should have metric "([^"]*)" = ([\d.]+)$`, tc.activeRunHasMetric)
+	ctx.Step(`^the active run should have param "([^"]*)" = "([^"]*)"$`, tc.activeRunHasParam)
+	ctx.Step(`^the active run should be the existing run$`, tc.activeRunIsExistingRun)
+	ctx.Step(`^the active run should be in the experiment$`, tc.activeRunInExperiment)
+	ctx.Step(`^the experiment should have (\d+) runs?$`, tc.experimentHasRuns)
+	ctx.Step(`^a subprocess started with the active run's environment logs param "([^"]*)" = "([^"]*)"$`, tc.subprocessLogsParam)
+
+	// Get-or-create steps
+	ctx.Step(`^I get or create an experiment with a unique name$`, tc.getOrCreateUniqueExperiment)
+	ctx.Step(`^I get or create the experiment again$`, tc.getOrCreateExperimentAgain)
+	ctx.Step(`^I get or create the experiment again, restoring it if deleted$`, tc.getOrCreateExperimentRestoring)
+	ctx.Step(`^I attempt to get or create the experiment again$`, tc.attemptGetOrCreateExperimentAgain)
+	ctx.Step(`^the experiment should (not )?have been created$`, tc.experimentWasCreated)
+	ctx.Step(`^both calls should return the same experiment$`, tc.sameExperimentReturned)
+	ctx.Step(`^(\d+) workers get or create an experiment with the same unique name at once$`, tc.workersGetOrCreateExperiment)
+	ctx.Step(`^every worker should get the same (?:experiment|run)$`, tc.everyWorkerGetsSameID)
+	ctx.Step(`^exactly (\d+) workers? should have created it$`, tc.workersCreated)
+	ctx.Step(`^I resume or start the run with key "([^"]*)"$`, tc.resumeOrStartRun)
+	ctx.Step(`^another process started a run with key "([^"]*)" that the next search misses$`, tc.otherProcessStartedRun)
+	ctx.Step(`^I attempt to resume or start a run with an empty key$`, tc.attemptResumeOrStartWithEmptyKey)
+	ctx.Step(`^the run should have been started$`, tc.runWasStarted)
+	ctx.Step(`^the run should have been resumed$`, tc.runWasResumed)
+	ctx.Step(`^the resumed run should be one the workers got$`, tc.resumedRunIsAWorkers)
+	ctx.Step(`^the resumed run should be the first one$`, tc.resumedRunIsFirst)
+	ctx.Step(`^the resumed run ends with status "([^"]*)"$`, tc.resumedRunEnds)
+	ctx.Step(`^the resumed run should have status "([^"]*)"$`, tc.resumedRunHasStatus)
+	ctx.Step(`^(\d+) workers resume or start the run with key "([^"]*)" at once$`, tc.workersResumeOrStartRun)
+
+	// Source tags steps
+	ctx.Step(`^a source built from commit "([^"]*)" at "([^"]*)" with uncommitted changes$`, tc.sourceBuiltFromCommit)
+	ctx.Step(`^the source tags should be:$`, tc.sourceTagsShouldBe)
+	ctx.Step(`^I detect the source of the running program$`, tc.detectSource)
+	ctx.Step(`^the detected main module should be "([^"]*)"$`, tc.detectedModuleShouldBe)
+	ctx.Step(`^the detected source should have the running Go version, GOOS and GOARCH$`, tc.detectedGoEnvironment)
+	ctx.Step(`^the detected dependencies should include "([^"]*)"$`, tc.detectedDependenciesInclude)
+	ctx.Step(`^I create a run with source tracking$`, tc.createRunWithSourceTracking)
+	ctx.Step(`^I create a run with source tracking and tag "([^"]*)" = "([^"]*)"$`, tc.createRunWithSourceAndTag)
+	ctx.Step(`^the run should have a tag "([^"]*)"$`, tc.runHasNonEmptyTag)
+	ctx.Step(`^the run should have artifact "([^"]*)"$`, tc.runHasArtifact)
+	ctx.Step(`^I log the text "([^"]*)" to artifact "([^"]*)"$`, tc.logTextArtifact)
+	ctx.Step(`^I attempt to log text to artifact "([^"]*)" of run "([^"]*)"$`, tc.attemptLogTextToRun)
+
+	// System metrics steps
+	ctx.Step(`^I start logging system metrics to the active run every (\d+) milliseconds$`, tc.startSystemMetricsOnActiveRun)
+	ctx.Step(`^I attempt to start logging system metrics to the active run again$`, tc.attemptStartSystemMetricsOnActiveRun)
+	ctx.Step(`^I wait (\d+) milliseconds$`, tc.waitMilliseconds)
+	ctx.Step(`^I end the active run with status "([^"]*)"$`, tc.endActiveRun)
+	ctx.Step(`^the active run should have status "([^"]*)"$`, tc.activeRunHasStatus)
+	ctx.Step(`^the active run should have metric "([^"]*)" logged at least (\d+) times$`, tc.activeRunHasMetricsLoggedAtLeast)
+	ctx.Step(`^the active run should have metric "([^"]*)" logged once$`, tc.activeRunHasMetricLoggedOnce)
+	ctx.Step(`^the active run should have a positive "([^"]*)"$`, tc.activeRunHasPositiveMetric)
+	ctx.Step(`^I attempt to start system metrics for run "([^"]*)" every (-?\d+) milliseconds$`, tc.attemptStartSystemMetrics)
+	ctx.Step(`^a system metrics monitor for run "([^"]*)" runs for (\d+) milliseconds$`, tc.runSystemMetricsMonitor)
+	ctx.Step(`^the monitor should have reported an error containing "([^"]*)"$`, tc.monitorReportedError)
+
+	// Profiler steps
+	ctx.Step(`^I capture a CPU profile while burning CPU for (\d+) milliseconds$`, tc.captureCPUProfileWhileBurning)
+	ctx.Step(`^I summarize the profile by "([^"]*)" keeping the top (\d+) functions$`, tc.summarizeProfile)
+	ctx.Step(`^the summary should total "([^"]*)" in "([^"]*)"$`, tc.profileSummaryHas)
+	ctx.Step(`^the top function should be "([^"]*)"$`, tc.topFunctionShouldBe)
+	ctx.Step(`^the summary should have at most (\d+) top functions$`, tc.summaryHasAtMostTop)
+	ctx.Step(`^I start profiling the active run every (\d+) milliseconds with a CPU window of (\d+) milliseconds$`, tc.startProfilerOnActiveRun)
+	ctx.Step(`^the job burns CPU for (\d+) milliseconds$`, tc.jobBurnsCPU)
+	ctx.Step(`^the active run should have at least (\d+) profiles under "([^"]*)"$`, tc.activeRunHasArtifactsUnder)
+	ctx.Step(`^the active run should have tag "([^"]*)" containing "([^"]*)"$`, tc.activeRunHasTagContaining)
+	ctx.Step(`^the active run should have logged a positive "([^"]*)"$`, tc.activeRunLoggedPositiveMetric)
+	ctx.Step(`^I attempt to start a profiler every (-?\d+) milliseconds with a CPU window of (-?\d+) milliseconds and snapshot "([^"]*)"$`, tc.attemptStartProfiler)
+	ctx.Step(`^a profiler for run "([^"]*)" runs for (\d+) milliseconds$`, tc.runProfiler)
+
+	// Go test result steps
+	ctx.Step(`^go test printed:$`, tc.goTestPrinted)
+	ctx.Step(`^I parse the go test output$`, tc.parseGoTestOutput)
+	ctx.Step(`^the report should count (\d+) passed, (\d+) failed and (\d+) skipped tests$`, tc.reportCounts)
+	ctx.Step(`^the failed tests should be "([^"]*)"$`, tc.failedTestsShouldBe)
+	ctx.Step(`^the failed packages should be "([^"]*)"$`, tc.failedPackagesShouldBe)
+	ctx.Step(`^the report should have benchmarks:$`, tc.reportBenchmarksShouldBe)
+	ctx.Step(`^the report should be from "([^"]*)" "([^"]*)" on "([^"]*)"$`, tc.reportFromMachine)
+	ctx.Step(`^I record a benchmark "([^"]*)" that reports ([\d.]+) "([^"]*)" with testing\.Benchmark$`, tc.recordBenchmark)
+	ctx.Step(`^the report should have a positive "([^"]*)" for "([^"]*)"$`, tc.reportHasPositiveValue)
+	ctx.Step(`^the report should have ([\d.]+) "([^"]*)" for "([^"]*)"$`, tc.reportHasValue)
+	ctx.Step(`^the metric key of "([^"]*)" in "([^"]*)" should be "([^"]*)"$`, tc.metricKeyShouldBe)
+	ctx.Step(`^I log the go test report to the experiment with commit "([^"]*)"$`, tc.logGoTestReport)
+	ctx.Step(`^the report should have metric "([^"]*)" = ([\d.]+) at step (\d+)$`, tc.reportHasMetric)
+	ctx.Step(`^the metric "([^"]*)" should have been logged as (.+)$`, tc.metricLoggedAs)
+	ctx.Step(`^(\d+) tests named "([^"]*)" failed$`, tc.manyTestsFailed)
+	ctx.Step(`^the failed tests tag should be at most (\d+) characters and end with "([^"]*)"$`, tc.failedTestsTagLimited)
+
+	// Artifact transfer steps
+	ctx.Step(`^a local directory with files:$`, tc.localDirectoryWithFiles)
+	ctx.Step(`^I log the local file "([^"]*)" to artifact directory "([^"]*)"$`, tc.logLocalFile)
+	ctx.Step(`^I log the local directory to artifact directory "([^"]*)"$`, tc.logLocalDirectory)
+	ctx.Step(`^I download artifacts "([^"]*)" of the run$`, tc.downloadArtifacts)
+	ctx.Step(`^I download artifact "([^"]*)" of the run to "([^"]*)"$`, tc.downloadArtifact)
+	ctx.Step(`^I attempt to download artifact "([^"]*)" of the run$`, tc.attemptDownloadArtifact)
+	ctx.Step(`^I attempt to download artifacts "([^"]*)" of the run$`, tc.attemptDownloadArtifacts)
+	ctx.Step(`^I attempt to download artifacts "([^"]*)" of run "([^"]*)"$`, tc.attemptDownloadArtifactsOfRun)
+	ctx.Step(`^I attempt to log a file to artifact directory "([^"]*)" of run "([^"]*)"$`, tc.attemptLogLocalFileToRun)
+	ctx.Step(`^the downloaded files should be:$`, tc.downloadedFilesShouldBe)
+	ctx.Step(`^the downloaded file "([^"]*)" should have mode "([^"]*)"$`, tc.downloadedFileShouldHaveMode)
+	ctx.Step(`^the download should be at "([^"]*)"$`, tc.downloadedPathShouldBe)
+	ctx.Step(`^the artifact "([^"]*)" should contain "([^"]*)"$`, tc.openedArtifactShouldContain)
+
+	// Multipart upload steps
+	ctx.Step(`^an artifact proxy that (supports|does not support) multipart upload$`, tc.multipartArtifactProxy)
+	ctx.Step(`^a local file of (\d+) bytes$`, tc.localFileOfBytes)
+	ctx.Step(`^part (\d+) fails (\d+) times with status (\d+)$`, tc.partFails)
+	ctx.Step(`^part (\d+) always fails with status (\d+)$`, tc.partAlwaysFails)
+	ctx.Step(`^the failing parts recover$`, tc.partsRecover)
+	ctx.Step(`^the proxy forgets its open uploads$`, tc.proxyForgetsUploads)
+	ctx.Step(`^I upload the file in parts of (\d+) bytes, (\d+) at a time$`, tc.uploadInParts)
+	ctx.Step(`^I upload the file in parts of (\d+) bytes with a state file$`, tc.uploadInPartsWithState)
+	ctx.Step(`^I attempt to upload the file in parts of (\d+) bytes without retries$`, tc.attemptUploadWithoutRetries)
+	ctx.Step(`^I attempt to upload the file in parts of (\d+) bytes, cancelling after (\d+) parts$`, tc.attemptUploadCancelledAfter)
+	ctx.Step(`^the proxy should store the file at "([^"]*)"$`, tc.proxyShouldStoreFile)
+	ctx.Step(`^the proxy should store no files$`, tc.proxyShouldStoreNothing)
+	ctx.Step(`^(\d+) parts should have been uploaded$`, tc.partsShouldBeUploaded)
+	ctx.Step(`^part (\d+) should have been attempted (\d+) times$`, tc.partAttemptedTimes)
+	ctx.Step(`^(\d+) multipart uploads should have been created$`, tc.uploadsShouldBeCreated)
+	ctx.Step(`^the upload should (be|not be) aborted$`, tc.uploadShouldBeAborted)
+	ctx.Step(`^the upload state file should (exist|not exist)$`, tc.stateFileShouldExist)
+	ctx.Step(`^progress should have been reported (\d+) times, ending at (\d+) bytes$`, tc.progressShouldBeReported)
+
+	// Artifact repository steps
+	ctx.Step(`^I resolve the artifact repository of "([^"]*)"$`, tc.resolveArtifactRepository)
+	ctx.Step(`^an MLflow client with token "([^"]*)" for an artifact proxy recording authorization$`, tc.clientWithTokenForAuthRecorder)
+	ctx.Step(`^I list the artifact repository of "([^"]*)"$`, tc.listRecordedArtifactRepository)
+	ctx.Step(`^the artifact proxy should have received token "([^"]*)"$`, tc.authRecorderShouldHaveReceivedToken)
+	ctx.Step(`^the artifact proxy should have received no token$`, tc.authRecorderShouldHaveReceivedNoToken)
+	ctx.Step(`^the artifact repository should be a (\w+) repository$`, tc.resolvedRepositoryShouldBe)
+	ctx.Step(`^the local artifact repository root should be "([^"]*)"$`, tc.localRepositoryRootShouldBe)
+	ctx.Step(`^an artifact repository on a (local directory|tracking server proxy|S3 bucket|MinIO bucket|Azure container|Azurite container)$`, tc.artifactRepositoryBackend)
+	ctx.Step(`^I upload "([^"]*)" to "([^"]*)" in the repository$`, tc.uploadToRepository)
+	ctx.Step(`^listing "([^"]*)" in the repository should give "([^"]*)"$`, tc.repositoryListingShouldBe)
+	ctx.Step(`^"([^"]*)" should be a (file|directory) in the repository$`, tc.repositoryStatShouldBe)
+	ctx.Step(`^"([^"]*)" should contain "([^"]*)" in the repository$`, tc.repositoryFileShouldContain)
+	ctx.Step(`^"([^"]*)" should not exist in the repository$`, tc.repositoryPathShouldNotExist)
+	ctx.Step(`^I delete "([^"]*)" from the repository$`, tc.deleteFromRepository)
+	ctx.Step(`^an experiment storing artifacts in a local directory exists$`, tc.experimentWithLocalArtifactStore)
+	ctx.Step(`^an experiment storing artifacts in a custom "memory" store exists$`, tc.experimentWithMemoryArtifactStore)
+	ctx.Step(`^the local artifact store should have file "([^"]*)" with "([^"]*)"$`, tc.localArtifactStoreShouldHaveFile)
+	ctx.Step(`^the local artifact store file "([^"]*)" should have mode "([^"]*)"$`, tc.localArtifactStoreFileShouldHaveMode)
+	ctx.Step(`^the run's artifact repository should be a (\w+) repository$`, tc.runArtifactRepositoryShouldBe)
+
+	// S3 steps
+	ctx.Step(`^I sign the AWS get-vanilla test request$`, tc.signAWSTestRequest)
+	ctx.Step(`^the Authorization header should be "([^"]*)"$`, tc.authorizationShouldBe)
+	ctx.Step(`^an S3 store$`, tc.s3Store)
+	ctx.Step(`^an S3 store with a self-signed certificate$`, tc.s3StoreWithSelfSignedCertificate)
+	ctx.Step(`^an S3 artifact repository with parts of (\d+) bytes, (\d+) at a time$`, tc.s3RepositoryWithParts)
+	ctx.Step(`^an S3 artifact repository with parts of (\d+) bytes, (\d+) at a time, encrypted with (a KMS key|a customer key)$`, tc.s3RepositoryWithEncryptedParts)
+	ctx.Step(`^the environment variable "([^"]*)" is "([^"]*)"$`, tc.environmentVariableIs)
+	ctx.Step(`^the environment variable "([^"]*)" is:$`, tc.environmentVariableIsDoc)
+	ctx.Step(`^AWS credentials from (.+)$`, tc.awsCredentialsFrom)
+	ctx.Step(`^a shared AWS profile using "([^"]*)"$`, tc.sharedProfileUsing)
+	ctx.Step(`^the S3 repository should have bucket "([^"]*)" and prefix "([^"]*)"$`, tc.s3RepositoryShouldHaveBucketAndPrefix)
+	ctx.Step(`^the S3 store should have seen access key "([^"]*)" and session token "([^"]*)"$`, tc.s3StoreShouldHaveSeen)
+	ctx.Step(`^the S3 store should have seen requests signed for region "([^"]*)"$`, tc.s3StoreShouldHaveSeenRegion)
+	ctx.Step(`^I attempt to upload "([^"]*)" to "([^"]*)" in the repository$`, tc.attemptUploadToRepository)
+	ctx.Step(`^I upload (\d+) random bytes to "([^"]*)" in the repository$`, tc.uploadRandomBytesToRepository)
+	ctx.Step(`^I upload (\d+) random bytes of (known|unknown) size to "([^"]*)" in the repository$`, tc.uploadRandomBytesOfSize)
+	ctx.Step(`^I attempt to upload (\d+) random bytes to "([^"]*)" in the repository$`, tc.attemptUploadRandomBytes)
+	ctx.Step(`^"([^"]*)" should download intact from the repository$`, tc.repositoryFileShouldDownloadIntact)
+	ctx.Step(`^the S3 store should have received (\d+) single uploads? and (\d+) parts?$`, tc.s3StoreShouldHaveReceived)
+	ctx.Step(`^the S3 store should have served (\d+) ranged requests?$`, tc.s3StoreShouldHaveServedRanges)
+	ctx.Step(`^S3 part (\d+) fails (\d+) times with status (\d+)$`, tc.s3PartFails)
+	ctx.Step(`^S3 part (\d+) always fails with status (\d+)$`, tc.s3PartAlwaysFails)
+	ctx.Step(`^S3 part (\d+) should have been attempted (\d+) times$`, tc.s3PartAttempted)
+	ctx.Step(`^the S3 store should have aborted (\d+) uploads? and have none open$`, tc.s3StoreShouldHaveAborted)
+	ctx.Step(`^the S3 object of "([^"]*)" should have header "([^"]*)" set to "([^"]*)"$`, tc.s3ObjectShouldHaveHeader)
+	ctx.Step(`^downloading "([^"]*)" without the customer key should fail with "([^"]*)"$`, tc.downloadWithoutCustomerKeyFails)
+	ctx.Step(`^an experiment storing artifacts in the S3 store exists$`, tc.experimentWithS3ArtifactStore)
+	ctx.Step(`^the S3 store should have run artifact "([^"]*)" with "([^"]*)"$`, tc.s3StoreShouldHaveRunArtifact)
+
+	// Azure steps
+	ctx.Step(`^I sign an Azure test request with Shared Key$`, tc.signAzureTestRequest)
+	ctx.Step(`^an Azure store$`, tc.azureStore)
+	ctx.Step(`^an Azure artifact repository with blocks of (\d+) bytes, (\d+) at a time$`, tc.azureRepositoryWithBlocks)
+	ctx.Step(`^Azure credentials from (.+)$`, tc.azureCredentialsFrom)
+	ctx.Step(`^I open the Azure repository of "([^"]*)" with the environment's credentials$`, tc.openAzureRepository)
+	ctx.Step(`^the Azure repository should have account "([^"]*)", container "([^"]*)" and prefix "([^"]*)"$`, tc.azureRepositoryShouldHave)
+	ctx.Step(`^the Azure store should have seen requests authorized with (Shared Key|a SAS token)$`, tc.azureStoreShouldHaveSeenAuth)
+	ctx.Step(`^the Azure store should have received (\d+) single uploads? and (\d+) blocks? in (\d+) block lists?$`, tc.azureStoreShouldHaveReceived)
+	ctx.Step(`^the Azure store should have served (\d+) ranged requests?$`, tc.azureStoreShouldHaveServedRanges)
+	ctx.Step(`^Azure block (\d+) fails (\d+) times with status (\d+)$`, tc.azureBlockFails)
+	ctx.Step(`^Azure block (\d+) always fails with status (\d+)$`, tc.azureBlockAlwaysFails)
+	ctx.Step(`^Azure block (\d+) should have been attempted (\d+) times$`, tc.azureBlockAttempted)
+	ctx.Step(`^uploading "([^"]*)" with the wrong account key should fail with "([^"]*)"$`, tc.uploadWithWrongKeyFails)
+	ctx.Step(`^an experiment storing artifacts in the Azure store exists$`, tc.experimentWithAzureArtifactStore)
+	ctx.Step(`^the Azure store should have run artifact "([^"]*)" with "([^"]*)"$`, tc.azureStoreShouldHaveRunArtifact)
+
+	// Artifact file system steps
+	ctx.Step(`^the tracking server lists artifacts in pages of (\d+)$`, tc.artifactsListedInPages)
+	ctx.Step(`^I open the run's artifact file system$`, tc.openArtifactFS)
+	ctx.Step(`^I open the run's artifact file system with a local cache$`, tc.openCachedArtifactFS)
+	ctx.Step(`^walking "([^"]*)" in the artifact file system should give "([^"]*)"$`, tc.walkingArtifactFSShouldGive)
+	ctx.Step(`^reading "([^"]*)" from the artifact file system should give "([^"]*)"$`, tc.readingArtifactFSShouldGive)
+	ctx.Step(`^"([^"]*)" should be a file of (\d+) bytes in the artifact file system$`, tc.artifactFSStatShouldBeFile)
+	ctx.Step(`^"([^"]*)" should be a directory in the artifact file system$`, tc.artifactFSStatShouldBeDir)
+	ctx.Step(`^opening "([^"]*)" in the artifact file system should (not exist|be invalid)$`, tc.artifactFSOpenShouldFail)
+	ctx.Step(`^I open "([^"]*)" in the artifact file system$`, tc.openInArtifactFS)
+	ctx.Step(`^reading the open artifact file should give "([^"]*)"$`, tc.readingOpenArtifactShouldGive)
+	ctx.Step(`^the tracking server should have served (\d+) artifact listings? and (\d+) downloads?$`, tc.trackingServerShouldHaveServed)
+	ctx.Step(`^the artifact file system should pass fstest with "([^"]*)"$`, tc.artifactFSShouldPassFSTest)
+	ctx.Step(`^the artifact cache should have "([^"]*)" for the run$`, tc.cacheShouldHaveArtifact)
+	ctx.Step(`^I serve the artifact file system over HTTP$`, tc.serveArtifactFSOverHTTP)
+	ctx.Step(`^a GET of "([^"]*)" should return (\d+) with "([^"]*)"$`, tc.httpGetShouldReturnContent)
+	ctx.Step(`^a GET of "([^"]*)" for bytes "([^"]*)" should return (\d+) with "([^"]*)"$`, tc.httpRangeGetShouldReturn)
+	ctx.Step(`^executing template "([^"]*)" parsed from "([^"]*)" in the artifact file system with "([^"]*)" should give "([^"]*)"$`, tc.executingArtifactTemplateShouldGive)
+
+	// Recursive artifact download steps
+	ctx.Step(`^I download the run's artifacts "([^"]*)" with (\d+) workers$`, tc.downloadRunArtifacts)
+	ctx.Step(`^I attempt to download the run's artifacts "([^"]*)"$`, tc.attemptDownloadRunArtifacts)
+	ctx.Step(`^I attempt to download the run's artifacts "([^"]*)" with a cancelled context$`, tc.attemptDownloadRunArtifactsCancelled)
+	ctx.Step(`^the tracking server reports "([^"]*)" as (\d+) bytes$`, tc.trackingServerReportsSize)
+	ctx.Step(`^I change the downloaded file "([^"]*)" to "([^"]*)"$`, tc.changeDownloadedFile)
+	ctx.Step(`^the manifest should list "([^"]*)"$`, tc.manifestShouldList)
+	ctx.Step(`^the manifest should count (\d+) downloaded files? of (\d+) bytes$`, tc.manifestShouldCount)
+	ctx.Step(`^the manifest's local path should be "([^"]*)"$`, tc.manifestLocalPathShouldBe)
+	ctx.Step(`^the download directory should have no "([^"]*)"$`, tc.downloadDirShouldHaveNoFile)
+
+	// Content-addressed artifact steps
+	ctx.Step(`^I log the local directory content-addressed to artifact directory "([^"]*)"$`, tc.logLocalDirectoryContentAddressed)
+	ctx.Step(`^I log the local file "([^"]*)" content-addressed to artifact directory "([^"]*)"$`, tc.logLocalFileContentAddressed)
+	ctx.Step(`^a second run in the experiment logs the local directory content-addressed to artifact directory "([^"]*)"$`, tc.secondRunLogsContentAddressed)
+	ctx.Step(`^a run of a second experiment with the same artifact location logs the local directory content-addressed to artifact directory "([^"]*)"$`, tc.otherExperimentRunLogsContentAddressed)
+	ctx.Step(`^the second run logs the local file "([^"]*)" content-addressed to artifact directory "([^"]*)"$`, tc.secondRunLogsFileContentAddressed)
+	ctx.Step(`^I delete "([^"]*)" from the second run's artifacts$`, tc.deleteSecondRunArtifacts)
+	ctx.Step(`^the experiment's blob store should hold (\d+) blobs?$`, tc.blobStoreShouldHold)
+	ctx.Step(`^the tracking server should have received (\d+) blob uploads?$`, tc.trackingServerShouldHaveReceivedBlobs)
+	ctx.Step(`^the run should store pointer files for "([^"]*)" under "([^"]*)"$`, tc.runShouldStorePointerFiles)
+	ctx.Step(`^the blob of "([^"]*)" is overwritten with "([^"]*)"$`, tc.overwriteBlob)
+	ctx.Step(`^the pointer file of "([^"]*)" names a blob store on another host holding "([^"]*)"$`, tc.pointerToOtherHost)
+	ctx.Step(`^the other host should have received no requests$`, tc.otherHostShouldHaveReceivedNoRequests)
+	ctx.Step(`^I collect the experiment's artifact blobs( as a dry run)?$`, tc.collectArtifactBlobs)
+	ctx.Step(`^the collection should have found (\d+) unreferenced blobs? of (\d+) bytes and (\d+) referenced$`, tc.collectionShouldHaveFound)
+	ctx.Step(`^reading "([^"]*)" of the second run should fail with "([^"]*)"$`, tc.readingSecondRunArtifactShouldFail)
+
+	// Artifact encryption steps
+	ctx.Step(`^artifact encryption uses a key file$`, tc.encryptionUsesKeyFile)
+	ctx.Step(`^artifact encryption rotates to a new key file$`, tc.encryptionRotatesKeyFile)
+	ctx.Step(`^artifact encryption uses only the newest key file$`, tc.encryptionUsesOnlyNewestKeyFile)
+	ctx.Step(`^artifact encryption uses the key in "([^"]*)"$`, tc.encryptionUsesEnvKey)
+	ctx.Step(`^artifact encryption uses KMS key "([^"]*)"$`, tc.encryptionUsesKMSKey)
+	ctx.Step(`^KMS key "([^"]*)" is disabled$`, tc.kmsKeyDisabled)
+	ctx.Step(`^artifact encryption is turned off$`, tc.encryptionTurnedOff)
+	ctx.Step(`^a local file "([^"]*)" of (\d+) random bytes$`, tc.localFileOfRandomBytes)
+	ctx.Step(`^I log the local directory encrypted to artifact directory "([^"]*)"$`, tc.logLocalDirectoryEncrypted)
+	ctx.Step(`^I log the local file "([^"]*)" encrypted to artifact directory "([^"]*)"$`, tc.logLocalFileEncrypted)
+	ctx.Step(`^I attempt to log the local directory encrypted to artifact directory "([^"]*)"$`, tc.attemptLogLocalDirectoryEncrypted)
+	ctx.Step(`^the artifact "([^"]*)" should match the local file "([^"]*)"$`, tc.artifactShouldMatchLocalFile)
+	ctx.Step(`^the run should store "([^"]*)" encrypted without "([^"]*)"$`, tc.runShouldStoreEncrypted)
+	ctx.Step(`^the stored ciphertext of "([^"]*)" is (altered|truncated)$`, tc.tamperWithCiphertext)
+	ctx.Step(`^the encryption sidecar of "([^"]*)" gives a segment size of (-?\d+) bytes$`, tc.sidecarSegmentSize)
+	ctx.Step(`^the encrypted artifact "([^"]*)" is copied to "([^"]*)"( of a second run)?$`, tc.copyEncryptedArtifact)
+	ctx.Step(`^only the (ciphertext|sidecar) of "([^"]*)" is copied to "([^"]*)"$`, tc.interruptedUpload)
+	ctx.Step(`^I note the stored ciphertext of "([^"]*)"$`, tc.noteCiphertext)
+	ctx.Step(`^the stored ciphertext of "([^"]*)" should be unchanged$`, tc.ciphertextShouldBeUnchanged)
+	ctx.Step(`^I rotate the run's artifact keys$`, tc.rotateArtifactKeys)
+	ctx.Step(`^(\d+) artifacts? should have been re-wrapped$`, tc.rotatedKeysShouldBe)
+
+	// Model signing steps
+	ctx.Step(`^a signing key for "([^"]*)"$`, tc.signingKeyFor)
+	ctx.Step(`^a signing key for "([^"]*)" held by a signing service$`, tc.remoteSigningKeyFor)
+	ctx.Step(`^the signing service of "([^"]*)" is down$`, tc.signingServiceDown)
+	ctx.Step(`^a version of the model registers the run's artifacts "([^"]*)" by (run URI|artifact URI)$`, tc.modelVersionOfRunArtifacts)
+	ctx.Step(`^I sign the model version as "([^"]*)"$`, tc.signModelVersion)
+	ctx.Step(`^I attempt to sign the model version as "([^"]*)"$`, tc.attemptSignModelVersion)
+	ctx.Step(`^I verify the model version trusting "([^"]*)"$`, tc.verifyModelVersion)
+	ctx.Step(`^I attempt to verify the model version trusting "([^"]*)"$`, tc.attemptVerifyModelVersion)
+	ctx.Step(`^I attempt to verify the model version into a directory holding "([^"]*)" trusting "([^"]*)"$`, tc.attemptVerifyModelVersionIntoDirectoryHolding)
+	ctx.Step(`^the verified files should be in the download directory$`, tc.verifiedFilesShouldBeDownloaded)
+	ctx.Step(`^the download directory should be empty$`, tc.downloadDirectoryShouldBeEmpty)
+	ctx.Step(`^the signed manifest should list "([^"]*)"$`, tc.signedManifestShouldList)
+	ctx.Step(`^the model version should be signed by "([^"]*)"$`, tc.modelVersionShouldBeSignedBy)
+	ctx.Step(`^the run's artifact "([^"]*)" is replaced with "([^"]*)"$`, tc.replaceRunArtifact)
+	ctx.Step(`^the run's artifact "([^"]*)" is deleted$`, tc.deleteRunArtifact)
+
 	// Other steps
 	ctx.Step(`^fix this step$`, tc.fixThisStep)
 }
@@ -0,0 +1,121 @@
+package features
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/julpayne/mlflow-go-client/pkg/mlflow"
+)
+
+// System metrics step implementations
+
+func (tc *testContext) startSystemMetricsOnActiveRun(interval int) error {
+	return tc.activeRun.StartSystemMetrics(mlflow.SystemMetricsOptions{
+		SamplingInterval: time.Duration(interval) * time.Millisecond,
+	})
+}
+
+func (tc *testContext) attemptStartSystemMetricsOnActiveRun() error {
+	tc.lastError = tc.activeRun.StartSystemMetrics(mlflow.SystemMetricsOptions{})
+	return nil
+}
+
+func (tc *testContext) waitMilliseconds(ms int) error {
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+func (tc *testContext) endActiveRun(status string) error {
+	return tc.activeRun.End(mlflow.RunStatus(status))
+}
+
+func (tc *testContext) activeRunHasStatus(status string) error {
+	run, err := tc.activeRun.Run()
+	if err != nil {
+		return err
+	}
+	if run.Info.Status != mlflow.RunStatus(status) {
+		return fmt.Errorf("expected status %s, got %s", status, run.Info.Status)
+	}
+	return nil
+}
+
+func (tc *testContext) metricHistoryOfActiveRun(key string) ([]mlflow.Metric, error) {
+	resp, err := tc.client.GetMetricHistory(mlflow.GetMetricHistoryRequest{RunID: tc.activeRun.RunID, MetricKey: key, MaxResults: 1000})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Metrics, nil
+}
+
+func (tc *testContext) activeRunHasMetricsLoggedAtLeast(key string, count int) error {
+	history, err := tc.metricHistoryOfActiveRun(key)
+	if err != nil {
+		return err
+	}
+	if len(history) < count {
+		return fmt.Errorf("expected %s to be logged at least %d times, got %d", key, count, len(history))
+	}
+	for i, m := range history {
+		if m.Step != int64(i) {
+			return fmt.Errorf("expected %s to be logged at steps 0..%d, got step %d at %d", key, len(history)-1, m.Step, i)
+		}
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunHasMetricLoggedOnce(key string) error {
+	history, err := tc.metricHistoryOfActiveRun(key)
+	if err != nil {
+		return err
+	}
+	if len(history) != 1 {
+		return fmt.Errorf("expected %s to be logged once, got %d", key, len(history))
+	}
+	return nil
+}
+
+func (tc *testContext) activeRunHasPositiveMetric(key string) error {
+	run, err := tc.activeRun.Run()
+	if err != nil {
+		return err
+	}
+	if value, ok := run.Metric(key); !ok || value <= 0 {
+		return fmt.Errorf("expected a positive %s, got %v (present: %v)", key, value, ok)
+	}
+	return nil
+}
+
+func (tc *testContext) attemptStartSystemMetrics(runID string, interval int) error {
+	_, tc.lastError = tc.client.StartSystemMetrics(runID, mlflow.SystemMetricsOptions{
+		SamplingInterval: time.Duration(interval) * time.Millisecond,
+	})
+	return nil
+}
+
+func (tc *testContext) runSystemMetricsMonitor(runID string, ms int) error {
+	tc.monitorErrors = nil
+	monitor, err := tc.client.StartSystemMetrics(runID, mlflow.SystemMetricsOptions{
+		SamplingInterval: 10 * time.Millisecond,
+		OnError:          func(err error) { tc.monitorErrors = append(tc.monitorErrors, err) },
+	})
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	tc.lastError = monitor.Stop()
+	if again := monitor.Stop(); again != tc.lastError {
+		return fmt.Errorf("expected stopping again to return the same error, got %v", again)
+	}
+	return nil
+}
+
+func (tc *testContext) monitorReportedError(text string) error {
+	for _, err := range tc.monitorErrors {
+		if strings.Contains(err.Error(), text) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected an error containing %q to be reported, got %v", text, tc.monitorErrors)
+}